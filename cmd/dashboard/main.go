@@ -19,14 +19,18 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
 	dashboardclientset "github.com/tektoncd/dashboard/pkg/client/clientset/versioned"
 	"github.com/tektoncd/dashboard/pkg/controllers"
 	"github.com/tektoncd/dashboard/pkg/csrf"
 	"github.com/tektoncd/dashboard/pkg/endpoints"
 	"github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/redact"
 	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/websocket"
 	"k8s.io/client-go/dynamic"
 	k8sclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -40,14 +44,38 @@ var (
 	triggersNamespace  = flag.String("triggers-namespace", "", "Namespace where Tekton triggers is installed (assumes same namespace as dashboard if not specified)")
 	kubeConfigPath     = flag.String("kube-config", "", "Path to kube config file")
 	portNumber         = flag.Int("port", 8080, "Dashboard port number")
+	websocketPort      = flag.Int("websocket-port", 0, "If set, serves the websocket and event stream endpoints on this port instead of the dashboard port")
 	readOnly           = flag.Bool("read-only", false, "Enable or disable read only mode")
 	isOpenshift        = flag.Bool("openshift", false, "Indicates the dashboard is running on openshift")
 	logoutUrl          = flag.String("logout-url", "", "If set, enables logout on the frontend and binds the logout button to this url")
 	tenantNamespace    = flag.String("namespace", "", "If set, limits the scope of resources watched to this namespace only")
+	deniedNamespaces   = flag.String("denied-namespaces", "", "Comma separated namespaces, such as kube-system, hidden from the dashboard. Their resources cannot be accessed and their events are not sent")
 	logLevel           = flag.String("log-level", "info", "Minimum log level output by the logger")
 	logFormat          = flag.String("log-format", "json", "Format for log output (json or console)")
 	streamLogs         = flag.Bool("stream-logs", false, "Enable log streaming instead of polling")
 	externalLogs       = flag.String("external-logs", "", "External logs provider url")
+	wsReadBufferSize   = flag.Int("websocket-read-buffer-size", websocket.DefaultReadBufferSize, "Size in bytes of the read buffer allocated to each websocket connection. Memory use grows with this value times the number of connected clients")
+	wsWriteBufferSize  = flag.Int("websocket-write-buffer-size", websocket.DefaultWriteBufferSize, "Size in bytes of the write buffer allocated to each websocket connection. Frames larger than the buffer need several writes, memory use grows with this value times the number of connected clients")
+	wsMaxClients       = flag.Int("websocket-max-clients", 0, "Maximum number of websocket clients, low priority clients are disconnected first when exceeded (0 for unlimited)")
+	wsBufferSize       = flag.Int("websocket-buffer-size", 0, "Number of events buffered for each websocket client so slow clients do not hold up others (0 to wait for every client)")
+	wsOverflowPolicy   = flag.String("websocket-overflow-policy", string(broadcaster.DropOldest), "What to do when a websocket client's buffer is full: drop-oldest, drop-newest or disconnect, unless the client chooses with its overflow parameter")
+	wsSendTimeout      = flag.Duration("websocket-send-timeout", broadcaster.DefaultSendTimeout, "How long a websocket client may go without reading events, or with its buffer full, before it is disconnected as too slow (0 to wait forever)")
+	wsPingInterval     = flag.Duration("websocket-ping-interval", websocket.DefaultPingInterval, "How often websocket clients are pinged to detect lost connections")
+	wsPongWait         = flag.Duration("websocket-pong-wait", websocket.DefaultPongWait, "How long a websocket client may go without answering pings before its connection is closed as lost, must be longer than websocket-ping-interval")
+	wsReplaySize       = flag.Int("websocket-replay-size", broadcaster.DefaultReplaySize, "Number of the latest events kept so websocket clients reconnecting with since can catch up on those they missed (0 to keep none)")
+	wsFanOutWorkers    = flag.Int("websocket-fan-out-workers", broadcaster.DefaultFanOutWorkers, "Number of goroutines delivering resource events to websocket clients, each to its own share of them so a slow client only holds up the clients of its worker")
+	wsMaxObjectSize    = flag.Int("websocket-max-object-size", 0, "Size in bytes above which resources are sent over websockets as a truncated reference to fetch with the REST API instead of in full (0 for unlimited)")
+	logCacheSize       = flag.Int64("log-cache-size", 0, "Maximum size in bytes of the logs of completed TaskRuns cached so they can be served once pods are gone. Logs are cached in memory unless log-cache-dir is set (0 disables the memory cache, or does not limit the directory)")
+	logCacheDir        = flag.String("log-cache-dir", "", "If set, caches the logs of completed TaskRuns in this directory")
+	maxLogStreams      = flag.Int("max-log-streams", 0, "Maximum number of pod log streams open at the same time, further log requests get HTTP code 429 (0 for unlimited)")
+	tenantID           = flag.String("tenant-id", "", "If set, included as Tenant in every websocket resource event so frontends proxying several clusters can tell where events come from")
+	eventWebhookURL    = flag.String("event-webhook-url", "", "If set, every resource event is also POSTed as JSON to this URL, failed deliveries are retried with backoff")
+	informerEventRate  = flag.Int("informer-max-events-per-second", 0, "Maximum number of events of each kind processed per second, above it only the latest state of each object is sent and a ResyncRequired event tells clients to list the kind again (0 for unlimited)")
+	informerResyncs    = flag.Bool("informer-send-resyncs", false, "Send the Updated events of periodic informer resyncs, which leave objects unchanged, with FromResync set instead of dropping them")
+	redactPaths        = flag.String("redact-paths", "", "Comma separated paths of fields, such as spec.steps.env.value, whose values are replaced with [REDACTED] in websocket events and API responses")
+	tektonVersion      = flag.String("tekton-api-version", "", "Tekton Pipelines API version (v1beta1 or v1) used by all handlers and informers, checked against the cluster at startup. Defaults to the version preferred by the cluster")
+	pprofPort          = flag.Int("pprof-port", 0, "If set, serves the pprof profiling endpoints under /debug/pprof/ on this port of localhost only, never on the dashboard port")
+	authorizeUsers     = flag.Bool("authorize-users", false, "Check the access of the user set in the X-Forwarded-User header with a SubjectAccessReview before serving API requests")
 )
 
 func main() {
@@ -101,16 +129,40 @@ func main() {
 		logging.Log.Errorf("Error building rest transport: %s", err.Error())
 	}
 
+	resolvedTektonVersion, err := endpoints.ResolveTektonVersion(k8sClient.Discovery(), *tektonVersion)
+	if err != nil {
+		logging.Log.Fatal(err)
+	}
+	logging.Log.Infof("Using Tekton API version %s", resolvedTektonVersion)
+
+	customRunGVR, isCustomRunSupported := endpoints.DetectCustomRunGVR(k8sClient.Discovery())
+	stepActionGVR, isStepActionSupported := endpoints.DetectStepActionGVR(k8sClient.Discovery())
+
+	var denied []string
+	for _, namespace := range strings.Split(*deniedNamespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			denied = append(denied, namespace)
+		}
+	}
+
 	options := endpoints.Options{
 		InstallNamespace:   installNamespace,
 		PipelinesNamespace: *pipelinesNamespace,
 		TriggersNamespace:  *triggersNamespace,
 		TenantNamespace:    *tenantNamespace,
+		DeniedNamespaces:   denied,
 		ReadOnly:           *readOnly,
 		IsOpenShift:        *isOpenshift,
 		LogoutURL:          *logoutUrl,
 		StreamLogs:         *streamLogs,
 		ExternalLogsURL:    *externalLogs,
+		TektonVersion:      resolvedTektonVersion,
+		CustomRunGVR:       customRunGVR,
+		StepActionGVR:      stepActionGVR,
+		WebsocketPort:      *websocketPort,
+
+		WebsocketReadBufferSize:  *wsReadBufferSize,
+		WebsocketWriteBufferSize: *wsWriteBufferSize,
 	}
 
 	resource := endpoints.Resource{
@@ -121,42 +173,107 @@ func main() {
 		K8sClient:       k8sClient,
 		Options:         options,
 	}
+	if *authorizeUsers {
+		resource.Authorizer = endpoints.SubjectAccessReviewAuthorizer{Client: k8sClient, Options: options}
+	}
 
 	isTriggersInstalled := endpoints.IsTriggersInstalled(resource, *triggersNamespace)
 
-	ctx := signals.NewContext()
+	endpoints.ResourcesBroadcaster.SetPoolLimit(*wsMaxClients)
+	overflowPolicy, err := broadcaster.ParseOverflowPolicy(*wsOverflowPolicy)
+	if err != nil {
+		logging.Log.Fatal(err)
+	}
+	endpoints.ResourcesBroadcaster.SetClientBuffer(*wsBufferSize, overflowPolicy)
+	endpoints.ResourcesBroadcaster.SetSendTimeout(*wsSendTimeout)
+	endpoints.ResourcesBroadcaster.SetReplaySize(*wsReplaySize)
+	if *wsFanOutWorkers < 1 {
+		logging.Log.Fatalf("websocket-fan-out-workers %d must be at least 1", *wsFanOutWorkers)
+	}
+	endpoints.ResourcesBroadcaster.SetFanOutWorkers(*wsFanOutWorkers)
+	if *wsPingInterval <= 0 || *wsPongWait <= *wsPingInterval {
+		logging.Log.Fatalf("websocket-pong-wait %s must be longer than websocket-ping-interval %s, which must be positive", *wsPongWait, *wsPingInterval)
+	}
+	websocket.SetKeepalive(*wsPingInterval, *wsPongWait)
+	endpoints.ResourcesBroadcaster.SetMaxObjectSize(*wsMaxObjectSize)
+	if *logCacheDir != "" {
+		if resource.LogCache, err = endpoints.NewDirectoryLogCache(*logCacheDir, *logCacheSize); err != nil {
+			logging.Log.Fatalf("Error creating log cache: %s", err)
+		}
+	} else if *logCacheSize > 0 {
+		resource.LogCache = endpoints.NewMemoryLogCache(*logCacheSize)
+	}
 
-	routerHandler := router.Register(resource)
+	endpoints.ResourcesBroadcaster.SetTenant(*tenantID)
+	if *redactPaths != "" {
+		resource.Redactor = redact.NewRedactor(strings.Split(*redactPaths, ","))
+		endpoints.ResourcesBroadcaster.SetRedactor(resource.Redactor)
+	}
+	endpoints.LogStreams.SetLimit(*maxLogStreams)
+
+	ctx := signals.NewContext()
 
 	logging.Log.Info("Creating controllers")
+	controllers.SetMaxEventRate(*informerEventRate)
+	controllers.SetSendResyncs(*informerResyncs)
+	controllers.SetDeniedNamespaces(denied)
 	resyncDur := time.Second * 30
-	controllers.StartTektonControllers(resource.DynamicClient, resyncDur, *tenantNamespace, ctx.Done())
+	// The informer caches are served by the API so must be set before routing
+	resource.Listers = controllers.StartTektonControllers(resource.DynamicClient, resyncDur, *tenantNamespace, resolvedTektonVersion, ctx.Done())
+	resource.PipelineRunLister = resource.Listers["PipelineRun"]
+	if isCustomRunSupported {
+		resource.Listers["CustomRun"] = controllers.StartCustomRunController(resource.DynamicClient, resyncDur, *tenantNamespace, customRunGVR, ctx.Done())
+	}
+	if isStepActionSupported {
+		resource.Listers["StepAction"] = controllers.StartStepActionController(resource.DynamicClient, resyncDur, *tenantNamespace, stepActionGVR, ctx.Done())
+	}
+	if isTriggersInstalled {
+		for kind, lister := range controllers.StartTriggersControllers(resource.DynamicClient, resyncDur, *tenantNamespace, ctx.Done()) {
+			resource.Listers[kind] = lister
+		}
+	}
+	routerHandler := router.Register(resource)
 	controllers.StartKubeControllers(resource.K8sClient, resyncDur, *tenantNamespace, *readOnly, routerHandler, ctx.Done())
 	controllers.StartDashboardControllers(resource.DashboardClient, resyncDur, *tenantNamespace, ctx.Done())
 
-	if isTriggersInstalled {
-		controllers.StartTriggersControllers(resource.DynamicClient, resyncDur, *tenantNamespace, ctx.Done())
+	if resource.LogCache != nil {
+		go resource.CacheCompletedLogs(ctx.Done())
+	}
+	endpoints.ObservePipelineRuns(ctx.Done())
+	if *eventWebhookURL != "" {
+		go broadcaster.Forward[broadcaster.SocketData](endpoints.ResourcesBroadcaster.Broadcaster, broadcaster.NewWebhookSink(*eventWebhookURL), ctx.Done())
 	}
 
 	logging.Log.Infof("Creating server and entering wait loop")
 	CSRF := csrf.Protect()
-	server := &http.Server{Addr: fmt.Sprintf(":%d", *portNumber), Handler: CSRF(routerHandler)}
-
-	errCh := make(chan error, 1)
-	defer close(errCh)
-	go func() {
-		// Don't forward ErrServerClosed as that indicates we're already shutting down.
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errCh <- fmt.Errorf("dashboard server failed: %w", err)
-		}
-	}()
+	servers := []*http.Server{{Addr: fmt.Sprintf(":%d", *portNumber), Handler: CSRF(routerHandler)}}
+	if *websocketPort != 0 {
+		servers = append(servers, &http.Server{Addr: fmt.Sprintf(":%d", *websocketPort), Handler: CSRF(router.RegisterWebsockets(resource))})
+	}
+	if *pprofPort != 0 {
+		servers = append(servers, &http.Server{Addr: fmt.Sprintf("localhost:%d", *pprofPort), Handler: router.RegisterProfiling()})
+	}
+
+	// Buffered so a server failing during shutdown never blocks, the channel
+	// is left for the garbage collector rather than closed under a sender
+	errCh := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			// Don't forward ErrServerClosed as that indicates we're already shutting down.
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("dashboard server on %s failed: %w", server.Addr, err)
+			}
+		}(server)
+	}
 
 	select {
 	case err := <-errCh:
 		logging.Log.Fatal(err)
 	case <-ctx.Done():
-		if err := server.Shutdown(context.Background()); err != nil {
-			logging.Log.Fatal(err)
+		for _, server := range servers {
+			if err := server.Shutdown(context.Background()); err != nil {
+				logging.Log.Fatal(err)
+			}
 		}
 	}
 }
@@ -1,6 +1,6 @@
 module github.com/tektoncd/dashboard
 
-go 1.15
+go 1.18
 
 // Pin k8s deps to 1.16.5
 replace (
@@ -39,4 +39,5 @@ require (
 	k8s.io/code-generator v0.18.0
 	k8s.io/utils v0.0.0-20200324210504-a9aa75ae1b89 // indirect
 	knative.dev/pkg v0.0.0-20200702222342-ea4d6e985ba0
+	sigs.k8s.io/yaml v1.2.0
 )
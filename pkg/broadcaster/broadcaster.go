@@ -15,7 +15,11 @@ package broadcaster
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type MessageType string
@@ -44,6 +48,12 @@ const (
 	TaskRunCreated               MessageType = "TaskRunCreated"
 	TaskRunDeleted               MessageType = "TaskRunDeleted"
 	TaskRunUpdated               MessageType = "TaskRunUpdated"
+	CustomRunCreated             MessageType = "CustomRunCreated"
+	CustomRunDeleted             MessageType = "CustomRunDeleted"
+	CustomRunUpdated             MessageType = "CustomRunUpdated"
+	StepActionCreated            MessageType = "StepActionCreated"
+	StepActionDeleted            MessageType = "StepActionDeleted"
+	StepActionUpdated            MessageType = "StepActionUpdated"
 	ConditionCreated             MessageType = "ConditionCreated"
 	ConditionDeleted             MessageType = "ConditionDeleted"
 	ConditionUpdated             MessageType = "ConditionUpdated"
@@ -68,73 +78,414 @@ const (
 	EventListenerCreated         MessageType = "EventListenerCreated"
 	EventListenerDeleted         MessageType = "EventListenerDeleted"
 	EventListenerUpdated         MessageType = "EventListenerUpdated"
+	RunStats                     MessageType = "RunStats"
+	StepCompleted                MessageType = "StepCompleted"
+	RunCompleted                 MessageType = "RunCompleted"
+	ControlError                 MessageType = "ControlError"
+	MaxEventsReached             MessageType = "MaxEventsReached"
+	CompactionComplete           MessageType = "CompactionComplete"
+	LogStreamsCapped             MessageType = "LogStreamsCapped"
+	EventsDropped                MessageType = "EventsDropped"
 )
 
 type SocketData struct {
 	MessageType MessageType
 	Payload     interface{}
+	// Identifies the dashboard the message comes from when frontends
+	// multiplex several of them, empty unless set with SetTenant
+	Tenant string `json:",omitempty"`
+	// Set when the Payload is an ObjectReference to an object too large to
+	// broadcast, see SetMaxObjectSize
+	Truncated bool `json:",omitempty"`
+	// Seconds between the start and completion of a completed run, set for
+	// subscribers enriching run events
+	Duration *float64 `json:",omitempty"`
+	// Seconds since the start of a running run, set for subscribers enriching
+	// run events
+	Elapsed *float64 `json:",omitempty"`
+	// Set when the Payload could not be converted to the API version a
+	// subscriber asked for and is in the version it was received in
+	Unconverted bool `json:",omitempty"`
+	// Set on Updated events sent for a periodic informer resync rather than
+	// a change of the object, see controllers.SetSendResyncs
+	FromResync bool `json:",omitempty"`
+	// Set when the Payload is a delta from the previous state of the object
+	// instead of the object, for subscribers asking for deltas
+	Delta bool `json:",omitempty"`
+	// The object before an Updated event, set by the controllers so
+	// subscribers can be sent deltas. It is never sent
+	Previous interface{} `json:"-"`
+	// Increases with each message broadcast, subscribers resume from the
+	// last one they received, see WithReplay
+	Sequence uint64 `json:",omitempty"`
+	// The Payload as a delta from Previous, computed once per message for
+	// the subscribers asking for deltas, see SetDeltaFunc. It is never sent
+	DeltaPayload interface{} `json:"-"`
 }
 
-// Only a pointer to the struct should be used
-type Broadcaster struct {
+// Priority decides which subscribers are evicted first when the pool is over its limit
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+)
+
+// OverflowPolicy decides what happens to a message when a buffered
+// subscriber's buffer is full
+type OverflowPolicy string
+
+const (
+	// DropOldest discards the oldest queued message to make room
+	DropOldest OverflowPolicy = "drop-oldest"
+	// DropNewest discards the message being sent
+	DropNewest OverflowPolicy = "drop-newest"
+	// Disconnect evicts the subscriber
+	Disconnect OverflowPolicy = "disconnect"
+)
+
+// ParseOverflowPolicy validates an overflow policy name
+func ParseOverflowPolicy(policy string) (OverflowPolicy, error) {
+	switch OverflowPolicy(policy) {
+	case DropOldest, DropNewest, Disconnect:
+		return OverflowPolicy(policy), nil
+	}
+	return "", fmt.Errorf("invalid overflow policy '%s', must be one of %s, %s or %s", policy, DropOldest, DropNewest, Disconnect)
+}
+
+// DefaultSendTimeout is how long a message waits for a subscriber that is not
+// reading before the subscriber is evicted as too slow, see SetSendTimeout
+const DefaultSendTimeout = 30 * time.Second
+
+// Values of Subscriber.evicted
+const (
+	notEvicted int32 = iota
+	evictedOverLimit
+	evictedTooSlow
+	evictedOverflowed
+)
+
+// Filter decides whether a message is delivered to a subscriber
+type Filter[T any] func(T) bool
+
+// Transform changes a message before it is delivered to a subscriber, it must
+// not modify the payload which is shared by all subscribers
+type Transform[T any] func(T) T
+
+// Messages builds the messages of type T a Broadcaster sends besides those it
+// receives, and numbers the messages it receives. A nil Messages leaves
+// messages unnumbered and ends initial messages with the zero value of T
+type Messages[T any] interface {
+	// Numbered returns the message with the sequence number it was given,
+	// see WithReplay
+	Numbered(msg T, sequence uint64) T
+	// ReplayComplete follows the replayed messages of a subscriber
+	ReplayComplete(replayed int) T
+	// ReplayUnavailable is sent instead of replaying when the messages after
+	// the sequence number are no longer kept
+	ReplayUnavailable(oldest uint64) T
+	// CompactionComplete follows the compacted messages of a subscriber
+	CompactionComplete(compacted int) T
+}
+
+// compactor keeps the messages bringing subscribers created WithCompacted up
+// to date, such as an EventCache
+type compactor[T any] interface {
+	add(msg T)
+	lock()
+	unlock()
+	// compactedLocked returns the messages accepted by accepts, the compactor
+	// must be locked
+	compactedLocked(accepts func(T) bool) []T
+}
+
+// Broadcaster delivers the messages of type T received on its channel to its
+// subscribers. Only a pointer to the struct should be used
+type Broadcaster[T any] struct {
 	expired bool
 	// Explicit name to specify locking condition
 	expiredLock sync.Mutex
-	subscribers *sync.Map //map[*Subscriber]struct{}
-	c           chan SocketData
+	subscribers *sync.Map //map[*Subscriber[T]]struct{}
+	c           chan T
+	// Maximum number of subscribers, 0 means unlimited. Guarded by expiredLock
+	poolLimit int
+	// Incremented for each subscription to order subscribers
+	subscriptions uint64
+	// Buffer given to new subscribers, 0 holds up the fan-out worker of each
+	// subscriber until it has received the message. Guarded by expiredLock
+	bufferSize int
+	overflow   OverflowPolicy
+	// How long a subscriber may hold up a message before it is evicted, 0
+	// waits forever. Guarded by expiredLock
+	sendTimeout time.Duration
+	// Changes each message before it is numbered, nil leaves them as they
+	// are. Set on creation
+	prepare func(T) T
+	// Numbers messages and builds those ending initial messages, may be nil
+	messages Messages[T]
+	// Caches messages for compacted subscriptions, set before broadcasting
+	events compactor[T]
+	// Numbers messages and keeps the latest ones for subscribers resuming
+	replay *replayBuffer[T]
+	// The subscribers of each fan-out worker, a subscriber is in the shard
+	// of its sequence modulo the number of workers. Set before broadcasting,
+	// guarded by expiredLock
+	shards []*sync.Map //map[*Subscriber[T]]struct{}
 }
 
 // Wrapper return type for subscriptions
-type Subscriber struct {
-	subChan   chan SocketData
+type Subscriber[T any] struct {
+	// dropped counts the messages discarded by the overflow policy, first
+	// so atomic operations on it are 64-bit aligned
+	dropped uint64
+
+	subChan   chan T
 	unsubChan chan struct{}
+	priority  Priority
+	sequence  uint64
+	evicted   int32
+	filters   []Filter[T]
+	overflow  OverflowPolicy
+	maxEvents int
+	compacted bool
+	// initial are the compacted events to send before live ones
+	initial []T
+	// transforms are applied in order to accepted messages
+	transforms []Transform[T]
+	// filter is replaced while subscribed, guarded by filterMutex
+	filter      Filter[T]
+	filterMutex sync.RWMutex
+	// sendTimeout and fullSince are only used by the fan-out worker of the
+	// subscriber, fullSince is when the buffer was last found full since it
+	// had room
+	sendTimeout time.Duration
+	fullSince   time.Time
+	// replay subscribers start with the messages after replaySince, live
+	// messages up to replayedTo are skipped as they were replayed
+	replay      bool
+	replaySince uint64
+	replayedTo  uint64
+	// initialComplete is sent after the initial messages
+	initialComplete T
+}
+
+// SubscribeOption configures a subscription
+type SubscribeOption[T any] func(*Subscriber[T])
+
+// WithPriority sets the eviction priority of a subscriber, subscribers are
+// PriorityNormal by default
+func WithPriority[T any](priority Priority) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.priority = priority
+	}
+}
+
+// WithMaxEvents asks the writer of a subscriber to close its connection once
+// it has sent max messages, 0 means unlimited
+func WithMaxEvents[T any](max int) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.maxEvents = max
+	}
+}
+
+// WithFilter only delivers messages accepted by the filter. Filters are
+// evaluated at fan-out and a message must be accepted by all of them
+func WithFilter[T any](filter Filter[T]) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.filters = append(s.filters, filter)
+	}
+}
+
+// WithTransform changes the messages delivered to the subscriber, after they
+// are accepted by its filters
+func WithTransform[T any](transform Transform[T]) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.transforms = append(s.transforms, transform)
+	}
+}
+
+// WithBuffer gives the subscriber its own buffer of size messages and overflow
+// policy instead of the broadcaster's, so a slow consumer never holds up the
+// broadcast
+func WithBuffer[T any](size int, policy OverflowPolicy) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.subChan = make(chan T, size)
+		s.overflow = policy
+	}
+}
+
+// WithOverflow overrides the broadcaster's overflow policy for the subscriber,
+// it only applies to buffered subscribers
+func WithOverflow[T any](policy OverflowPolicy) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.overflow = policy
+	}
+}
+
+// WithSendTimeout overrides the broadcaster's send timeout for the subscriber,
+// 0 never evicts it for being slow. Long lived subscribers inside the server
+// that must see every message they can should disable it
+func WithSendTimeout[T any](timeout time.Duration) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.sendTimeout = timeout
+	}
+}
+
+// WithCompacted starts the subscription with the latest event of each object
+// from the broadcaster's EventCache, see Initial
+func WithCompacted[T any]() SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.compacted = true
+	}
+}
+
+// Initial returns the compacted events a subscriber created WithCompacted, or
+// the replayed messages of a subscriber created WithReplay, has to send before
+// its live events, followed by InitialComplete. No event broadcast after the
+// subscription is missing, though some may be both compacted and received
+// live. Replayed messages are never received live
+func (s *Subscriber[T]) Initial() []T {
+	return s.initial
+}
+
+// InitialComplete returns the message to send after the Initial ones, such as
+// CompactionComplete
+func (s *Subscriber[T]) InitialComplete() T {
+	return s.initialComplete
+}
+
+// MaxEvents returns the number of messages after which the subscriber's
+// connection is closed, 0 means unlimited
+func (s *Subscriber[T]) MaxEvents() int {
+	return s.maxEvents
+}
+
+// Priority returns the eviction priority of the subscriber
+func (s *Subscriber[T]) Priority() Priority {
+	return s.priority
+}
+
+// Evicted reports whether the subscriber was removed to bring the pool back
+// under its limit, for being too slow or by its overflow policy, rather than
+// unsubscribing
+func (s *Subscriber[T]) Evicted() bool {
+	return atomic.LoadInt32(&s.evicted) != notEvicted
+}
+
+// TooSlow reports whether the subscriber was evicted for not reading its
+// messages within the broadcaster's send timeout
+func (s *Subscriber[T]) TooSlow() bool {
+	return atomic.LoadInt32(&s.evicted) == evictedTooSlow
+}
+
+// Overflowed reports whether the subscriber was evicted by the Disconnect
+// overflow policy once its buffer was full
+func (s *Subscriber[T]) Overflowed() bool {
+	return atomic.LoadInt32(&s.evicted) == evictedOverflowed
+}
+
+// Dropped returns the number of messages discarded so far because the
+// subscriber's buffer was full
+func (s *Subscriber[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
 }
 
 // Read-Only access to the subscription channel
 // Open or nil, never closed
-func (s *Subscriber) SubChan() <-chan SocketData {
+func (s *Subscriber[T]) SubChan() <-chan T {
 	return s.subChan
 }
 
 // Closed on unsubscribe or when broadcast parent channel closes
-func (s *Subscriber) UnsubChan() <-chan struct{} {
+func (s *Subscriber[T]) UnsubChan() <-chan struct{} {
 	return s.unsubChan
 }
 
+// SetFilter replaces the filter applied on top of those given with WithFilter
+// for messages fanned out from now on, nil accepts all messages
+func (s *Subscriber[T]) SetFilter(filter Filter[T]) {
+	s.filterMutex.Lock()
+	defer s.filterMutex.Unlock()
+	s.filter = filter
+}
+
+// accepts reports whether the message passes all of the subscriber's filters
+func (s *Subscriber[T]) accepts(msg T) bool {
+	for _, filter := range s.filters {
+		if !filter(msg) {
+			return false
+		}
+	}
+	s.filterMutex.RLock()
+	filter := s.filter
+	s.filterMutex.RUnlock()
+	return filter == nil || filter(msg)
+}
+
+// transform applies the subscriber's transforms to a message it accepted
+func (s *Subscriber[T]) transform(msg T) T {
+	for _, transform := range s.transforms {
+		msg = transform(msg)
+	}
+	return msg
+}
+
 var expiredError error = errors.New("Broadcaster expired")
 
-// Creates broadcaster from channel parameter and immediately starts broadcasting
+// New creates a broadcaster of messages of type T from the channel parameter
+// and immediately starts broadcasting, numbering messages and ending initial
+// messages with messages, which may be nil.
 // Without any subscribers, received data will be discarded
 // Broadcaster should be the only channel reader
-func NewBroadcaster(c chan SocketData) *Broadcaster {
+func New[T any](c chan T, messages Messages[T]) *Broadcaster[T] {
+	return newBroadcaster(c, messages, nil)
+}
+
+// newBroadcaster creates a broadcaster changing each message with prepare,
+// which may be nil, before numbering it
+func newBroadcaster[T any](c chan T, messages Messages[T], prepare func(T) T) *Broadcaster[T] {
 	if c == nil {
 		panic("Channel passed cannot be nil")
 	}
 
-	b := &Broadcaster{subscribers: new(sync.Map)}
+	b := &Broadcaster[T]{subscribers: new(sync.Map), sendTimeout: DefaultSendTimeout, messages: messages, prepare: prepare, shards: newShards(DefaultFanOutWorkers)}
+	b.replay = newReplayBuffer(DefaultReplaySize, messages)
 	b.c = c
 	go func() {
+		var workers []chan numbered[T]
+		var fanOut sync.WaitGroup
 		for {
 			msg, channelOpen := <-b.c
 			if channelOpen {
-				b.subscribers.Range(func(key, value interface{}) bool {
-					subscriber := key.(*Subscriber)
-					select {
-					case subscriber.subChan <- msg:
-					case <-subscriber.unsubChan:
-					}
-					return true
-				})
+				if b.prepare != nil {
+					msg = b.prepare(msg)
+				}
+				numbered := b.replay.add(msg)
+				if b.events != nil {
+					b.events.add(numbered.msg)
+				}
+				if workers == nil {
+					workers = b.startFanOut(&fanOut)
+				}
+				for _, worker := range workers {
+					worker <- numbered
+				}
 			} else {
+				// Deliver the messages still queued before closing
+				for _, worker := range workers {
+					close(worker)
+				}
+				fanOut.Wait()
 				b.expiredLock.Lock()
 				b.expired = true
 				b.subscribers.Range(func(key, value interface{}) bool {
-					subscriber := key.(*Subscriber)
+					subscriber := key.(*Subscriber[T])
 					close(subscriber.unsubChan)
 					return true
 				})
 				// Remove references
 				b.subscribers = nil
+				b.shards = nil
 				b.expiredLock.Unlock()
 				return
 			}
@@ -143,30 +494,249 @@ func NewBroadcaster(c chan SocketData) *Broadcaster {
 	return b
 }
 
-func (b *Broadcaster) Expired() bool {
+func (b *Broadcaster[T]) Expired() bool {
 	b.expiredLock.Lock()
 	defer b.expiredLock.Unlock()
 	return b.expired
 }
 
 // Subscriber expected to constantly consume or unsubscribe
-func (b *Broadcaster) Subscribe() (*Subscriber, error) {
+// Subscribing over the pool limit evicts the lowest priority subscribers
+func (b *Broadcaster[T]) Subscribe(opts ...SubscribeOption[T]) (*Subscriber[T], error) {
 	b.expiredLock.Lock()
 	defer b.expiredLock.Unlock()
 
 	if b.expired {
-		return &Subscriber{}, expiredError
+		return &Subscriber[T]{}, expiredError
 	}
-	newSub := &Subscriber{
-		subChan:   make(chan SocketData),
+	b.subscriptions++
+	newSub := &Subscriber[T]{
+		subChan:   make(chan T, b.bufferSize),
 		unsubChan: make(chan struct{}),
+		priority:  PriorityNormal,
+		sequence:  b.subscriptions,
+		overflow:  b.overflow,
+
+		sendTimeout: b.sendTimeout,
+	}
+	for _, opt := range opts {
+		opt(newSub)
+	}
+	if newSub.replay {
+		// Holding the replay buffer while subscribing means every message is
+		// either replayed or delivered live
+		b.replay.mutex.Lock()
+		defer b.replay.mutex.Unlock()
+		replayed, ok := b.replay.sinceLocked(newSub.replaySince, newSub.accepts)
+		for i := range replayed {
+			replayed[i] = newSub.transform(replayed[i])
+		}
+		newSub.initial = []T{}
+		if ok {
+			newSub.initial = replayed
+		}
+		if b.messages != nil {
+			newSub.initialComplete = b.messages.ReplayUnavailable(b.replay.oldest)
+			if ok {
+				newSub.initialComplete = b.messages.ReplayComplete(len(replayed))
+			}
+		}
+		newSub.replayedTo = b.replay.sequence
+	} else if newSub.compacted && b.events != nil {
+		// Holding the cache while subscribing means every event is either
+		// compacted or delivered live
+		b.events.lock()
+		defer b.events.unlock()
+		newSub.initial = b.events.compactedLocked(newSub.accepts)
+		for i := range newSub.initial {
+			newSub.initial[i] = newSub.transform(newSub.initial[i])
+		}
+		if b.messages != nil {
+			newSub.initialComplete = b.messages.CompactionComplete(len(newSub.initial))
+		}
 	}
 	// Generate unique key
 	b.subscribers.Store(newSub, struct{}{})
+	b.shardLocked(newSub).Store(newSub, struct{}{})
+	if b.poolLimit > 0 {
+		b.evictLocked(b.poolSizeLocked() - b.poolLimit)
+	}
 	return newSub, nil
 }
 
-func (b *Broadcaster) Unsubscribe(sub *Subscriber) error {
+// SetPoolLimit sets the maximum number of subscribers, 0 means unlimited.
+// Subscribers over the new limit are evicted immediately
+func (b *Broadcaster[T]) SetPoolLimit(limit int) {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	b.poolLimit = limit
+	if !b.expired && limit > 0 {
+		b.evictLocked(b.poolSizeLocked() - limit)
+	}
+}
+
+// Full returns whether a new subscriber with the given priority would be
+// evicted straight away, as the pool is at its limit and no subscriber has a
+// lower priority
+func (b *Broadcaster[T]) Full(priority Priority) bool {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	if b.poolLimit <= 0 || b.poolSizeLocked() < b.poolLimit {
+		return false
+	}
+	full := true
+	b.subscribers.Range(func(key, value interface{}) bool {
+		if key.(*Subscriber[T]).priority < priority {
+			full = false
+		}
+		return full
+	})
+	return full
+}
+
+// Evict removes up to count subscribers, lowest priority first and most
+// recent first within a priority, returning the number evicted
+func (b *Broadcaster[T]) Evict(count int) int {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	if b.expired {
+		return 0
+	}
+	return b.evictLocked(count)
+}
+
+// evictLocked must be called holding expiredLock
+func (b *Broadcaster[T]) evictLocked(count int) int {
+	if count <= 0 {
+		return 0
+	}
+	candidates := []*Subscriber[T]{}
+	b.subscribers.Range(func(key, value interface{}) bool {
+		candidates = append(candidates, key.(*Subscriber[T]))
+		return true
+	})
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].sequence > candidates[j].sequence
+	})
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+	for _, sub := range candidates[:count] {
+		b.evictSubscriberLocked(sub, evictedOverLimit)
+	}
+	return count
+}
+
+// evictSubscriberLocked must be called holding expiredLock
+func (b *Broadcaster[T]) evictSubscriberLocked(sub *Subscriber[T], reason int32) {
+	if _, ok := b.subscribers.Load(sub); !ok {
+		return
+	}
+	atomic.StoreInt32(&sub.evicted, reason)
+	b.subscribers.Delete(sub)
+	b.shardLocked(sub).Delete(sub)
+	close(sub.unsubChan)
+}
+
+// SetClientBuffer sets the buffer size and overflow policy of new subscribers.
+// A size of 0 holds up the fan-out worker of each subscriber until it has
+// received the message, larger sizes let slow subscribers fall behind until their buffer
+// fills up and the policy applies
+func (b *Broadcaster[T]) SetClientBuffer(size int, policy OverflowPolicy) {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	b.bufferSize = size
+	b.overflow = policy
+}
+
+// SetSendTimeout sets how long a message may wait for a new subscriber before
+// it is evicted as too slow, 0 waits forever. Unbuffered subscribers are
+// evicted once they have not received a message within the timeout, buffered
+// ones once their buffer has stayed full that long whatever their policy
+func (b *Broadcaster[T]) SetSendTimeout(timeout time.Duration) {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	b.sendTimeout = timeout
+}
+
+// deliver sends the message to a subscriber, applying its overflow policy
+// when its buffer is full and evicting it once it is too slow
+func (b *Broadcaster[T]) deliver(sub *Subscriber[T], msg T) {
+	if cap(sub.subChan) == 0 {
+		if sub.sendTimeout <= 0 {
+			select {
+			case sub.subChan <- msg:
+			case <-sub.unsubChan:
+			}
+			return
+		}
+		timer := time.NewTimer(sub.sendTimeout)
+		defer timer.Stop()
+		select {
+		case sub.subChan <- msg:
+		case <-sub.unsubChan:
+		case <-timer.C:
+			b.evict(sub, evictedTooSlow)
+		}
+		return
+	}
+	full := false
+	for {
+		select {
+		case sub.subChan <- msg:
+			if !full {
+				sub.fullSince = time.Time{}
+			}
+			return
+		case <-sub.unsubChan:
+			return
+		default:
+		}
+		full = true
+		if sub.fullSince.IsZero() {
+			sub.fullSince = time.Now()
+		} else if sub.sendTimeout > 0 && time.Since(sub.fullSince) >= sub.sendTimeout {
+			b.evict(sub, evictedTooSlow)
+			return
+		}
+		switch sub.overflow {
+		case DropNewest:
+			atomic.AddUint64(&sub.dropped, 1)
+			return
+		case Disconnect:
+			b.evict(sub, evictedOverflowed)
+			return
+		default:
+			// Make room by dropping the oldest message, unless the
+			// subscriber has just read it
+			select {
+			case <-sub.subChan:
+				atomic.AddUint64(&sub.dropped, 1)
+			default:
+			}
+		}
+	}
+}
+
+// evict removes a subscriber for the given reason
+func (b *Broadcaster[T]) evict(sub *Subscriber[T], reason int32) {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	if !b.expired {
+		b.evictSubscriberLocked(sub, reason)
+	}
+}
+
+func (b *Broadcaster[T]) Unsubscribe(sub *Subscriber[T]) error {
 	b.expiredLock.Lock()
 	defer b.expiredLock.Unlock()
 
@@ -175,6 +745,7 @@ func (b *Broadcaster) Unsubscribe(sub *Subscriber) error {
 	}
 	if _, ok := b.subscribers.Load(sub); ok {
 		b.subscribers.Delete(sub)
+		b.shardLocked(sub).Delete(sub)
 		close(sub.unsubChan)
 		return nil
 	}
@@ -183,13 +754,18 @@ func (b *Broadcaster) Unsubscribe(sub *Subscriber) error {
 
 // Iterates over sync.Map and returns number of elements
 // Response can be oversized if counted subscriptions are cancelled while counting
-func (b *Broadcaster) PoolSize() (size int) {
+func (b *Broadcaster[T]) PoolSize() (size int) {
 	b.expiredLock.Lock()
 	defer b.expiredLock.Unlock()
 
 	if b.expired {
 		return 0
 	}
+	return b.poolSizeLocked()
+}
+
+// poolSizeLocked must be called holding expiredLock
+func (b *Broadcaster[T]) poolSizeLocked() (size int) {
 	b.subscribers.Range(func(key, value interface{}) bool {
 		size++
 		return true
@@ -1,8 +1,10 @@
 package broadcaster
 
 import (
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 )
 
 // Add and remove Subscribers
@@ -93,6 +95,210 @@ func TestUnsubDataSend(t *testing.T) {
 	expectSubscribersSynced(t, 2, subscriberMessages)
 }
 
+// Ensure low priority subscribers are evicted before normal ones
+func TestEvictByPriority(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	normal, _ := broadcaster.Subscribe()
+	low, _ := broadcaster.Subscribe(WithPriority[SocketData](PriorityLow))
+	newerNormal, _ := broadcaster.Subscribe(WithPriority[SocketData](PriorityNormal))
+	expectPoolSize(t, broadcaster, 3)
+
+	if evicted := broadcaster.Evict(1); evicted != 1 {
+		t.Errorf("Expected 1 subscriber evicted, actual %d", evicted)
+	}
+	expectUnsubscribed(t, low)
+	if !low.Evicted() {
+		t.Error("Low priority subscriber was not marked as evicted")
+	}
+	expectPoolSize(t, broadcaster, 2)
+
+	// Going over the pool limit evicts the most recent normal subscriber
+	broadcaster.SetPoolLimit(1)
+	expectUnsubscribed(t, newerNormal)
+	expectPoolSize(t, broadcaster, 1)
+	if normal.Evicted() {
+		t.Error("Oldest normal priority subscriber should not be evicted")
+	}
+
+	// New low priority subscribers are evicted straight away when the pool is full
+	if !broadcaster.Full(PriorityLow) || !broadcaster.Full(PriorityNormal) {
+		t.Error("Pool should be full for new subscribers")
+	}
+	rejected, _ := broadcaster.Subscribe(WithPriority[SocketData](PriorityLow))
+	expectUnsubscribed(t, rejected)
+	expectPoolSize(t, broadcaster, 1)
+	close(c)
+}
+
+// Ensure filtered subscribers only receive accepted messages without blocking others
+func TestFilteredDataSend(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	all, _ := broadcaster.Subscribe()
+	logsOnly, _ := broadcaster.Subscribe(WithFilter(func(data SocketData) bool {
+		return data.MessageType == Log
+	}))
+	var allMessages, logMessages int32
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		allMessages = subscriberRead(t, all)
+		wg.Done()
+	}()
+	go func() {
+		logMessages = subscriberRead(t, logsOnly)
+		wg.Done()
+	}()
+	c <- SocketData{MessageType: TaskCreated}
+	c <- SocketData{MessageType: Log}
+	c <- SocketData{MessageType: TaskDeleted}
+	close(c)
+	wg.Wait()
+	if allMessages != 3 {
+		t.Errorf("Expected unfiltered subscriber to receive 3 messages, actual %d", allMessages)
+	}
+	if logMessages != 1 {
+		t.Errorf("Expected filtered subscriber to receive 1 message, actual %d", logMessages)
+	}
+}
+
+// Ensure each overflow policy applies once a subscriber's buffer is full,
+// counting the messages dropped, and that subscribers may choose their own
+func TestOverflowPolicies(t *testing.T) {
+	messages := []SocketData{{MessageType: TaskCreated}, {MessageType: TaskUpdated}, {MessageType: TaskDeleted}}
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []MessageType
+		evicted  bool
+		dropped  uint64
+	}{
+		{DropOldest, []MessageType{TaskUpdated, TaskDeleted}, false, 1},
+		{DropNewest, []MessageType{TaskCreated, TaskUpdated}, false, 1},
+		{Disconnect, []MessageType{TaskCreated, TaskUpdated}, true, 0},
+	}
+	for _, test := range tests {
+		c := make(chan SocketData)
+		broadcaster := NewBroadcaster(c)
+		broadcaster.SetClientBuffer(2, test.policy)
+		slow, _ := broadcaster.Subscribe()
+		chosen, _ := broadcaster.Subscribe(WithOverflow[SocketData](DropNewest))
+		for _, message := range messages {
+			c <- message
+		}
+		closeAwaitExpired(c, broadcaster)
+
+		if slow.Evicted() != test.evicted || slow.Overflowed() != test.evicted {
+			t.Errorf("%s: expected evicted and overflowed %t, actual %t and %t", test.policy, test.evicted, slow.Evicted(), slow.Overflowed())
+		}
+		if slow.Dropped() != test.dropped {
+			t.Errorf("%s: expected %d dropped, actual %d", test.policy, test.dropped, slow.Dropped())
+		}
+		var received []MessageType
+		for len(slow.SubChan()) > 0 {
+			received = append(received, (<-slow.SubChan()).MessageType)
+		}
+		if !reflect.DeepEqual(received, test.expected) {
+			t.Errorf("%s: expected buffered %v, actual %v", test.policy, test.expected, received)
+		}
+		if chosen.Evicted() || chosen.Dropped() != 1 {
+			t.Errorf("%s: expected the subscriber's own %s policy, actual evicted %t, %d dropped", test.policy, DropNewest, chosen.Evicted(), chosen.Dropped())
+		}
+	}
+}
+
+// Ensure subscribers that stop reading are evicted once the send timeout
+// passes, whether they are buffered or not, while readers are kept
+func TestSlowSubscriberEvicted(t *testing.T) {
+	for _, bufferSize := range []int{0, 2} {
+		c := make(chan SocketData)
+		broadcaster := NewBroadcaster(c)
+		broadcaster.SetClientBuffer(bufferSize, DropOldest)
+		broadcaster.SetSendTimeout(100 * time.Millisecond)
+		healthy, _ := createSubscribers(t, broadcaster, 2)
+		for _, sub := range healthy {
+			go subscriberRead(t, sub)
+		}
+		slow, _ := broadcaster.Subscribe()
+		patient, _ := broadcaster.Subscribe(WithBuffer[SocketData](1, DropNewest), WithSendTimeout[SocketData](0))
+
+		deadline := time.Now().Add(5 * time.Second)
+		for broadcaster.PoolSize() > len(healthy)+1 && time.Now().Before(deadline) {
+			c <- SocketData{MessageType: TaskUpdated}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if size := broadcaster.PoolSize(); size != len(healthy)+1 {
+			t.Fatalf("buffer %d: expected pool size %d once the slow subscriber is evicted, actual %d", bufferSize, len(healthy)+1, size)
+		}
+		if !slow.Evicted() || !slow.TooSlow() {
+			t.Errorf("buffer %d: expected slow subscriber evicted as too slow, actual evicted %t, too slow %t", bufferSize, slow.Evicted(), slow.TooSlow())
+		}
+		if patient.Evicted() {
+			t.Errorf("buffer %d: expected subscriber without send timeout kept", bufferSize)
+		}
+		for _, sub := range healthy {
+			if sub.Evicted() {
+				t.Errorf("buffer %d: expected reading subscribers kept", bufferSize)
+			}
+		}
+		closeAwaitExpired(c, broadcaster)
+	}
+}
+
+// Ensure the tenant identifier is set on every message once configured
+func TestTenantDataSend(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetClientBuffer(2, DropNewest)
+	sub, _ := broadcaster.Subscribe()
+	c <- SocketData{MessageType: TaskCreated}
+	for len(sub.SubChan()) == 0 {
+	}
+	broadcaster.SetTenant("cluster-a")
+	c <- SocketData{MessageType: TaskUpdated}
+	closeAwaitExpired(c, broadcaster)
+
+	if data := <-sub.SubChan(); data.Tenant != "" {
+		t.Errorf("Expected no tenant by default, actual %q", data.Tenant)
+	}
+	if data := <-sub.SubChan(); data.Tenant != "cluster-a" {
+		t.Errorf("Expected tenant cluster-a, actual %q", data.Tenant)
+	}
+}
+
+// Ensure the delta of a message is computed once whatever the number of
+// subscribers, and only for messages with a previous state
+func TestDeltaComputedOnce(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetClientBuffer(2, DropNewest)
+	computed := 0
+	broadcaster.SetDeltaFunc(func(data SocketData) (interface{}, bool) {
+		computed++
+		return "delta", true
+	})
+	subs := []*Subscriber[SocketData]{}
+	for i := 0; i < 3; i++ {
+		sub, _ := broadcaster.Subscribe()
+		subs = append(subs, sub)
+	}
+	c <- SocketData{MessageType: TaskCreated}
+	c <- SocketData{MessageType: TaskUpdated, Previous: "previous"}
+	closeAwaitExpired(c, broadcaster)
+
+	if computed != 1 {
+		t.Errorf("Expected the delta computed once, actual %d times", computed)
+	}
+	for i, sub := range subs {
+		if data := <-sub.SubChan(); data.DeltaPayload != nil {
+			t.Errorf("Subscriber[%d]: expected no delta without a previous state, actual %v", i, data.DeltaPayload)
+		}
+		if data := <-sub.SubChan(); data.DeltaPayload != "delta" {
+			t.Errorf("Subscriber[%d]: expected delta, actual %v", i, data.DeltaPayload)
+		}
+	}
+}
+
 // Testing utility functions below
 
 func expectSubscribersSynced(t *testing.T, expectedMessages int32, messages []int32) {
@@ -104,7 +310,7 @@ func expectSubscribersSynced(t *testing.T, expectedMessages int32, messages []in
 }
 
 // Responds with the number of messages read
-func subscriberRead(t *testing.T, s *Subscriber) int32 {
+func subscriberRead(t *testing.T, s *Subscriber[SocketData]) int32 {
 	var messagesReceived int32
 	for {
 		select {
@@ -117,12 +323,12 @@ func subscriberRead(t *testing.T, s *Subscriber) int32 {
 }
 
 // Return subscriber slice with requested number of subscribers
-func createSubscribers(t *testing.T, b *Broadcaster, reqSubs int32) ([]*Subscriber, error) {
-	subscriberList := []*Subscriber{}
+func createSubscribers(t *testing.T, b *SocketBroadcaster, reqSubs int32) ([]*Subscriber[SocketData], error) {
+	subscriberList := []*Subscriber[SocketData]{}
 	for i := 0; int32(i) < reqSubs; i++ {
 		sub, err := b.Subscribe()
 		if err != nil {
-			return []*Subscriber{}, err
+			return []*Subscriber[SocketData]{}, err
 		}
 		subscriberList = append(subscriberList, sub)
 	}
@@ -137,9 +343,47 @@ func sendData(c chan SocketData, numberOfTimes int32) {
 	}
 }
 
+// Broadcasters of other types of messages deliver them typed, filtered and
+// transformed, and replay them without numbering them
+func TestTypedDataSend(t *testing.T) {
+	c := make(chan int)
+	broadcaster := New[int](c, nil)
+	even, _ := broadcaster.Subscribe(WithBuffer[int](10, DropNewest), WithFilter(func(n int) bool {
+		return n%2 == 0
+	}), WithTransform(func(n int) int {
+		return n * 10
+	}))
+	for n := 1; n <= 4; n++ {
+		c <- n
+	}
+	received := []int{}
+	for len(received) < 2 {
+		select {
+		case n := <-even.SubChan():
+			received = append(received, n)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 2 messages, received %v", received)
+		}
+	}
+	if !reflect.DeepEqual(received, []int{20, 40}) {
+		t.Errorf("Expected [20 40], actual %v", received)
+	}
+
+	replayed, _ := broadcaster.Subscribe(WithReplay[int](broadcaster.replay.oldest))
+	if initial := replayed.Initial(); !reflect.DeepEqual(initial, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected [1 2 3 4] replayed, actual %v", initial)
+	}
+	if complete := replayed.InitialComplete(); complete != 0 {
+		t.Errorf("Expected the zero value after the replayed messages, actual %d", complete)
+	}
+	close(c)
+	for !broadcaster.Expired() {
+	}
+}
+
 // Close broadcaster channel
 // Waits until all subscribers have been removed
-func closeAwaitExpired(c chan SocketData, b *Broadcaster) {
+func closeAwaitExpired(c chan SocketData, b *SocketBroadcaster) {
 	close(c)
 	for {
 		if b.Expired() {
@@ -151,7 +395,7 @@ func closeAwaitExpired(c chan SocketData, b *Broadcaster) {
 // Expected unsubcribe behavior:
 // SubChan set to nil
 // UnsubChan closed
-func expectUnsubscribed(t *testing.T, s *Subscriber) {
+func expectUnsubscribed(t *testing.T, s *Subscriber[SocketData]) {
 	select {
 	case <-s.UnsubChan():
 	default:
@@ -159,7 +403,7 @@ func expectUnsubscribed(t *testing.T, s *Subscriber) {
 	}
 }
 
-func expectPoolSize(t *testing.T, b *Broadcaster, expected int32) {
+func expectPoolSize(t *testing.T, b *SocketBroadcaster, expected int32) {
 	if poolSize := b.PoolSize(); int32(poolSize) != expected {
 		t.Errorf("Expected Poolsize: %d, Actual: %d\n", expected, poolSize)
 	}
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultTombstoneTTL is how long the Deleted event of an object is kept
+const DefaultTombstoneTTL = 5 * time.Minute
+
+// DefaultHistorySize is how many object events, across all objects, are kept
+// for object histories
+const DefaultHistorySize = 1000
+
+// objectEventSuffixes end the message types of object events
+var objectEventSuffixes = []string{"Created", "Updated", "Deleted"}
+
+// EventCache keeps the latest event of each object broadcast, and the Deleted
+// events of recently deleted objects as tombstones, so new subscribers can
+// reconstruct the current state before following live events. The most
+// recent events of all objects are also kept in a ring buffer to give the
+// history of an object
+type EventCache struct {
+	mutex        sync.Mutex
+	tombstoneTTL time.Duration
+	// Incremented for each cached event to keep them in broadcast order
+	sequence uint64
+	latest   map[string]*cachedEvent
+	// history is a ring buffer of historySize events, the oldest one at
+	// historyNext once full
+	history     []historyEntry
+	historyNext int
+	historySize int
+	// Number of events of each object in history, and whether older ones
+	// were evicted
+	historyCount   map[string]int
+	historyEvicted map[string]bool
+}
+
+type historyEntry struct {
+	key   string
+	event HistoryEvent
+}
+
+// HistoryEvent is an event of an object kept in its history
+type HistoryEvent struct {
+	MessageType     MessageType
+	ResourceVersion string
+	Time            time.Time
+	Payload         interface{}
+}
+
+type cachedEvent struct {
+	data     SocketData
+	sequence uint64
+	// deleted is when the object was deleted, zero while it exists
+	deleted time.Time
+}
+
+// NewEventCache returns an EventCache keeping tombstones for tombstoneTTL
+func NewEventCache(tombstoneTTL time.Duration) *EventCache {
+	return &EventCache{
+		tombstoneTTL:   tombstoneTTL,
+		latest:         map[string]*cachedEvent{},
+		historySize:    DefaultHistorySize,
+		historyCount:   map[string]int{},
+		historyEvicted: map[string]bool{},
+	}
+}
+
+// SetHistorySize sets how many events are kept for object histories, zero
+// disables them. It must be called before events are cached
+func (c *EventCache) SetHistorySize(size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.historySize = size
+}
+
+// objectKey identifies the object of an event by kind, namespace and name,
+// messages that are not object events have no key
+func objectKey(data SocketData) (string, bool) {
+	messageType := string(data.MessageType)
+	for _, suffix := range objectEventSuffixes {
+		if !strings.HasSuffix(messageType, suffix) {
+			continue
+		}
+		object, ok := payloadObject(data.Payload)
+		if !ok {
+			return "", false
+		}
+		return strings.TrimSuffix(messageType, suffix) + "/" + object.GetNamespace() + "/" + object.GetName(), true
+	}
+	return "", false
+}
+
+// payloadObject returns the object of an event payload, unwrapping the
+// tombstones of objects deleted while the informer was disconnected and the
+// references to truncated objects
+func payloadObject(payload interface{}) (metav1.Object, bool) {
+	switch p := payload.(type) {
+	case cache.DeletedFinalStateUnknown:
+		payload = p.Obj
+	case ObjectReference:
+		return &metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name, UID: p.UID, ResourceVersion: p.ResourceVersion}, true
+	}
+	object, err := meta.Accessor(payload)
+	return object, err == nil
+}
+
+// add caches an object event and drops expired tombstones
+func (c *EventCache) add(data SocketData) {
+	key, ok := objectKey(data)
+	if !ok {
+		return
+	}
+	// Compacted events are sent to subscribers that have not seen the
+	// previous state so are never deltas
+	data.Previous = nil
+	data.DeltaPayload = nil
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	c.sequence++
+	event := &cachedEvent{data: data, sequence: c.sequence}
+	if strings.HasSuffix(string(data.MessageType), "Deleted") {
+		event.deleted = now
+	}
+	c.latest[key] = event
+	for k, e := range c.latest {
+		if !e.deleted.IsZero() && now.Sub(e.deleted) > c.tombstoneTTL {
+			delete(c.latest, k)
+		}
+	}
+	c.addHistoryLocked(key, data, now)
+}
+
+// addHistoryLocked appends an event to the history ring buffer, evicting the
+// oldest event once full, the mutex must be held
+func (c *EventCache) addHistoryLocked(key string, data SocketData, now time.Time) {
+	if c.historySize <= 0 {
+		return
+	}
+	event := HistoryEvent{MessageType: data.MessageType, Time: now, Payload: data.Payload}
+	if object, ok := payloadObject(data.Payload); ok {
+		event.ResourceVersion = object.GetResourceVersion()
+	}
+	entry := historyEntry{key: key, event: event}
+	if len(c.history) < c.historySize {
+		c.history = append(c.history, entry)
+		c.historyCount[key]++
+		return
+	}
+	evicted := c.history[c.historyNext].key
+	if c.historyCount[evicted]--; c.historyCount[evicted] <= 0 {
+		delete(c.historyCount, evicted)
+		delete(c.historyEvicted, evicted)
+	} else {
+		c.historyEvicted[evicted] = true
+	}
+	c.history[c.historyNext] = entry
+	c.historyNext = (c.historyNext + 1) % len(c.history)
+	c.historyCount[key]++
+}
+
+// History returns the events kept for an object identified by the kind
+// prefix of its message types, oldest first. Evicted is set when older events
+// of the object were dropped from the history
+func (c *EventCache) History(kind, namespace, name string) (events []HistoryEvent, evicted bool) {
+	key := kind + "/" + namespace + "/" + name
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	events = []HistoryEvent{}
+	if c.historyCount[key] > 0 {
+		for i := range c.history {
+			entry := c.history[(c.historyNext+i)%len(c.history)]
+			if entry.key == key {
+				events = append(events, entry.event)
+			}
+		}
+	}
+	_, cached := c.latest[key]
+	return events, c.historyEvicted[key] || (len(events) == 0 && cached)
+}
+
+func (c *EventCache) lock() {
+	c.mutex.Lock()
+}
+
+func (c *EventCache) unlock() {
+	c.mutex.Unlock()
+}
+
+// compactedLocked returns the cached events accepted by accepts in broadcast
+// order, the mutex must be held
+func (c *EventCache) compactedLocked(accepts func(SocketData) bool) []SocketData {
+	events := make([]*cachedEvent, 0, len(c.latest))
+	now := time.Now()
+	for _, event := range c.latest {
+		if !event.deleted.IsZero() && now.Sub(event.deleted) > c.tombstoneTTL {
+			continue
+		}
+		if accepts(event.data) {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].sequence < events[j].sequence
+	})
+	compacted := make([]SocketData, len(events))
+	for i, event := range events {
+		compacted[i] = event.data
+	}
+	return compacted
+}
+
+// Compacted returns the latest event of each object and the tombstones of
+// recently deleted objects, in broadcast order
+func (c *EventCache) Compacted() []SocketData {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.compactedLocked(func(SocketData) bool { return true })
+}
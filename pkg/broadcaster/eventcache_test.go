@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func object(name string) *metav1.ObjectMeta {
+	return &metav1.ObjectMeta{Namespace: "default", Name: name}
+}
+
+// The cache keeps the latest event of each object in broadcast order and
+// drops expired tombstones
+func TestEventCacheCompacted(t *testing.T) {
+	events := NewEventCache(50 * time.Millisecond)
+	events.add(SocketData{MessageType: TaskCreated, Payload: object("a")})
+	events.add(SocketData{MessageType: TaskCreated, Payload: object("b")})
+	events.add(SocketData{MessageType: TaskRunCreated, Payload: object("a")})
+	events.add(SocketData{MessageType: TaskUpdated, Payload: object("a")})
+	events.add(SocketData{MessageType: TaskDeleted, Payload: cache.DeletedFinalStateUnknown{Key: "default/b", Obj: object("b")}})
+	// Not object events
+	events.add(SocketData{MessageType: Log, Payload: "line"})
+	events.add(SocketData{MessageType: TaskCreated, Payload: "not an object"})
+
+	describe := func() []string {
+		var described []string
+		for _, data := range events.Compacted() {
+			var name string
+			if tombstone, ok := data.Payload.(cache.DeletedFinalStateUnknown); ok {
+				name = tombstone.Obj.(*metav1.ObjectMeta).Name
+			} else {
+				name = data.Payload.(*metav1.ObjectMeta).Name
+			}
+			described = append(described, fmt.Sprintf("%s %s", data.MessageType, name))
+		}
+		return described
+	}
+	expected := "[TaskRunCreated a TaskUpdated a TaskDeleted b]"
+	if actual := fmt.Sprint(describe()); actual != expected {
+		t.Errorf("Expected %s, actual %s", expected, actual)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	expected = "[TaskRunCreated a TaskUpdated a]"
+	if actual := fmt.Sprint(describe()); actual != expected {
+		t.Errorf("Expected %s after the tombstone expired, actual %s", expected, actual)
+	}
+}
+
+// Compacted subscribers start with the cached events they accept
+func TestSubscribeCompacted(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetEventCache(NewEventCache(DefaultTombstoneTTL))
+	live, _ := broadcaster.Subscribe()
+	for _, data := range []SocketData{
+		{MessageType: TaskCreated, Payload: object("a")},
+		{MessageType: TaskRunCreated, Payload: object("b")},
+	} {
+		c <- data
+		<-live.SubChan()
+	}
+
+	compacted, _ := broadcaster.Subscribe(WithCompacted[SocketData](), WithFilter(func(data SocketData) bool {
+		return data.MessageType == TaskRunCreated
+	}))
+	if initial := compacted.Initial(); len(initial) != 1 || initial[0].MessageType != TaskRunCreated {
+		t.Errorf("Expected the TaskRunCreated event, actual %v", initial)
+	}
+	if initial := live.Initial(); initial != nil {
+		t.Errorf("Expected no initial events without WithCompacted, actual %v", initial)
+	}
+}
+
+// The history keeps the events of each object in order until they are
+// evicted by newer events
+func TestEventCacheHistory(t *testing.T) {
+	events := NewEventCache(DefaultTombstoneTTL)
+	events.SetHistorySize(4)
+	withVersion := func(name, resourceVersion string) *metav1.ObjectMeta {
+		o := object(name)
+		o.ResourceVersion = resourceVersion
+		return o
+	}
+	events.add(SocketData{MessageType: TaskCreated, Payload: withVersion("a", "1")})
+	events.add(SocketData{MessageType: TaskCreated, Payload: withVersion("b", "2")})
+	events.add(SocketData{MessageType: TaskUpdated, Payload: withVersion("a", "3")})
+	events.add(SocketData{MessageType: TaskRunCreated, Payload: withVersion("a", "4")})
+
+	describe := func(history []HistoryEvent) string {
+		var described []string
+		for _, event := range history {
+			described = append(described, fmt.Sprintf("%s %s", event.MessageType, event.ResourceVersion))
+		}
+		return fmt.Sprint(described)
+	}
+	history, evicted := events.History("Task", "default", "a")
+	if expected := "[TaskCreated 1 TaskUpdated 3]"; describe(history) != expected || evicted {
+		t.Errorf("Expected %s without eviction, actual %s evicted %t", expected, describe(history), evicted)
+	}
+
+	events.add(SocketData{MessageType: TaskUpdated, Payload: withVersion("a", "5")})
+	events.add(SocketData{MessageType: TaskUpdated, Payload: withVersion("a", "6")})
+	history, evicted = events.History("Task", "default", "a")
+	if expected := "[TaskUpdated 3 TaskUpdated 5 TaskUpdated 6]"; describe(history) != expected || !evicted {
+		t.Errorf("Expected %s with eviction, actual %s evicted %t", expected, describe(history), evicted)
+	}
+	// All the events of b were evicted but it is still cached
+	if history, evicted = events.History("Task", "default", "b"); len(history) != 0 || !evicted {
+		t.Errorf("Expected no events with eviction for b, actual %s evicted %t", describe(history), evicted)
+	}
+	if history, evicted = events.History("Task", "default", "unknown"); len(history) != 0 || evicted {
+		t.Errorf("Expected no history for an unknown object, actual %s evicted %t", describe(history), evicted)
+	}
+}
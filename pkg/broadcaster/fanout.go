@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import "sync"
+
+// DefaultFanOutWorkers is how many goroutines deliver the messages of a
+// broadcaster to its subscribers, see SetFanOutWorkers
+const DefaultFanOutWorkers = 4
+
+// fanOutQueueSize is how many messages each fan-out worker may fall behind
+// the broadcast before it holds up the other workers
+const fanOutQueueSize = 64
+
+// SetFanOutWorkers sets how many goroutines deliver messages to subscribers,
+// each to its own share of them. A subscriber slow to receive a message only
+// holds up the subscribers of its worker, and the messages of each subscriber
+// are delivered in order. It must be called before broadcasting
+func (b *Broadcaster[T]) SetFanOutWorkers(workers int) {
+	b.expiredLock.Lock()
+	defer b.expiredLock.Unlock()
+
+	if b.expired {
+		return
+	}
+	b.shards = newShards(workers)
+	b.subscribers.Range(func(key, value interface{}) bool {
+		subscriber := key.(*Subscriber[T])
+		b.shardLocked(subscriber).Store(subscriber, struct{}{})
+		return true
+	})
+}
+
+// newShards returns the empty subscriber sets of count fan-out workers, at
+// least one
+func newShards(count int) []*sync.Map {
+	if count < 1 {
+		count = 1
+	}
+	shards := make([]*sync.Map, count)
+	for i := range shards {
+		shards[i] = new(sync.Map)
+	}
+	return shards
+}
+
+// shardLocked returns the subscriber set of the fan-out worker delivering to
+// the subscriber, it must be called holding expiredLock
+func (b *Broadcaster[T]) shardLocked(sub *Subscriber[T]) *sync.Map {
+	return b.shards[sub.sequence%uint64(len(b.shards))]
+}
+
+// startFanOut starts a fan-out worker for each shard, each is sent every
+// message and returns once its channel is closed and the messages queued
+// delivered
+func (b *Broadcaster[T]) startFanOut(wg *sync.WaitGroup) []chan numbered[T] {
+	b.expiredLock.Lock()
+	shards := b.shards
+	b.expiredLock.Unlock()
+	workers := make([]chan numbered[T], len(shards))
+	for i := range workers {
+		workers[i] = make(chan numbered[T], fanOutQueueSize)
+		wg.Add(1)
+		go func(shard *sync.Map, messages <-chan numbered[T]) {
+			defer wg.Done()
+			b.fanOut(shard, messages)
+		}(shards[i], workers[i])
+	}
+	return workers
+}
+
+// fanOut delivers messages to the subscribers of a shard
+func (b *Broadcaster[T]) fanOut(shard *sync.Map, messages <-chan numbered[T]) {
+	for numbered := range messages {
+		shard.Range(func(key, value interface{}) bool {
+			subscriber := key.(*Subscriber[T])
+			if numbered.sequence > subscriber.replayedTo && subscriber.accepts(numbered.msg) {
+				b.deliver(subscriber, subscriber.transform(numbered.msg))
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A subscriber that is not reading only holds up the subscribers of its own
+// fan-out worker, and every subscriber receives the messages in order
+func TestFanOutWorkers(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetFanOutWorkers(2)
+	// Consecutive subscribers are delivered to by different workers
+	stuck, _ := broadcaster.Subscribe(WithSendTimeout[SocketData](0))
+	reading, _ := broadcaster.Subscribe(WithSendTimeout[SocketData](0))
+
+	messages := []MessageType{TaskCreated, TaskUpdated, TaskDeleted}
+	for _, messageType := range messages {
+		c <- SocketData{MessageType: messageType}
+	}
+	receive := func(sub *Subscriber[SocketData]) []MessageType {
+		received := []MessageType{}
+		for range messages {
+			select {
+			case data := <-sub.SubChan():
+				received = append(received, data.MessageType)
+			case <-time.After(time.Second):
+				return received
+			}
+		}
+		return received
+	}
+	if received := receive(reading); !reflect.DeepEqual(received, messages) {
+		t.Errorf("Expected %v while another subscriber is not reading, actual %v", messages, received)
+	}
+	if received := receive(stuck); !reflect.DeepEqual(received, messages) {
+		t.Errorf("Expected %v once reading, actual %v", messages, received)
+	}
+	closeAwaitExpired(c, broadcaster)
+}
+
+// Each subscriber is in the shard of exactly one worker until it leaves
+func TestFanOutShards(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetFanOutWorkers(3)
+	unsubscribed, _ := broadcaster.Subscribe()
+	evicted, _ := broadcaster.Subscribe(WithPriority[SocketData](PriorityLow))
+	remaining, _ := broadcaster.Subscribe()
+
+	shardsOf := func(sub *Subscriber[SocketData]) int {
+		broadcaster.expiredLock.Lock()
+		defer broadcaster.expiredLock.Unlock()
+		count := 0
+		for _, shard := range broadcaster.shards {
+			if _, ok := shard.Load(sub); ok {
+				count++
+			}
+		}
+		return count
+	}
+	for _, sub := range []*Subscriber[SocketData]{unsubscribed, evicted, remaining} {
+		if count := shardsOf(sub); count != 1 {
+			t.Errorf("Expected subscriber %d in 1 shard, actual %d", sub.sequence, count)
+		}
+	}
+
+	broadcaster.Unsubscribe(unsubscribed)
+	broadcaster.Evict(1)
+	for _, sub := range []*Subscriber[SocketData]{unsubscribed, evicted} {
+		if count := shardsOf(sub); count != 0 {
+			t.Errorf("Expected subscriber %d removed from its shard, in %d", sub.sequence, count)
+		}
+	}
+	if count := shardsOf(remaining); count != 1 {
+		t.Errorf("Expected the remaining subscriber in 1 shard, actual %d", count)
+	}
+	closeAwaitExpired(c, broadcaster)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// OwnershipChanged is sent after the Updated event of an object whose
+// ownerReferences changed, the payload is an OwnershipChange
+const OwnershipChanged MessageType = "OwnershipChanged"
+
+// OwnershipChange is the payload of an OwnershipChanged message, such as a
+// TaskRun adopted by a PipelineRun or orphaned
+type OwnershipChange struct {
+	Kind            string                  `json:"kind"`
+	Namespace       string                  `json:"namespace,omitempty"`
+	Name            string                  `json:"name"`
+	UID             types.UID               `json:"uid"`
+	ResourceVersion string                  `json:"resourceVersion"`
+	OldOwners       []metav1.OwnerReference `json:"oldOwners"`
+	NewOwners       []metav1.OwnerReference `json:"newOwners"`
+}
+
+// NewOwnershipChange returns the OwnershipChange of an object of a kind from
+// its old state to its new one, false if its ownerReferences are the same
+func NewOwnershipChange(kind string, oldObject, newObject metav1.Object) (OwnershipChange, bool) {
+	oldOwners, newOwners := oldObject.GetOwnerReferences(), newObject.GetOwnerReferences()
+	if sameOwners(oldOwners, newOwners) {
+		return OwnershipChange{}, false
+	}
+	if oldOwners == nil {
+		oldOwners = []metav1.OwnerReference{}
+	}
+	if newOwners == nil {
+		newOwners = []metav1.OwnerReference{}
+	}
+	return OwnershipChange{
+		Kind:            kind,
+		Namespace:       newObject.GetNamespace(),
+		Name:            newObject.GetName(),
+		UID:             newObject.GetUID(),
+		ResourceVersion: newObject.GetResourceVersion(),
+		OldOwners:       oldOwners,
+		NewOwners:       newOwners,
+	}, true
+}
+
+// sameOwners compares owners by UID, ignoring order and the other fields of
+// the references such as blockOwnerDeletion
+func sameOwners(a, b []metav1.OwnerReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	uids := map[types.UID]bool{}
+	for _, owner := range a {
+		uids[owner.UID] = true
+	}
+	for _, owner := range b {
+		if !uids[owner.UID] {
+			return false
+		}
+	}
+	return true
+}
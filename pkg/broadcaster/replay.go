@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"sync"
+	"time"
+)
+
+// Messages ending the replay of a subscriber created WithReplay
+const (
+	// ReplayComplete follows the replayed messages, the payload is their number
+	ReplayComplete MessageType = "ReplayComplete"
+	// ReplayUnavailable is sent instead of replaying when messages after the
+	// sequence number are no longer kept, or it was not given by this
+	// broadcaster. The payload is the oldest sequence number that can be
+	// resumed from, clients should list the resources again
+	ReplayUnavailable MessageType = "ReplayUnavailable"
+)
+
+// DefaultReplaySize is how many messages are kept for subscribers resuming
+// from a sequence number
+const DefaultReplaySize = 1000
+
+// numbered is a message with the sequence number the broadcaster gave it
+type numbered[T any] struct {
+	sequence uint64
+	msg      T
+}
+
+// replayBuffer numbers the messages of a broadcaster and keeps the latest
+// ones in a ring buffer so subscribers can resume after a disconnection
+type replayBuffer[T any] struct {
+	mutex sync.Mutex
+	// Stamps the sequence number on messages, may be nil
+	messages Messages[T]
+	// Sequence number of the latest message
+	sequence uint64
+	// Every message after oldest is kept
+	oldest uint64
+	// kept is a ring buffer of size messages, the oldest one at next once
+	// full
+	kept []numbered[T]
+	next int
+	size int
+}
+
+func newReplayBuffer[T any](size int, messages Messages[T]) *replayBuffer[T] {
+	// Sequence numbers start from the creation time, in microseconds so they
+	// stay exact as JSON numbers in browsers, so the sequence numbers of a
+	// previous run of the server are never mistaken for those of this one
+	start := uint64(time.Now().UnixNano() / int64(time.Microsecond))
+	return &replayBuffer[T]{messages: messages, sequence: start, oldest: start, size: size}
+}
+
+// add numbers a message and keeps it, evicting the oldest message once full
+func (r *replayBuffer[T]) add(msg T) numbered[T] {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	if r.messages != nil {
+		msg = r.messages.Numbered(msg, r.sequence)
+	}
+	entry := numbered[T]{sequence: r.sequence, msg: msg}
+	if r.size <= 0 {
+		r.oldest = r.sequence
+		return entry
+	}
+	if len(r.kept) < r.size {
+		r.kept = append(r.kept, entry)
+		return entry
+	}
+	r.oldest = r.kept[r.next].sequence
+	r.kept[r.next] = entry
+	r.next = (r.next + 1) % len(r.kept)
+	return entry
+}
+
+// sinceLocked returns the kept messages after the since sequence number that
+// are accepted, false if some of the messages after it are no longer kept or
+// it is not a sequence number of this broadcaster
+func (r *replayBuffer[T]) sinceLocked(since uint64, accepts Filter[T]) ([]T, bool) {
+	if since < r.oldest || since > r.sequence {
+		return nil, false
+	}
+	replayed := []T{}
+	for i := range r.kept {
+		entry := r.kept[(r.next+i)%len(r.kept)]
+		if entry.sequence > since && accepts(entry.msg) {
+			replayed = append(replayed, entry.msg)
+		}
+	}
+	return replayed, true
+}
+
+// SetReplaySize sets how many messages are kept for subscribers resuming from
+// a sequence number, 0 keeps none. It must be called before broadcasting
+func (b *Broadcaster[T]) SetReplaySize(size int) {
+	b.replay.mutex.Lock()
+	defer b.replay.mutex.Unlock()
+
+	b.replay.size = size
+	b.replay.kept = nil
+	b.replay.next = 0
+	b.replay.oldest = b.replay.sequence
+}
+
+// WithReplay starts the subscription with the messages broadcast after the
+// since sequence number, followed by a ReplayComplete message, or only a
+// ReplayUnavailable message if they are no longer kept, see Initial
+func WithReplay[T any](since uint64) SubscribeOption[T] {
+	return func(s *Subscriber[T]) {
+		s.replay = true
+		s.replaySince = since
+	}
+}
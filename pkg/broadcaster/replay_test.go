@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Subscribers resuming from a sequence number get the messages after it, and
+// only live messages once subscribed
+func TestReplay(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	defer closeAwaitExpired(c, broadcaster)
+	broadcaster.SetReplaySize(3)
+	live, _ := broadcaster.Subscribe(WithBuffer[SocketData](10, DropNewest))
+
+	messages := []MessageType{TaskCreated, TaskUpdated, TaskRunCreated, TaskUpdated, TaskDeleted}
+	var sequences []uint64
+	for _, messageType := range messages {
+		c <- SocketData{MessageType: messageType}
+		sequences = append(sequences, (<-live.SubChan()).Sequence)
+	}
+	for i := 1; i < len(sequences); i++ {
+		if sequences[i] != sequences[i-1]+1 {
+			t.Fatalf("Expected consecutive sequence numbers, actual %v", sequences)
+		}
+	}
+
+	tests := []struct {
+		since    uint64
+		filter   Filter[SocketData]
+		expected []MessageType
+		complete MessageType
+	}{
+		{sequences[1], nil, []MessageType{TaskRunCreated, TaskUpdated, TaskDeleted}, ReplayComplete},
+		{sequences[3], nil, []MessageType{TaskDeleted}, ReplayComplete},
+		{sequences[4], nil, []MessageType{}, ReplayComplete},
+		{sequences[1], func(data SocketData) bool { return data.MessageType != TaskRunCreated }, []MessageType{TaskUpdated, TaskDeleted}, ReplayComplete},
+		// Messages after the first two are no longer kept
+		{sequences[0], nil, []MessageType{}, ReplayUnavailable},
+		// Sequence numbers of another broadcaster
+		{sequences[4] + 1, nil, []MessageType{}, ReplayUnavailable},
+		{0, nil, []MessageType{}, ReplayUnavailable},
+	}
+	for _, test := range tests {
+		opts := []SubscribeOption[SocketData]{WithReplay[SocketData](test.since), WithBuffer[SocketData](10, DropNewest)}
+		if test.filter != nil {
+			opts = append(opts, WithFilter(test.filter))
+		}
+		sub, _ := broadcaster.Subscribe(opts...)
+		replayed := []MessageType{}
+		for _, data := range sub.Initial() {
+			replayed = append(replayed, data.MessageType)
+		}
+		if !reflect.DeepEqual(replayed, test.expected) {
+			t.Errorf("since %d: expected replayed %v, actual %v", test.since, test.expected, replayed)
+		}
+		if complete := sub.InitialComplete().MessageType; complete != test.complete {
+			t.Errorf("since %d: expected %s, actual %s", test.since, test.complete, complete)
+		}
+		broadcaster.Unsubscribe(sub)
+	}
+
+	// Live messages follow the replayed ones without repeating them
+	resumed, _ := broadcaster.Subscribe(WithReplay[SocketData](sequences[2]), WithBuffer[SocketData](10, DropNewest))
+	c <- SocketData{MessageType: TaskRunDeleted}
+	select {
+	case data := <-resumed.SubChan():
+		if data.MessageType != TaskRunDeleted || data.Sequence != sequences[4]+1 {
+			t.Errorf("Expected live %s with sequence %d, actual %s %d", TaskRunDeleted, sequences[4]+1, data.MessageType, data.Sequence)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected live message")
+	}
+}
+
+// Sequence numbers of a broadcaster created earlier are older than those kept
+// by a new one, as after a restart of the server
+func TestReplayAfterRestart(t *testing.T) {
+	c := make(chan SocketData)
+	previous := NewBroadcaster(c)
+	sub, _ := previous.Subscribe(WithBuffer[SocketData](1, DropNewest))
+	c <- SocketData{MessageType: TaskCreated}
+	since := (<-sub.SubChan()).Sequence
+	closeAwaitExpired(c, previous)
+
+	time.Sleep(time.Millisecond)
+	c = make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	defer closeAwaitExpired(c, broadcaster)
+	resumed, _ := broadcaster.Subscribe(WithReplay[SocketData](since))
+	if complete := resumed.InitialComplete().MessageType; complete != ReplayUnavailable {
+		t.Errorf("Expected %s, actual %s", ReplayUnavailable, complete)
+	}
+}
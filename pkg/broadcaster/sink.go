@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+)
+
+// sinkBufferSize is how many messages wait for a slow sink before the oldest
+// are dropped
+const sinkBufferSize = 1000
+
+// Sink receives the messages of a broadcaster, e.g. for archival or alerting
+// in an external system
+type Sink[T any] interface {
+	Send(data T) error
+}
+
+// Forward sends each message of the broadcaster to the sink until stopCh is
+// closed or the broadcaster expires. The sink has its own buffer so failing
+// or slow deliveries never hold up the other subscribers
+func Forward[T any](b *Broadcaster[T], sink Sink[T], stopCh <-chan struct{}) {
+	for {
+		// A slow sink loses its oldest messages rather than its subscription
+		subscriber, err := b.Subscribe(WithBuffer[T](sinkBufferSize, DropOldest), WithSendTimeout[T](0))
+		if err != nil {
+			return
+		}
+		if !forward(subscriber, sink, stopCh) {
+			b.Unsubscribe(subscriber)
+			return
+		}
+		if !subscriber.Evicted() {
+			return
+		}
+		// Subscribe again when evicted by the pool limit
+	}
+}
+
+// forward sends the messages of a subscriber to the sink, returning true once
+// the subscriber is unsubscribed and false when stopCh is closed
+func forward[T any](subscriber *Subscriber[T], sink Sink[T], stopCh <-chan struct{}) bool {
+	for {
+		select {
+		case data := <-subscriber.SubChan():
+			if err := sink.Send(data); err != nil {
+				logging.Log.Errorf("Error sending event to sink: %s", err)
+			}
+		case <-subscriber.UnsubChan():
+			return true
+		case <-stopCh:
+			return false
+		}
+	}
+}
+
+// WebhookSink POSTs each message as JSON to a URL, retrying failed deliveries
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+	// Retries is how many times a failed delivery is retried
+	Retries int
+	// Backoff is the wait before the first retry, doubled for each retry after
+	Backoff time.Duration
+}
+
+// NewWebhookSink returns a WebhookSink retrying failed deliveries three times
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		URL:     url,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Retries: 3,
+		Backoff: time.Second,
+	}
+}
+
+// Send POSTs the message, a response status other than 2xx is a failure
+func (s *WebhookSink) Send(data SocketData) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	backoff := s.Backoff
+	for attempt := 0; ; attempt++ {
+		err = s.post(body)
+		if err == nil || attempt == s.Retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	response, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Events are forwarded to the webhook, retrying failures, without holding up
+// other subscribers
+func TestWebhookSinkForward(t *testing.T) {
+	var mutex sync.Mutex
+	var received []SocketData
+	requests := 0
+	webhook := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests++
+		// The first delivery fails and is retried
+		if requests == 1 {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var data SocketData
+		if err := json.NewDecoder(request.Body).Decode(&data); err != nil {
+			t.Errorf("Error decoding forwarded event: %s", err)
+		}
+		received = append(received, data)
+	}))
+	defer webhook.Close()
+
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	client, _ := broadcaster.Subscribe()
+	sink := NewWebhookSink(webhook.URL)
+	sink.Backoff = 10 * time.Millisecond
+	stopCh := make(chan struct{})
+	forwarded := make(chan struct{})
+	go func() {
+		Forward[SocketData](broadcaster.Broadcaster, sink, stopCh)
+		close(forwarded)
+	}()
+	awaitPoolSize(t, broadcaster, 2)
+
+	sent := []SocketData{{MessageType: TaskCreated, Payload: "a"}, {MessageType: TaskDeleted, Payload: "b"}}
+	for _, data := range sent {
+		c <- data
+		select {
+		case <-client.SubChan():
+		case <-time.After(time.Second):
+			t.Fatal("Client did not receive the event")
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mutex.Lock()
+		done := len(received) == len(sent)
+		mutex.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Webhook did not receive the forwarded events")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mutex.Lock()
+	for i := range sent {
+		if received[i].MessageType != sent[i].MessageType || received[i].Payload != sent[i].Payload {
+			t.Errorf("Expected event %+v, actual %+v", sent[i], received[i])
+		}
+	}
+	if requests != len(sent)+1 {
+		t.Errorf("Expected %d requests including the retry, actual %d", len(sent)+1, requests)
+	}
+	mutex.Unlock()
+
+	close(stopCh)
+	<-forwarded
+	expectPoolSize(t, broadcaster, 1)
+	close(c)
+}
+
+func awaitPoolSize(t *testing.T, b *SocketBroadcaster, expected int) {
+	deadline := time.Now().Add(time.Second)
+	for b.PoolSize() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected pool size %d, actual %d", expected, b.PoolSize())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
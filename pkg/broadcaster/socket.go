@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"sync"
+
+	"github.com/tektoncd/dashboard/pkg/redact"
+)
+
+// DeltaFunc returns the Payload of a message as a delta from its Previous
+// state, false if it cannot be sent as a delta
+type DeltaFunc func(SocketData) (interface{}, bool)
+
+// SocketBroadcaster broadcasts the SocketData sent to websocket clients. It
+// stamps the tenant, masks and truncates objects and computes deltas before
+// the messages are numbered, and caches them for compacted subscriptions
+type SocketBroadcaster struct {
+	*Broadcaster[SocketData]
+
+	// Guards tenant, maxObjectSize and redactor
+	mutex sync.Mutex
+	// Stamped on every message sent to subscribers
+	tenant string
+	// Size in bytes above which objects are replaced by a reference, 0 means
+	// unlimited
+	maxObjectSize int
+	// Masks fields of objects before they are sent, nil sends them as they
+	// are
+	redactor *redact.Redactor
+	// Computes the DeltaPayload of messages with a previous state, set
+	// before broadcasting
+	delta DeltaFunc
+}
+
+// socketMessages numbers SocketData and builds the messages ending initial
+// messages
+type socketMessages struct{}
+
+func (socketMessages) Numbered(msg SocketData, sequence uint64) SocketData {
+	msg.Sequence = sequence
+	return msg
+}
+
+func (socketMessages) ReplayComplete(replayed int) SocketData {
+	return SocketData{MessageType: ReplayComplete, Payload: replayed}
+}
+
+func (socketMessages) ReplayUnavailable(oldest uint64) SocketData {
+	return SocketData{MessageType: ReplayUnavailable, Payload: oldest}
+}
+
+func (socketMessages) CompactionComplete(compacted int) SocketData {
+	return SocketData{MessageType: CompactionComplete, Payload: compacted}
+}
+
+// Creates broadcaster from channel parameter and immediately starts broadcasting
+// Without any subscribers, received data will be discarded
+// Broadcaster should be the only channel reader
+func NewBroadcaster(c chan SocketData) *SocketBroadcaster {
+	b := &SocketBroadcaster{}
+	b.Broadcaster = newBroadcaster[SocketData](c, socketMessages{}, b.prepare)
+	return b
+}
+
+// prepare stamps the tenant, masks and truncates the objects and computes the
+// delta of a message
+func (b *SocketBroadcaster) prepare(msg SocketData) SocketData {
+	b.mutex.Lock()
+	tenant, redactor, maxObjectSize := b.tenant, b.redactor, b.maxObjectSize
+	b.mutex.Unlock()
+
+	if tenant != "" {
+		msg.Tenant = tenant
+	}
+	if !redactor.Empty() {
+		msg.Payload = redactor.Payload(msg.Payload)
+		if msg.Previous != nil {
+			msg.Previous = redactor.Payload(msg.Previous)
+		}
+	}
+	if maxObjectSize > 0 {
+		msg = truncate(msg, maxObjectSize)
+	}
+	if b.delta != nil && msg.Previous != nil {
+		if delta, ok := b.delta(msg); ok {
+			msg.DeltaPayload = delta
+		}
+	}
+	return msg
+}
+
+// SetEventCache caches the object events broadcast for compacted
+// subscriptions, it must be called before broadcasting
+func (b *SocketBroadcaster) SetEventCache(events *EventCache) {
+	if events != nil {
+		b.events = events
+	}
+}
+
+// SetDeltaFunc sets how the DeltaPayload of messages with a previous state is
+// computed, once for all subscribers. It must be called before broadcasting
+func (b *SocketBroadcaster) SetDeltaFunc(delta DeltaFunc) {
+	b.delta = delta
+}
+
+// SetTenant sets the identifier included in every message sent to
+// subscribers, an empty identifier leaves messages unchanged
+func (b *SocketBroadcaster) SetTenant(tenant string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.tenant = tenant
+}
+
+// SetRedactor masks the fields of objects before they are sent to
+// subscribers, nil sends them unchanged
+func (b *SocketBroadcaster) SetRedactor(redactor *redact.Redactor) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.redactor = redactor
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"sync"
+	"time"
+)
+
+// ResyncRequired is sent once a Throttle has dropped events, the payload is a
+// ResyncMarker naming the kind clients should list again
+const ResyncRequired MessageType = "ResyncRequired"
+
+// throttleWindow is the period the rate of a Throttle applies to
+const throttleWindow = time.Second
+
+// ResyncMarker is the payload of a ResyncRequired message
+type ResyncMarker struct {
+	Kind string `json:"kind"`
+	// Number of intermediate states of objects that were never sent
+	Dropped int `json:"dropped"`
+}
+
+// Throttle sends at most rate events of a kind per second. Under a higher
+// change rate the events over the rate are held back and only the latest
+// state of each object is sent in the next seconds, followed by a
+// ResyncRequired message if intermediate states were dropped. This bounds the
+// work of the server itself, whatever its subscribers do
+type Throttle struct {
+	kind string
+	rate int
+	send func(SocketData)
+
+	// Guards the fields below, held while sending so the events of an object
+	// are sent in order
+	mutex       sync.Mutex
+	windowStart time.Time
+	count       int
+	// Latest held back event of each object, keys in arrival order
+	pending  map[string]SocketData
+	order    []string
+	dropped  int
+	flushing bool
+}
+
+// NewThrottle returns a Throttle passing at most rate events of a kind per
+// second to send, rate must be positive
+func NewThrottle(kind string, rate int, send func(SocketData)) *Throttle {
+	return &Throttle{kind: kind, rate: rate, send: send, pending: map[string]SocketData{}}
+}
+
+// Send passes the event on straight away while under the rate, or holds it
+// back until the next second. It must not be called holding resources the
+// send function needs
+func (t *Throttle) Send(data SocketData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(t.windowStart) >= throttleWindow {
+		t.windowStart, t.count = now, 0
+	}
+	key, ok := throttleKey(data)
+	if !ok {
+		t.send(data)
+		return
+	}
+	if held, ok := t.pending[key]; ok {
+		// Clients that have not seen the creation must still be told about it
+		if held.MessageType == MessageType(t.kind+"Created") && data.MessageType == MessageType(t.kind+"Updated") {
+			data.MessageType = held.MessageType
+		}
+		// Subscribers last saw the state before the held back event
+		data.Previous = held.Previous
+		t.pending[key] = data
+		t.dropped++
+		return
+	}
+	if t.count < t.rate {
+		t.count++
+		t.send(data)
+		return
+	}
+	t.pending[key] = data
+	t.order = append(t.order, key)
+	if !t.flushing {
+		t.flushing = true
+		time.AfterFunc(t.windowStart.Add(throttleWindow).Sub(now), t.flush)
+	}
+}
+
+// flush sends up to rate held back events at the start of a window, and the
+// ResyncRequired message once they have all been sent
+func (t *Throttle) flush() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.windowStart, t.count = time.Now(), 0
+	for len(t.order) > 0 && t.count < t.rate {
+		key := t.order[0]
+		t.order = t.order[1:]
+		data := t.pending[key]
+		delete(t.pending, key)
+		t.count++
+		t.send(data)
+	}
+	if len(t.order) > 0 {
+		time.AfterFunc(throttleWindow, t.flush)
+		return
+	}
+	t.flushing = false
+	if t.dropped > 0 {
+		t.send(SocketData{MessageType: ResyncRequired, Payload: ResyncMarker{Kind: t.kind, Dropped: t.dropped}})
+		t.dropped = 0
+	}
+}
+
+// throttleKey identifies the object of an event
+func throttleKey(data SocketData) (string, bool) {
+	object, ok := payloadObject(data.Payload)
+	if !ok {
+		return "", false
+	}
+	return object.GetNamespace() + "/" + object.GetName(), true
+}
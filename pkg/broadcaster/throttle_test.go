@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recorder collects the messages a Throttle sends
+type recorder struct {
+	mutex sync.Mutex
+	sent  []SocketData
+}
+
+func (r *recorder) send(data SocketData) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sent = append(r.sent, data)
+}
+
+func (r *recorder) messages() []SocketData {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]SocketData{}, r.sent...)
+}
+
+func version(name string, resourceVersion int) *metav1.ObjectMeta {
+	return &metav1.ObjectMeta{Namespace: "default", Name: name, ResourceVersion: strconv.Itoa(resourceVersion)}
+}
+
+// Under a burst the throttle sends the events within its rate, then only the
+// latest state of each object and a resync marker
+func TestThrottleBurst(t *testing.T) {
+	var recorded recorder
+	throttle := NewThrottle("Task", 5, recorded.send)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		throttle.Send(SocketData{MessageType: TaskCreated, Payload: version(name, 1)})
+	}
+	for resourceVersion := 2; resourceVersion <= 21; resourceVersion++ {
+		for _, name := range []string{"a", "b", "c", "d"} {
+			throttle.Send(SocketData{MessageType: TaskUpdated, Payload: version(name, resourceVersion)})
+		}
+	}
+	throttle.Send(SocketData{MessageType: TaskCreated, Payload: version("f", 1)})
+	throttle.Send(SocketData{MessageType: TaskUpdated, Payload: version("f", 2)})
+
+	describe := func(messages []SocketData) []string {
+		var described []string
+		for _, data := range messages {
+			if marker, ok := data.Payload.(ResyncMarker); ok {
+				described = append(described, fmt.Sprintf("%s %s %d", data.MessageType, marker.Kind, marker.Dropped))
+				continue
+			}
+			object := data.Payload.(*metav1.ObjectMeta)
+			described = append(described, fmt.Sprintf("%s %s@%s", data.MessageType, object.Name, object.ResourceVersion))
+		}
+		return described
+	}
+	immediate := []string{"TaskCreated a@1", "TaskCreated b@1", "TaskCreated c@1", "TaskCreated d@1", "TaskUpdated a@2"}
+	if actual := describe(recorded.messages()); !reflect.DeepEqual(actual, immediate) {
+		t.Fatalf("Expected %v sent within the rate, actual %v", immediate, actual)
+	}
+
+	// The held back events are sent in the next window
+	deadline := time.Now().Add(3 * time.Second)
+	for len(recorded.messages()) < 11 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 86 events were received, 10 states were sent, the creation of f carries
+	// its latest state
+	expected := append(immediate,
+		"TaskUpdated b@21", "TaskUpdated c@21", "TaskUpdated d@21", "TaskUpdated a@21", "TaskCreated f@2",
+		"ResyncRequired Task 76",
+	)
+	if actual := describe(recorded.messages()); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, actual %v", expected, actual)
+	}
+}
+
+// Events under the rate are sent as they are, without a marker
+func TestThrottleUnderRate(t *testing.T) {
+	var recorded recorder
+	throttle := NewThrottle("Task", 10, recorded.send)
+	for resourceVersion := 1; resourceVersion <= 10; resourceVersion++ {
+		throttle.Send(SocketData{MessageType: TaskUpdated, Payload: version("a", resourceVersion)})
+	}
+	throttle.Send(SocketData{MessageType: Log, Payload: "not an object"})
+	time.Sleep(1200 * time.Millisecond)
+	if sent := len(recorded.messages()); sent != 11 {
+		t.Errorf("Expected 11 messages, actual %d", sent)
+	}
+}
+
+// BenchmarkThrottleBurst reports the share of a burst of updates to a few
+// objects that is sent on
+func BenchmarkThrottleBurst(b *testing.B) {
+	var recorded recorder
+	throttle := NewThrottle("Task", 100, recorded.send)
+	objects := make([]*metav1.ObjectMeta, 10)
+	for i := range objects {
+		objects[i] = version(strconv.Itoa(i), i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		throttle.Send(SocketData{MessageType: TaskUpdated, Payload: objects[i%len(objects)]})
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(len(recorded.messages()))/float64(b.N), "sent/op")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ObjectReference identifies an object broadcast without its body, clients
+// fetch the object if they need more
+type ObjectReference struct {
+	Kind            string    `json:"kind"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	UID             types.UID `json:"uid"`
+	ResourceVersion string    `json:"resourceVersion"`
+}
+
+// SetMaxObjectSize sets the size in bytes of the JSON encoding of an object
+// above which it is broadcast as a truncated ObjectReference, 0 means
+// unlimited. Payloads that are not objects are always sent in full
+func (b *SocketBroadcaster) SetMaxObjectSize(size int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.maxObjectSize = size
+}
+
+// truncate replaces the object of a message with a reference when it encodes
+// to more than maxSize bytes
+func truncate(msg SocketData, maxSize int) SocketData {
+	object, ok := payloadObject(msg.Payload)
+	if !ok {
+		return msg
+	}
+	encoded, err := json.Marshal(msg.Payload)
+	if err != nil || len(encoded) <= maxSize {
+		return msg
+	}
+	reference := ObjectReference{
+		Namespace:       object.GetNamespace(),
+		Name:            object.GetName(),
+		UID:             object.GetUID(),
+		ResourceVersion: object.GetResourceVersion(),
+	}
+	if typed, ok := msg.Payload.(runtime.Object); ok {
+		reference.Kind = typed.GetObjectKind().GroupVersionKind().Kind
+	}
+	if reference.Kind == "" {
+		reference.Kind = string(msg.MessageType)
+		for _, suffix := range objectEventSuffixes {
+			reference.Kind = strings.TrimSuffix(reference.Kind, suffix)
+		}
+	}
+	msg.Payload = reference
+	msg.Truncated = true
+	return msg
+}
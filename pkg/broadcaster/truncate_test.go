@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcaster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Objects larger than the maximum size are broadcast as references, smaller
+// objects and other payloads in full
+func TestMaxObjectSize(t *testing.T) {
+	c := make(chan SocketData)
+	broadcaster := NewBroadcaster(c)
+	broadcaster.SetMaxObjectSize(1024)
+	subscriber, _ := broadcaster.Subscribe()
+
+	object := func(name string, statusSize int) *unstructured.Unstructured {
+		run := &unstructured.Unstructured{}
+		run.SetAPIVersion("tekton.dev/v1beta1")
+		run.SetKind("PipelineRun")
+		run.SetNamespace("default")
+		run.SetName(name)
+		run.SetUID(types.UID("uid-" + name))
+		run.SetResourceVersion("7")
+		unstructured.SetNestedField(run.Object, strings.Repeat("x", statusSize), "status", "message")
+		return run
+	}
+	small := object("small", 10)
+	log := strings.Repeat("line\n", 500)
+
+	for _, test := range []struct {
+		sent     SocketData
+		expected SocketData
+	}{
+		{
+			sent:     SocketData{MessageType: PipelineRunUpdated, Payload: small},
+			expected: SocketData{MessageType: PipelineRunUpdated, Payload: small},
+		},
+		{
+			sent: SocketData{MessageType: PipelineRunUpdated, Payload: object("large", 2048)},
+			expected: SocketData{MessageType: PipelineRunUpdated, Truncated: true, Payload: ObjectReference{
+				Kind: "PipelineRun", Namespace: "default", Name: "large", UID: "uid-large", ResourceVersion: "7",
+			}},
+		},
+		{
+			sent:     SocketData{MessageType: Log, Payload: log},
+			expected: SocketData{MessageType: Log, Payload: log},
+		},
+	} {
+		c <- test.sent
+		if received := <-subscriber.SubChan(); !reflect.DeepEqual(received, test.expected) {
+			t.Errorf("Expected %+v, actual %+v", test.expected, received)
+		}
+	}
+}
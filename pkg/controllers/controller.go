@@ -22,30 +22,82 @@ import (
 	kubecontroller "github.com/tektoncd/dashboard/pkg/controllers/kubernetes"
 	tektoncontroller "github.com/tektoncd/dashboard/pkg/controllers/tekton"
 	triggerscontroller "github.com/tektoncd/dashboard/pkg/controllers/triggers"
+	controllerutils "github.com/tektoncd/dashboard/pkg/controllers/utils"
 	"github.com/tektoncd/dashboard/pkg/logging"
 	"github.com/tektoncd/dashboard/pkg/router"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	k8sinformers "k8s.io/client-go/informers"
 	k8sclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 )
 
-func StartTektonControllers(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace string, stopCh <-chan struct{}) {
+// SetMaxEventRate throttles the events of each kind watched by the controllers
+// started afterwards to rate per second, 0 means unlimited
+func SetMaxEventRate(rate int) {
+	controllerutils.SetMaxEventRate(rate)
+}
+
+// SetSendResyncs sends the Updated events of the periodic resyncs of the
+// controllers started afterwards, tagged FromResync, instead of dropping them
+func SetSendResyncs(enabled bool) {
+	controllerutils.SetSendResyncs(enabled)
+}
+
+// SetDeniedNamespaces drops the events of the given namespaces, and of the
+// resources in them, from the controllers started afterwards
+func SetDeniedNamespaces(namespaces []string) {
+	controllerutils.SetDeniedNamespaces(namespaces)
+}
+
+// StartTektonControllers creates and starts Tekton controllers, Tekton
+// Pipelines kinds are watched in the given API version. ClusterTasks are not
+// served by v1 so are always watched in v1beta1. The returned listers read
+// the namespaced kinds from the informer caches, keyed by kind
+func StartTektonControllers(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace, tektonVersion string, stopCh <-chan struct{}) map[string]cache.GenericLister {
 	logging.Log.Info("Creating Tekton controllers")
 	clusterInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(clientset, resyncDur)
 	tenantInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clientset, resyncDur, tenantNamespace, nil)
 
 	tektoncontroller.NewClusterTaskController(clusterInformerFactory)
-	tektoncontroller.NewTaskController(tenantInformerFactory)
-	tektoncontroller.NewTaskRunController(tenantInformerFactory)
-	tektoncontroller.NewPipelineController(tenantInformerFactory)
-	tektoncontroller.NewPipelineRunController(tenantInformerFactory)
-	tektoncontroller.NewConditionController(tenantInformerFactory)
-	tektoncontroller.NewPipelineResourceController(tenantInformerFactory)
+	listers := map[string]cache.GenericLister{
+		"Task":             tektoncontroller.NewTaskController(tenantInformerFactory, tektonVersion),
+		"TaskRun":          tektoncontroller.NewTaskRunController(tenantInformerFactory, tektonVersion),
+		"Pipeline":         tektoncontroller.NewPipelineController(tenantInformerFactory, tektonVersion),
+		"PipelineRun":      tektoncontroller.NewPipelineRunController(tenantInformerFactory, tektonVersion),
+		"Condition":        tektoncontroller.NewConditionController(tenantInformerFactory),
+		"PipelineResource": tektoncontroller.NewPipelineResourceController(tenantInformerFactory),
+	}
 
 	logging.Log.Info("Starting Tekton controllers")
 	clusterInformerFactory.Start(stopCh)
 	tenantInformerFactory.Start(stopCh)
+	return listers
+}
+
+// StartCustomRunController creates and starts the controller for custom task
+// runs served as the given resource, returning a lister reading them from the
+// informer cache
+func StartCustomRunController(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace string, gvr schema.GroupVersionResource, stopCh <-chan struct{}) cache.GenericLister {
+	logging.Log.Info("Creating CustomRun controller")
+	tenantInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clientset, resyncDur, tenantNamespace, nil)
+	lister := tektoncontroller.NewCustomRunController(tenantInformerFactory, gvr)
+	logging.Log.Info("Starting CustomRun controller")
+	tenantInformerFactory.Start(stopCh)
+	return lister
+}
+
+// StartStepActionController creates and starts the controller for StepActions
+// served as the given resource, returning a lister reading them from the
+// informer cache
+func StartStepActionController(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace string, gvr schema.GroupVersionResource, stopCh <-chan struct{}) cache.GenericLister {
+	logging.Log.Info("Creating StepAction controller")
+	tenantInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clientset, resyncDur, tenantNamespace, nil)
+	lister := tektoncontroller.NewStepActionController(tenantInformerFactory, gvr)
+	logging.Log.Info("Starting StepAction controller")
+	tenantInformerFactory.Start(stopCh)
+	return lister
 }
 
 func StartKubeControllers(clientset k8sclientset.Interface, resyncDur time.Duration, tenantNamespace string, readOnly bool, handler *router.Handler, stopCh <-chan struct{}) {
@@ -68,19 +120,25 @@ func StartKubeControllers(clientset k8sclientset.Interface, resyncDur time.Durat
 	tenantInformerFactory.Start(stopCh)
 }
 
-func StartTriggersControllers(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace string, stopCh <-chan struct{}) {
+// StartTriggersControllers creates and starts Triggers controllers, the
+// returned listers read the namespaced kinds from the informer caches, keyed
+// by kind
+func StartTriggersControllers(clientset dynamic.Interface, resyncDur time.Duration, tenantNamespace string, stopCh <-chan struct{}) map[string]cache.GenericLister {
 	logging.Log.Info("Creating Triggers controllers")
 	clusterInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(clientset, resyncDur)
 	tenantInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clientset, resyncDur, tenantNamespace, nil)
 
 	triggerscontroller.NewClusterTriggerBindingController(clusterInformerFactory)
-	triggerscontroller.NewTriggerBindingController(tenantInformerFactory)
-	triggerscontroller.NewTriggerTemplateController(tenantInformerFactory)
-	triggerscontroller.NewEventListenerController(tenantInformerFactory)
+	listers := map[string]cache.GenericLister{
+		"TriggerBinding":  triggerscontroller.NewTriggerBindingController(tenantInformerFactory),
+		"TriggerTemplate": triggerscontroller.NewTriggerTemplateController(tenantInformerFactory),
+		"EventListener":   triggerscontroller.NewEventListenerController(tenantInformerFactory),
+	}
 
 	logging.Log.Info("Starting Triggers controllers")
 	clusterInformerFactory.Start(stopCh)
 	tenantInformerFactory.Start(stopCh)
+	return listers
 }
 
 // StartDashboardControllers creates and starts Dashboard controllers
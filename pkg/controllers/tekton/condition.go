@@ -19,9 +19,10 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewConditionController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewConditionController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) cache.GenericLister {
 	logging.Log.Debug("In NewConditionController")
 
 	gvr := schema.GroupVersionResource{
@@ -30,12 +31,14 @@ func NewConditionController(sharedInformerFactory dynamicinformer.DynamicSharedI
 		Resource: "conditions",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"Condition",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.ConditionCreated,
 		broadcaster.ConditionUpdated,
 		broadcaster.ConditionDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
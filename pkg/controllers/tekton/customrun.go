@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tekton
+
+import (
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	"github.com/tektoncd/dashboard/pkg/controllers/utils"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewCustomRunController watches custom task runs, served as either v1beta1
+// CustomRuns or v1alpha1 Runs depending on the Tekton release, and returns a
+// lister reading them from the informer's cache
+func NewCustomRunController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory, gvr schema.GroupVersionResource) cache.GenericLister {
+	logging.Log.Debug("In NewCustomRunController")
+
+	informer := sharedInformerFactory.ForResource(gvr)
+	utils.NewController(
+		"CustomRun",
+		informer.Informer(),
+		broadcaster.CustomRunCreated,
+		broadcaster.CustomRunUpdated,
+		broadcaster.CustomRunDeleted,
+		nil,
+	)
+	return informer.Lister()
+}
@@ -19,23 +19,26 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewPipelineController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewPipelineController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory, version string) cache.GenericLister {
 	logging.Log.Debug("In NewPipelineController")
 
 	gvr := schema.GroupVersionResource{
 		Group:    "tekton.dev",
-		Version:  "v1beta1",
+		Version:  version,
 		Resource: "pipelines",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"Pipeline",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.PipelineCreated,
 		broadcaster.PipelineUpdated,
 		broadcaster.PipelineDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
@@ -19,9 +19,10 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewPipelineResourceController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewPipelineResourceController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) cache.GenericLister {
 	logging.Log.Debug("In NewPipelineResourceController")
 
 	gvr := schema.GroupVersionResource{
@@ -30,12 +31,14 @@ func NewPipelineResourceController(sharedInformerFactory dynamicinformer.Dynamic
 		Resource: "pipelineresources",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"PipelineResource",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.PipelineResourceCreated,
 		broadcaster.PipelineResourceUpdated,
 		broadcaster.PipelineResourceDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
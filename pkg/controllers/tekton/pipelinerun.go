@@ -19,23 +19,28 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewPipelineRunController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+// NewPipelineRunController broadcasts PipelineRun events and returns a lister
+// reading PipelineRuns from the informer's cache
+func NewPipelineRunController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory, version string) cache.GenericLister {
 	logging.Log.Debug("In NewPipelineRunController")
 
 	gvr := schema.GroupVersionResource{
 		Group:    "tekton.dev",
-		Version:  "v1beta1",
+		Version:  version,
 		Resource: "pipelineruns",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"PipelineRun",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.PipelineRunCreated,
 		broadcaster.PipelineRunUpdated,
 		broadcaster.PipelineRunDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
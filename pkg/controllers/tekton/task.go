@@ -19,23 +19,26 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewTaskController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewTaskController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory, version string) cache.GenericLister {
 	logging.Log.Debug("In NewTaskController")
 
 	gvr := schema.GroupVersionResource{
 		Group:    "tekton.dev",
-		Version:  "v1beta1",
+		Version:  version,
 		Resource: "tasks",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"Task",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.TaskCreated,
 		broadcaster.TaskUpdated,
 		broadcaster.TaskDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
@@ -19,23 +19,26 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewTaskRunController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewTaskRunController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory, version string) cache.GenericLister {
 	logging.Log.Debug("In NewTaskRunController")
 
 	gvr := schema.GroupVersionResource{
 		Group:    "tekton.dev",
-		Version:  "v1beta1",
+		Version:  version,
 		Resource: "taskruns",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"TaskRun",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.TaskRunCreated,
 		broadcaster.TaskRunUpdated,
 		broadcaster.TaskRunDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
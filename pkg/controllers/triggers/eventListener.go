@@ -19,9 +19,10 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewEventListenerController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewEventListenerController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) cache.GenericLister {
 	logging.Log.Debug("In NewEventListenerController")
 
 	gvr := schema.GroupVersionResource{
@@ -30,12 +31,14 @@ func NewEventListenerController(sharedInformerFactory dynamicinformer.DynamicSha
 		Resource: "eventlisteners",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"EventListener",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.EventListenerCreated,
 		broadcaster.EventListenerUpdated,
 		broadcaster.EventListenerDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
@@ -19,9 +19,10 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewTriggerBindingController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewTriggerBindingController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) cache.GenericLister {
 	logging.Log.Debug("In NewTriggerBindingController")
 
 	gvr := schema.GroupVersionResource{
@@ -30,12 +31,14 @@ func NewTriggerBindingController(sharedInformerFactory dynamicinformer.DynamicSh
 		Resource: "triggerbindings",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"TriggerBinding",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.TriggerBindingCreated,
 		broadcaster.TriggerBindingUpdated,
 		broadcaster.TriggerBindingDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
@@ -19,9 +19,10 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 )
 
-func NewTriggerTemplateController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) {
+func NewTriggerTemplateController(sharedInformerFactory dynamicinformer.DynamicSharedInformerFactory) cache.GenericLister {
 	logging.Log.Debug("In NewTriggerTemplateController")
 
 	gvr := schema.GroupVersionResource{
@@ -30,12 +31,14 @@ func NewTriggerTemplateController(sharedInformerFactory dynamicinformer.DynamicS
 		Resource: "triggertemplates",
 	}
 
+	informer := sharedInformerFactory.ForResource(gvr)
 	utils.NewController(
 		"TriggerTemplate",
-		sharedInformerFactory.ForResource(gvr).Informer(),
+		informer.Informer(),
 		broadcaster.TriggerTemplateCreated,
 		broadcaster.TriggerTemplateUpdated,
 		broadcaster.TriggerTemplateDeleted,
 		nil,
 	)
+	return informer.Lister()
 }
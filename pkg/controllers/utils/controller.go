@@ -22,6 +22,37 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
+// maxEventRate is the number of events of each kind sent per second before
+// they are throttled, 0 means unlimited
+var maxEventRate int
+
+// SetMaxEventRate throttles the events of each kind of the controllers created
+// afterwards to rate per second, see broadcaster.Throttle. 0 means unlimited
+func SetMaxEventRate(rate int) {
+	maxEventRate = rate
+}
+
+// sendResyncs sends the Updated events of periodic resyncs instead of
+// dropping them
+var sendResyncs bool
+
+// SetSendResyncs sends the Updated events of the periodic resyncs of the
+// controllers created afterwards, with FromResync set so clients can tell
+// them from changes. By default they are dropped
+func SetSendResyncs(enabled bool) {
+	sendResyncs = enabled
+}
+
+// deniedNamespaces are the namespaces whose events, and the events of their
+// resources, are not sent
+var deniedNamespaces []string
+
+// SetDeniedNamespaces drops the events of the given namespaces and of the
+// resources in them for the controllers created afterwards
+func SetDeniedNamespaces(namespaces []string) {
+	deniedNamespaces = namespaces
+}
+
 func NewController(kind string, informer cache.SharedIndexInformer, onCreated, onUpdated, onDeleted broadcaster.MessageType, filter func(interface{}, bool) interface{}) {
 	logging.Log.Debug("In NewController")
 
@@ -31,16 +62,29 @@ func NewController(kind string, informer cache.SharedIndexInformer, onCreated, o
 		}
 	}
 
+	send := func(data broadcaster.SocketData) {
+		endpoints.ResourcesChannel <- data
+	}
+	if maxEventRate > 0 {
+		send = broadcaster.NewThrottle(kind, maxEventRate, send).Send
+	}
+
 	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
+			if utils.InDeniedNamespace(deniedNamespaces, obj) {
+				return
+			}
 			logging.Log.Debugf("Controller detected %s '%s' created", kind, obj.(metav1.Object).GetName())
 			data := broadcaster.SocketData{
 				MessageType: onCreated,
 				Payload:     filter(obj, true),
 			}
-			endpoints.ResourcesChannel <- data
+			send(data)
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
+			if utils.InDeniedNamespace(deniedNamespaces, newObj) {
+				return
+			}
 			oldResource, newResource := oldObj.(metav1.Object), newObj.(metav1.Object)
 			// If resourceVersion differs between old and new, an actual update event was observed
 			if oldResource.GetResourceVersion() != newResource.GetResourceVersion() {
@@ -48,17 +92,32 @@ func NewController(kind string, informer cache.SharedIndexInformer, onCreated, o
 				data := broadcaster.SocketData{
 					MessageType: onUpdated,
 					Payload:     filter(newObj, true),
+					Previous:    filter(oldObj, true),
+				}
+				send(data)
+				if change, changed := broadcaster.NewOwnershipChange(kind, oldResource, newResource); changed {
+					logging.Log.Debugf("Controller detected %s '%s' ownership changed", kind, newResource.GetName())
+					send(broadcaster.SocketData{MessageType: broadcaster.OwnershipChanged, Payload: change})
+				}
+			} else if sendResyncs {
+				data := broadcaster.SocketData{
+					MessageType: onUpdated,
+					Payload:     filter(newObj, true),
+					FromResync:  true,
 				}
-				endpoints.ResourcesChannel <- data
+				send(data)
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
+			if utils.InDeniedNamespace(deniedNamespaces, obj) {
+				return
+			}
 			logging.Log.Debugf("Controller detected %s '%s' deleted", kind, utils.GetDeletedObjectMeta(obj).GetName())
 			data := broadcaster.SocketData{
 				MessageType: onDeleted,
 				Payload:     filter(obj, false),
 			}
-			endpoints.ResourcesChannel <- data
+			send(data)
 		},
 	})
 }
@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	"github.com/tektoncd/dashboard/pkg/endpoints"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamicclientset "k8s.io/client-go/dynamic/fake"
+	k8sinformers "k8s.io/client-go/informers"
+	fakek8sclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+// Resync events are sent tagged FromResync, changes are not
+func TestSendResyncs(t *testing.T) {
+	SetSendResyncs(true)
+	defer SetSendResyncs(false)
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	client := fakedynamicclientset.NewSimpleDynamicClient(runtime.NewScheme())
+	task := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "Task",
+		"metadata":   map[string]interface{}{"name": "resynced", "namespace": "resync", "resourceVersion": "1"},
+	}}
+	if _, err := client.Resource(gvr).Namespace("resync").Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	subscriber, _ := endpoints.ResourcesBroadcaster.Subscribe(broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		object, ok := data.Payload.(metav1.Object)
+		return ok && data.MessageType == broadcaster.TaskUpdated && object.GetNamespace() == "resync"
+	}))
+	defer endpoints.ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	// Informers resync at most once a second
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, time.Second, "resync", nil)
+	NewController("Task", factory.ForResource(gvr).Informer(), broadcaster.TaskCreated, broadcaster.TaskUpdated, broadcaster.TaskDeleted, nil)
+	factory.Start(stopCh)
+
+	timeout := time.After(10 * time.Second)
+	// Events of the resourceVersion last seen are resyncs
+	lastVersion := "1"
+	resynced, updated, changed := false, false, false
+	for !resynced || !changed {
+		select {
+		case data := <-subscriber.SubChan():
+			resourceVersion := data.Payload.(metav1.Object).GetResourceVersion()
+			if data.FromResync != (resourceVersion == lastVersion) {
+				t.Fatalf("Event of resourceVersion %s after %s: expected FromResync %t, actual %t", resourceVersion, lastVersion, !data.FromResync, data.FromResync)
+			}
+			if data.FromResync {
+				resynced = true
+			} else {
+				changed = true
+				lastVersion = resourceVersion
+			}
+		case <-timeout:
+			t.Fatalf("Expected a resync and a change, resynced %t, changed %t", resynced, changed)
+		}
+		if resynced && !updated {
+			task.SetResourceVersion("2")
+			task.SetLabels(map[string]string{"changed": "true"})
+			if _, err := client.Resource(gvr).Namespace("resync").Update(task, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("Error updating task: %v", err)
+			}
+			updated = true
+		}
+	}
+}
+
+// Events of denied namespaces and of their resources are not sent
+func TestDeniedNamespaces(t *testing.T) {
+	SetDeniedNamespaces([]string{"denied"})
+	defer SetDeniedNamespaces(nil)
+
+	subscriber, _ := endpoints.ResourcesBroadcaster.Subscribe(broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		return data.MessageType == broadcaster.NamespaceCreated || data.MessageType == broadcaster.TaskCreated
+	}))
+	defer endpoints.ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	k8sClient := fakek8sclientset.NewSimpleClientset()
+	for _, name := range []string{"denied", "allowed"} {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if _, err := k8sClient.CoreV1().Namespaces().Create(namespace); err != nil {
+			t.Fatalf("Error creating namespace: %v", err)
+		}
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	dynamicClient := fakedynamicclientset.NewSimpleDynamicClient(runtime.NewScheme())
+	for _, namespace := range []string{"denied", "allowed"} {
+		task := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tekton.dev/v1beta1",
+			"kind":       "Task",
+			"metadata":   map[string]interface{}{"name": "task", "namespace": namespace, "resourceVersion": "1"},
+		}}
+		if _, err := dynamicClient.Resource(gvr).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	k8sFactory := k8sinformers.NewSharedInformerFactory(k8sClient, 0)
+	NewController("Namespace", k8sFactory.Core().V1().Namespaces().Informer(), broadcaster.NamespaceCreated, broadcaster.NamespaceUpdated, broadcaster.NamespaceDeleted, nil)
+	dynamicFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	NewController("Task", dynamicFactory.ForResource(gvr).Informer(), broadcaster.TaskCreated, broadcaster.TaskUpdated, broadcaster.TaskDeleted, nil)
+	k8sFactory.Start(stopCh)
+	dynamicFactory.Start(stopCh)
+
+	received := map[broadcaster.MessageType]bool{}
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case data := <-subscriber.SubChan():
+			object := data.Payload.(metav1.Object)
+			if object.GetName() == "denied" || object.GetNamespace() == "denied" {
+				t.Fatalf("Expected no events of the denied namespace, actual %s of %s/%s", data.MessageType, object.GetNamespace(), object.GetName())
+			}
+			received[data.MessageType] = true
+		case <-timeout:
+			t.Fatalf("Expected events of the allowed namespace, actual %v", received)
+		}
+	}
+	// Events of the denied namespace may come after those of the allowed one
+	select {
+	case data := <-subscriber.SubChan():
+		object := data.Payload.(metav1.Object)
+		if object.GetName() == "denied" || object.GetNamespace() == "denied" {
+			t.Fatalf("Expected no events of the denied namespace, actual %s of %s/%s", data.MessageType, object.GetNamespace(), object.GetName())
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// Adding an ownerReference sends OwnershipChanged with the old and new owners
+func TestOwnershipChanged(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	client := fakedynamicclientset.NewSimpleDynamicClient(runtime.NewScheme())
+	taskRun := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "TaskRun",
+		"metadata":   map[string]interface{}{"name": "adopted", "namespace": "ownership", "uid": "taskrun-uid", "resourceVersion": "1"},
+	}}
+	if _, err := client.Resource(gvr).Namespace("ownership").Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	subscriber, _ := endpoints.ResourcesBroadcaster.Subscribe(broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		if data.MessageType == broadcaster.TaskRunCreated {
+			return data.Payload.(metav1.Object).GetNamespace() == "ownership"
+		}
+		change, ok := data.Payload.(broadcaster.OwnershipChange)
+		return ok && change.Namespace == "ownership"
+	}))
+	defer endpoints.ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, "ownership", nil)
+	NewController("TaskRun", factory.ForResource(gvr).Informer(), broadcaster.TaskRunCreated, broadcaster.TaskRunUpdated, broadcaster.TaskRunDeleted, nil)
+	factory.Start(stopCh)
+
+	timeout := time.After(5 * time.Second)
+	select {
+	case <-subscriber.SubChan():
+	case <-timeout:
+		t.Fatal("Expected TaskRunCreated event")
+	}
+
+	owner := metav1.OwnerReference{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun", Name: "pipelinerun", UID: "pipelinerun-uid"}
+	taskRun.SetOwnerReferences([]metav1.OwnerReference{owner})
+	taskRun.SetResourceVersion("2")
+	if _, err := client.Resource(gvr).Namespace("ownership").Update(taskRun, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating taskRun: %v", err)
+	}
+
+	select {
+	case data := <-subscriber.SubChan():
+		change := data.Payload.(broadcaster.OwnershipChange)
+		if change.Kind != "TaskRun" || change.Name != "adopted" || change.UID != "taskrun-uid" {
+			t.Errorf("Expected ownership change of TaskRun adopted, actual %+v", change)
+		}
+		if len(change.OldOwners) != 0 {
+			t.Errorf("Expected no old owners, actual %+v", change.OldOwners)
+		}
+		if len(change.NewOwners) != 1 || change.NewOwners[0].UID != owner.UID || change.NewOwners[0].Name != owner.Name {
+			t.Errorf("Expected new owner %+v, actual %+v", owner, change.NewOwners)
+		}
+	case <-timeout:
+		t.Fatal("Expected OwnershipChanged event")
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+// RequireWriteAccess is a route filter rejecting requests that modify
+// resources when the dashboard is running in read-only mode
+func (r Resource) RequireWriteAccess(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+	if r.Options.ReadOnly {
+		utils.RespondErrorMessage(response, "the dashboard is running in read-only mode", http.StatusForbidden)
+		return
+	}
+	chain.ProcessFilter(request, response)
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Number of step log streams a single active logs client may have open
+const (
+	defaultActiveLogStreams = 20
+	maxActiveLogStreams     = 100
+)
+
+// ActiveLogLine is a line of the log of a step of a running TaskRun
+type ActiveLogLine struct {
+	TaskRun string `json:"taskRun"`
+	Step    string `json:"step"`
+	Text    string `json:"text"`
+}
+
+// ActiveLogsCapped is sent when the logs of a TaskRun are not streamed
+// because the client already has Limit streams open
+type ActiveLogsCapped struct {
+	TaskRun string `json:"taskRun"`
+	Limit   int    `json:"limit"`
+}
+
+// activeLogStream is a step log stream, with the TaskRun it belongs to
+type activeLogStream struct {
+	taskRun string
+	stream  io.Closer
+}
+
+// activeLogs tracks the step log streams opened for an active logs client.
+// It is only used from the goroutine writing to the client
+type activeLogs struct {
+	resource  Resource
+	namespace string
+	limit     int
+	open      int
+	grep      *regexp.Regexp
+	// streams are the open streams of each TaskRun, TaskRuns whose streams
+	// all ended stay in started so their logs are not sent twice
+	streams map[string][]io.Closer
+	started map[string]bool
+	lines   chan broadcaster.SocketData
+	ended   chan activeLogStream
+	done    chan struct{}
+}
+
+// EstablishActiveLogsWebsocket streams the step logs of all the running
+// TaskRuns of a namespace as Log messages tagged with their TaskRun and step.
+// TaskRuns that start while connected join the stream. The maxStreams
+// parameter caps the number of step logs streamed at once, the logs of
+// TaskRuns over the cap are not streamed and a LogStreamsCapped message is
+// sent instead. grep only sends the lines matching a regular expression
+func (r Resource) EstablishActiveLogsWebsocket(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	limit, err := intParameter(request, "maxStreams", defaultActiveLogStreams, maxActiveLogStreams)
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	grep, err := grepParameter(request)
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !acceptSubscriber(response, broadcaster.PriorityNormal) {
+		return
+	}
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+
+	// Subscribe before listing so no TaskRun starting in between is missed
+	subscriber, _ := ResourcesBroadcaster.Subscribe(broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		if data.MessageType != broadcaster.TaskRunCreated && data.MessageType != broadcaster.TaskRunUpdated && data.MessageType != broadcaster.TaskRunDeleted {
+			return false
+		}
+		object, ok := payloadMeta(data.Payload)
+		return ok && object.GetNamespace() == namespace
+	}))
+	logs := &activeLogs{
+		resource:  r,
+		namespace: namespace,
+		limit:     limit,
+		grep:      grep,
+		streams:   map[string][]io.Closer{},
+		started:   map[string]bool{},
+		lines:     make(chan broadcaster.SocketData),
+		ended:     make(chan activeLogStream),
+		done:      make(chan struct{}),
+	}
+	defer logs.close()
+	lost := make(chan struct{})
+	websocket.MonitorConnection(connection, func() {
+		ResourcesBroadcaster.Unsubscribe(subscriber)
+		close(lost)
+	})
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		logging.Log.Errorf("Error listing TaskRuns of namespace %s: %s", namespace, err)
+		websocket.ReportClosing(connection)
+		return
+	}
+	send := func(data broadcaster.SocketData) bool {
+		return websocket.Send(connection, data)
+	}
+	for i := range taskRuns.Items {
+		if !logs.update(&taskRuns.Items[i], false, send) {
+			return
+		}
+	}
+	for {
+		select {
+		case data := <-subscriber.SubChan():
+			taskRun, ok := data.Payload.(*unstructured.Unstructured)
+			if ok && !logs.update(taskRun, data.MessageType == broadcaster.TaskRunDeleted, send) {
+				return
+			}
+		case line := <-logs.lines:
+			if !websocket.Send(connection, line) {
+				return
+			}
+		case ended := <-logs.ended:
+			logs.remove(ended)
+		case <-subscriber.UnsubChan():
+			if subscriber.Evicted() {
+				websocket.ReportOverloaded(connection)
+			}
+			return
+		case <-lost:
+			return
+		}
+	}
+}
+
+// update starts streaming the logs of a TaskRun once it is running with a
+// pod, or stops streaming them once it is deleted. It returns false if the
+// client has gone away
+func (l *activeLogs) update(taskRun *unstructured.Unstructured, deleted bool, send func(broadcaster.SocketData) bool) bool {
+	name := taskRun.GetName()
+	if deleted {
+		for _, stream := range l.streams[name] {
+			stream.Close()
+		}
+		delete(l.started, name)
+		return true
+	}
+	if l.started[name] || runStatus(taskRun) != RunRunning {
+		return true
+	}
+	pod, err := l.resource.taskRunPod(taskRun)
+	if err != nil {
+		// The pod is not created yet, the TaskRun is updated once it is
+		return true
+	}
+	var steps []string
+	for _, container := range pod.Spec.Containers {
+		if strings.HasPrefix(container.Name, stepContainerPrefix) {
+			steps = append(steps, container.Name)
+		}
+	}
+	if l.open+len(steps) > l.limit {
+		return send(broadcaster.SocketData{MessageType: broadcaster.LogStreamsCapped, Payload: ActiveLogsCapped{TaskRun: name, Limit: l.limit}})
+	}
+	l.started[name] = true
+	for _, container := range steps {
+		l.stream(name, pod, container)
+	}
+	return true
+}
+
+// stream opens the log of a step and forwards its lines until it ends
+func (l *activeLogs) stream(taskRun string, pod *corev1.Pod, container string) {
+	if !LogStreams.acquire() {
+		logging.Log.Warnf("Not streaming the log of %s of TaskRun %s, too many concurrent log streams", container, taskRun)
+		return
+	}
+	stream, err := PodLogs(l.resource.K8sClient, l.namespace, pod.Name, &corev1.PodLogOptions{Container: container, Follow: true})
+	if err != nil {
+		LogStreams.release()
+		logging.Log.Errorf("Error streaming the log of %s of TaskRun %s: %s", container, taskRun, err)
+		return
+	}
+	l.open++
+	l.streams[taskRun] = append(l.streams[taskRun], stream)
+	step := strings.TrimPrefix(container, stepContainerPrefix)
+	go func() {
+		defer LogStreams.release()
+		defer stream.Close()
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+		for scanner.Scan() {
+			if l.grep != nil && !l.grep.MatchString(scanner.Text()) {
+				continue
+			}
+			line := broadcaster.SocketData{MessageType: broadcaster.Log, Payload: ActiveLogLine{TaskRun: taskRun, Step: step, Text: scanner.Text()}}
+			select {
+			case l.lines <- line:
+			case <-l.done:
+				return
+			}
+		}
+		select {
+		case l.ended <- activeLogStream{taskRun: taskRun, stream: stream}:
+		case <-l.done:
+		}
+	}()
+}
+
+// remove forgets a stream that has ended
+func (l *activeLogs) remove(ended activeLogStream) {
+	streams := l.streams[ended.taskRun]
+	for i, stream := range streams {
+		if stream == ended.stream {
+			l.open--
+			streams = append(streams[:i], streams[i+1:]...)
+			break
+		}
+	}
+	if len(streams) == 0 {
+		delete(l.streams, ended.taskRun)
+	} else {
+		l.streams[ended.taskRun] = streams
+	}
+}
+
+// close stops all the streams of a client
+func (l *activeLogs) close() {
+	close(l.done)
+	for _, streams := range l.streams {
+		for _, stream := range streams {
+			stream.Close()
+		}
+	}
+}
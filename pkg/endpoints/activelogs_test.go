@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// createRunningTaskRun creates a running TaskRun and its pod with a step
+func createRunningTaskRun(r *Resource, t *testing.T, namespace, name, step string) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-" + step}},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name, "1")
+	unstructured.SetNestedField(taskRun.Object, name+"-pod", "status", "podName")
+	unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+		map[string]interface{}{"type": "Succeeded", "status": "Unknown", "reason": "Running"},
+	}, "status", "conditions")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+}
+
+// readActiveLogLines reads log lines until one of each expected TaskRun
+// arrived
+func readActiveLogLines(t *testing.T, connection *gorillaSocket.Conn, taskRuns ...string) map[string][]ActiveLogLine {
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	lines := map[string][]ActiveLogLine{}
+	for {
+		arrived := true
+		for _, taskRun := range taskRuns {
+			arrived = arrived && len(lines[taskRun]) > 0
+		}
+		if arrived {
+			return lines
+		}
+		var socketData broadcaster.SocketData
+		if err := connection.ReadJSON(&socketData); err != nil {
+			t.Fatalf("Error reading log lines of %v, received %+v: %s", taskRuns, lines, err)
+		}
+		if socketData.MessageType != broadcaster.Log {
+			t.Fatalf("Expected a log line, actual %s", socketData.MessageType)
+		}
+		var line ActiveLogLine
+		payload, _ := json.Marshal(socketData.Payload)
+		json.Unmarshal(payload, &line)
+		lines[line.TaskRun] = append(lines[line.TaskRun], line)
+	}
+}
+
+// The active logs websocket tags the lines of the running TaskRuns, and
+// streams the logs of TaskRuns started while connected
+func TestActiveLogsWebsocket(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{
+		"step-build":  "compiling\n",
+		"step-test":   "testing\n",
+		"step-deploy": "deploying\n",
+	})()
+
+	createRunningTaskRun(r, t, namespace, "build", "build")
+	createRunningTaskRun(r, t, namespace, "test", "test")
+
+	websocketURL := url.URL{
+		Scheme: "ws",
+		Host:   strings.TrimPrefix(server.URL, "http://"),
+		Path:   fmt.Sprintf("/v1/websockets/namespaces/%s/active-logs", namespace),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+
+	lines := readActiveLogLines(t, connection, "build", "test")
+	for taskRun, expected := range map[string]ActiveLogLine{
+		"build": {TaskRun: "build", Step: "build", Text: "compiling"},
+		"test":  {TaskRun: "test", Step: "test", Text: "testing"},
+	} {
+		if len(lines[taskRun]) != 1 || lines[taskRun][0] != expected {
+			t.Errorf("Expected line %+v, actual %+v", expected, lines[taskRun])
+		}
+	}
+
+	createRunningTaskRun(r, t, namespace, "deploy", "deploy")
+	lines = readActiveLogLines(t, connection, "deploy")
+	expected := ActiveLogLine{TaskRun: "deploy", Step: "deploy", Text: "deploying"}
+	if len(lines["deploy"]) != 1 || lines["deploy"][0] != expected {
+		t.Errorf("Expected line %+v, actual %+v", expected, lines["deploy"])
+	}
+}
+
+// The active logs websocket only sends the lines matching grep
+func TestActiveLogsWebsocketGrep(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{
+		"step-build": "compiling\nwarning: unused variable\nlinking\n",
+	})()
+
+	createRunningTaskRun(r, t, namespace, "build", "build")
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     fmt.Sprintf("/v1/websockets/namespaces/%s/active-logs", namespace),
+		RawQuery: "grep=warning",
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+
+	lines := readActiveLogLines(t, connection, "build")
+	expected := ActiveLogLine{TaskRun: "build", Step: "build", Text: "warning: unused variable"}
+	if len(lines["build"]) != 1 || lines["build"][0] != expected {
+		t.Errorf("Expected line %+v, actual %+v", expected, lines["build"])
+	}
+	// Lines after the match are not sent either
+	connection.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	var socketData broadcaster.SocketData
+	if err := connection.ReadJSON(&socketData); err == nil {
+		t.Errorf("Expected no further lines, actual %+v", socketData)
+	}
+
+	websocketURL.RawQuery = "grep=(unclosed"
+	if status, _ := dialUpgradeError(websocketURL.String(), nil, t); status != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid grep, actual %d", http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8sclientset "k8s.io/client-go/kubernetes"
+)
+
+// UserHeader carries the name of the user authenticated by the proxy in front
+// of the dashboard, as set by oauth2-proxy
+const UserHeader = "X-Forwarded-User"
+
+// Authorizer decides whether a user may perform a verb on a kind of resource.
+// Downstream builds may set their own on Resource to replace Kubernetes RBAC
+type Authorizer interface {
+	Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error)
+}
+
+// AccessReview is whether a user may perform a verb on an object, and why
+type AccessReview struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// AccessReviewer is implemented by Authorizers able to explain their decisions
+type AccessReviewer interface {
+	Review(ctx context.Context, user, verb, kind, namespace, name string) (AccessReview, error)
+}
+
+// SubjectAccessReviewAuthorizer asks the Kubernetes API server whether the
+// user is allowed using a SubjectAccessReview
+type SubjectAccessReviewAuthorizer struct {
+	Client k8sclientset.Interface
+	// Options decide the API versions of the kinds checked
+	Options Options
+}
+
+// Authorize implements Authorizer. Requests without a user, as when no proxy
+// forwards one, are decided by the RBAC of the dashboard's own service account
+func (a SubjectAccessReviewAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	review, err := a.Review(ctx, user, verb, kind, namespace, name)
+	return review.Allowed, err
+}
+
+// Review implements AccessReviewer, without a user the dashboard's own service
+// account is reviewed with a SelfSubjectAccessReview
+func (a SubjectAccessReviewAuthorizer) Review(ctx context.Context, user, verb, kind, namespace, name string) (AccessReview, error) {
+	resourceKind, ok := lookupAnyKind(a.Options, kind)
+	if !ok {
+		return AccessReview{}, fmt.Errorf("unknown kind '%s'", kind)
+	}
+	attributes := &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     resourceKind.GVR.Group,
+		Version:   resourceKind.GVR.Version,
+		Resource:  resourceKind.GVR.Resource,
+		Name:      name,
+	}
+	var status authorizationv1.SubjectAccessReviewStatus
+	if user == "" {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}
+		result, err := a.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(review)
+		if err != nil {
+			return AccessReview{}, err
+		}
+		status = result.Status
+	} else {
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{User: user, ResourceAttributes: attributes},
+		}
+		result, err := a.Client.AuthorizationV1().SubjectAccessReviews().Create(review)
+		if err != nil {
+			return AccessReview{}, err
+		}
+		status = result.Status
+	}
+	return AccessReview{Allowed: status.Allowed, Reason: status.Reason}, nil
+}
+
+// Authorize returns a route filter consulting the Authorizer, if any, before
+// the handler runs. The namespace and name are read from the path parameters
+func (r Resource) Authorize(verb, kind string) restful.FilterFunction {
+	return func(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+		if r.checkAuthorized(request, response, verb, kind, request.PathParameter("namespace"), request.PathParameter("name")) {
+			chain.ProcessFilter(request, response)
+		}
+	}
+}
+
+// checkAuthorized consults the Authorizer, if any, and responds with an error
+// unless the request is allowed
+func (r Resource) checkAuthorized(request *restful.Request, response *restful.Response, verb, kind, namespace, name string) bool {
+	allowed, err := r.authorized(request, verb, kind, namespace, name)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		user := request.HeaderParameter(UserHeader)
+		if websocket.IsUpgradeRequest(request.Request) {
+			// Websocket clients get a JSON error, and a 401 when the proxy
+			// no longer forwards a user so they can authenticate again
+			if user == "" {
+				websocket.RespondUpgradeError(response, http.StatusUnauthorized, "no authenticated user")
+				return false
+			}
+			websocket.RespondUpgradeError(response, http.StatusForbidden, fmt.Sprintf("user '%s' cannot %s %s in namespace %s", user, verb, kind, namespace))
+			return false
+		}
+		utils.RespondErrorMessage(response, fmt.Sprintf("user '%s' cannot %s %s in namespace %s", user, verb, kind, namespace), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorized checks the requesting user against the Authorizer, for handlers
+// acting on several kinds or objects that cannot be checked by a route filter
+func (r Resource) authorized(request *restful.Request, verb, kind, namespace, name string) (bool, error) {
+	if r.Authorizer == nil {
+		return true, nil
+	}
+	user := request.HeaderParameter(UserHeader)
+	allowed, err := r.Authorizer.Authorize(request.Request.Context(), user, verb, kind, namespace, name)
+	if err != nil {
+		logging.Log.Errorf("Error authorizing %s %s for user '%s': %s", verb, kind, user, err)
+	}
+	return allowed, err
+}
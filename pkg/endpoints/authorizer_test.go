@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakek8sclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// denyKindAuthorizer denies every request on a single kind
+type denyKindAuthorizer struct {
+	kind string
+}
+
+func (a denyKindAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	return kind != a.kind, nil
+}
+
+// Routes are blocked when the Authorizer denies access to their kind
+func TestAuthorizerDeniesKind(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = denyKindAuthorizer{kind: "TaskRun"}
+	server.Config.Handler = router.Register(*r)
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "secret", "1")
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/secret/steps", server.URL, namespace), nil)
+	httpReq.Header.Set(UserHeader, "alice")
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting steps: %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusForbidden, response.StatusCode)
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/waittimes", server.URL, namespace), nil)
+	httpReq.Header.Set(UserHeader, "alice")
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting wait times: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d for allowed kind, actual %d", http.StatusOK, response.StatusCode)
+	}
+}
+
+// Requests without a forwarded user are decided by a SelfSubjectAccessReview
+// of the dashboard's service account rather than allowed
+func TestSubjectAccessReviewAuthorizerWithoutUser(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = SubjectAccessReviewAuthorizer{Client: r.K8sClient, Options: r.Options}
+	server.Config.Handler = router.Register(*r)
+
+	allowed := false
+	r.K8sClient.(*fakek8sclientset.Clientset).PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: allowed}
+		return true, review, nil
+	})
+
+	url := fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/waittimes", server.URL, namespace)
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting wait times: %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected statusCode %d without a user, actual %d", http.StatusForbidden, response.StatusCode)
+	}
+
+	allowed = true
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting wait times: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d when the service account is allowed, actual %d", http.StatusOK, response.StatusCode)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MaxBatchSize is the maximum number of references accepted by a single batch request
+const MaxBatchSize = 50
+
+// ObjectReference identifies a namespaced object by kind and name
+type ObjectReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// BatchResult is the outcome of fetching one reference of a batch, either the
+// object or the error and status code returned for it
+type BatchResult struct {
+	Kind   string                     `json:"kind"`
+	Name   string                     `json:"name"`
+	Object *unstructured.Unstructured `json:"object,omitempty"`
+	Status int                        `json:"status"`
+	Error  string                     `json:"error,omitempty"`
+}
+
+// BatchGet fetches a list of object references from a namespace concurrently.
+// Results are returned in request order, a missing or unknown object is
+// reported in its own result rather than failing the request
+func (r Resource) BatchGet(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	var references []ObjectReference
+	if err := request.ReadEntity(&references); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if len(references) > MaxBatchSize {
+		utils.RespondErrorMessage(response, fmt.Sprintf("batch of %d references exceeds the maximum of %d", len(references), MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	results := make([]BatchResult, len(references))
+	var wg sync.WaitGroup
+	for i, reference := range references {
+		wg.Add(1)
+		go func(i int, reference ObjectReference) {
+			defer wg.Done()
+			results[i] = r.getReference(request, namespace, reference)
+		}(i, reference)
+	}
+	wg.Wait()
+
+	response.WriteEntity(results)
+}
+
+func (r Resource) getReference(request *restful.Request, namespace string, reference ObjectReference) BatchResult {
+	result := BatchResult{Kind: reference.Kind, Name: reference.Name}
+	kind, ok := lookupKind(r.Options, reference.Kind)
+	if !ok {
+		result.Status = http.StatusBadRequest
+		result.Error = fmt.Sprintf("unknown kind '%s'", reference.Kind)
+		return result
+	}
+	result.Kind = kind.Kind
+	if allowed, err := r.authorized(request, "get", kind.Kind, namespace, reference.Name); !allowed {
+		result.Status = http.StatusForbidden
+		result.Error = fmt.Sprintf("not allowed to get %s %s", kind.Kind, reference.Name)
+		if err != nil {
+			result.Status = http.StatusInternalServerError
+			result.Error = err.Error()
+		}
+		return result
+	}
+
+	object, err := r.DynamicClient.Resource(kind.GVR).Namespace(namespace).Get(reference.Name, metav1.GetOptions{})
+	if err != nil {
+		result.Status = errorStatus(err)
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = http.StatusOK
+	result.Object = object
+	return result
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// POST batch-get returns the found objects and per-item errors for the others
+func TestPOSTBatchGet(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipeline := testutils.GetObject("v1beta1", "Pipeline", namespace, "build", "1")
+	pipelinesGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	if _, err := r.DynamicClient.Resource(pipelinesGVR).Namespace(namespace).Create(pipeline, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipeline: %v", err)
+	}
+	task := testutils.GetObject("v1beta1", "Task", namespace, "compile", "1")
+	tasksGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasksGVR).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	references := []ObjectReference{
+		{Kind: "Pipeline", Name: "build"},
+		{Kind: "tasks", Name: "compile"},
+		{Kind: "Task", Name: "missing"},
+	}
+	body, _ := json.Marshal(references)
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/batch-get", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error posting batch: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var results []BatchResult
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		t.Fatalf("Error decoding batch results: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, actual %d", len(results))
+	}
+	for i, name := range []string{"build", "compile"} {
+		if results[i].Status != http.StatusOK || results[i].Object == nil || results[i].Object.GetName() != name {
+			t.Errorf("Expected %s to be found, actual %+v", name, results[i])
+		}
+	}
+	if results[1].Kind != "Task" {
+		t.Errorf("Expected plural kind to be normalised to Task, actual %s", results[1].Kind)
+	}
+	if results[2].Status != http.StatusNotFound || results[2].Object != nil || results[2].Error == "" {
+		t.Errorf("Expected missing task to be reported as not found, actual %+v", results[2])
+	}
+}
+
+// POST batch-get rejects batches over the size limit
+func TestPOSTBatchGetTooLarge(t *testing.T) {
+	server, _, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	references := make([]ObjectReference, MaxBatchSize+1)
+	body, _ := json.Marshal(references)
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/batch-get", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error posting batch: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
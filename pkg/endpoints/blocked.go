@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultBlockedThreshold is how long a pipeline task waits before it is
+// reported as blocked
+const defaultBlockedThreshold = 10 * time.Minute
+
+// Reasons a pipeline task is blocked
+const (
+	BlockedWhenExpression   = "WhenExpression"
+	BlockedCustomRunPending = "CustomRunPending"
+	BlockedPodUnscheduled   = "PodUnscheduled"
+)
+
+// BlockedPipelineRun is a running PipelineRun with tasks waiting for longer
+// than the threshold
+type BlockedPipelineRun struct {
+	Name      string        `json:"name"`
+	StartTime time.Time     `json:"startTime"`
+	Tasks     []BlockedTask `json:"tasks"`
+}
+
+// BlockedTask is a pipeline task that has not started, the Run waiting is
+// the TaskRun or CustomRun created for it if any
+type BlockedTask struct {
+	PipelineTask string  `json:"pipelineTask"`
+	Reason       string  `json:"reason"`
+	Message      string  `json:"message,omitempty"`
+	Run          string  `json:"run,omitempty"`
+	Waiting      float64 `json:"waitingSeconds"`
+}
+
+// GetBlockedPipelineRuns lists the running PipelineRuns of a namespace with a
+// pipeline task waiting for longer than the threshold parameter: gated by a
+// when expression since the run started, running a custom task whose
+// CustomRun has not completed, or whose TaskRun pod cannot be scheduled
+func (r Resource) GetBlockedPipelineRuns(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	threshold, err := durationParameter(request, "threshold", defaultBlockedThreshold)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	kind, _ := lookupKind(r.Options, "PipelineRun")
+	pipelineRuns, err := r.listKind(kind, namespace)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	childOptions := metav1.ListOptions{LabelSelector: PipelineRunLabel}
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(childOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	children := map[string][]*unstructured.Unstructured{}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		children[taskRun.GetLabels()[PipelineRunLabel]] = append(children[taskRun.GetLabels()[PipelineRunLabel]], taskRun)
+	}
+	// Clusters without custom task support do not serve CustomRuns
+	if customRuns, err := r.DynamicClient.Resource(r.Options.GetCustomRunGVR()).Namespace(namespace).List(childOptions); err == nil {
+		for i := range customRuns.Items {
+			customRun := &customRuns.Items[i]
+			children[customRun.GetLabels()[PipelineRunLabel]] = append(children[customRun.GetLabels()[PipelineRunLabel]], customRun)
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	blocked := []BlockedPipelineRun{}
+	for _, pipelineRun := range pipelineRuns {
+		started, ok := nestedTime(pipelineRun, "status", "startTime")
+		if runStatus(pipelineRun) != RunRunning || !ok || now.Sub(started) < threshold {
+			continue
+		}
+		pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+		if err != nil {
+			logging.Log.Warnf("Error getting the Pipeline of PipelineRun %s: %s", pipelineRun.GetName(), err)
+			continue
+		}
+		tasks, err := r.blockedTasks(pipelineRun, pipelineSpec, children[pipelineRun.GetName()], started, now, threshold)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		if len(tasks) > 0 {
+			blocked = append(blocked, BlockedPipelineRun{Name: pipelineRun.GetName(), StartTime: started, Tasks: tasks})
+		}
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Name < blocked[j].Name
+	})
+	response.WriteEntity(blocked)
+}
+
+// blockedTasks returns the tasks of a PipelineRun waiting for longer than the
+// threshold, in the order the Pipeline declares them
+func (r Resource) blockedTasks(pipelineRun *unstructured.Unstructured, pipelineSpec map[string]interface{}, children []*unstructured.Unstructured, started, now time.Time, threshold time.Duration) ([]BlockedTask, error) {
+	byTask := map[string]*unstructured.Unstructured{}
+	for _, child := range children {
+		byTask[child.GetLabels()[PipelineTaskLabel]] = child
+	}
+	skipped := map[string]bool{}
+	skippedTasks, _, _ := unstructured.NestedSlice(pipelineRun.Object, "status", "skippedTasks")
+	for _, s := range skippedTasks {
+		if task, ok := s.(map[string]interface{}); ok {
+			name, _ := task["name"].(string)
+			skipped[name] = true
+		}
+	}
+
+	blocked := []BlockedTask{}
+	for _, field := range []string{"tasks", "finally"} {
+		tasks, _, _ := unstructured.NestedSlice(pipelineSpec, field)
+		for _, t := range tasks {
+			task, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := task["name"].(string)
+			if skipped[name] {
+				continue
+			}
+			child, found := byTask[name]
+			var blockedTask *BlockedTask
+			var since time.Time
+			var err error
+			switch {
+			case !found && field == "tasks":
+				// Finally tasks always wait for the other tasks to complete
+				if dependenciesDone(task, byTask, skipped) {
+					blockedTask, since = whenExpressionBlock(task), started
+				}
+			case found && child.GetKind() == "TaskRun":
+				blockedTask, since, err = r.unscheduledBlock(child)
+			case found:
+				blockedTask, since = customRunBlock(child), child.GetCreationTimestamp().Time
+			}
+			if err != nil {
+				return nil, err
+			}
+			if blockedTask == nil || now.Sub(since) < threshold {
+				continue
+			}
+			blockedTask.PipelineTask = name
+			blockedTask.Waiting = now.Sub(since).Seconds()
+			blocked = append(blocked, *blockedTask)
+		}
+	}
+	return blocked, nil
+}
+
+// dependenciesDone is true when the tasks a pipeline task runs after, or uses
+// the results of, have completed or were skipped
+func dependenciesDone(task map[string]interface{}, byTask map[string]*unstructured.Unstructured, skipped map[string]bool) bool {
+	runAfter, _, _ := unstructured.NestedStringSlice(task, "runAfter")
+	for _, dependency := range append(runAfter, referencedTasks(task)...) {
+		if skipped[dependency] {
+			continue
+		}
+		child, found := byTask[dependency]
+		if !found {
+			return false
+		}
+		if status := runStatus(child); status == RunPending || status == RunRunning {
+			return false
+		}
+	}
+	return true
+}
+
+// whenExpressionBlock reports a task without a run yet as waiting on its when
+// expressions, if it has any
+func whenExpressionBlock(task map[string]interface{}) *BlockedTask {
+	when, _, _ := unstructured.NestedSlice(task, "when")
+	if len(when) == 0 {
+		return nil
+	}
+	var expressions []string
+	for _, w := range when {
+		expression, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cel, _ := expression["cel"].(string); cel != "" {
+			expressions = append(expressions, cel)
+			continue
+		}
+		input, _ := expression["input"].(string)
+		operator, _ := expression["operator"].(string)
+		values, _, _ := unstructured.NestedStringSlice(expression, "values")
+		expressions = append(expressions, fmt.Sprintf("%s %s [%s]", input, operator, strings.Join(values, ", ")))
+	}
+	return &BlockedTask{Reason: BlockedWhenExpression, Message: "waiting on " + strings.Join(expressions, " and ")}
+}
+
+// customRunBlock reports a CustomRun that has not completed
+func customRunBlock(customRun *unstructured.Unstructured) *BlockedTask {
+	if status := runStatus(customRun); status != RunPending && status != RunRunning {
+		return nil
+	}
+	_, _, message, _ := succeededCondition(customRun)
+	return &BlockedTask{Reason: BlockedCustomRunPending, Message: message, Run: customRun.GetName()}
+}
+
+// unscheduledBlock reports a TaskRun whose pod exists but cannot be scheduled,
+// since the pod was created
+func (r Resource) unscheduledBlock(taskRun *unstructured.Unstructured) (*BlockedTask, time.Time, error) {
+	if status := runStatus(taskRun); status != RunPending && status != RunRunning {
+		return nil, time.Time{}, nil
+	}
+	pod, err := r.taskRunPod(taskRun)
+	if k8serrors.IsNotFound(err) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return &BlockedTask{Reason: BlockedPodUnscheduled, Message: condition.Message, Run: taskRun.GetName()}, pod.CreationTimestamp.Time, nil
+		}
+	}
+	return nil, time.Time{}, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET blocked PipelineRuns reports the running runs with a task gated by a
+// when expression for longer than the threshold
+func TestGETBlockedPipelineRuns(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineSpec := map[string]interface{}{
+		"tasks": []interface{}{
+			map[string]interface{}{"name": "build", "taskRef": map[string]interface{}{"name": "build"}},
+			map[string]interface{}{
+				"name":    "deploy",
+				"taskRef": map[string]interface{}{"name": "deploy"},
+				"when": []interface{}{
+					map[string]interface{}{"input": "$(params.approved)", "operator": "in", "values": []interface{}{"true"}},
+				},
+			},
+			map[string]interface{}{
+				"name":    "notify",
+				"taskRef": map[string]interface{}{"name": "notify"},
+				"when": []interface{}{
+					map[string]interface{}{"input": "$(params.notify)", "operator": "in", "values": []interface{}{"true"}},
+				},
+			},
+		},
+	}
+	runs := map[string]time.Duration{"gated": time.Hour, "recent": time.Minute}
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for name, age := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, name, "1")
+		unstructured.SetNestedField(pipelineRun.Object, pipelineSpec, "status", "pipelineSpec")
+		unstructured.SetNestedField(pipelineRun.Object, time.Now().Add(-age).UTC().Format(time.RFC3339), "status", "startTime")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": "Unknown", "reason": "Running"},
+		}, "status", "conditions")
+		// The notify task was already skipped
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{map[string]interface{}{"name": "notify"}}, "status", "skippedTasks")
+		if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name+"-build", "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: name, PipelineTaskLabel: "build"})
+		unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": "Unknown", "reason": "Running"},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	getBlocked := func() []BlockedPipelineRun {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/blocked?threshold=10m", server.URL, namespace), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting blocked pipelineRuns: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var blocked []BlockedPipelineRun
+		if err := json.NewDecoder(response.Body).Decode(&blocked); err != nil {
+			t.Fatalf("Error decoding blocked pipelineRuns: %v", err)
+		}
+		return blocked
+	}
+	// PipelineRuns are read from the informer cache which is eventually consistent
+	var blocked []BlockedPipelineRun
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if blocked = getBlocked(); len(blocked) > 0 {
+			break
+		}
+	}
+
+	if len(blocked) != 1 || blocked[0].Name != "gated" {
+		t.Fatalf("Expected only the gated pipelineRun to be blocked, actual %+v", blocked)
+	}
+	if tasks := blocked[0].Tasks; len(tasks) != 1 || tasks[0].PipelineTask != "deploy" || tasks[0].Reason != BlockedWhenExpression || tasks[0].Waiting < 3500 {
+		t.Errorf("Expected the deploy task blocked by its when expression for an hour, actual %+v", tasks)
+	}
+}
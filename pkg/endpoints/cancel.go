@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pipelineRunCancelledStatus is the spec.status requesting the cancellation
+// of a PipelineRun in the given Tekton API version
+func pipelineRunCancelledStatus(version string) string {
+	if version == "v1beta1" {
+		return "PipelineRunCancelled"
+	}
+	return "Cancelled"
+}
+
+// CancelPipelineRuns cancels the PipelineRuns of the namespace matching the
+// required labelSelector query parameter that have not completed yet,
+// reporting the outcome per run
+func (r Resource) CancelPipelineRuns(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	labelSelector := request.QueryParameter("labelSelector")
+	if labelSelector == "" {
+		utils.RespondErrorMessage(response, "labelSelector must not be empty", http.StatusBadRequest)
+		return
+	}
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if selector.Empty() {
+		utils.RespondErrorMessage(response, "labelSelector must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"status": pipelineRunCancelledStatus(r.Options.GetTektonVersion()),
+		},
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	pipelineRuns := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace)
+	list, err := pipelineRuns.List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	results := []ItemResult{}
+	for _, pipelineRun := range list.Items {
+		if status := runStatus(&pipelineRun); status != RunRunning && status != RunPending {
+			continue
+		}
+		result := ItemResult{Name: pipelineRun.GetName(), Status: http.StatusOK}
+		if _, err := pipelineRuns.Patch(pipelineRun.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			result.Status = errorStatus(err)
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	response.WriteEntity(results)
+}
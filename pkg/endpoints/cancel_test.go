@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// POST PipelineRun cancel only cancels the running runs matching the selector
+func TestPOSTCancelPipelineRuns(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	runs := []struct {
+		name   string
+		app    string
+		status string
+	}{
+		{"checkout-running", "checkout", "Unknown"},
+		{"checkout-done", "checkout", "True"},
+		{"payments-running", "payments", "Unknown"},
+	}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetLabels(map[string]string{"app": run.app})
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/cancel?labelSelector=app=checkout", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error cancelling pipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var results []ItemResult
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		t.Fatalf("Error decoding results: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "checkout-running" || results[0].Status != http.StatusOK {
+		t.Fatalf("Expected only checkout-running to be cancelled, actual %+v", results)
+	}
+
+	expected := map[string]string{"checkout-running": "PipelineRunCancelled", "checkout-done": "", "payments-running": ""}
+	for name, expectedStatus := range expected {
+		pipelineRun, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Error getting pipelineRun: %v", err)
+		}
+		if status, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "status"); status != expectedStatus {
+			t.Errorf("Expected %s spec.status '%s', actual '%s'", name, expectedStatus, status)
+		}
+	}
+}
+
+// POST PipelineRun cancel requires a label selector
+func TestPOSTCancelPipelineRunsWithoutSelector(t *testing.T) {
+	server, _, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/cancel", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error cancelling pipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
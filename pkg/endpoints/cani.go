@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+// objectVerbs are the verbs that can be checked on a single object, with
+// whether they modify it
+var objectVerbs = map[string]bool{
+	"get":    false,
+	"watch":  false,
+	"update": true,
+	"patch":  true,
+	"delete": true,
+}
+
+// GetCanI reviews whether the requesting user may perform the verb query
+// parameter on a single object, so clients can hide actions that would fail
+func (r Resource) GetCanI(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	name := request.PathParameter("name")
+	kind, ok := lookupKind(r.Options, request.PathParameter("kind"))
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("unknown kind '%s'", request.PathParameter("kind")), http.StatusNotFound)
+		return
+	}
+	verb := request.QueryParameter("verb")
+	writes, ok := objectVerbs[verb]
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("invalid verb '%s', must be get, watch, update, patch or delete", verb), http.StatusBadRequest)
+		return
+	}
+	if writes && r.Options.ReadOnly {
+		response.WriteEntity(AccessReview{Reason: "the dashboard is running in read-only mode"})
+		return
+	}
+
+	review, err := r.review(request, verb, kind.Kind, namespace, name)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.WriteEntity(review)
+}
+
+// review explains whether the requesting user may perform a verb on an object.
+// Without an Authorizer requests are made with the dashboard's own service
+// account, which is reviewed instead
+func (r Resource) review(request *restful.Request, verb, kind, namespace, name string) (AccessReview, error) {
+	user := request.HeaderParameter(UserHeader)
+	var reviewer AccessReviewer
+	switch authorizer := r.Authorizer.(type) {
+	case nil:
+		user = ""
+		reviewer = SubjectAccessReviewAuthorizer{Client: r.K8sClient, Options: r.Options}
+	case AccessReviewer:
+		reviewer = authorizer
+	default:
+		allowed, err := r.authorized(request, verb, kind, namespace, name)
+		if err != nil || allowed {
+			return AccessReview{Allowed: allowed}, err
+		}
+		return AccessReview{Reason: fmt.Sprintf("user '%s' cannot %s %s %s in namespace %s", user, verb, kind, name, namespace)}, nil
+	}
+	return reviewer.Review(request.Request.Context(), user, verb, kind, namespace, name)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakek8sclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// objectAuthorizer only allows the verb on a single object
+type objectAuthorizer struct {
+	verb, name string
+}
+
+func (a objectAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	return verb == a.verb && name == a.name, nil
+}
+
+func getCanI(t *testing.T, url, user string) AccessReview {
+	httpReq := testutils.DummyHTTPRequest("GET", url, nil)
+	httpReq.Header.Set(UserHeader, user)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting can-i: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var review AccessReview
+	if err := json.NewDecoder(response.Body).Decode(&review); err != nil {
+		t.Fatalf("Error decoding access review: %v", err)
+	}
+	return review
+}
+
+// GET can-i reviews the verb on the exact object requested
+func TestGETCanI(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = objectAuthorizer{verb: "delete", name: "disposable"}
+	server.Config.Handler = router.Register(*r)
+
+	review := getCanI(t, fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/disposable/can-i?verb=delete", server.URL, namespace), "alice")
+	if !review.Allowed {
+		t.Errorf("Expected delete to be allowed, actual %+v", review)
+	}
+	review = getCanI(t, fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/precious/can-i?verb=delete", server.URL, namespace), "alice")
+	expected := AccessReview{Reason: fmt.Sprintf("user 'alice' cannot delete PipelineRun precious in namespace %s", namespace)}
+	if review != expected {
+		t.Errorf("Expected %+v, actual %+v", expected, review)
+	}
+
+	for query, status := range map[string]int{
+		"pipelineruns/disposable/can-i?verb=escalate": http.StatusBadRequest,
+		"pipelineruns/disposable/can-i":               http.StatusBadRequest,
+		"secrets/disposable/can-i?verb=delete":        http.StatusNotFound,
+	} {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/%s", server.URL, namespace, query), nil))
+		if err != nil {
+			t.Fatalf("Error getting can-i: %v", err)
+		}
+		if response.StatusCode != status {
+			t.Errorf("Expected statusCode %d for %s, actual %d", status, query, response.StatusCode)
+		}
+	}
+}
+
+// The SubjectAccessReview of the user is scoped to the object, and its reason
+// returned
+func TestGETCanISubjectAccessReview(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = SubjectAccessReviewAuthorizer{Client: r.K8sClient, Options: r.Options}
+	server.Config.Handler = router.Register(*r)
+
+	var reviewed authorizationv1.SubjectAccessReviewSpec
+	r.K8sClient.(*fakek8sclientset.Clientset).PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviewed = review.Spec
+		review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: true, Reason: "RBAC: allowed by RoleBinding"}
+		return true, review, nil
+	})
+
+	review := getCanI(t, fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/can-i?verb=delete", server.URL, namespace), "alice")
+	expected := AccessReview{Allowed: true, Reason: "RBAC: allowed by RoleBinding"}
+	if review != expected {
+		t.Errorf("Expected %+v, actual %+v", expected, review)
+	}
+	attributes := reviewed.ResourceAttributes
+	if reviewed.User != "alice" || attributes == nil || attributes.Verb != "delete" || attributes.Resource != "taskruns" || attributes.Namespace != namespace || attributes.Name != "build" {
+		t.Errorf("Expected a review of alice deleting taskruns/build, actual %+v", reviewed)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// PipelineRunLabel is the label Tekton sets on TaskRuns to reference the
+// PipelineRun that created them
+const PipelineRunLabel = "tekton.dev/pipelineRun"
+
+// How long a foreground delete waits for the PipelineRun to be removed
+const (
+	foregroundDeleteInterval = 500 * time.Millisecond
+	foregroundDeleteTimeout  = 30 * time.Second
+)
+
+var cascadePropagation = map[string]metav1.DeletionPropagation{
+	"foreground": metav1.DeletePropagationForeground,
+	"background": metav1.DeletePropagationBackground,
+	"orphan":     metav1.DeletePropagationOrphan,
+}
+
+// DeletePipelineRun deletes a PipelineRun using the propagation policy given
+// by the cascade query parameter. With foreground cascade the garbage
+// collector removes the child TaskRuns first and the response is only sent
+// once the PipelineRun is gone, or 202 if it is still being deleted
+func (r Resource) DeletePipelineRun(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	deleteOptions := &metav1.DeleteOptions{}
+	cascade := request.QueryParameter("cascade")
+	if cascade != "" {
+		propagation, ok := cascadePropagation[cascade]
+		if !ok {
+			utils.RespondErrorMessage(response, fmt.Sprintf("invalid cascade '%s', must be one of foreground, background or orphan", cascade), http.StatusBadRequest)
+			return
+		}
+		deleteOptions.PropagationPolicy = &propagation
+	}
+
+	pipelineRuns := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace)
+	if _, err := pipelineRuns.Get(name, metav1.GetOptions{}); err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	if err := pipelineRuns.Delete(name, deleteOptions); err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	if cascade == "foreground" {
+		err := wait.PollImmediate(foregroundDeleteInterval, foregroundDeleteTimeout, func() (bool, error) {
+			_, err := pipelineRuns.Get(name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err == wait.ErrWaitTimeout {
+			response.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if err != nil {
+			utils.RespondError(response, fmt.Errorf("waiting for PipelineRun %s to be deleted: %w", name, err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response.WriteHeader(http.StatusNoContent)
+}
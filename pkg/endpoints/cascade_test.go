@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DELETE PipelineRun with foreground cascade waits for the PipelineRun and
+// leaves the child TaskRuns to the garbage collector
+func TestDELETEPipelineRunForegroundCascade(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	taskRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "run", "1")
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	for _, name := range []string{"run-build", "run-test"} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "run"})
+		if _, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("DELETE", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/run?cascade=foreground", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error deleting pipelineRun: %v", err)
+	}
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusNoContent, response.StatusCode)
+	}
+
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Get("run", metav1.GetOptions{}); err == nil {
+		t.Error("Expected pipelineRun to be deleted")
+	}
+	taskRuns, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Error listing taskRuns: %v", err)
+	}
+	if len(taskRuns.Items) != 2 {
+		t.Errorf("Expected child taskRuns to be left to the garbage collector, %d remain", len(taskRuns.Items))
+	}
+}
+
+// DELETE PipelineRun is rejected in read-only mode
+func TestDELETEPipelineRunReadOnly(t *testing.T) {
+	server, r, namespace := testutils.DummyServerWithOptions(Options{ReadOnly: true})
+	defer server.Close()
+
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "run", "1")
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("DELETE", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/run", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error deleting pipelineRun: %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusForbidden, response.StatusCode)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// lastAppliedAnnotation is set by kubectl apply and describes the source
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// CloneRequest names the copy of a Pipeline or Task, in the source namespace
+// unless Namespace is set. Labels are merged into the copy's labels, a null
+// value removes the label
+type CloneRequest struct {
+	NewName   string             `json:"newName"`
+	Namespace string             `json:"namespace,omitempty"`
+	Labels    map[string]*string `json:"labels,omitempty"`
+}
+
+// ClonePipeline creates a copy of a Pipeline under a new name
+func (r Resource) ClonePipeline(request *restful.Request, response *restful.Response) {
+	r.clone(request, response, "Pipeline", "pipelines")
+}
+
+// CloneTask creates a copy of a Task under a new name
+func (r Resource) CloneTask(request *restful.Request, response *restful.Response) {
+	r.clone(request, response, "Task", "tasks")
+}
+
+func (r Resource) clone(request *restful.Request, response *restful.Response, kind, resource string) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	var cloneRequest CloneRequest
+	if err := request.ReadEntity(&cloneRequest); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if cloneRequest.NewName == "" {
+		utils.RespondErrorMessage(response, "newName must not be empty", http.StatusBadRequest)
+		return
+	}
+	target := cloneRequest.Namespace
+	if target == "" {
+		target = namespace
+	}
+	if allowed, err := r.authorized(request, "create", kind, target, ""); !allowed {
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		utils.RespondErrorMessage(response, fmt.Sprintf("user '%s' cannot create %s in namespace %s", request.HeaderParameter(UserHeader), kind, target), http.StatusForbidden)
+		return
+	}
+
+	source, err := r.getTektonResource(resource, namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	duplicate := freshCopy(source, cloneRequest.NewName, target)
+	if len(cloneRequest.Labels) > 0 {
+		labels := duplicate.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range cloneRequest.Labels {
+			if value == nil {
+				delete(labels, key)
+			} else {
+				labels[key] = *value
+			}
+		}
+		duplicate.SetLabels(labels)
+	}
+
+	created, err := r.DynamicClient.Resource(r.tektonGVR(resource)).Namespace(target).Create(duplicate, metav1.CreateOptions{})
+	if err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			utils.RespondErrorMessage(response, fmt.Sprintf("%s %s already exists in namespace %s", kind, cloneRequest.NewName, target), http.StatusConflict)
+			return
+		}
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.WriteHeaderAndEntity(http.StatusCreated, created)
+}
+
+// freshCopy copies an object's spec, labels and annotations under a new name,
+// leaving out the metadata and status managed by the API server
+func freshCopy(source *unstructured.Unstructured, name, namespace string) *unstructured.Unstructured {
+	duplicate := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	duplicate.SetAPIVersion(source.GetAPIVersion())
+	duplicate.SetKind(source.GetKind())
+	duplicate.SetName(name)
+	duplicate.SetNamespace(namespace)
+	duplicate.SetLabels(source.GetLabels())
+	annotations := source.GetAnnotations()
+	delete(annotations, lastAppliedAnnotation)
+	if len(annotations) > 0 {
+		duplicate.SetAnnotations(annotations)
+	}
+	if spec, ok := source.Object["spec"]; ok {
+		duplicate.Object["spec"] = spec
+	}
+	return duplicate
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// POST clone Pipeline creates a copy with the same spec and fresh metadata
+func TestPOSTClonePipeline(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipeline := testutils.GetObject("v1beta1", "Pipeline", namespace, "build", "1")
+	pipeline.SetUID(types.UID("source-uid"))
+	pipeline.SetCreationTimestamp(metav1.Now())
+	pipeline.SetLabels(map[string]string{"app": "web", "stage": "dev"})
+	pipeline.SetAnnotations(map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"})
+	spec := map[string]interface{}{
+		"tasks": []interface{}{map[string]interface{}{"name": "compile", "taskRef": map[string]interface{}{"name": "go-build"}}},
+	}
+	unstructured.SetNestedMap(pipeline.Object, spec, "spec")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipeline, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipeline: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"newName":   "build-variant",
+		"namespace": "sandbox",
+		"labels":    map[string]interface{}{"stage": "test", "app": nil},
+	})
+	clone := func() *http.Response {
+		httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/pipelines/build/clone", server.URL, namespace), bytes.NewBuffer(body))
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error cloning pipeline: %v", err)
+		}
+		return response
+	}
+	if response := clone(); response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusCreated, response.StatusCode)
+	}
+
+	copied, err := r.DynamicClient.Resource(gvr).Namespace("sandbox").Get("build-variant", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting clone: %v", err)
+	}
+	copiedSpec, _, _ := unstructured.NestedMap(copied.Object, "spec")
+	if !reflect.DeepEqual(copiedSpec, spec) {
+		t.Errorf("Expected spec %+v, actual %+v", spec, copiedSpec)
+	}
+	if copied.GetUID() != "" || copied.GetResourceVersion() != "" || !copied.GetCreationTimestamp().Time.IsZero() {
+		t.Errorf("Expected fresh metadata, actual uid '%s' resourceVersion '%s' creationTimestamp %s", copied.GetUID(), copied.GetResourceVersion(), copied.GetCreationTimestamp())
+	}
+	if expected := map[string]string{"stage": "test"}; !reflect.DeepEqual(copied.GetLabels(), expected) {
+		t.Errorf("Expected labels %v, actual %v", expected, copied.GetLabels())
+	}
+	if len(copied.GetAnnotations()) != 0 {
+		t.Errorf("Expected no annotations, actual %v", copied.GetAnnotations())
+	}
+
+	if response := clone(); response.StatusCode != http.StatusConflict {
+		t.Errorf("Expected statusCode %d cloning onto an existing pipeline, actual %d", http.StatusConflict, response.StatusCode)
+	}
+}
+
+// POST clone Task responds 404 if the source does not exist
+func TestPOSTCloneTaskNotFound(t *testing.T) {
+	server, _, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	body, _ := json.Marshal(CloneRequest{NewName: "copy"})
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/tasks/missing/clone", server.URL, namespace), bytes.NewBuffer(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error cloning task: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
@@ -14,7 +14,9 @@ limitations under the License.
 package endpoints
 
 import (
+	"bytes"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -38,9 +40,13 @@ type Properties struct {
 	TenantNamespace    string `json:"TenantNamespace,omitempty"`
 	StreamLogs         bool   `json:"StreamLogs"`
 	ExternalLogsURL    string `json:"ExternalLogsURL"`
+	WebsocketPort      int    `json:"WebsocketPort,omitempty"`
 }
 
-// ProxyRequest does as the name suggests: proxies requests and logs what's going on
+// ProxyRequest does as the name suggests: proxies requests and logs what's going on.
+// Updates and patches with an If-Match header only apply to that resourceVersion
+// and fail with 412 Precondition Failed if the object has changed. Pod logs
+// only return the lines matching the grep query parameter when it is set
 func (r Resource) ProxyRequest(request *restful.Request, response *restful.Response) {
 	parsedURL, err := url.Parse(request.Request.URL.String())
 	if err != nil {
@@ -48,9 +54,54 @@ func (r Resource) ProxyRequest(request *restful.Request, response *restful.Respo
 		return
 	}
 
+	var writer http.ResponseWriter = response
+	podLog := request.Request.Method == http.MethodGet && podLogPath(request.PathParameter("subpath"))
+	if podLog {
+		// grep is applied by the dashboard rather than passed on
+		grep, err := grepParameter(request)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusBadRequest)
+			return
+		}
+		if grep != nil {
+			query := parsedURL.Query()
+			query.Del("grep")
+			parsedURL.RawQuery = query.Encode()
+			grepped := &grepWriter{ResponseWriter: response, grep: grep}
+			defer grepped.close()
+			writer = grepped
+		}
+	}
+
 	uri := request.PathParameter("subpath") + "?" + parsedURL.RawQuery
+	if r.proxyDenyingNamespaces(request, response, uri) {
+		return
+	}
+	// Pod logs read through the proxy count against the log streams as well
+	if podLog {
+		if !acquireLogStream(request, response) {
+			return
+		}
+		defer LogStreams.release()
+	}
+
+	method := request.Request.Method
+	if resourceVersion := ifMatchVersion(request.Request); resourceVersion != "" && (method == http.MethodPut || method == http.MethodPatch) {
+		body, err := ioutil.ReadAll(request.Request.Body)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusBadRequest)
+			return
+		}
+		body, err = withResourceVersion(body, request.Request.Header.Get("Content-Type"), resourceVersion)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusBadRequest)
+			return
+		}
+		request.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		writer = preconditionWriter{response}
+	}
 
-	if statusCode, err := utils.Proxy(request.Request, response, r.Config.Host+"/"+uri, r.HttpClient); err != nil {
+	if statusCode, err := utils.Proxy(request.Request, writer, r.Config.Host+"/"+uri, r.HttpClient); err != nil {
 		utils.RespondError(response, err, statusCode)
 	}
 }
@@ -74,6 +125,7 @@ func (r Resource) GetProperties(request *restful.Request, response *restful.Resp
 		LogoutURL:          r.Options.LogoutURL,
 		TenantNamespace:    r.Options.TenantNamespace,
 		StreamLogs:         r.Options.StreamLogs,
+		WebsocketPort:      r.Options.WebsocketPort,
 	}
 
 	if r.Options.ExternalLogsURL != "" {
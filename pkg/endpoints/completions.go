@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RunCompletion summarises a PipelineRun or TaskRun that has just finished
+type RunCompletion struct {
+	Kind           string     `json:"kind"`
+	Namespace      string     `json:"namespace"`
+	Name           string     `json:"name"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+	Duration       float64    `json:"durationSeconds"`
+}
+
+// runEventKinds maps the run events the completions websocket listens to onto
+// the kind of run, and whether the run was deleted
+var runEventKinds = map[broadcaster.MessageType]struct {
+	kind    string
+	deleted bool
+}{
+	broadcaster.PipelineRunUpdated: {kind: "PipelineRun"},
+	broadcaster.PipelineRunDeleted: {kind: "PipelineRun", deleted: true},
+	broadcaster.TaskRunUpdated:     {kind: "TaskRun"},
+	broadcaster.TaskRunDeleted:     {kind: "TaskRun", deleted: true},
+}
+
+// EstablishCompletionsWebsocket sends a RunCompleted message each time a
+// PipelineRun or TaskRun reaches a final Succeeded condition, all other run
+// events are filtered out server side. With an Authorizer, only runs the user
+// may get are reported
+func (r Resource) EstablishCompletionsWebsocket(request *restful.Request, response *restful.Response) {
+	if !acceptSubscriber(response, broadcaster.PriorityNormal) {
+		return
+	}
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+	// Runs that finished before the client connected are not reported when
+	// they are updated again. Timestamps only have second precision
+	connected := time.Now().Truncate(time.Second)
+	opts := []broadcaster.SubscribeOption[broadcaster.SocketData]{broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		_, ok := runEventKinds[data.MessageType]
+		return ok
+	})}
+	if r.Authorizer != nil {
+		opts = append(opts, broadcaster.WithFilter(r.readAccessFilter(request.Request.Context(), request.HeaderParameter(UserHeader))))
+	}
+	subscriber, _ := ResourcesBroadcaster.Subscribe(opts...)
+	websocket.MonitorConnection(connection, func() {
+		ResourcesBroadcaster.Unsubscribe(subscriber)
+	})
+
+	completed := map[string]bool{}
+	for {
+		select {
+		case data := <-subscriber.SubChan():
+			completion, ok := runCompleted(data, completed, connected)
+			if ok && !websocket.Send(connection, broadcaster.SocketData{MessageType: broadcaster.RunCompleted, Payload: completion, Tenant: data.Tenant}) {
+				ResourcesBroadcaster.Unsubscribe(subscriber)
+				return
+			}
+		case <-subscriber.UnsubChan():
+			if subscriber.Evicted() {
+				websocket.ReportOverloaded(connection)
+			}
+			return
+		}
+	}
+}
+
+// runCompleted returns the completion of the run in a run event if it has just
+// finished. completed tracks the runs already reported so each is sent once
+func runCompleted(data broadcaster.SocketData, completed map[string]bool, since time.Time) (RunCompletion, bool) {
+	event := runEventKinds[data.MessageType]
+	object, ok := payloadMeta(data.Payload)
+	if !ok {
+		return RunCompletion{}, false
+	}
+	key := event.kind + "/" + object.GetNamespace() + "/" + object.GetName()
+	if event.deleted {
+		delete(completed, key)
+		return RunCompletion{}, false
+	}
+	run, ok := data.Payload.(*unstructured.Unstructured)
+	if !ok {
+		return RunCompletion{}, false
+	}
+
+	status := runStatus(run)
+	if completed[key] || status == RunPending || status == RunRunning {
+		return RunCompletion{}, false
+	}
+	completed[key] = true
+	completion := RunCompletion{
+		Kind:      event.kind,
+		Namespace: run.GetNamespace(),
+		Name:      run.GetName(),
+		Status:    status,
+		Duration:  runDuration(run).Seconds(),
+	}
+	_, completion.Reason, completion.Message, _ = succeededCondition(run)
+	if started, ok := nestedTime(run, "status", "startTime"); ok {
+		completion.StartTime = &started
+	}
+	if finished, ok := nestedTime(run, "status", "completionTime"); ok {
+		if finished.Before(since) {
+			return RunCompletion{}, false
+		}
+		completion.CompletionTime = &finished
+	}
+	return completion, true
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The completions websocket only reports a run once it has finished
+func TestCompletionsWebsocket(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{Scheme: "ws", Host: strings.TrimPrefix(server.URL, "http://"), Path: "/v1/websockets/completions"}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected the completions client within pool")
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	taskRuns := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	if _, err := taskRuns.Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	started := time.Now().UTC()
+	updates := []func(){
+		// Running
+		func() {
+			unstructured.SetNestedField(taskRun.Object, started.Format(time.RFC3339), "status", "startTime")
+			unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+				map[string]interface{}{"type": "Succeeded", "status": "Unknown", "reason": "Running"},
+			}, "status", "conditions")
+		},
+		// Finished
+		func() {
+			unstructured.SetNestedField(taskRun.Object, started.Add(time.Minute).Format(time.RFC3339), "status", "completionTime")
+			unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+				map[string]interface{}{"type": "Succeeded", "status": "False", "reason": "Failed", "message": "step build failed"},
+			}, "status", "conditions")
+		},
+		// Updated after finishing
+		func() {
+			taskRun.SetLabels(map[string]string{"archived": "true"})
+		},
+	}
+	for i, update := range updates {
+		update()
+		taskRun.SetResourceVersion(strconv.Itoa(i + 2))
+		if _, err := taskRuns.Update(taskRun, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Error updating taskRun: %v", err)
+		}
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := connection.ReadMessage()
+	if err != nil {
+		t.Fatalf("Read error: %s", err)
+	}
+	var socketData struct {
+		MessageType broadcaster.MessageType
+		Payload     RunCompletion
+	}
+	if err := json.Unmarshal(message, &socketData); err != nil {
+		t.Fatalf("Error decoding message: %s", err)
+	}
+	completion := socketData.Payload
+	if socketData.MessageType != broadcaster.RunCompleted || completion.Kind != "TaskRun" || completion.Name != "build" ||
+		completion.Status != RunFailed || completion.Message != "step build failed" || completion.Duration != 60 {
+		t.Errorf("Expected a failed TaskRun completion after 60s, actual %s %+v", socketData.MessageType, completion)
+	}
+
+	connection.SetReadDeadline(time.Now().Add(time.Second))
+	if _, message, err := connection.ReadMessage(); err == nil {
+		t.Errorf("Expected a single completion, actual %s", message)
+	}
+}
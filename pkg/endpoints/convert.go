@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"strings"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// convertibleKinds are the Tekton Pipelines kinds served in every one of
+// TektonVersions, by their plural resource name
+var convertibleKinds = map[string]string{
+	"Pipeline":    "pipelines",
+	"PipelineRun": "pipelineruns",
+	"Task":        "tasks",
+	"TaskRun":     "taskruns",
+}
+
+// convertTransform returns a Transform delivering Tekton Pipelines objects in
+// the given API version. The API server converts the object when it is read
+// in that version, objects that cannot be read again, like deleted ones, are
+// sent in the version they were received in and flagged Unconverted
+func (r Resource) convertTransform(version string) (broadcaster.Transform[broadcaster.SocketData], error) {
+	if !isTektonVersion(version) {
+		return nil, fmt.Errorf("invalid convertTo '%s', must be one of %v", version, TektonVersions)
+	}
+	apiVersion := tektonGroup + "/" + version
+	return func(data broadcaster.SocketData) broadcaster.SocketData {
+		object, ok := data.Payload.(*unstructured.Unstructured)
+		if !ok {
+			if _, tombstone := data.Payload.(cache.DeletedFinalStateUnknown); tombstone {
+				data.Unconverted = true
+			}
+			return data
+		}
+		gv, err := schema.ParseGroupVersion(object.GetAPIVersion())
+		if err != nil || gv.Group != tektonGroup || object.GetAPIVersion() == apiVersion {
+			return data
+		}
+		resource, ok := convertibleKinds[object.GetKind()]
+		if !ok || strings.HasSuffix(string(data.MessageType), "Deleted") {
+			data.Unconverted = true
+			return data
+		}
+		gvr := schema.GroupVersionResource{Group: tektonGroup, Version: version, Resource: resource}
+		converted, err := r.DynamicClient.Resource(gvr).Namespace(object.GetNamespace()).Get(object.GetName(), metav1.GetOptions{})
+		if err != nil {
+			logging.Log.Debugf("Could not convert %s %s/%s to %s: %v", object.GetKind(), object.GetNamespace(), object.GetName(), apiVersion, err)
+			data.Unconverted = true
+			return data
+		}
+		data.Payload = converted
+		return data
+	}, nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CriticalPath is the chain of dependent pipeline tasks of a PipelineRun that
+// took the longest in total
+type CriticalPath struct {
+	Tasks    []CriticalPathTask `json:"tasks"`
+	Duration float64            `json:"durationSeconds"`
+}
+
+// CriticalPathTask is a pipeline task on the critical path and how long its
+// TaskRun ran. Tasks without a TaskRun, e.g. skipped ones, take no time
+type CriticalPathTask struct {
+	PipelineTask string  `json:"pipelineTask"`
+	TaskRun      string  `json:"taskRun,omitempty"`
+	Duration     float64 `json:"durationSeconds"`
+}
+
+// resultReference matches a reference to the results of another pipeline task,
+// which makes the referencing task depend on it
+var resultReference = regexp.MustCompile(`\$\(tasks\.([^.)]+)\.results\.`)
+
+// pipelineTaskNode is a pipeline task and the tasks it has to run after
+type pipelineTaskNode struct {
+	name      string
+	runAfter  []string
+	taskRun   string
+	duration  time.Duration
+	finally   bool
+	resolving bool
+	resolved  bool
+	// longest is the duration of the longest chain ending with this task
+	longest time.Duration
+	// previous is the task before this one on that chain
+	previous string
+}
+
+// GetPipelineRunCriticalPath returns the chain of pipeline tasks with the
+// longest total TaskRun duration, following runAfter and result references.
+// Finally tasks run after all other tasks
+func (r Resource) GetPipelineRunCriticalPath(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	nodes, order := pipelineTaskNodes(pipelineSpec)
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		node, ok := nodes[taskRun.GetLabels()[PipelineTaskLabel]]
+		if !ok {
+			continue
+		}
+		node.taskRun = taskRun.GetName()
+		node.duration = runDuration(taskRun)
+	}
+
+	var last *pipelineTaskNode
+	for _, taskName := range order {
+		node := nodes[taskName]
+		if err := resolveLongest(nodes, node); err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		if last == nil || node.longest > last.longest {
+			last = node
+		}
+	}
+
+	path := CriticalPath{Tasks: []CriticalPathTask{}}
+	if last != nil {
+		path.Duration = last.longest.Seconds()
+		for node := last; node != nil; node = nodes[node.previous] {
+			path.Tasks = append([]CriticalPathTask{{
+				PipelineTask: node.name,
+				TaskRun:      node.taskRun,
+				Duration:     node.duration.Seconds(),
+			}}, path.Tasks...)
+		}
+	}
+	response.WriteEntity(path)
+}
+
+// pipelineRunSpec returns the Pipeline spec a PipelineRun ran, as resolved by
+// the controller, embedded or referenced
+func (r Resource) pipelineRunSpec(pipelineRun *unstructured.Unstructured) (map[string]interface{}, error) {
+	if spec, found, _ := unstructured.NestedMap(pipelineRun.Object, "status", "pipelineSpec"); found {
+		return spec, nil
+	}
+	if spec, found, _ := unstructured.NestedMap(pipelineRun.Object, "spec", "pipelineSpec"); found {
+		return spec, nil
+	}
+	refName, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "pipelineRef", "name")
+	pipeline, err := r.getTektonResource("pipelines", pipelineRun.GetNamespace(), refName)
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(pipeline.Object, "spec")
+	return spec, nil
+}
+
+// pipelineTaskNodes returns the tasks and finally tasks of a Pipeline spec by
+// name, along with their names in declaration order
+func pipelineTaskNodes(pipelineSpec map[string]interface{}) (map[string]*pipelineTaskNode, []string) {
+	nodes := map[string]*pipelineTaskNode{}
+	var order, dagTasks []string
+	for _, field := range []string{"tasks", "finally"} {
+		tasks, _, _ := unstructured.NestedSlice(pipelineSpec, field)
+		for _, t := range tasks {
+			task, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			node := &pipelineTaskNode{finally: field == "finally"}
+			node.name, _ = task["name"].(string)
+			if node.finally {
+				node.runAfter = dagTasks
+			} else {
+				runAfter, _, _ := unstructured.NestedStringSlice(task, "runAfter")
+				node.runAfter = append(runAfter, referencedTasks(task)...)
+				dagTasks = append(dagTasks, node.name)
+			}
+			nodes[node.name] = node
+			order = append(order, node.name)
+		}
+	}
+	return nodes, order
+}
+
+// referencedTasks returns the pipeline tasks whose results are referenced by
+// a task, e.g. in its params or when expressions
+func referencedTasks(task map[string]interface{}) []string {
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return nil
+	}
+	var referenced []string
+	for _, match := range resultReference.FindAllStringSubmatch(string(encoded), -1) {
+		referenced = append(referenced, match[1])
+	}
+	return referenced
+}
+
+// resolveLongest computes the longest chain of tasks ending with node
+func resolveLongest(nodes map[string]*pipelineTaskNode, node *pipelineTaskNode) error {
+	if node.resolved {
+		return nil
+	}
+	if node.resolving {
+		return fmt.Errorf("pipeline tasks form a cycle through '%s'", node.name)
+	}
+	node.resolving = true
+	for _, dependency := range node.runAfter {
+		previous, ok := nodes[dependency]
+		if !ok || previous == node {
+			continue
+		}
+		if err := resolveLongest(nodes, previous); err != nil {
+			return err
+		}
+		if node.previous == "" || previous.longest > nodes[node.previous].longest {
+			node.previous = previous.name
+		}
+	}
+	node.longest = node.duration
+	if node.previous != "" {
+		node.longest += nodes[node.previous].longest
+	}
+	node.resolving = false
+	node.resolved = true
+	return nil
+}
+
+// runDuration is how long a run has been running, up to its completion
+func runDuration(run *unstructured.Unstructured) time.Duration {
+	started, ok := nestedTime(run, "status", "startTime")
+	if !ok {
+		return 0
+	}
+	completed, ok := nestedTime(run, "status", "completionTime")
+	if !ok {
+		completed = time.Now()
+	}
+	return completed.Sub(started)
+}
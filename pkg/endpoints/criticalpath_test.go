@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun critical path follows the slowest of parallel branches
+func TestGETPipelineRunCriticalPath(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	// fetch fans out to a slow lint and a fast test, build needs test's
+	// result and lint, notify is a finally task
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "ci", "1")
+	unstructured.SetNestedField(pipelineRun.Object, map[string]interface{}{
+		"tasks": []interface{}{
+			map[string]interface{}{"name": "fetch"},
+			map[string]interface{}{"name": "lint", "runAfter": []interface{}{"fetch"}},
+			map[string]interface{}{"name": "test", "runAfter": []interface{}{"fetch"}},
+			map[string]interface{}{"name": "build", "runAfter": []interface{}{"lint"}, "params": []interface{}{
+				map[string]interface{}{"name": "digest", "value": "$(tasks.test.results.digest)"},
+			}},
+			map[string]interface{}{"name": "docs", "runAfter": []interface{}{"fetch"}},
+		},
+		"finally": []interface{}{
+			map[string]interface{}{"name": "notify"},
+		},
+	}, "spec", "pipelineSpec")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	durations := map[string]time.Duration{
+		"fetch":  10 * time.Second,
+		"lint":   50 * time.Second,
+		"test":   30 * time.Second,
+		"build":  20 * time.Second,
+		"docs":   60 * time.Second,
+		"notify": 5 * time.Second,
+	}
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for pipelineTask, duration := range durations {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "ci-"+pipelineTask, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "ci", PipelineTaskLabel: pipelineTask})
+		unstructured.SetNestedField(taskRun.Object, start.Format(time.RFC3339), "status", "startTime")
+		unstructured.SetNestedField(taskRun.Object, start.Add(duration).Format(time.RFC3339), "status", "completionTime")
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/ci/critical-path", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting critical path: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var path CriticalPath
+	if err := json.NewDecoder(response.Body).Decode(&path); err != nil {
+		t.Fatalf("Error decoding critical path: %v", err)
+	}
+
+	// fetch, lint, build takes 80s, longer than fetch, docs at 70s and
+	// fetch, test, build at 60s
+	expected := CriticalPath{
+		Tasks: []CriticalPathTask{
+			{PipelineTask: "fetch", TaskRun: "ci-fetch", Duration: 10},
+			{PipelineTask: "lint", TaskRun: "ci-lint", Duration: 50},
+			{PipelineTask: "build", TaskRun: "ci-build", Duration: 20},
+			{PipelineTask: "notify", TaskRun: "ci-notify", Duration: 5},
+		},
+		Duration: 85,
+	}
+	if !reflect.DeepEqual(path, expected) {
+		t.Errorf("Expected critical path %+v, actual %+v", expected, path)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// DefaultCustomRunGVR is the resource of custom task runs used when none was
+// detected
+var DefaultCustomRunGVR = schema.GroupVersionResource{Group: tektonGroup, Version: "v1beta1", Resource: "customruns"}
+
+// customRunGVRs are the resources custom task runs may be served as, in order
+// of preference. Older Tekton releases only serve v1alpha1 Runs
+var customRunGVRs = []schema.GroupVersionResource{
+	DefaultCustomRunGVR,
+	{Group: tektonGroup, Version: "v1alpha1", Resource: "runs"},
+}
+
+// GetCustomRunGVR returns the CustomRunGVR property if set or the
+// DefaultCustomRunGVR otherwise
+func (o Options) GetCustomRunGVR() schema.GroupVersionResource {
+	if o.CustomRunGVR != (schema.GroupVersionResource{}) {
+		return o.CustomRunGVR
+	}
+	return DefaultCustomRunGVR
+}
+
+// DetectCustomRunGVR returns the preferred resource the cluster serves custom
+// task runs as, found is false if custom tasks are not supported
+func DetectCustomRunGVR(client discovery.DiscoveryInterface) (gvr schema.GroupVersionResource, found bool) {
+	return detectGVR(client, customRunGVRs)
+}
+
+// detectGVR returns the first candidate resource served by the cluster
+func detectGVR(client discovery.DiscoveryInterface, candidates []schema.GroupVersionResource) (gvr schema.GroupVersionResource, found bool) {
+	for _, candidate := range candidates {
+		resources, err := client.ServerResourcesForGroupVersion(candidate.GroupVersion().String())
+		if err != nil {
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if resource.Name == candidate.Resource {
+				return candidate, true
+			}
+		}
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+// GetCustomRuns lists the custom task runs in a namespace, optionally
+// filtered with the labelSelector query parameter. When NDJSON is accepted
+// they are listed a page at a time and written one per line
+func (r Resource) GetCustomRuns(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	selector := request.QueryParameter("labelSelector")
+	if _, err := labels.Parse(selector); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	listOptions := metav1.ListOptions{LabelSelector: selector}
+	if acceptsNDJSON(request) {
+		streamNDJSON(response, r.DynamicClient.Resource(r.Options.GetCustomRunGVR()).Namespace(namespace), listOptions)
+		return
+	}
+
+	customRuns, err := r.DynamicClient.Resource(r.Options.GetCustomRunGVR()).Namespace(namespace).List(listOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.WriteEntity(customRuns)
+}
+
+// GetCustomRun gets a single custom task run by name
+func (r Resource) GetCustomRun(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	customRun, err := r.DynamicClient.Resource(r.Options.GetCustomRunGVR()).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	response.WriteEntity(customRun)
+}
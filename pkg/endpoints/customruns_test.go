@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// CustomRuns are listed with a label selector and their creation is broadcast
+func TestCustomRuns(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	subscriber, _ := ResourcesBroadcaster.Subscribe()
+	defer ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "customruns"}
+	for _, name := range []string{"wait", "other"} {
+		customRun := testutils.GetObject("v1beta1", "CustomRun", namespace, name, "1")
+		customRun.SetLabels(map[string]string{PipelineRunLabel: name + "-run"})
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(customRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating customRun: %v", err)
+		}
+	}
+
+	timeout := time.After(5 * time.Second)
+	for created := 0; created < 2; {
+		select {
+		case <-timeout:
+			t.Fatalf("Expected %s events for both customRuns", broadcaster.CustomRunCreated)
+		case event := <-subscriber.SubChan():
+			if event.MessageType == broadcaster.CustomRunCreated {
+				created++
+			}
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/customruns?labelSelector=%s=wait-run", server.URL, namespace, PipelineRunLabel), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error listing customRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var list struct {
+		Items []metav1.PartialObjectMetadata `json:"items"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&list); err != nil {
+		t.Fatalf("Error decoding customRuns: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "wait" {
+		t.Errorf("Expected only the 'wait' customRun, actual %+v", list.Items)
+	}
+
+	for name, expected := range map[string]int{"wait": http.StatusOK, "missing": http.StatusNotFound} {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/customruns/%s", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting customRun: %v", err)
+		}
+		if response.StatusCode != expected {
+			t.Errorf("%s: expected statusCode %d, actual %d", name, expected, response.StatusCode)
+		}
+	}
+}
+
+// Older Tekton releases serve custom task runs as v1alpha1 Runs
+func TestDetectCustomRunGVR(t *testing.T) {
+	client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	if _, found := DetectCustomRunGVR(client); found {
+		t.Error("Expected no custom run resource without Tekton")
+	}
+
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "tekton.dev/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "runs"}, {Name: "conditions"}},
+	}}
+	expected := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1alpha1", Resource: "runs"}
+	if gvr, found := DetectCustomRunGVR(client); !found || gvr != expected {
+		t.Errorf("Expected %v, actual %v (found %t)", expected, gvr, found)
+	}
+}
+
+// CustomRuns are written one per line when NDJSON is accepted
+func TestCustomRunsNDJSON(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "customruns"}
+	for _, name := range []string{"first", "second", "third"} {
+		customRun := testutils.GetObject("v1beta1", "CustomRun", namespace, name, "1")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(customRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating customRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/customruns", server.URL, namespace), nil)
+	httpReq.Header.Set("Accept", MIMENDJSON)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error listing customRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != MIMENDJSON {
+		t.Errorf("Expected Content-Type %s, actual %s", MIMENDJSON, contentType)
+	}
+	names := map[string]bool{}
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		var object metav1.PartialObjectMetadata
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			t.Fatalf("Error decoding line %q: %v", scanner.Text(), err)
+		}
+		names[object.Name] = true
+	}
+	if len(names) != 3 || !names["first"] || !names["second"] || !names["third"] {
+		t.Errorf("Expected one line per customRun, actual %v", names)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PatchOperation is a JSON Patch (RFC 6902) operation, Value is ignored by
+// remove operations
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// ObjectDelta is the payload of an Updated event sent as a delta, Patch turns
+// the object at FromResourceVersion into the object at ResourceVersion
+type ObjectDelta struct {
+	Kind                string           `json:"kind"`
+	Namespace           string           `json:"namespace,omitempty"`
+	Name                string           `json:"name"`
+	UID                 types.UID        `json:"uid"`
+	ResourceVersion     string           `json:"resourceVersion"`
+	FromResourceVersion string           `json:"fromResourceVersion"`
+	Patch               []PatchOperation `json:"patch"`
+}
+
+// DefaultDeltaSnapshotEvery is how often the update of an object is sent
+// whole to subscribers receiving deltas, so clients whose copy drifted catch
+// up without fetching it again
+const DefaultDeltaSnapshotEvery = 10
+
+// deltaTransform returns a transform replacing the object of Updated events
+// with the JSON Patch from the previous state of the object, which the
+// ResourcesBroadcaster computes once per event with objectDelta. Every
+// snapshotEvery-th update of an object is sent whole as a snapshot, never if
+// 0. Events without a delta, or whose object was converted to another
+// version, are sent whole
+func deltaTransform(snapshotEvery int) broadcaster.Transform[broadcaster.SocketData] {
+	var mutex sync.Mutex
+	// Number of deltas sent for each object since it was last sent whole
+	deltas := map[types.UID]int{}
+	return func(data broadcaster.SocketData) broadcaster.SocketData {
+		object, ok := data.Payload.(*unstructured.Unstructured)
+		if !ok {
+			return data
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		uid := object.GetUID()
+		if strings.HasSuffix(string(data.MessageType), "Deleted") {
+			delete(deltas, uid)
+			return data
+		}
+		previous, ok := data.Previous.(*unstructured.Unstructured)
+		converted := !ok || previous.GetAPIVersion() != object.GetAPIVersion()
+		if data.DeltaPayload == nil || converted || snapshotEvery > 0 && deltas[uid] >= snapshotEvery-1 {
+			delete(deltas, uid)
+			return data
+		}
+		deltas[uid]++
+		data.Payload = data.DeltaPayload
+		data.Delta = true
+		return data
+	}
+}
+
+// objectDelta returns an ObjectDelta with a JSON Patch from the previous
+// state of the object of an Updated event, false if it cannot be sent as a
+// delta
+func objectDelta(data broadcaster.SocketData) (interface{}, bool) {
+	if !strings.HasSuffix(string(data.MessageType), "Updated") || data.Previous == nil {
+		return nil, false
+	}
+	current, ok := data.Payload.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	previous, ok := data.Previous.(*unstructured.Unstructured)
+	if !ok || previous.GetAPIVersion() != current.GetAPIVersion() {
+		return nil, false
+	}
+	patch, err := jsonPatch(previous.Object, current.Object)
+	if err != nil {
+		return nil, false
+	}
+	return ObjectDelta{
+		Kind:                current.GetKind(),
+		Namespace:           current.GetNamespace(),
+		Name:                current.GetName(),
+		UID:                 current.GetUID(),
+		ResourceVersion:     current.GetResourceVersion(),
+		FromResourceVersion: previous.GetResourceVersion(),
+		Patch:               patch,
+	}, true
+}
+
+// jsonPatch returns the operations turning one JSON document into another.
+// Both are compared as they encode to JSON, lists whose length changed are
+// replaced whole
+func jsonPatch(from, to interface{}) ([]PatchOperation, error) {
+	var err error
+	if from, err = normalizeJSON(from); err != nil {
+		return nil, err
+	}
+	if to, err = normalizeJSON(to); err != nil {
+		return nil, err
+	}
+	patch := []PatchOperation{}
+	diffJSON("", from, to, &patch)
+	return patch, nil
+}
+
+// normalizeJSON returns a value as decoded from its JSON encoding, so numbers
+// and nested types compare the same whatever they were built from
+func normalizeJSON(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	err = json.Unmarshal(encoded, &decoded)
+	return decoded, err
+}
+
+func diffJSON(path string, from, to interface{}, patch *[]PatchOperation) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for key := range f {
+			keys = append(keys, key)
+		}
+		for key := range t {
+			if _, found := f[key]; !found {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fromValue, inFrom := f[key]
+			toValue, inTo := t[key]
+			keyPath := path + "/" + escapePointer(key)
+			switch {
+			case !inTo:
+				*patch = append(*patch, PatchOperation{Op: "remove", Path: keyPath})
+			case !inFrom:
+				*patch = append(*patch, PatchOperation{Op: "add", Path: keyPath, Value: toValue})
+			default:
+				diffJSON(keyPath, fromValue, toValue, patch)
+			}
+		}
+		return
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok || len(t) != len(f) {
+			break
+		}
+		for i := range f {
+			diffJSON(path+"/"+strconv.Itoa(i), f[i], t[i], patch)
+		}
+		return
+	}
+	*patch = append(*patch, PatchOperation{Op: "replace", Path: path, Value: to})
+}
+
+// escapePointer escapes a key for use in a JSON Pointer (RFC 6901)
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
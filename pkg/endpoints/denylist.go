@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+)
+
+// NamespaceDenied reports whether a namespace is hidden by the denylist
+func (o Options) NamespaceDenied(namespace string) bool {
+	return utils.NamespaceDenied(o.DeniedNamespaces, namespace)
+}
+
+// hides reports whether an object is in, or is, a denied namespace
+func (o Options) hides(obj interface{}) bool {
+	return utils.InDeniedNamespace(o.DeniedNamespaces, obj)
+}
+
+// DenyNamespaces is a filter responding with 403 to requests for the
+// resources of a denied namespace, read from the namespace path parameter
+func (r Resource) DenyNamespaces(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+	namespace := request.PathParameter("namespace")
+	if r.Options.NamespaceDenied(namespace) {
+		respondNamespaceDenied(request, response, namespace)
+		return
+	}
+	chain.ProcessFilter(request, response)
+}
+
+func respondNamespaceDenied(request *restful.Request, response *restful.Response, namespace string) {
+	message := fmt.Sprintf("namespace %s is not available in the dashboard", namespace)
+	if websocket.IsUpgradeRequest(request.Request) {
+		websocket.RespondUpgradeError(response, http.StatusForbidden, message)
+		return
+	}
+	utils.RespondErrorMessage(response, message, http.StatusForbidden)
+}
+
+// proxyPath is what a request proxied to the Kubernetes API is for
+type proxyPath struct {
+	// namespace the path is scoped to, or the namespace it gets
+	namespace string
+	// collection is set for the paths listing a resource across namespaces,
+	// including the namespaces themselves
+	collection bool
+	watch      bool
+}
+
+// parseProxyPath reads the namespace of an API server path, such as
+// apis/tekton.dev/v1beta1/namespaces/default/pipelineruns
+func parseProxyPath(path string) proxyPath {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return proxyPath{}
+	}
+	var parsed proxyPath
+	if len(segments) > 0 && segments[0] == "watch" {
+		parsed.watch = true
+		segments = segments[1:]
+	}
+	switch {
+	case len(segments) >= 2 && segments[0] == "namespaces":
+		parsed.namespace = segments[1]
+	case len(segments) == 1:
+		parsed.collection = true
+	}
+	return parsed
+}
+
+// proxyDenyingNamespaces proxies a request to the Kubernetes API unless it is
+// for a denied namespace, removing the objects of denied namespaces from
+// lists across namespaces. It returns false if the request is not affected by
+// the denylist and should be proxied as is
+func (r Resource) proxyDenyingNamespaces(request *restful.Request, response *restful.Response, uri string) bool {
+	if len(r.Options.DeniedNamespaces) == 0 {
+		return false
+	}
+	path := parseProxyPath(request.PathParameter("subpath"))
+	if path.watch || request.QueryParameter("watch") == "true" || request.QueryParameter("watch") == "1" {
+		path.watch = true
+	}
+	switch {
+	case r.Options.NamespaceDenied(path.namespace):
+		respondNamespaceDenied(request, response, path.namespace)
+	case !path.collection:
+		return false
+	case request.Request.Method != http.MethodGet:
+		return false
+	case path.watch:
+		utils.RespondErrorMessage(response, "watching resources across namespaces is not allowed while namespaces are denied, watch a namespace instead", http.StatusForbidden)
+	default:
+		r.proxyFilteredList(request, response, uri)
+	}
+	return true
+}
+
+// proxyFilteredList gets a list from the Kubernetes API and responds with it
+// without the items in or of denied namespaces. Errors are passed on as is
+func (r Resource) proxyFilteredList(request *restful.Request, response *restful.Response, uri string) {
+	req, err := http.NewRequest(http.MethodGet, r.Config.Host+"/"+uri, nil)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	req = req.WithContext(request.Request.Context())
+	req.Header.Set("Accept", "application/json")
+	resp, err := r.HttpClient.Do(req)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		var list map[string]interface{}
+		if err := json.Unmarshal(body, &list); err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		if items, ok := list["items"].([]interface{}); ok {
+			namespaces := strings.HasSuffix(strings.Trim(request.PathParameter("subpath"), "/"), "/namespaces")
+			kept := []interface{}{}
+			for _, item := range items {
+				object, _ := item.(map[string]interface{})
+				metadata, _ := object["metadata"].(map[string]interface{})
+				namespace, _ := metadata["namespace"].(string)
+				if namespaces {
+					namespace, _ = metadata["name"].(string)
+				}
+				if !r.Options.NamespaceDenied(namespace) {
+					kept = append(kept, item)
+				}
+			}
+			list["items"] = kept
+		}
+		if body, err = json.Marshal(list); err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	response.AddHeader("Content-Type", resp.Header.Get("Content-Type"))
+	response.WriteHeader(resp.StatusCode)
+	response.Write(body)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// The resources of denied namespaces cannot be accessed, and lists across
+// namespaces leave them out
+func TestDeniedNamespaces(t *testing.T) {
+	server, r, namespace := testutils.DummyServerWithOptions(Options{DeniedNamespaces: []string{"kube-system"}})
+	defer server.Close()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/v1/namespaces" {
+			t.Errorf("Expected only namespaces to be listed, actual %s", req.URL.Path)
+		}
+		json.NewEncoder(w).Encode(corev1.NamespaceList{
+			Items: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			},
+		})
+	}))
+	defer apiServer.Close()
+	r.Config = &rest.Config{Host: apiServer.URL}
+	r.HttpClient = http.DefaultClient
+	server.Config.Handler = router.Register(*r)
+
+	for path, expected := range map[string]int{
+		fmt.Sprintf("/v1/namespaces/%s/pipelineruns", namespace):                 http.StatusOK,
+		"/v1/namespaces/kube-system/pipelineruns":                                http.StatusForbidden,
+		"/v1/namespaces/kube-system/taskruns/name/steps":                         http.StatusForbidden,
+		"/v1/namespaces/kube-system/label-keys":                                  http.StatusForbidden,
+		"/proxy/api/v1/namespaces/kube-system":                                   http.StatusForbidden,
+		"/proxy/api/v1/namespaces/kube-system/pods":                              http.StatusForbidden,
+		"/proxy/apis/tekton.dev/v1beta1/namespaces/kube-system/pipelineruns/run": http.StatusForbidden,
+		"/proxy/apis/tekton.dev/v1beta1/pipelineruns?watch=true":                 http.StatusForbidden,
+	} {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", server.URL+path, nil))
+		if err != nil {
+			t.Fatalf("Error getting %s: %v", path, err)
+		}
+		if response.StatusCode != expected {
+			t.Errorf("Expected statusCode %d for %s, actual %d", expected, path, response.StatusCode)
+		}
+	}
+
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", server.URL+"/proxy/api/v1/namespaces", nil))
+	if err != nil {
+		t.Fatalf("Error listing namespaces: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var namespaces corev1.NamespaceList
+	if err := json.NewDecoder(response.Body).Decode(&namespaces); err != nil {
+		t.Fatalf("Error decoding namespaces: %v", err)
+	}
+	var names []string
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	if expected := []string{"default"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected namespaces %v, actual %v", expected, names)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PipelineDrift compares the Pipeline spec a PipelineRun ran with against the
+// current spec of the Pipeline it references
+type PipelineDrift struct {
+	Pipeline        string       `json:"pipeline"`
+	PipelineDeleted bool         `json:"pipelineDeleted"`
+	Changed         bool         `json:"changed"`
+	Changes         []SpecChange `json:"changes"`
+}
+
+// SpecChange is a field that differs between the spec the run used and the
+// current Pipeline, a missing value means the field is not set on that side
+type SpecChange struct {
+	Path     string      `json:"path"`
+	Run      interface{} `json:"run,omitempty"`
+	Pipeline interface{} `json:"pipeline,omitempty"`
+}
+
+// GetPipelineRunDrift reports whether the Pipeline referenced by a PipelineRun
+// has changed since the run resolved it, and which fields differ
+func (r Resource) GetPipelineRunDrift(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	refName, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "pipelineRef", "name")
+	if refName == "" {
+		utils.RespondErrorMessage(response, fmt.Sprintf("PipelineRun '%s' does not reference a Pipeline", name), http.StatusBadRequest)
+		return
+	}
+	runSpec, found, _ := unstructured.NestedMap(pipelineRun.Object, "status", "pipelineSpec")
+	if !found {
+		utils.RespondErrorMessage(response, fmt.Sprintf("PipelineRun '%s' has not resolved its Pipeline yet", name), http.StatusConflict)
+		return
+	}
+
+	drift := PipelineDrift{Pipeline: refName, Changes: []SpecChange{}}
+	pipeline, err := r.getTektonResource("pipelines", namespace, refName)
+	if k8serrors.IsNotFound(err) {
+		drift.PipelineDeleted = true
+		drift.Changed = true
+		response.WriteEntity(drift)
+		return
+	}
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	pipelineSpec, _, _ := unstructured.NestedMap(pipeline.Object, "spec")
+	diffSpecs("", runSpec, pipelineSpec, &drift.Changes)
+	drift.Changed = len(drift.Changes) > 0
+
+	response.WriteEntity(drift)
+}
+
+// diffSpecs appends the fields that differ between two decoded JSON values,
+// descending into objects and into lists index by index
+func diffSpecs(path string, run, pipeline interface{}, changes *[]SpecChange) {
+	runMap, runIsMap := run.(map[string]interface{})
+	pipelineMap, pipelineIsMap := pipeline.(map[string]interface{})
+	if runIsMap && pipelineIsMap {
+		keys := map[string]bool{}
+		for key := range runMap {
+			keys[key] = true
+		}
+		for key := range pipelineMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			diffSpecs(field, runMap[key], pipelineMap[key], changes)
+		}
+		return
+	}
+
+	runList, runIsList := run.([]interface{})
+	pipelineList, pipelineIsList := pipeline.([]interface{})
+	if runIsList && pipelineIsList {
+		for i := 0; i < len(runList) || i < len(pipelineList); i++ {
+			var runItem, pipelineItem interface{}
+			if i < len(runList) {
+				runItem = runList[i]
+			}
+			if i < len(pipelineList) {
+				pipelineItem = pipelineList[i]
+			}
+			diffSpecs(fmt.Sprintf("%s[%d]", path, i), runItem, pipelineItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(run, pipeline) {
+		*changes = append(*changes, SpecChange{Path: path, Run: run, Pipeline: pipeline})
+	}
+}
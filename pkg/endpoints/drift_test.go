@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun drift reports the fields of the Pipeline changed since the run
+func TestGETPipelineRunDrift(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	tasks := func(image string) []interface{} {
+		return []interface{}{
+			map[string]interface{}{"name": "fetch", "taskRef": map[string]interface{}{"name": "git-clone"}},
+			map[string]interface{}{"name": "build", "params": []interface{}{
+				map[string]interface{}{"name": "image", "value": image},
+			}},
+		}
+	}
+	pipelineRuns := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}).Namespace(namespace)
+	for _, name := range []string{"current", "orphaned"} {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, name, "1")
+		unstructured.SetNestedField(pipelineRun.Object, name+"-pipeline", "spec", "pipelineRef", "name")
+		unstructured.SetNestedField(pipelineRun.Object, map[string]interface{}{"tasks": tasks("kaniko:v1")}, "status", "pipelineSpec")
+		if _, err := pipelineRuns.Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+	// The Pipeline changed the build image and added a workspace after the run
+	pipeline := testutils.GetObject("v1beta1", "Pipeline", namespace, "current-pipeline", "1")
+	unstructured.SetNestedField(pipeline.Object, map[string]interface{}{
+		"tasks":      tasks("kaniko:v2"),
+		"workspaces": []interface{}{map[string]interface{}{"name": "source"}},
+	}, "spec")
+	pipelines := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	if _, err := r.DynamicClient.Resource(pipelines).Namespace(namespace).Create(pipeline, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipeline: %v", err)
+	}
+
+	tests := map[string]PipelineDrift{
+		"current": {
+			Pipeline: "current-pipeline",
+			Changed:  true,
+			Changes: []SpecChange{
+				{Path: "tasks[1].params[0].value", Run: "kaniko:v1", Pipeline: "kaniko:v2"},
+				{Path: "workspaces", Pipeline: []interface{}{map[string]interface{}{"name": "source"}}},
+			},
+		},
+		"orphaned": {
+			Pipeline:        "orphaned-pipeline",
+			PipelineDeleted: true,
+			Changed:         true,
+			Changes:         []SpecChange{},
+		},
+	}
+	for name, expected := range tests {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/%s/drift", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting drift: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected statusCode %d, actual %d", name, http.StatusOK, response.StatusCode)
+		}
+		var drift PipelineDrift
+		if err := json.NewDecoder(response.Body).Decode(&drift); err != nil {
+			t.Fatalf("Error decoding drift: %v", err)
+		}
+		if !reflect.DeepEqual(drift, expected) {
+			t.Errorf("%s: expected drift %+v, actual %+v", name, expected, drift)
+		}
+	}
+}
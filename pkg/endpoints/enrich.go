@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+// runEventPrefixes are the prefixes of the message types of run events
+var runEventPrefixes = []string{"PipelineRun", "TaskRun", "CustomRun"}
+
+// enrichments are the transforms websocket clients can ask for by name with
+// the enrich parameter
+var enrichments = map[string]broadcaster.Transform[broadcaster.SocketData]{
+	"duration": enrichDuration,
+}
+
+// enrichTransforms parses the comma separated names of enrichments
+func enrichTransforms(enrich string) ([]broadcaster.Transform[broadcaster.SocketData], error) {
+	var transforms []broadcaster.Transform[broadcaster.SocketData]
+	for _, name := range strings.Split(enrich, ",") {
+		transform, ok := enrichments[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid enrich '%s', must be duration", name)
+		}
+		transforms = append(transforms, transform)
+	}
+	return transforms, nil
+}
+
+// enrichDuration sets the Duration of completed runs and the Elapsed time of
+// running ones from their status timestamps
+func enrichDuration(data broadcaster.SocketData) broadcaster.SocketData {
+	if !isRunEvent(data.MessageType) {
+		return data
+	}
+	payload := data.Payload
+	if tombstone, ok := payload.(cache.DeletedFinalStateUnknown); ok {
+		payload = tombstone.Obj
+	}
+	run, ok := payload.(*unstructured.Unstructured)
+	if !ok {
+		return data
+	}
+	started, ok := nestedTime(run, "status", "startTime")
+	if !ok {
+		return data
+	}
+	if completed, ok := nestedTime(run, "status", "completionTime"); ok {
+		duration := completed.Sub(started).Seconds()
+		data.Duration = &duration
+	} else {
+		elapsed := time.Since(started).Seconds()
+		data.Elapsed = &elapsed
+	}
+	return data
+}
+
+func isRunEvent(messageType broadcaster.MessageType) bool {
+	for _, prefix := range runEventPrefixes {
+		if strings.HasPrefix(string(messageType), prefix) {
+			return true
+		}
+	}
+	return false
+}
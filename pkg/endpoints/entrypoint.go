@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// entrypointBoolFlags are the flags of Tekton's entrypoint binary that take no
+// value
+var entrypointBoolFlags = map[string]bool{
+	"wait_file_content":     true,
+	"breakpoint_on_failure": true,
+	"debug_before_step":     true,
+	"enable_spire":          true,
+}
+
+// StepEntrypoint is how Tekton's entrypoint binary orders a step: it waits for
+// the wait file before running the step, then writes the post file that the
+// next step waits for
+type StepEntrypoint struct {
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	WaitFile  string `json:"waitFile,omitempty"`
+	PostFile  string `json:"postFile,omitempty"`
+	// WaitsFor is the step writing the wait file, empty for the first step
+	// which waits for the pod to be ready
+	WaitsFor   string            `json:"waitsFor,omitempty"`
+	Entrypoint string            `json:"entrypoint,omitempty"`
+	Args       []string          `json:"args"`
+	Flags      map[string]string `json:"flags"`
+}
+
+// GetTaskRunEntrypoint returns the entrypoint flags Tekton injected in each
+// step container of a TaskRun's pod, in step order, to show which prior step
+// a step is waiting for
+func (r Resource) GetTaskRunEntrypoint(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	steps := []StepEntrypoint{}
+	// Steps by the post file they write
+	posters := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+			continue
+		}
+		flags, args := parseEntrypointArgs(container.Args)
+		step := StepEntrypoint{
+			Name:       strings.TrimPrefix(container.Name, stepContainerPrefix),
+			Container:  container.Name,
+			WaitFile:   flags["wait_file"],
+			PostFile:   flags["post_file"],
+			Entrypoint: flags["entrypoint"],
+			Args:       args,
+			Flags:      flags,
+		}
+		step.WaitsFor = posters[step.WaitFile]
+		if step.PostFile != "" {
+			posters[step.PostFile] = step.Name
+		}
+		steps = append(steps, step)
+	}
+
+	response.WriteEntity(steps)
+}
+
+// parseEntrypointArgs splits the args of a step container into the flags
+// given to the entrypoint binary and the args of the step after "--"
+func parseEntrypointArgs(containerArgs []string) (map[string]string, []string) {
+	flags := map[string]string{}
+	args := []string{}
+	for i := 0; i < len(containerArgs); i++ {
+		arg := containerArgs[i]
+		if arg == "--" {
+			args = append(args, containerArgs[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+			continue
+		}
+		flag := strings.TrimLeft(arg, "-")
+		if j := strings.Index(flag, "="); j >= 0 {
+			flags[flag[:j]] = flag[j+1:]
+			continue
+		}
+		if entrypointBoolFlags[flag] || i+1 == len(containerArgs) {
+			flags[flag] = "true"
+			continue
+		}
+		i++
+		flags[flag] = containerArgs[i]
+	}
+	return flags, args
+}
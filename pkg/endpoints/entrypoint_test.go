@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET TaskRun entrypoint reports the wait and post file chain of the steps
+func TestGETTaskRunEntrypoint(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "step-clone", Args: []string{
+					"-wait_file", "/tekton/downward/ready", "-wait_file_content",
+					"-post_file", "/tekton/run/0/out", "-termination_path", "/tekton/termination",
+					"-entrypoint", "git", "--", "clone", "repo",
+				}},
+				{Name: "step-test", Args: []string{
+					"-wait_file", "/tekton/run/0/out", "-post_file", "/tekton/run/1/out",
+					"-entrypoint=go", "--", "test", "./...",
+				}},
+				{Name: "sidecar-db"},
+				{Name: "step-push", Args: []string{
+					"-wait_file", "/tekton/run/1/out", "-post_file", "/tekton/run/2/out", "-entrypoint", "docker", "--",
+				}},
+			},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/entrypoint", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting entrypoint: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var steps []StepEntrypoint
+	if err := json.NewDecoder(response.Body).Decode(&steps); err != nil {
+		t.Fatalf("Error decoding entrypoint: %v", err)
+	}
+	expected := []StepEntrypoint{
+		{
+			Name: "clone", Container: "step-clone", WaitFile: "/tekton/downward/ready", PostFile: "/tekton/run/0/out",
+			Entrypoint: "git", Args: []string{"clone", "repo"},
+			Flags: map[string]string{
+				"wait_file": "/tekton/downward/ready", "wait_file_content": "true", "post_file": "/tekton/run/0/out",
+				"termination_path": "/tekton/termination", "entrypoint": "git",
+			},
+		},
+		{
+			Name: "test", Container: "step-test", WaitFile: "/tekton/run/0/out", PostFile: "/tekton/run/1/out", WaitsFor: "clone",
+			Entrypoint: "go", Args: []string{"test", "./..."},
+			Flags: map[string]string{"wait_file": "/tekton/run/0/out", "post_file": "/tekton/run/1/out", "entrypoint": "go"},
+		},
+		{
+			Name: "push", Container: "step-push", WaitFile: "/tekton/run/1/out", PostFile: "/tekton/run/2/out", WaitsFor: "test",
+			Entrypoint: "docker", Args: []string{},
+			Flags: map[string]string{"wait_file": "/tekton/run/1/out", "post_file": "/tekton/run/2/out", "entrypoint": "docker"},
+		},
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Expected steps %+v, actual %+v", expected, steps)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// How many of the most recent deliveries are returned by default and at most
+const (
+	defaultDeliveries = 20
+	maxDeliveries     = 100
+)
+
+// EventListenerOverview is the triggers of an EventListener and the recent
+// events it delivered, newest first
+type EventListenerOverview struct {
+	Name       string            `json:"name"`
+	Triggers   []TriggerOverview `json:"triggers"`
+	Deliveries []Delivery        `json:"deliveries"`
+}
+
+// TriggerOverview names the bindings and template of a trigger, or the
+// Trigger it references
+type TriggerOverview struct {
+	Name       string   `json:"name"`
+	TriggerRef string   `json:"triggerRef,omitempty"`
+	Bindings   []string `json:"bindings"`
+	Template   string   `json:"template,omitempty"`
+}
+
+// Delivery is an event the EventListener received, with the PipelineRuns it
+// created and their status
+type Delivery struct {
+	EventID string        `json:"eventID"`
+	Trigger string        `json:"trigger,omitempty"`
+	Created time.Time     `json:"created"`
+	Runs    []DeliveryRun `json:"runs"`
+}
+
+// DeliveryRun is a PipelineRun created for a delivery
+type DeliveryRun struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// GetEventListenerOverview returns the triggers of an EventListener with the
+// recent deliveries it made, found from the PipelineRuns carrying its label
+// and grouped by event ID
+func (r Resource) GetEventListenerOverview(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	count, err := intParameter(request, "deliveries", defaultDeliveries, maxDeliveries)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	kind, _ := lookupKind(r.Options, "EventListener")
+	eventListener, err := r.DynamicClient.Resource(kind.GVR).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", EventListenerLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	overview := EventListenerOverview{
+		Name:       name,
+		Triggers:   eventListenerTriggers(eventListener),
+		Deliveries: []Delivery{},
+	}
+	byEvent := map[string]int{}
+	// Runs are newest first so deliveries are too
+	for _, pipelineRun := range recentRuns(pipelineRuns.Items, len(pipelineRuns.Items)) {
+		source := runSource(pipelineRun)
+		// Runs created before Triggers recorded event IDs are deliveries of
+		// their own
+		eventID := source.EventID
+		if eventID == "" {
+			eventID = string(pipelineRun.GetUID())
+		}
+		i, found := byEvent[eventID]
+		if !found {
+			if len(overview.Deliveries) == count {
+				continue
+			}
+			i = len(overview.Deliveries)
+			byEvent[eventID] = i
+			overview.Deliveries = append(overview.Deliveries, Delivery{
+				EventID: source.EventID,
+				Trigger: source.Trigger,
+				Created: pipelineRun.GetCreationTimestamp().Time,
+				Runs:    []DeliveryRun{},
+			})
+		}
+		overview.Deliveries[i].Runs = append(overview.Deliveries[i].Runs, DeliveryRun{Name: pipelineRun.GetName(), Status: runStatus(pipelineRun)})
+	}
+	response.WriteEntity(overview)
+}
+
+// eventListenerTriggers reads the triggers of an EventListener's spec,
+// bindings and templates are named by their ref, or name in older versions
+func eventListenerTriggers(eventListener *unstructured.Unstructured) []TriggerOverview {
+	triggers := []TriggerOverview{}
+	specTriggers, _, _ := unstructured.NestedSlice(eventListener.Object, "spec", "triggers")
+	for _, t := range specTriggers {
+		trigger, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		overview := TriggerOverview{Bindings: []string{}}
+		overview.Name, _, _ = unstructured.NestedString(trigger, "name")
+		overview.TriggerRef, _, _ = unstructured.NestedString(trigger, "triggerRef")
+		bindings, _, _ := unstructured.NestedSlice(trigger, "bindings")
+		for _, b := range bindings {
+			if binding, ok := b.(map[string]interface{}); ok {
+				if name := refOrName(binding); name != "" {
+					overview.Bindings = append(overview.Bindings, name)
+				}
+			}
+		}
+		if template, found, _ := unstructured.NestedMap(trigger, "template"); found {
+			overview.Template = refOrName(template)
+		}
+		triggers = append(triggers, overview)
+	}
+	return triggers
+}
+
+// refOrName returns the ref of a binding or template, or its name
+func refOrName(reference map[string]interface{}) string {
+	if ref, _ := reference["ref"].(string); ref != "" {
+		return ref
+	}
+	name, _ := reference["name"].(string)
+	return name
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET EventListener overview links its triggers to the runs they created
+func TestGETEventListenerOverview(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	eventListener := testutils.GetObject("v1alpha1", "EventListener", namespace, "github-listener", "1")
+	eventListener.SetAPIVersion("triggers.tekton.dev/v1alpha1")
+	unstructured.SetNestedSlice(eventListener.Object, []interface{}{
+		map[string]interface{}{
+			"name":     "push",
+			"bindings": []interface{}{map[string]interface{}{"ref": "github-push"}},
+			"template": map[string]interface{}{"ref": "build-template"},
+		},
+	}, "spec", "triggers")
+	eventListenersGVR := schema.GroupVersionResource{Group: "triggers.tekton.dev", Version: "v1alpha1", Resource: "eventlisteners"}
+	if _, err := r.DynamicClient.Resource(eventListenersGVR).Namespace(namespace).Create(eventListener, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating eventListener: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, run := range []struct {
+		name     string
+		listener string
+	}{
+		{"build-push", "github-listener"},
+		{"build-other", "gitlab-listener"},
+	} {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetCreationTimestamp(metav1.NewTime(now))
+		pipelineRun.SetLabels(map[string]string{
+			EventListenerLabel: run.listener,
+			TriggerLabel:       "push",
+			EventIDLabel:       run.name + "-event",
+		})
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": "False", "reason": "Failed"},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/eventlisteners/github-listener/overview", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting EventListener overview: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var overview EventListenerOverview
+	if err := json.NewDecoder(response.Body).Decode(&overview); err != nil {
+		t.Fatalf("Error decoding EventListener overview: %v", err)
+	}
+
+	expected := EventListenerOverview{
+		Name:     "github-listener",
+		Triggers: []TriggerOverview{{Name: "push", Bindings: []string{"github-push"}, Template: "build-template"}},
+		Deliveries: []Delivery{{
+			EventID: "build-push-event",
+			Trigger: "push",
+			Created: now,
+			Runs:    []DeliveryRun{{Name: "build-push", Status: RunFailed}},
+		}},
+	}
+	if len(overview.Deliveries) != 1 {
+		t.Fatalf("Expected a delivery, actual %+v", overview.Deliveries)
+	}
+	if !overview.Deliveries[0].Created.Equal(now) {
+		t.Errorf("Expected delivery created %s, actual %s", now, overview.Deliveries[0].Created)
+	}
+	overview.Deliveries[0].Created = now
+	if !reflect.DeepEqual(overview, expected) {
+		t.Errorf("Expected %+v, actual %+v", expected, overview)
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/eventlisteners/missing/overview", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting EventListener overview: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
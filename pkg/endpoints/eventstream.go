@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/sse"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+// lastEventIDHeader is sent by browsers reconnecting to an event stream with
+// the id of the last event they received
+const lastEventIDHeader = "Last-Event-ID"
+
+// EstablishResourcesEventStream streams the events of the resources websocket
+// as server-sent events, for clients behind proxies that block websocket
+// upgrades. It takes the same query parameters as EstablishResourcesWebsocket,
+// but the subscription cannot be changed once established. Events have their
+// Sequence as id, so browsers reconnecting after an error resume from the
+// last event received with the Last-Event-ID header, which takes precedence
+// over since
+func (r Resource) EstablishResourcesEventStream(request *restful.Request, response *restful.Response) {
+	since := request.QueryParameter("since")
+	if lastEventID := request.HeaderParameter(lastEventIDHeader); lastEventID != "" {
+		since = lastEventID
+	}
+	opts, priority, err := r.resourcesSubscribeOptions(request, since)
+	if err != nil {
+		utils.RespondErrorMessage(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if ResourcesBroadcaster.Full(priority) {
+		response.AddHeader("Retry-After", strconv.Itoa(int(subscriberRetryAfter.Seconds())))
+		utils.RespondErrorMessage(response, fmt.Sprintf("too many clients, retry in %s", subscriberRetryAfter), http.StatusServiceUnavailable)
+		return
+	}
+	writer, err := sse.NewWriter(response)
+	if err != nil {
+		logging.Log.Errorf("Could not start event stream: %s", err)
+		utils.RespondErrorMessage(response, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	subscriber, err := ResourcesBroadcaster.Subscribe(opts...)
+	if err != nil {
+		logging.Log.Errorf("Could not subscribe to resources: %s", err)
+		return
+	}
+	defer ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	if initial := subscriber.Initial(); initial != nil {
+		for _, data := range initial {
+			if writer.WriteEvent(data) != nil {
+				return
+			}
+		}
+		if writer.WriteEvent(subscriber.InitialComplete()) != nil {
+			return
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	events := streamedEvents(subscriber, done)
+	if err := writer.Stream(events, request.Request.Context().Done(), 0); err != nil {
+		logging.Log.Debugf("Event stream closed: %s", err)
+	}
+}
+
+// streamedEvents forwards the messages of a subscriber until it is
+// unsubscribed, or its MaxEvents have been sent followed by a MaxEventsReached
+// message, then closes the returned channel to end the stream. Messages
+// dropped by the overflow policy are reported with EventsDropped as on
+// websockets. It stops when done is closed. Browsers reconnect to a stream
+// ended by the server, so evicted subscribers resume from their last event
+func streamedEvents(subscriber *broadcaster.Subscriber[broadcaster.SocketData], done <-chan struct{}) <-chan broadcaster.SocketData {
+	events := make(chan broadcaster.SocketData)
+	go func() {
+		defer close(events)
+		forward := func(data broadcaster.SocketData) bool {
+			select {
+			case events <- data:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		maxEvents := subscriber.MaxEvents()
+		sent := 0
+		var reported uint64
+		for {
+			select {
+			case data := <-subscriber.SubChan():
+				if dropped := subscriber.Dropped(); dropped > reported {
+					reported = dropped
+					if !forward(broadcaster.SocketData{MessageType: broadcaster.EventsDropped, Payload: dropped}) {
+						return
+					}
+				}
+				if !forward(data) {
+					return
+				}
+				if sent++; maxEvents > 0 && sent >= maxEvents {
+					forward(broadcaster.SocketData{MessageType: broadcaster.MaxEventsReached, Payload: maxEvents})
+					return
+				}
+			case <-subscriber.UnsubChan():
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+	return events
+}
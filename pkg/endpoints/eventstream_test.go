@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// streamEvent is a server-sent event with its decoded data
+type streamEvent struct {
+	id   string
+	name string
+	data broadcaster.SocketData
+}
+
+// openEventStream gets an event stream, failing unless it is accepted
+func openEventStream(t *testing.T, streamURL string, header http.Header) (*http.Response, *bufio.Reader) {
+	request, err := http.NewRequest("GET", streamURL, nil)
+	if err != nil {
+		t.Fatalf("Error creating request: %s", err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Error getting %s: %s", streamURL, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, actual %s", contentType)
+	}
+	return response, bufio.NewReader(response.Body)
+}
+
+// readStreamEvent reads the next event of a stream, skipping comments
+func readStreamEvent(t *testing.T, reader *bufio.Reader) streamEvent {
+	var event streamEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event.name != "" {
+				return event
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.data); err != nil {
+				t.Fatalf("Error decoding event data: %s", err)
+			}
+		default:
+			t.Fatalf("Unexpected line in event stream: %q", line)
+		}
+	}
+}
+
+// Resource events are streamed with their sequence number as id, and
+// reconnecting with the Last-Event-ID header replays the events missed
+func TestEventStream(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	query := url.Values{"kinds": {"TaskCreated"}, "namespaces": {namespace}}
+	streamURL := server.URL + "/v1/sse/resources?" + query.Encode()
+	response, reader := openEventStream(t, streamURL, nil)
+	defer response.Body.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	tasks := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "stream-first", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	first := readStreamEvent(t, reader)
+	if first.name != string(broadcaster.TaskCreated) || payloadName(first.data) != "stream-first" {
+		t.Fatalf("Expected %s of stream-first, actual %s of %s", broadcaster.TaskCreated, first.name, payloadName(first.data))
+	}
+	if first.id != strconv.FormatUint(first.data.Sequence, 10) {
+		t.Errorf("Expected event id %d, actual %s", first.data.Sequence, first.id)
+	}
+	response.Body.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "stream-second", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	awaitFatal(func() bool {
+		for _, socketData := range ResourcesEvents.Compacted() {
+			if payloadName(socketData) == "stream-second" {
+				return true
+			}
+		}
+		return false
+	}, t, "Expected events to be broadcast")
+
+	// The header takes precedence over since, which is no longer kept
+	query.Set("since", "1")
+	response, reader = openEventStream(t, server.URL+"/v1/sse/resources?"+query.Encode(), http.Header{"Last-Event-ID": {first.id}})
+	defer response.Body.Close()
+	replayed := readStreamEvent(t, reader)
+	if payloadName(replayed.data) != "stream-second" || replayed.data.Sequence <= first.data.Sequence {
+		t.Errorf("Expected stream-second replayed after %d, actual %s %d", first.data.Sequence, payloadName(replayed.data), replayed.data.Sequence)
+	}
+	if complete := readStreamEvent(t, reader); complete.name != string(broadcaster.ReplayComplete) || complete.id != "" {
+		t.Errorf("Expected %s without id, actual %s with id %q", broadcaster.ReplayComplete, complete.name, complete.id)
+	}
+}
+
+// Invalid parameters are rejected before streaming
+func TestEventStreamInvalidParameters(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+
+	tests := []struct {
+		query       string
+		lastEventID string
+	}{
+		{"kinds=Unknown", ""},
+		{"priority=high", ""},
+		{"overflow=drop-all", ""},
+		{"", "not-a-sequence"},
+		{"since=not-a-sequence", ""},
+	}
+	for _, test := range tests {
+		request, err := http.NewRequest("GET", server.URL+"/v1/sse/resources?"+test.query, nil)
+		if err != nil {
+			t.Fatalf("Error creating request: %s", err)
+		}
+		if test.lastEventID != "" {
+			request.Header.Set("Last-Event-ID", test.lastEventID)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Error getting event stream: %s", err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("%q, Last-Event-ID %q: expected statusCode %d, actual %d", test.query, test.lastEventID, http.StatusBadRequest, response.StatusCode)
+		}
+	}
+	if ResourcesBroadcaster.PoolSize() != 0 {
+		t.Errorf("Expected no subscribers, actual %d", ResourcesBroadcaster.PoolSize())
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const defaultFailureWindow = 24 * time.Hour
+
+// FailureReason is how many PipelineRuns failed with a Succeeded condition
+// reason, and the failed steps of their TaskRuns
+type FailureReason struct {
+	Reason    string     `json:"reason"`
+	Count     int        `json:"count"`
+	StepExits []StepExit `json:"stepExits"`
+}
+
+// StepExit is how many times a step of a pipeline task exited with a non zero
+// exit code
+type StepExit struct {
+	PipelineTask string `json:"pipelineTask"`
+	Step         string `json:"step"`
+	ExitCode     int64  `json:"exitCode"`
+	Count        int    `json:"count"`
+}
+
+// GetPipelineRunFailureReasons tallies the reasons of the PipelineRuns that
+// failed within the window, most frequent first
+func (r Resource) GetPipelineRunFailureReasons(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	window, err := durationParameter(request, "window", defaultFailureWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	cutoff := time.Now().Add(-window)
+	// reasons of the failed PipelineRuns by namespace and name
+	failed := map[string]string{}
+	tally := map[string]*FailureReason{}
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		if runStatus(pipelineRun) != RunFailed {
+			continue
+		}
+		completed, ok := nestedTime(pipelineRun, "status", "completionTime")
+		if !ok {
+			completed = pipelineRun.GetCreationTimestamp().Time
+		}
+		if completed.Before(cutoff) {
+			continue
+		}
+		_, reason, _, _ := succeededCondition(pipelineRun)
+		if reason == "" {
+			reason = RunFailed
+		}
+		failed[pipelineRun.GetNamespace()+"/"+pipelineRun.GetName()] = reason
+		if tally[reason] == nil {
+			tally[reason] = &FailureReason{Reason: reason, StepExits: []StepExit{}}
+		}
+		tally[reason].Count++
+	}
+
+	if len(failed) > 0 {
+		taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{LabelSelector: PipelineRunLabel})
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		for i := range taskRuns.Items {
+			taskRun := &taskRuns.Items[i]
+			labels := taskRun.GetLabels()
+			reason, ok := failed[taskRun.GetNamespace()+"/"+labels[PipelineRunLabel]]
+			if !ok || runStatus(taskRun) != RunFailed {
+				continue
+			}
+			if step, exitCode, ok := failedStep(taskRun); ok {
+				tally[reason].addStepExit(labels[PipelineTaskLabel], step, exitCode)
+			}
+		}
+	}
+
+	reasons := []FailureReason{}
+	for _, reason := range tally {
+		sort.Slice(reason.StepExits, func(i, j int) bool {
+			a, b := reason.StepExits[i], reason.StepExits[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			if a.PipelineTask != b.PipelineTask {
+				return a.PipelineTask < b.PipelineTask
+			}
+			return a.Step < b.Step
+		})
+		reasons = append(reasons, *reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
+		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+
+	response.WriteEntity(reasons)
+}
+
+func (f *FailureReason) addStepExit(pipelineTask, step string, exitCode int64) {
+	for i := range f.StepExits {
+		exit := &f.StepExits[i]
+		if exit.PipelineTask == pipelineTask && exit.Step == step && exit.ExitCode == exitCode {
+			exit.Count++
+			return
+		}
+	}
+	f.StepExits = append(f.StepExits, StepExit{PipelineTask: pipelineTask, Step: step, ExitCode: exitCode, Count: 1})
+}
+
+// failedStep returns the first step of a TaskRun that exited with a non zero
+// exit code
+func failedStep(taskRun *unstructured.Unstructured) (string, int64, bool) {
+	steps, _, _ := unstructured.NestedSlice(taskRun.Object, "status", "steps")
+	for _, s := range steps {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if exitCode, found, _ := unstructured.NestedInt64(step, "terminated", "exitCode"); found && exitCode != 0 {
+			name, _ := step["name"].(string)
+			return name, exitCode, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun failure reasons ranks the reasons of recent failures
+func TestGETPipelineRunFailureReasons(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	now := time.Now()
+	pipelineRuns := []struct {
+		name      string
+		status    string
+		reason    string
+		completed time.Time
+	}{
+		{"timeout-1", "False", "PipelineRunTimeout", now.Add(-time.Hour)},
+		{"failed-1", "False", "Failed", now.Add(-2 * time.Hour)},
+		{"failed-2", "False", "Failed", now.Add(-3 * time.Hour)},
+		{"failed-old", "False", "Failed", now.Add(-48 * time.Hour)},
+		{"cancelled", "False", "PipelineRunCancelled", now.Add(-time.Hour)},
+		{"succeeded", "True", "Succeeded", now.Add(-time.Hour)},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, run := range pipelineRuns {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		unstructured.SetNestedField(pipelineRun.Object, run.completed.UTC().Format(time.RFC3339), "status", "completionTime")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status, "reason": run.reason},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+	// Both failures come from the test step exiting with 1
+	for _, pipelineRun := range []string{"failed-1", "failed-2", "failed-old"} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, pipelineRun+"-unit", "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: pipelineRun, PipelineTaskLabel: "unit"})
+		unstructured.SetNestedField(taskRun.Object, map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Succeeded", "status": "False", "reason": "Failed"}},
+			"steps": []interface{}{
+				map[string]interface{}{"name": "setup", "terminated": map[string]interface{}{"exitCode": int64(0)}},
+				map[string]interface{}{"name": "test", "terminated": map[string]interface{}{"exitCode": int64(1)}},
+			},
+		}, "status")
+		taskRunGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+		if _, err := r.DynamicClient.Resource(taskRunGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/failure-reasons?window=24h", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting failure reasons: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var reasons []FailureReason
+	if err := json.NewDecoder(response.Body).Decode(&reasons); err != nil {
+		t.Fatalf("Error decoding failure reasons: %v", err)
+	}
+	expected := []FailureReason{
+		{Reason: "Failed", Count: 2, StepExits: []StepExit{{PipelineTask: "unit", Step: "test", ExitCode: 1, Count: 2}}},
+		{Reason: "PipelineRunTimeout", Count: 1, StepExits: []StepExit{}},
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Errorf("Expected failure reasons %+v, actual %+v", expected, reasons)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	restful "github.com/emicklei/go-restful"
+)
+
+// Features returns the optional backend features enabled by the options,
+// keyed by feature name
+func (o Options) Features() map[string]bool {
+	return map[string]bool{
+		"readOnly":        o.ReadOnly,
+		"logStreaming":    o.StreamLogs,
+		"externalLogs":    o.ExternalLogsURL != "",
+		"tenantNamespace": o.TenantNamespace != "",
+		"logout":          o.LogoutURL != "",
+		"openShift":       o.IsOpenShift,
+	}
+}
+
+// GetFeatures returns the optional features enabled on this dashboard so the
+// UI can adapt its behaviour with a single call at startup
+func (r Resource) GetFeatures(request *restful.Request, response *restful.Response) {
+	response.WriteEntity(r.Options.Features())
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+)
+
+// GET features reports the features enabled by the server options
+func TestGETFeatures(t *testing.T) {
+	options := Options{
+		ReadOnly:        true,
+		StreamLogs:      true,
+		ExternalLogsURL: "http://logs.example.com",
+	}
+	server, _, _ := testutils.DummyServerWithOptions(options)
+	defer server.Close()
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/features", server.URL), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting features: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var features map[string]bool
+	if err := json.NewDecoder(response.Body).Decode(&features); err != nil {
+		t.Fatalf("Error decoding features: %v", err)
+	}
+	expected := map[string]bool{
+		"readOnly":        true,
+		"logStreaming":    true,
+		"externalLogs":    true,
+		"tenantNamespace": false,
+		"logout":          false,
+		"openShift":       false,
+	}
+	if !reflect.DeepEqual(features, expected) {
+		t.Errorf("Expected features %v, actual %v", expected, features)
+	}
+}
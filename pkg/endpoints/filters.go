@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// payloadMeta returns the object metadata of a websocket payload, unwrapping
+// deletion tombstones. Payloads without metadata such as extensions are not ok
+func payloadMeta(payload interface{}) (metav1.Object, bool) {
+	if tombstone, ok := payload.(cache.DeletedFinalStateUnknown); ok {
+		payload = tombstone.Obj
+	}
+	if payload == nil {
+		return nil, false
+	}
+	object, err := meta.Accessor(payload)
+	if err != nil {
+		return nil, false
+	}
+	return object, true
+}
+
+// extensionEvent reports whether a message is the event of a service
+// extension, whose payload has no metadata as it is dashboard wide
+func extensionEvent(messageType broadcaster.MessageType) bool {
+	switch messageType {
+	case broadcaster.ServiceExtensionCreated, broadcaster.ServiceExtensionUpdated, broadcaster.ServiceExtensionDeleted:
+		return true
+	}
+	return false
+}
+
+// annotationRequirement is a single term of an annotation selector
+type annotationRequirement struct {
+	key    string
+	value  string
+	equals bool
+	negate bool
+}
+
+func (a annotationRequirement) matches(annotations map[string]string) bool {
+	value, found := annotations[a.key]
+	matched := found && (!a.equals || value == a.value)
+	return matched != a.negate
+}
+
+// parseAnnotationSelector parses a comma separated list of annotation
+// requirements. Each term is one of key, !key, key=value or key!=value
+func parseAnnotationSelector(selector string) ([]annotationRequirement, error) {
+	var requirements []annotationRequirement
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		var requirement annotationRequirement
+		switch {
+		case term == "":
+			return nil, fmt.Errorf("invalid annotation selector '%s': empty requirement", selector)
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			requirement = annotationRequirement{key: parts[0], value: parts[1], equals: true, negate: true}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(strings.Replace(term, "==", "=", 1), "=", 2)
+			requirement = annotationRequirement{key: parts[0], value: parts[1], equals: true}
+		case strings.HasPrefix(term, "!"):
+			requirement = annotationRequirement{key: strings.TrimPrefix(term, "!"), negate: true}
+		default:
+			requirement = annotationRequirement{key: term}
+		}
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+		if requirement.key == "" || strings.ContainsAny(requirement.key, "=!") {
+			return nil, fmt.Errorf("invalid annotation selector '%s': bad key in '%s'", selector, term)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// annotationFilter returns a websocket filter only accepting objects whose
+// annotations match the selector
+func annotationFilter(selector string) (broadcaster.Filter[broadcaster.SocketData], error) {
+	requirements, err := parseAnnotationSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return func(data broadcaster.SocketData) bool {
+		object, ok := payloadMeta(data.Payload)
+		if !ok {
+			return false
+		}
+		annotations := object.GetAnnotations()
+		for _, requirement := range requirements {
+			if !requirement.matches(annotations) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// ageFilter returns a websocket filter only accepting objects created at least
+// minAge and at most maxAge before the event, a zero age is not checked
+func ageFilter(minAge, maxAge time.Duration) broadcaster.Filter[broadcaster.SocketData] {
+	return func(data broadcaster.SocketData) bool {
+		object, ok := payloadMeta(data.Payload)
+		if !ok {
+			return false
+		}
+		age := time.Since(object.GetCreationTimestamp().Time)
+		return (minAge == 0 || age >= minAge) && (maxAge == 0 || age <= maxAge)
+	}
+}
+
+// resourcesMessageTypes are the messages of the resources websocket clients
+// may select with the kinds parameter
+var resourcesMessageTypes = []broadcaster.MessageType{
+	broadcaster.NamespaceCreated, broadcaster.NamespaceUpdated, broadcaster.NamespaceDeleted,
+	broadcaster.PipelineCreated, broadcaster.PipelineUpdated, broadcaster.PipelineDeleted,
+	broadcaster.ClusterTaskCreated, broadcaster.ClusterTaskUpdated, broadcaster.ClusterTaskDeleted,
+	broadcaster.TaskCreated, broadcaster.TaskUpdated, broadcaster.TaskDeleted,
+	broadcaster.PipelineResourceCreated, broadcaster.PipelineResourceUpdated, broadcaster.PipelineResourceDeleted,
+	broadcaster.PipelineRunCreated, broadcaster.PipelineRunUpdated, broadcaster.PipelineRunDeleted,
+	broadcaster.TaskRunCreated, broadcaster.TaskRunUpdated, broadcaster.TaskRunDeleted,
+	broadcaster.CustomRunCreated, broadcaster.CustomRunUpdated, broadcaster.CustomRunDeleted,
+	broadcaster.StepActionCreated, broadcaster.StepActionUpdated, broadcaster.StepActionDeleted,
+	broadcaster.ConditionCreated, broadcaster.ConditionUpdated, broadcaster.ConditionDeleted,
+	broadcaster.ResourceExtensionCreated, broadcaster.ResourceExtensionUpdated, broadcaster.ResourceExtensionDeleted,
+	broadcaster.ServiceExtensionCreated, broadcaster.ServiceExtensionUpdated, broadcaster.ServiceExtensionDeleted,
+	broadcaster.ServiceAccountCreated, broadcaster.ServiceAccountUpdated, broadcaster.ServiceAccountDeleted,
+	broadcaster.TriggerBindingCreated, broadcaster.TriggerBindingUpdated, broadcaster.TriggerBindingDeleted,
+	broadcaster.ClusterTriggerBindingCreated, broadcaster.ClusterTriggerBindingUpdated, broadcaster.ClusterTriggerBindingDeleted,
+	broadcaster.TriggerTemplateCreated, broadcaster.TriggerTemplateUpdated, broadcaster.TriggerTemplateDeleted,
+	broadcaster.EventListenerCreated, broadcaster.EventListenerUpdated, broadcaster.EventListenerDeleted,
+	broadcaster.OwnershipChanged, broadcaster.ResyncRequired,
+}
+
+// knownKind reports whether a kind, ignoring case, is the kind of one of the
+// resourcesMessageTypes, or with events set one of the message types
+func knownKind(kind string, events bool) bool {
+	for _, messageType := range resourcesMessageTypes {
+		if strings.EqualFold(messageKind(messageType), kind) || (events && strings.EqualFold(string(messageType), kind)) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitParameter splits a comma separated parameter, ignoring empty values
+func splitParameter(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// kindsFilter returns a websocket filter only accepting the comma separated
+// message types, e.g. TaskCreated, or kinds, e.g. Task for all of its events.
+// Selecting a kind also accepts its OwnershipChanged and ResyncRequired
+// messages. Unknown values are an error rather than matching nothing, and the
+// filter is nil when no kind is given
+func kindsFilter(kinds string) (broadcaster.Filter[broadcaster.SocketData], error) {
+	selected := map[string]bool{}
+	for _, kind := range splitParameter(kinds) {
+		if !knownKind(kind, true) {
+			return nil, fmt.Errorf("invalid kind '%s', must be a kind such as TaskRun or an event such as TaskRunCreated", kind)
+		}
+		selected[strings.ToLower(kind)] = true
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	return func(data broadcaster.SocketData) bool {
+		if selected[strings.ToLower(string(data.MessageType))] || selected[strings.ToLower(messageKind(data.MessageType))] {
+			return true
+		}
+		switch payload := data.Payload.(type) {
+		case broadcaster.OwnershipChange:
+			return selected[strings.ToLower(payload.Kind)]
+		case broadcaster.ResyncMarker:
+			return selected[strings.ToLower(payload.Kind)]
+		}
+		return false
+	}, nil
+}
+
+// namespacesFilter returns a websocket filter only accepting objects in one of
+// the comma separated namespaces, cluster scoped objects are not accepted.
+// Truncated objects are narrowed by the namespace of their reference. Other
+// payloads without metadata are not accepted, except for extensions and
+// ResyncRequired messages, which only name a kind. The filter is nil when no
+// namespace is given
+func namespacesFilter(namespaces string) broadcaster.Filter[broadcaster.SocketData] {
+	selected := map[string]bool{}
+	for _, namespace := range splitParameter(namespaces) {
+		selected[namespace] = true
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	return inNamespaces(selected)
+}
+
+// inNamespaces returns a websocket filter only accepting objects in the
+// selected namespaces, as for namespacesFilter
+func inNamespaces(selected map[string]bool) broadcaster.Filter[broadcaster.SocketData] {
+	return func(data broadcaster.SocketData) bool {
+		switch payload := data.Payload.(type) {
+		case broadcaster.OwnershipChange:
+			return selected[payload.Namespace]
+		case broadcaster.ObjectReference:
+			return selected[payload.Namespace]
+		case broadcaster.ResyncMarker:
+			return true
+		}
+		if extensionEvent(data.MessageType) {
+			return true
+		}
+		object, ok := payloadMeta(data.Payload)
+		return ok && selected[object.GetNamespace()]
+	}
+}
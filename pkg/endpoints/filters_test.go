@@ -0,0 +1,285 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Only events for objects matching the annotation selector reach the client
+func TestWebsocketAnnotationSelector(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"annotationSelector": {"dashboard.tekton.dev/pin=true"}}.Encode(),
+	}
+	websocketChan := clientWebsocket(websocketURL.String(), 2*time.Second, t)
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected annotation filtered client within pool")
+
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "tasks",
+	}
+	unpinned := testutils.GetObject("v1beta1", "Task", namespace, "unpinned", "1")
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(unpinned, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	pinned := testutils.GetObject("v1beta1", "Task", namespace, "pinned", "1")
+	pinned.SetAnnotations(map[string]string{"dashboard.tekton.dev/pin": "true"})
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pinned, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	var received []string
+	for socketData := range websocketChan {
+		payload, _ := socketData.Payload.(map[string]interface{})
+		metadata, _ := payload["metadata"].(map[string]interface{})
+		received = append(received, fmt.Sprintf("%s %v", socketData.MessageType, metadata["name"]))
+	}
+	expected := fmt.Sprintf("%s pinned", broadcaster.TaskCreated)
+	if len(received) != 1 || received[0] != expected {
+		t.Errorf("Expected only %q, actual %v", expected, received)
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Only events for objects created within maxAge reach the client
+func TestWebsocketMaxAge(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"maxAge": {"1h"}}.Encode(),
+	}
+	websocketChan := clientWebsocket(websocketURL.String(), 2*time.Second, t)
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected age filtered client within pool")
+
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "tasks",
+	}
+	old := testutils.GetObject("v1beta1", "Task", namespace, "old", "1")
+	old.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-2 * time.Hour)))
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(old, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	recent := testutils.GetObject("v1beta1", "Task", namespace, "new", "1")
+	recent.SetCreationTimestamp(metav1.Now())
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(recent, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	var received []string
+	for socketData := range websocketChan {
+		payload, _ := socketData.Payload.(map[string]interface{})
+		metadata, _ := payload["metadata"].(map[string]interface{})
+		received = append(received, fmt.Sprintf("%s %v", socketData.MessageType, metadata["name"]))
+	}
+	expected := fmt.Sprintf("%s new", broadcaster.TaskCreated)
+	if len(received) != 1 || received[0] != expected {
+		t.Errorf("Expected only %q, actual %v", expected, received)
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Malformed annotation selectors fail the upgrade
+func TestWebsocketInvalidAnnotationSelector(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/websockets/resources?annotationSelector=%s", server.URL, url.QueryEscape("pin,=true")), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error connecting to websocket: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
+
+// Clients connected with namespaces and kinds only count the events they
+// subscribed to, clients without them still receive everything
+func TestWebsocketNamespacesAndKinds(t *testing.T) {
+	server, r, installNamespace := testutils.DummyServer()
+	defer server.Close()
+
+	// Expected creates, updates and deletes of each kind for each client
+	type counts [3]int32
+	clients := []struct {
+		query    url.Values
+		expected map[string]counts
+	}{
+		{url.Values{}, map[string]counts{"Task": {2, 2, 2}, "ClusterTask": {1, 1, 1}, "ServiceExtension": {1, 1, 1}, "Namespace": {1, 0, 1}}},
+		{url.Values{"kinds": {"Task"}}, map[string]counts{"Task": {2, 2, 2}}},
+		{url.Values{"kinds": {"TaskCreated,ClusterTaskDeleted"}}, map[string]counts{"Task": {2, 0, 0}, "ClusterTask": {0, 0, 1}}},
+		{url.Values{"namespaces": {installNamespace}}, map[string]counts{"Task": {1, 1, 1}, "ServiceExtension": {1, 1, 1}}},
+		{url.Values{"namespaces": {"other, unused"}, "kinds": {"task"}}, map[string]counts{"Task": {1, 1, 1}}},
+	}
+
+	records := make([]map[string]*informerRecord, len(clients))
+	done := make(chan struct{}, len(clients))
+	for i, client := range clients {
+		records[i] = map[string]*informerRecord{}
+		websocketURL := url.URL{
+			Scheme:   "ws",
+			Host:     strings.TrimPrefix(server.URL, "http://"),
+			Path:     "/v1/websockets/resources",
+			RawQuery: client.query.Encode(),
+		}
+		websocketChan := clientWebsocket(websocketURL.String(), 3*time.Second, t)
+		go func(record map[string]*informerRecord) {
+			defer func() { done <- struct{}{} }()
+			for socketData := range websocketChan {
+				kind := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(string(socketData.MessageType), "Created"), "Updated"), "Deleted")
+				if record[kind] == nil {
+					record[kind] = &informerRecord{CRD: kind}
+				}
+				record[kind].Handle(strings.TrimPrefix(string(socketData.MessageType), kind))
+			}
+		}(records[i])
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == len(clients)
+	}, t, fmt.Sprintf("Expected %d clients within pool", len(clients)))
+
+	CUDTasks(r, t, installNamespace)
+	CUDTasks(r, t, "other")
+	CUDClusterTasks(r, t)
+	CUDExtensions(r, t, installNamespace)
+	CDNamespaces(r, t)
+	for range clients {
+		<-done
+	}
+
+	for i, client := range clients {
+		for kind, record := range records[i] {
+			if _, ok := client.expected[kind]; !ok {
+				t.Errorf("Client with %s expected no %s events, actual creates[%d], updates[%d] and deletes[%d]", client.query.Encode(), kind, record.Create(), record.Update(), record.Delete())
+			}
+		}
+		for kind, expected := range client.expected {
+			record := records[i][kind]
+			if record == nil {
+				record = &informerRecord{CRD: kind}
+			}
+			if actual := (counts{record.Create(), record.Update(), record.Delete()}); actual != expected {
+				t.Errorf("Client with %s expected %s creates, updates and deletes %v, actual %v", client.query.Encode(), kind, expected, actual)
+			}
+		}
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Objects too large to send are narrowed by the namespace of their reference
+func TestWebsocketNamespacesTruncated(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	ResourcesBroadcaster.SetMaxObjectSize(1)
+	defer ResourcesBroadcaster.SetMaxObjectSize(0)
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"namespaces": {namespace}, "kinds": {"TaskCreated"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	// The Task of the other namespace is created first so its event would
+	// arrive before the selected one if it were delivered
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	for _, task := range []*unstructured.Unstructured{
+		testutils.GetObject("v1beta1", "Task", "other", "truncated-other", "1"),
+		testutils.GetObject("v1beta1", "Task", namespace, "truncated-selected", "1"),
+	} {
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(task.GetNamespace()).Create(task, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, message, err := connection.ReadMessage()
+	if err != nil {
+		t.Fatalf("Error reading events: %s", err)
+	}
+	var socketData broadcaster.SocketData
+	if err := json.Unmarshal(message, &socketData); err != nil {
+		t.Fatalf("Error decoding message: %s", err)
+	}
+	payload, _ := socketData.Payload.(map[string]interface{})
+	if name, _ := payload["name"].(string); !socketData.Truncated || name != "truncated-selected" {
+		t.Errorf("Expected truncated reference to truncated-selected, actual %s", message)
+	}
+}
+
+// Unknown kinds fail the upgrade instead of filtering out every event
+func TestWebsocketInvalidKinds(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"kinds": {"TaskRun,TaskRunStarted"}}.Encode(),
+	}
+	status, upgradeError := dialUpgradeError(websocketURL.String(), nil, t)
+	if status != http.StatusBadRequest || upgradeError.Code != websocket.CodeBadRequest {
+		t.Fatalf("Expected status %d and code %s, actual %d and %s", http.StatusBadRequest, websocket.CodeBadRequest, status, upgradeError.Code)
+	}
+	if !strings.Contains(upgradeError.Message, "TaskRunStarted") {
+		t.Errorf("Expected message naming the invalid kind, actual %s", upgradeError.Message)
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// maxContentHashes bounds the number of objects whose hash is cached
+const maxContentHashes = 10000
+
+// ContentHash is a hash of the spec of an object, unchanged when only its
+// metadata or status change
+type ContentHash struct {
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+	Hash            string `json:"hash"`
+}
+
+// cachedHash is the hash of an object at a resourceVersion
+type cachedHash struct {
+	resourceVersion string
+	hash            string
+}
+
+// contentHashes caches the hash of the latest resourceVersion seen of each
+// object, it is cleared when full
+var contentHashes = struct {
+	sync.Mutex
+	hashes map[string]cachedHash
+}{hashes: map[string]cachedHash{}}
+
+// GetContentHash returns a hash of the spec of an object so integrations can
+// tell whether it changed since last seen, regardless of updates to its
+// metadata or status. Hashes are cached per resourceVersion
+func (r Resource) GetContentHash(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	name := request.PathParameter("name")
+	kind, ok := lookupKind(r.Options, request.PathParameter("kind"))
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("unknown kind '%s'", request.PathParameter("kind")), http.StatusNotFound)
+		return
+	}
+	allowed, err := r.authorized(request, "get", kind.Kind, namespace, name)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		utils.RespondErrorMessage(response, fmt.Sprintf("not allowed to get %s %s", kind.Kind, name), http.StatusForbidden)
+		return
+	}
+
+	object, err := r.DynamicClient.Resource(kind.GVR).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	hash, err := objectContentHash(kind.Kind, object)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.AddHeader("ETag", `"`+hash+`"`)
+	response.WriteEntity(ContentHash{
+		Kind:            kind.Kind,
+		Namespace:       namespace,
+		Name:            name,
+		ResourceVersion: object.GetResourceVersion(),
+		Hash:            hash,
+	})
+}
+
+// objectContentHash returns the cached hash of an object at its
+// resourceVersion, or computes it
+func objectContentHash(kind string, object *unstructured.Unstructured) (string, error) {
+	key := kind + "/" + object.GetNamespace() + "/" + object.GetName()
+	contentHashes.Lock()
+	cached, found := contentHashes.hashes[key]
+	contentHashes.Unlock()
+	if found && cached.resourceVersion == object.GetResourceVersion() {
+		return cached.hash, nil
+	}
+
+	hash, err := specHash(object)
+	if err != nil {
+		return "", err
+	}
+	contentHashes.Lock()
+	defer contentHashes.Unlock()
+	if len(contentHashes.hashes) >= maxContentHashes {
+		contentHashes.hashes = map[string]cachedHash{}
+	}
+	contentHashes.hashes[key] = cachedHash{resourceVersion: object.GetResourceVersion(), hash: hash}
+	return hash, nil
+}
+
+// specHash is the SHA-256 of the JSON encoding of an object's spec, or of the
+// object without its metadata and status if it has no spec. Maps are encoded
+// with sorted keys so equal specs have the same hash
+func specHash(object *unstructured.Unstructured) (string, error) {
+	content, found := object.Object["spec"]
+	if !found {
+		rest := map[string]interface{}{}
+		for field, value := range object.Object {
+			if field != "metadata" && field != "status" {
+				rest[field] = value
+			}
+		}
+		content = rest
+	}
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
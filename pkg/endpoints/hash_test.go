@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET content hash only changes when the spec of the object changes
+func TestGETContentHash(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	tasks := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}).Namespace(namespace)
+	taskWithImage := func(resourceVersion, image string) *unstructured.Unstructured {
+		task := testutils.GetObject("v1beta1", "Task", namespace, "hashed", resourceVersion)
+		task.Object["spec"] = map[string]interface{}{
+			"steps": []interface{}{map[string]interface{}{"name": "build", "image": image}},
+		}
+		return task
+	}
+	getHash := func() ContentHash {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/tasks/hashed/hash", server.URL, namespace), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting hash: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var hash ContentHash
+		if err := json.NewDecoder(response.Body).Decode(&hash); err != nil {
+			t.Fatalf("Error decoding hash: %v", err)
+		}
+		return hash
+	}
+
+	if _, err := tasks.Create(taskWithImage("1", "golang:1.15"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	original := getHash()
+
+	// A no-op update only bumps the resourceVersion and labels
+	unchanged := taskWithImage("2", "golang:1.15")
+	unchanged.SetLabels(map[string]string{"app": "build"})
+	if _, err := tasks.Update(unchanged, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating task: %v", err)
+	}
+	updated := getHash()
+	if updated.ResourceVersion != "2" || updated.Hash != original.Hash {
+		t.Errorf("Expected hash %s at resourceVersion 2, actual %+v", original.Hash, updated)
+	}
+
+	if _, err := tasks.Update(taskWithImage("3", "golang:1.16"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating task: %v", err)
+	}
+	if changed := getHash(); changed.Hash == original.Hash {
+		t.Errorf("Expected the hash to change with the spec, actual %+v", changed)
+	}
+}
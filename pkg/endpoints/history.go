@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+// ObjectHistory is the sequence of events broadcast for an object that are
+// still kept by the event cache, Evicted is set when older ones were dropped
+type ObjectHistory struct {
+	Kind      string         `json:"kind"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	Evicted   bool           `json:"evicted"`
+	Events    []HistoryEvent `json:"events"`
+}
+
+// HistoryEvent is one Created, Updated or Deleted event of an object, with
+// the object as it was broadcast
+type HistoryEvent struct {
+	Type            string      `json:"type"`
+	ResourceVersion string      `json:"resourceVersion,omitempty"`
+	Time            time.Time   `json:"time"`
+	Object          interface{} `json:"object"`
+}
+
+// GetObjectHistory returns the recent events of an object kept in memory by
+// the resources broadcaster, oldest first, as a lightweight change log
+func (r Resource) GetObjectHistory(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	name := request.PathParameter("name")
+	kind, ok := lookupKind(r.Options, request.PathParameter("kind"))
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("unknown kind '%s'", request.PathParameter("kind")), http.StatusNotFound)
+		return
+	}
+	allowed, err := r.authorized(request, "get", kind.Kind, namespace, name)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		utils.RespondErrorMessage(response, fmt.Sprintf("not allowed to get %s %s", kind.Kind, name), http.StatusForbidden)
+		return
+	}
+
+	events, evicted := ResourcesEvents.History(kind.Kind, namespace, name)
+	history := ObjectHistory{Kind: kind.Kind, Namespace: namespace, Name: name, Evicted: evicted, Events: []HistoryEvent{}}
+	for _, event := range events {
+		history.Events = append(history.Events, HistoryEvent{
+			Type:            strings.TrimPrefix(string(event.MessageType), kind.Kind),
+			ResourceVersion: event.ResourceVersion,
+			Time:            event.Time,
+			Object:          event.Payload,
+		})
+	}
+	response.WriteEntity(history)
+}
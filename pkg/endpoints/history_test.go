@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET object history returns the events broadcast for the object in order
+func TestGETObjectHistory(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	tasks := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "history-task", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	for _, resourceVersion := range []string{"2", "3"} {
+		// Give the informer time to see each change
+		time.Sleep(100 * time.Millisecond)
+		if _, err := tasks.Update(testutils.GetObject("v1beta1", "Task", namespace, "history-task", resourceVersion), metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Error updating task: %v", err)
+		}
+	}
+
+	getHistory := func() ObjectHistory {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/tasks/history-task/history", server.URL, namespace), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting history: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var history ObjectHistory
+		if err := json.NewDecoder(response.Body).Decode(&history); err != nil {
+			t.Fatalf("Error decoding history: %v", err)
+		}
+		return history
+	}
+	var history ObjectHistory
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if history = getHistory(); len(history.Events) == 3 {
+			break
+		}
+	}
+
+	var described []string
+	for _, event := range history.Events {
+		described = append(described, fmt.Sprintf("%s %s", event.Type, event.ResourceVersion))
+	}
+	expected := "[Created 1 Updated 2 Updated 3]"
+	if actual := fmt.Sprint(described); actual != expected {
+		t.Errorf("Expected history %s, actual %s", expected, actual)
+	}
+	if history.Kind != "Task" || history.Evicted {
+		t.Errorf("Expected Task history without eviction, actual %+v", history)
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultServiceAccountName is the service account Tekton runs with when none
+// is set, unless changed in its config-defaults
+const defaultServiceAccountName = "default"
+
+// RunIdentity is the service accounts a PipelineRun's tasks run as, and the
+// secrets linked to those service accounts
+type RunIdentity struct {
+	ServiceAccountName string                  `json:"serviceAccountName"`
+	Tasks              []TaskIdentity          `json:"tasks"`
+	ServiceAccounts    []ServiceAccountSecrets `json:"serviceAccounts"`
+}
+
+// TaskIdentity is the service account of a pipeline task, Override is set
+// when the PipelineRun sets one for that task. TaskRun is empty until the
+// task has started
+type TaskIdentity struct {
+	PipelineTask       string `json:"pipelineTask"`
+	TaskRun            string `json:"taskRun,omitempty"`
+	ServiceAccountName string `json:"serviceAccountName"`
+	Override           bool   `json:"override"`
+}
+
+// ServiceAccountSecrets names the secrets linked to a service account, Found
+// is false if the service account does not exist
+type ServiceAccountSecrets struct {
+	Name             string   `json:"name"`
+	Found            bool     `json:"found"`
+	Secrets          []string `json:"secrets"`
+	ImagePullSecrets []string `json:"imagePullSecrets"`
+}
+
+// GetPipelineRunIdentity reports the service account of the PipelineRun and
+// of each of its tasks, as set on the TaskRuns created or as overridden by
+// the PipelineRun for tasks not started yet, with the names of the secrets
+// and image pull secrets linked to each service account
+func (r Resource) GetPipelineRunIdentity(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	identity := RunIdentity{
+		ServiceAccountName: pipelineRunServiceAccount(pipelineRun),
+		Tasks:              []TaskIdentity{},
+		ServiceAccounts:    []ServiceAccountSecrets{},
+	}
+	overrides := taskServiceAccountOverrides(pipelineRun)
+	started := map[string]bool{}
+	for _, taskRun := range taskRuns.Items {
+		pipelineTask := taskRun.GetLabels()[PipelineTaskLabel]
+		serviceAccountName, _, _ := unstructured.NestedString(taskRun.Object, "spec", "serviceAccountName")
+		if serviceAccountName == "" {
+			serviceAccountName = defaultServiceAccountName
+		}
+		_, override := overrides[pipelineTask]
+		identity.Tasks = append(identity.Tasks, TaskIdentity{
+			PipelineTask:       pipelineTask,
+			TaskRun:            taskRun.GetName(),
+			ServiceAccountName: serviceAccountName,
+			Override:           override,
+		})
+		started[pipelineTask] = true
+	}
+	for pipelineTask, serviceAccountName := range overrides {
+		if !started[pipelineTask] {
+			identity.Tasks = append(identity.Tasks, TaskIdentity{PipelineTask: pipelineTask, ServiceAccountName: serviceAccountName, Override: true})
+		}
+	}
+	sort.Slice(identity.Tasks, func(i, j int) bool {
+		if identity.Tasks[i].PipelineTask != identity.Tasks[j].PipelineTask {
+			return identity.Tasks[i].PipelineTask < identity.Tasks[j].PipelineTask
+		}
+		return identity.Tasks[i].TaskRun < identity.Tasks[j].TaskRun
+	})
+
+	names := map[string]bool{identity.ServiceAccountName: true}
+	for _, task := range identity.Tasks {
+		names[task.ServiceAccountName] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for serviceAccountName := range names {
+		sorted = append(sorted, serviceAccountName)
+	}
+	sort.Strings(sorted)
+	for _, serviceAccountName := range sorted {
+		secrets := ServiceAccountSecrets{Name: serviceAccountName, Secrets: []string{}, ImagePullSecrets: []string{}}
+		serviceAccount, err := r.K8sClient.CoreV1().ServiceAccounts(namespace).Get(serviceAccountName, metav1.GetOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			secrets.Found = true
+			for _, secret := range serviceAccount.Secrets {
+				secrets.Secrets = append(secrets.Secrets, secret.Name)
+			}
+			for _, secret := range serviceAccount.ImagePullSecrets {
+				secrets.ImagePullSecrets = append(secrets.ImagePullSecrets, secret.Name)
+			}
+		}
+		identity.ServiceAccounts = append(identity.ServiceAccounts, secrets)
+	}
+
+	response.WriteEntity(identity)
+}
+
+// pipelineRunServiceAccount returns the service account a PipelineRun's tasks
+// run as by default, set in spec in v1beta1 and in spec.taskRunTemplate in v1
+func pipelineRunServiceAccount(pipelineRun *unstructured.Unstructured) string {
+	if name, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "serviceAccountName"); name != "" {
+		return name
+	}
+	if name, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "taskRunTemplate", "serviceAccountName"); name != "" {
+		return name
+	}
+	return defaultServiceAccountName
+}
+
+// taskServiceAccountOverrides returns the service accounts a PipelineRun sets
+// for some of its tasks, by pipeline task. They are set in
+// spec.serviceAccountNames or spec.taskRunSpecs in v1beta1, and in
+// spec.taskRunSpecs in v1
+func taskServiceAccountOverrides(pipelineRun *unstructured.Unstructured) map[string]string {
+	overrides := map[string]string{}
+	serviceAccountNames, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "serviceAccountNames")
+	for _, s := range serviceAccountNames {
+		entry, _ := s.(map[string]interface{})
+		taskName, _ := entry["taskName"].(string)
+		serviceAccountName, _ := entry["serviceAccountName"].(string)
+		if taskName != "" && serviceAccountName != "" {
+			overrides[taskName] = serviceAccountName
+		}
+	}
+	taskRunSpecs, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "taskRunSpecs")
+	for _, s := range taskRunSpecs {
+		entry, _ := s.(map[string]interface{})
+		taskName, _ := entry["pipelineTaskName"].(string)
+		serviceAccountName, _ := entry["serviceAccountName"].(string)
+		if serviceAccountName == "" {
+			serviceAccountName, _ = entry["taskServiceAccountName"].(string)
+		}
+		if taskName != "" && serviceAccountName != "" {
+			overrides[taskName] = serviceAccountName
+		}
+	}
+	return overrides
+}
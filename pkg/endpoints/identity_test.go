@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun identity reports the service account of each task, with
+// the overrides set by the PipelineRun, and the secrets linked to them
+func TestGETPipelineRunIdentity(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "ci", "1")
+	pipelineRun.Object["spec"] = map[string]interface{}{
+		"serviceAccountName": "builder",
+		"serviceAccountNames": []interface{}{
+			map[string]interface{}{"taskName": "deploy", "serviceAccountName": "deployer"},
+		},
+		"taskRunSpecs": []interface{}{
+			map[string]interface{}{"pipelineTaskName": "publish", "taskServiceAccountName": "publisher"},
+		},
+	}
+	if _, err := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	// The publish task has not started yet
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for pipelineTask, serviceAccountName := range map[string]string{"build": "builder", "deploy": "deployer"} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "ci-"+pipelineTask, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "ci", PipelineTaskLabel: pipelineTask})
+		unstructured.SetNestedField(taskRun.Object, serviceAccountName, "spec", "serviceAccountName")
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+	serviceAccounts := []*corev1.ServiceAccount{
+		{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: namespace},
+			Secrets:          []corev1.ObjectReference{{Name: "git-credentials"}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: namespace},
+			Secrets:    []corev1.ObjectReference{{Name: "kubeconfig"}},
+		},
+	}
+	for _, serviceAccount := range serviceAccounts {
+		if _, err := r.K8sClient.CoreV1().ServiceAccounts(namespace).Create(serviceAccount); err != nil {
+			t.Fatalf("Error creating serviceAccount: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/ci/identity", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting identity: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var identity RunIdentity
+	if err := json.NewDecoder(response.Body).Decode(&identity); err != nil {
+		t.Fatalf("Error decoding identity: %v", err)
+	}
+
+	expected := RunIdentity{
+		ServiceAccountName: "builder",
+		Tasks: []TaskIdentity{
+			{PipelineTask: "build", TaskRun: "ci-build", ServiceAccountName: "builder"},
+			{PipelineTask: "deploy", TaskRun: "ci-deploy", ServiceAccountName: "deployer", Override: true},
+			{PipelineTask: "publish", ServiceAccountName: "publisher", Override: true},
+		},
+		ServiceAccounts: []ServiceAccountSecrets{
+			{Name: "builder", Found: true, Secrets: []string{"git-credentials"}, ImagePullSecrets: []string{"registry"}},
+			{Name: "deployer", Found: true, Secrets: []string{"kubeconfig"}, ImagePullSecrets: []string{}},
+			{Name: "publisher", Secrets: []string{}, ImagePullSecrets: []string{}},
+		},
+	}
+	if !reflect.DeepEqual(identity, expected) {
+		t.Errorf("Expected identity %+v, actual %+v", expected, identity)
+	}
+}
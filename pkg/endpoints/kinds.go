@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceKind associates a Kind with the GroupVersionResource used to access
+// it through the dynamic client
+type resourceKind struct {
+	Kind string
+	GVR  schema.GroupVersionResource
+}
+
+// namespacedKinds returns the namespaced Tekton kinds served by the dashboard
+// API, with Tekton Pipelines kinds in the API version given by the options
+func namespacedKinds(options Options) []resourceKind {
+	tektonGVR := func(resource string) schema.GroupVersionResource {
+		return schema.GroupVersionResource{Group: tektonGroup, Version: options.GetTektonVersion(), Resource: resource}
+	}
+	return []resourceKind{
+		{Kind: "Pipeline", GVR: tektonGVR("pipelines")},
+		{Kind: "PipelineRun", GVR: tektonGVR("pipelineruns")},
+		{Kind: "Task", GVR: tektonGVR("tasks")},
+		{Kind: "TaskRun", GVR: tektonGVR("taskruns")},
+		{Kind: "CustomRun", GVR: options.GetCustomRunGVR()},
+		{Kind: "StepAction", GVR: options.GetStepActionGVR()},
+		{Kind: "ResolutionRequest", GVR: resolutionRequestGVR},
+		{Kind: "Condition", GVR: schema.GroupVersionResource{Group: tektonGroup, Version: "v1alpha1", Resource: "conditions"}},
+		{Kind: "PipelineResource", GVR: schema.GroupVersionResource{Group: tektonGroup, Version: "v1alpha1", Resource: "pipelineresources"}},
+		{Kind: "TriggerBinding", GVR: schema.GroupVersionResource{Group: "triggers.tekton.dev", Version: "v1alpha1", Resource: "triggerbindings"}},
+		{Kind: "TriggerTemplate", GVR: schema.GroupVersionResource{Group: "triggers.tekton.dev", Version: "v1alpha1", Resource: "triggertemplates"}},
+		{Kind: "EventListener", GVR: schema.GroupVersionResource{Group: "triggers.tekton.dev", Version: "v1alpha1", Resource: "eventlisteners"}},
+	}
+}
+
+// lookupKind finds a namespaced kind by either its Kind or its plural resource
+// name, ignoring case
+func lookupKind(options Options, kind string) (resourceKind, bool) {
+	for _, k := range namespacedKinds(options) {
+		if strings.EqualFold(k.Kind, kind) || strings.EqualFold(k.GVR.Resource, kind) {
+			return k, true
+		}
+	}
+	return resourceKind{}, false
+}
+
+// clusterKinds returns the cluster scoped kinds whose events are broadcast.
+// ClusterTasks are not served by v1 so are always in v1beta1
+func clusterKinds() []resourceKind {
+	return []resourceKind{
+		{Kind: "ClusterTask", GVR: schema.GroupVersionResource{Group: tektonGroup, Version: "v1beta1", Resource: "clustertasks"}},
+		{Kind: "ClusterTriggerBinding", GVR: schema.GroupVersionResource{Group: "triggers.tekton.dev", Version: "v1alpha1", Resource: "clustertriggerbindings"}},
+		{Kind: "Namespace", GVR: schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}},
+	}
+}
+
+// lookupAnyKind finds a namespaced or cluster scoped kind, for checking access
+// to objects of either scope
+func lookupAnyKind(options Options, kind string) (resourceKind, bool) {
+	if k, ok := lookupKind(options, kind); ok {
+		return k, true
+	}
+	for _, k := range clusterKinds() {
+		if strings.EqualFold(k.Kind, kind) || strings.EqualFold(k.GVR.Resource, kind) {
+			return k, true
+		}
+	}
+	return resourceKind{}, false
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxLabelSuggestions caps the number of distinct keys or values returned
+const maxLabelSuggestions = 100
+
+// LabelSuggestions are the distinct label keys in use on a kind, or the
+// distinct values of Key when one was requested, sorted
+type LabelSuggestions struct {
+	Key    string   `json:"key,omitempty"`
+	Keys   []string `json:"keys,omitempty"`
+	Values []string `json:"values,omitempty"`
+	// Truncated is set when there were more than maxLabelSuggestions
+	Truncated bool `json:"truncated"`
+}
+
+// GetLabelKeys returns the label keys used on objects of the kind given by
+// the kind query parameter, or the values used for the key query parameter,
+// to power autocompletion of label filters. Annotations are scanned instead
+// when annotations is true
+func (r Resource) GetLabelKeys(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	kind, ok := lookupKind(r.Options, request.QueryParameter("kind"))
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("unknown kind '%s'", request.QueryParameter("kind")), http.StatusBadRequest)
+		return
+	}
+	if allowed, err := r.authorized(request, "list", kind.Kind, namespace, ""); !allowed {
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		utils.RespondErrorMessage(response, fmt.Sprintf("user '%s' cannot list %s in namespace %s", request.HeaderParameter(UserHeader), kind.Kind, namespace), http.StatusForbidden)
+		return
+	}
+
+	objects, err := r.listKind(kind, namespace)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	metadata := (*unstructured.Unstructured).GetLabels
+	if request.QueryParameter("annotations") == "true" {
+		metadata = (*unstructured.Unstructured).GetAnnotations
+	}
+	key := request.QueryParameter("key")
+	distinct := map[string]bool{}
+	for _, object := range objects {
+		for k, v := range metadata(object) {
+			if key == "" {
+				distinct[k] = true
+			} else if k == key {
+				distinct[v] = true
+			}
+		}
+	}
+
+	suggestions := LabelSuggestions{Key: key}
+	found := make([]string, 0, len(distinct))
+	for s := range distinct {
+		found = append(found, s)
+	}
+	sort.Strings(found)
+	if len(found) > maxLabelSuggestions {
+		found = found[:maxLabelSuggestions]
+		suggestions.Truncated = true
+	}
+	if key == "" {
+		suggestions.Keys = found
+	} else {
+		suggestions.Values = found
+	}
+	response.WriteEntity(suggestions)
+}
+
+// listKind returns the objects of a kind in a namespace, read from the
+// informer cache of the kind when available. Cached objects are shared with
+// the informer and must not be modified. Listing across namespaces leaves out
+// the objects of denied namespaces
+func (r Resource) listKind(kind resourceKind, namespace string) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	lister := r.Listers[kind.Kind]
+	if lister == nil && kind.Kind == "PipelineRun" {
+		lister = r.PipelineRunLister
+	}
+	if lister != nil {
+		cached, err := lister.ByNamespace(namespace).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range cached {
+			if o, ok := object.(*unstructured.Unstructured); ok && !r.Options.hides(o) {
+				objects = append(objects, o)
+			}
+		}
+		return objects, nil
+	}
+	list, err := r.DynamicClient.Resource(kind.GVR).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if !r.Options.hides(&list.Items[i]) {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func getLabelSuggestions(t *testing.T, url string) LabelSuggestions {
+	httpReq := testutils.DummyHTTPRequest("GET", url, nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting label keys: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var suggestions LabelSuggestions
+	if err := json.NewDecoder(response.Body).Decode(&suggestions); err != nil {
+		t.Fatalf("Error decoding label keys: %v", err)
+	}
+	return suggestions
+}
+
+// GET label keys returns the distinct keys, or values of a key, in use on a kind
+func TestGETLabelKeys(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRuns := []map[string]string{
+		{"app": "frontend", "team": "web"},
+		{"app": "backend", "env": "prod"},
+		{"app": "frontend"},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for i, runLabels := range pipelineRuns {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "run-"+strconv.Itoa(i), "1")
+		pipelineRun.SetLabels(runLabels)
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+	// Runs of other namespaces are not suggested
+	other := testutils.GetObject("v1beta1", "PipelineRun", "other", "run", "1")
+	other.SetLabels(map[string]string{"secret": "true"})
+	if _, err := r.DynamicClient.Resource(gvr).Namespace("other").Create(other, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	awaitFatal(func() bool {
+		cached, _ := r.PipelineRunLister.List(labels.Everything())
+		return len(cached) == len(pipelineRuns)+1
+	}, t, "PipelineRuns should be cached")
+
+	keys := getLabelSuggestions(t, fmt.Sprintf("%s/v1/namespaces/%s/label-keys?kind=PipelineRun", server.URL, namespace))
+	if expected := (LabelSuggestions{Keys: []string{"app", "env", "team"}}); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %+v, actual %+v", expected, keys)
+	}
+	values := getLabelSuggestions(t, fmt.Sprintf("%s/v1/namespaces/%s/label-keys?kind=pipelineruns&key=app", server.URL, namespace))
+	if expected := (LabelSuggestions{Key: "app", Values: []string{"backend", "frontend"}}); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected values %+v, actual %+v", expected, values)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/label-keys?kind=Unknown", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting label keys: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected statusCode %d for an unknown kind, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
+
+// GET label keys stops at a hundred distinct keys
+func TestGETLabelKeysTruncated(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	taskRunLabels := map[string]string{}
+	for i := 0; i < 120; i++ {
+		taskRunLabels[fmt.Sprintf("key-%03d", i)] = "true"
+	}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "run", "1")
+	taskRun.SetLabels(taskRunLabels)
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	awaitFatal(func() bool {
+		cached, _ := r.Listers["TaskRun"].List(labels.Everything())
+		return len(cached) == 1
+	}, t, "TaskRuns should be cached")
+
+	keys := getLabelSuggestions(t, fmt.Sprintf("%s/v1/namespaces/%s/label-keys?kind=TaskRun", server.URL, namespace))
+	if len(keys.Keys) != 100 || !keys.Truncated || keys.Keys[0] != "key-000" {
+		t.Errorf("Expected the first 100 keys truncated, actual %d keys truncated %t", len(keys.Keys), keys.Truncated)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// LabelRequest lists the runs to label and the labels to merge into each.
+// A null label value removes the label
+type LabelRequest struct {
+	Names  []string           `json:"names"`
+	Labels map[string]*string `json:"labels"`
+}
+
+// ItemResult is the outcome of a bulk operation on one named object
+type ItemResult struct {
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// LabelPipelineRuns merges the requested labels into each named PipelineRun,
+// reporting the outcome per run
+func (r Resource) LabelPipelineRuns(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	var labelRequest LabelRequest
+	if err := request.ReadEntity(&labelRequest); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if len(labelRequest.Names) == 0 || len(labelRequest.Labels) == 0 {
+		utils.RespondErrorMessage(response, "names and labels must not be empty", http.StatusBadRequest)
+		return
+	}
+	if len(labelRequest.Names) > MaxBatchSize {
+		utils.RespondErrorMessage(response, fmt.Sprintf("%d names exceeds the maximum of %d", len(labelRequest.Names), MaxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labelRequest.Labels,
+		},
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	pipelineRuns := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace)
+	results := make([]ItemResult, len(labelRequest.Names))
+	for i, name := range labelRequest.Names {
+		results[i] = ItemResult{Name: name, Status: http.StatusOK}
+		if _, err := pipelineRuns.Patch(name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			results[i].Status = errorStatus(err)
+			results[i].Error = err.Error()
+		}
+	}
+
+	response.WriteEntity(results)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PATCH PipelineRun labels merges the labels into each run that exists
+func TestPATCHPipelineRunLabels(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "pipelineruns",
+	}
+	for _, name := range []string{"first", "second"} {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, name, "1")
+		pipelineRun.SetLabels(map[string]string{"stale": "true", "app": "demo"})
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	body := []byte(`{"names": ["first", "missing", "second"], "labels": {"release": "v1", "stale": null}}`)
+	httpReq := testutils.DummyHTTPRequest("PATCH", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/labels", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error labelling pipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var results []ItemResult
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		t.Fatalf("Error decoding results: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, actual %d", len(results))
+	}
+	if results[0].Status != http.StatusOK || results[2].Status != http.StatusOK {
+		t.Errorf("Expected existing runs to be labelled, actual %+v", results)
+	}
+	if results[1].Name != "missing" || results[1].Status != http.StatusNotFound || results[1].Error == "" {
+		t.Errorf("Expected missing run to be flagged, actual %+v", results[1])
+	}
+
+	expected := map[string]string{"app": "demo", "release": "v1"}
+	for _, name := range []string{"first", "second"} {
+		pipelineRun, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Error getting pipelineRun: %v", err)
+		}
+		if labels := pipelineRun.GetLabels(); !reflect.DeepEqual(labels, expected) {
+			t.Errorf("Expected %s labels %v, actual %v", name, expected, labels)
+		}
+	}
+}
+
+// PATCH PipelineRun labels is rejected in read-only mode
+func TestPATCHPipelineRunLabelsReadOnly(t *testing.T) {
+	server, _, namespace := testutils.DummyServerWithOptions(Options{ReadOnly: true})
+	defer server.Close()
+
+	body := []byte(`{"names": ["first"], "labels": {"release": "v1"}}`)
+	httpReq := testutils.DummyHTTPRequest("PATCH", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/labels", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error labelling pipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusForbidden, response.StatusCode)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// LintResult is the outcome of a dry-run create, Warnings are the warnings
+// returned by the API server, such as uses of deprecated fields, and Errors
+// the validation errors if it was rejected
+type LintResult struct {
+	Valid    bool     `json:"valid"`
+	Warnings []string `json:"warnings"`
+	Errors   []string `json:"errors"`
+}
+
+// LintResource dry-run creates the object in the request body and returns
+// the warnings and validation errors of the API server and its admission
+// webhooks. Nothing is persisted
+func (r Resource) LintResource(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	kind, ok := lookupKind(r.Options, request.PathParameter("kind"))
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("unknown kind '%s'", request.PathParameter("kind")), http.StatusNotFound)
+		return
+	}
+	allowed, err := r.authorized(request, "create", kind.Kind, namespace, "")
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		utils.RespondErrorMessage(response, fmt.Sprintf("not allowed to create %s", kind.Kind), http.StatusForbidden)
+		return
+	}
+
+	object := &unstructured.Unstructured{}
+	if err := request.ReadEntity(&object.Object); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if object.GetKind() != "" && object.GetKind() != kind.Kind {
+		utils.RespondErrorMessage(response, fmt.Sprintf("expected a %s, got a %s", kind.Kind, object.GetKind()), http.StatusBadRequest)
+		return
+	}
+	// Warnings depend on the version the object is written in
+	gvr := kind.GVR
+	if gv, err := schema.ParseGroupVersion(object.GetAPIVersion()); err == nil && gv.Group == gvr.Group && gv.Version != "" {
+		gvr.Version = gv.Version
+	}
+	object.SetAPIVersion(gvr.GroupVersion().String())
+	object.SetKind(kind.Kind)
+	object.SetNamespace(namespace)
+
+	result, statusCode, err := r.dryRunCreate(gvr, namespace, object)
+	if err != nil {
+		utils.RespondError(response, err, statusCode)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// dryRunCreate sends a dry-run create to the API server directly, the dynamic
+// client does not expose the Warning headers of responses. Rejections for an
+// invalid object are returned as Errors, other failures as an error with the
+// status code to respond with
+func (r Resource) dryRunCreate(gvr schema.GroupVersionResource, namespace string, object *unstructured.Unstructured) (LintResult, int, error) {
+	body, err := json.Marshal(object.Object)
+	if err != nil {
+		return LintResult{}, http.StatusBadRequest, err
+	}
+	uri := fmt.Sprintf("%s/apis/%s/%s/namespaces/%s/%s?dryRun=All", r.Config.Host, gvr.Group, gvr.Version, namespace, gvr.Resource)
+	apiRequest, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return LintResult{}, http.StatusInternalServerError, err
+	}
+	apiRequest.Header.Set("Content-Type", restful.MIME_JSON)
+	apiRequest.Header.Set("Accept", restful.MIME_JSON)
+	apiResponse, err := r.HttpClient.Do(apiRequest)
+	if err != nil {
+		return LintResult{}, http.StatusBadGateway, err
+	}
+	defer apiResponse.Body.Close()
+
+	result := LintResult{Valid: true, Warnings: []string{}, Errors: []string{}}
+	for _, header := range apiResponse.Header.Values("Warning") {
+		result.Warnings = append(result.Warnings, warningText(header))
+	}
+	if apiResponse.StatusCode < 300 {
+		return result, http.StatusOK, nil
+	}
+
+	content, err := ioutil.ReadAll(apiResponse.Body)
+	if err != nil {
+		return LintResult{}, http.StatusBadGateway, err
+	}
+	var status metav1.Status
+	if err := json.Unmarshal(content, &status); err != nil || status.Message == "" {
+		status.Message = strings.TrimSpace(string(content))
+	}
+	if apiResponse.StatusCode != http.StatusBadRequest && apiResponse.StatusCode != http.StatusUnprocessableEntity {
+		return LintResult{}, apiResponse.StatusCode, fmt.Errorf("dry-run create failed: %s", status.Message)
+	}
+	result.Valid = false
+	if status.Details != nil && len(status.Details.Causes) > 0 {
+		for _, cause := range status.Details.Causes {
+			if cause.Field != "" {
+				result.Errors = append(result.Errors, cause.Field+": "+cause.Message)
+			} else {
+				result.Errors = append(result.Errors, cause.Message)
+			}
+		}
+	} else {
+		result.Errors = append(result.Errors, status.Message)
+	}
+	return result, http.StatusOK, nil
+}
+
+// warningText returns the text of a Warning header, formatted as
+// `299 - "text"` by the API server, or the whole header if it is not
+func warningText(header string) string {
+	fields := strings.SplitN(header, " ", 3)
+	if len(fields) != 3 {
+		return header
+	}
+	quoted := fields[2]
+	// A date may follow the quoted text
+	if end := strings.LastIndex(quoted, `" "`); end != -1 {
+		quoted = quoted[:end+1]
+	}
+	text, err := strconv.Unquote(quoted)
+	if err != nil {
+		return header
+	}
+	return text
+}
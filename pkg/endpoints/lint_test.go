@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Linting returns the warnings of a dry-run create and its validation errors
+func TestLintResource(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	// The API server warns of the deprecated resources field and rejects
+	// Pipelines without tasks
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("dryRun") != "All" {
+			t.Errorf("Expected a dry-run request, actual %s", req.URL)
+		}
+		if expected := fmt.Sprintf("/apis/tekton.dev/v1beta1/namespaces/%s/pipelines", namespace); req.URL.Path != expected {
+			t.Errorf("Expected request to %s, actual %s", expected, req.URL.Path)
+		}
+		var pipeline struct {
+			Spec map[string]interface{} `json:"spec"`
+		}
+		json.NewDecoder(req.Body).Decode(&pipeline)
+		if _, found := pipeline.Spec["resources"]; found {
+			w.Header().Add("Warning", `299 - "spec.resources is deprecated, use workspaces instead"`)
+		}
+		if _, found := pipeline.Spec["tasks"]; !found {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: "admission webhook denied the request",
+				Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{Field: "spec.tasks", Message: "expected at least one, got none"}}},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+	}))
+	defer apiServer.Close()
+	r.Config = &rest.Config{Host: apiServer.URL}
+	r.HttpClient = http.DefaultClient
+	server.Config.Handler = router.Register(*r)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected LintResult
+	}{
+		{
+			name:     "valid",
+			body:     `{"apiVersion": "tekton.dev/v1beta1", "kind": "Pipeline", "metadata": {"name": "build"}, "spec": {"tasks": [{"name": "build"}]}}`,
+			expected: LintResult{Valid: true, Warnings: []string{}, Errors: []string{}},
+		},
+		{
+			name:     "deprecated",
+			body:     `{"apiVersion": "tekton.dev/v1beta1", "kind": "Pipeline", "metadata": {"name": "build"}, "spec": {"resources": [{"name": "source"}], "tasks": [{"name": "build"}]}}`,
+			expected: LintResult{Valid: true, Warnings: []string{"spec.resources is deprecated, use workspaces instead"}, Errors: []string{}},
+		},
+		{
+			name: "invalid",
+			body: `{"apiVersion": "tekton.dev/v1beta1", "kind": "Pipeline", "metadata": {"name": "build"}, "spec": {"resources": [{"name": "source"}]}}`,
+			expected: LintResult{
+				Warnings: []string{"spec.resources is deprecated, use workspaces instead"},
+				Errors:   []string{"spec.tasks: expected at least one, got none"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/lint/pipelines", server.URL, namespace), strings.NewReader(test.body))
+			response, err := http.DefaultClient.Do(httpReq)
+			if err != nil {
+				t.Fatalf("Error linting: %v", err)
+			}
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+			}
+			var result LintResult
+			json.NewDecoder(response.Body).Decode(&result)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Expected %+v, actual %+v", test.expected, result)
+			}
+		})
+	}
+
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/lint/tasks", server.URL, namespace), strings.NewReader(`{"kind": "Pipeline"}`))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error linting: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected statusCode %d for a mismatched kind, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
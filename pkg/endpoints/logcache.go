@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogCache keeps the container logs of completed TaskRuns so they can still be
+// served once their pods have been garbage collected
+type LogCache interface {
+	// Put stores a log, evicting the oldest logs if the cache is full
+	Put(key string, log []byte)
+	// Get returns a stored log
+	Get(key string) ([]byte, bool)
+}
+
+// logCacheKey identifies the log of a container of a TaskRun
+func logCacheKey(namespace, taskRun, container string) string {
+	return namespace + "/" + taskRun + "/" + container
+}
+
+// memoryLogCache keeps logs in memory up to a total size in bytes
+type memoryLogCache struct {
+	mutex   sync.Mutex
+	maxSize int64
+	size    int64
+	logs    map[string][]byte
+	// Keys oldest first
+	order []string
+}
+
+// NewMemoryLogCache returns a LogCache keeping up to maxSize bytes of logs in
+// memory
+func NewMemoryLogCache(maxSize int64) LogCache {
+	return &memoryLogCache{maxSize: maxSize, logs: map[string][]byte{}}
+}
+
+func (c *memoryLogCache) Put(key string, log []byte) {
+	if int64(len(log)) > c.maxSize {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.remove(key)
+	c.logs[key] = log
+	c.order = append(c.order, key)
+	c.size += int64(len(log))
+	for c.size > c.maxSize {
+		c.remove(c.order[0])
+	}
+}
+
+func (c *memoryLogCache) Get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	log, ok := c.logs[key]
+	return log, ok
+}
+
+// remove deletes a log, the mutex must be held
+func (c *memoryLogCache) remove(key string) {
+	log, ok := c.logs[key]
+	if !ok {
+		return
+	}
+	delete(c.logs, key)
+	c.size -= int64(len(log))
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// directoryLogCache keeps logs as files in a directory, up to a total size in
+// bytes when maxSize is positive
+type directoryLogCache struct {
+	mutex   sync.Mutex
+	dir     string
+	maxSize int64
+}
+
+// NewDirectoryLogCache returns a LogCache storing logs in dir, which is created
+// if needed. The oldest files are removed once they exceed maxSize bytes, 0
+// means unlimited
+func NewDirectoryLogCache(dir string, maxSize int64) (LogCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &directoryLogCache{dir: dir, maxSize: maxSize}, nil
+}
+
+func (c *directoryLogCache) path(key string) string {
+	return filepath.Join(c.dir, url.PathEscape(key))
+}
+
+func (c *directoryLogCache) Put(key string, log []byte) {
+	if c.maxSize > 0 && int64(len(log)) > c.maxSize {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := ioutil.WriteFile(c.path(key), log, 0600); err != nil {
+		logging.Log.Errorf("Could not cache log %s: %s", key, err)
+		return
+	}
+	if c.maxSize > 0 {
+		c.evict()
+	}
+}
+
+func (c *directoryLogCache) Get(key string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	log, err := ioutil.ReadFile(c.path(key))
+	return log, err == nil
+}
+
+// evict removes the oldest files until the directory fits maxSize, the mutex
+// must be held
+func (c *directoryLogCache) evict() {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		logging.Log.Errorf("Could not list cached logs: %s", err)
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+	for _, file := range files {
+		if size <= c.maxSize {
+			return
+		}
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err == nil {
+			size -= file.Size()
+		}
+	}
+}
+
+// CacheCompletedLogs stores the logs of every container of each TaskRun that
+// completes in the Resource's LogCache, until stopCh is closed
+func (r Resource) CacheCompletedLogs(stopCh <-chan struct{}) {
+	// TaskRuns already complete on startup are not cached when updated again
+	started := time.Now().Truncate(time.Second)
+	subscriber, _ := ResourcesBroadcaster.Subscribe(broadcaster.WithSendTimeout[broadcaster.SocketData](0), broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		return data.MessageType == broadcaster.TaskRunUpdated || data.MessageType == broadcaster.TaskRunDeleted
+	}))
+	defer ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	completed := map[string]bool{}
+	for {
+		select {
+		case data := <-subscriber.SubChan():
+			if completion, ok := runCompleted(data, completed, started); ok {
+				// Fetching logs must not hold up the broadcast
+				go r.cacheTaskRunLogs(completion.Namespace, completion.Name)
+			}
+		case <-subscriber.UnsubChan():
+			return
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// cacheTaskRunLogs stores the log of each container of a TaskRun's pod
+func (r Resource) cacheTaskRunLogs(namespace, name string) {
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		logging.Log.Debugf("Could not cache logs of TaskRun %s: %s", name, err)
+		return
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			stream, err := openPodLogs(r.K8sClient, namespace, pod.Name, &corev1.PodLogOptions{Container: container.Name})
+			if err != nil {
+				logging.Log.Debugf("Could not cache log of container %s of TaskRun %s: %s", container.Name, name, err)
+				continue
+			}
+			log, err := ioutil.ReadAll(stream)
+			stream.Close()
+			if err != nil {
+				logging.Log.Debugf("Could not cache log of container %s of TaskRun %s: %s", container.Name, name, err)
+				continue
+			}
+			r.LogCache.Put(logCacheKey(namespace, name, container.Name), log)
+		}
+	}
+}
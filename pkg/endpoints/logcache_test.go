@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The logs of a completed TaskRun are served from the cache once its pod is gone
+func TestCachedTaskRunLogs(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{"step-build": "built\n", "sidecar-docker": "ready\n"})()
+
+	cache := NewMemoryLogCache(1024)
+	r.LogCache = cache
+	server.Config.Handler = router.Register(*r)
+	stopCh := make(chan struct{})
+	go r.CacheCompletedLogs(stopCh)
+	defer awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+	defer close(stopCh)
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected the log cache within pool")
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	taskRuns := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	if _, err := taskRuns.Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}, {Name: "sidecar-docker"}}},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	unstructured.SetNestedField(taskRun.Object, time.Now().UTC().Format(time.RFC3339), "status", "completionTime")
+	unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+		map[string]interface{}{"type": "Succeeded", "status": "True"},
+	}, "status", "conditions")
+	taskRun.SetResourceVersion("2")
+	if _, err := taskRuns.Update(taskRun, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating taskRun: %v", err)
+	}
+	awaitFatal(func() bool {
+		_, stepCached := cache.Get(namespace + "/build/step-build")
+		_, sidecarCached := cache.Get(namespace + "/build/sidecar-docker")
+		return stepCached && sidecarCached
+	}, t, "Expected the logs of the completed TaskRun to be cached")
+
+	if err := r.K8sClient.CoreV1().Pods(namespace).Delete("build-pod", &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting pod: %v", err)
+	}
+	logsURL := fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/containers/step-build/logs", server.URL, namespace)
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", logsURL+"?cached=true", nil))
+	if err != nil {
+		t.Fatalf("Error getting cached logs: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	if body, _ := ioutil.ReadAll(response.Body); string(body) != "built\n" {
+		t.Errorf("Expected cached step log, actual %q", body)
+	}
+
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", logsURL, nil))
+	if err != nil {
+		t.Fatalf("Error getting logs: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d without cached, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
+
+// Log caches evict the oldest logs once full
+func TestLogCacheEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logcache")
+	if err != nil {
+		t.Fatalf("Error creating directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	directoryCache, err := NewDirectoryLogCache(dir, 10)
+	if err != nil {
+		t.Fatalf("Error creating directory cache: %v", err)
+	}
+
+	caches := map[string]LogCache{"memory": NewMemoryLogCache(10), "directory": directoryCache}
+	for name, cache := range caches {
+		cache.Put("a", []byte("first"))
+		// Modification times need to differ for the directory cache
+		time.Sleep(10 * time.Millisecond)
+		cache.Put("b", []byte("second"))
+		cache.Put("large", []byte("more than ten bytes"))
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("%s: expected the oldest log to be evicted", name)
+		}
+		if log, ok := cache.Get("b"); !ok || string(log) != "second" {
+			t.Errorf("%s: expected the newest log to be kept, actual %q", name, log)
+		}
+		if _, ok := cache.Get("large"); ok {
+			t.Errorf("%s: expected a log larger than the cache to be skipped", name)
+		}
+	}
+}
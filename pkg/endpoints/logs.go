@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8sclientset "k8s.io/client-go/kubernetes"
+)
+
+// PodLogs opens a stream of a pod container's logs. It can be replaced in
+// tests as the fake clientset cannot serve logs
+var PodLogs = func(client k8sclientset.Interface, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return client.CoreV1().Pods(namespace).GetLogs(pod, options).Stream()
+}
+
+// GetTaskRunContainerLogs streams the log of any container of a TaskRun's pod,
+// including init containers and sidecars. The follow and tailLines query
+// parameters are passed on to the Kubernetes API, grep only streams the lines
+// matching a regular expression. With cached=true the log is served from the
+// LogCache once the pod is gone
+func (r Resource) GetTaskRunContainerLogs(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	container := request.PathParameter("container")
+
+	options := &corev1.PodLogOptions{Container: container}
+	if follow := request.QueryParameter("follow"); follow != "" {
+		var err error
+		if options.Follow, err = strconv.ParseBool(follow); err != nil {
+			utils.RespondErrorMessage(response, fmt.Sprintf("invalid follow '%s'", follow), http.StatusBadRequest)
+			return
+		}
+	}
+	if tailLines := request.QueryParameter("tailLines"); tailLines != "" {
+		lines, err := strconv.ParseInt(tailLines, 10, 64)
+		if err != nil || lines < 0 {
+			utils.RespondErrorMessage(response, fmt.Sprintf("invalid tailLines '%s'", tailLines), http.StatusBadRequest)
+			return
+		}
+		options.TailLines = &lines
+	}
+	grep, err := grepParameter(request)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	cached := false
+	if value := request.QueryParameter("cached"); value != "" {
+		var err error
+		if cached, err = strconv.ParseBool(value); err != nil {
+			utils.RespondErrorMessage(response, fmt.Sprintf("invalid cached '%s'", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if k8serrors.IsNotFound(err) && cached && r.LogCache != nil {
+		if log, ok := r.LogCache.Get(logCacheKey(namespace, name, container)); ok {
+			response.AddHeader("Content-Type", "text/plain")
+			copyLog(response, bytes.NewReader(log), grep)
+			return
+		}
+	}
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	if !hasContainer(pod, container) {
+		utils.RespondErrorMessage(response, fmt.Sprintf("container '%s' not found in pod %s", container, pod.Name), http.StatusNotFound)
+		return
+	}
+
+	if !acquireLogStream(request, response) {
+		return
+	}
+	defer LogStreams.release()
+	stream, err := PodLogs(r.K8sClient, namespace, pod.Name, options)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	defer stream.Close()
+
+	response.AddHeader("Content-Type", "text/plain")
+	if err := copyLog(utils.MakeFlushWriter(response), stream, grep); err != nil {
+		logging.Log.Debugf("Log stream of container %s of TaskRun %s ended: %s", container, name, err)
+	}
+}
+
+// grepParameter compiles the regular expression of the grep query parameter,
+// nil if it is not set
+func grepParameter(request *restful.Request) (*regexp.Regexp, error) {
+	value := request.QueryParameter("grep")
+	if value == "" {
+		return nil, nil
+	}
+	grep, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep '%s': %s", value, err)
+	}
+	return grep, nil
+}
+
+// copyLog copies a log, only the lines matching grep when it is set
+func copyLog(writer io.Writer, log io.Reader, grep *regexp.Regexp) error {
+	if grep == nil {
+		_, err := io.Copy(writer, log)
+		return err
+	}
+	reader := bufio.NewReader(log)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && grep.Match(bytes.TrimRight(line, "\r\n")) {
+			if _, err := writer.Write(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// grepWriter only passes on the lines of a successful response matching grep,
+// for logs proxied from the Kubernetes API. Error responses are passed on as
+// is. close writes the last line if it does not end with a newline
+type grepWriter struct {
+	http.ResponseWriter
+	grep    *regexp.Regexp
+	filter  bool
+	partial []byte
+}
+
+func (w *grepWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusOK {
+		w.filter = true
+		// Fewer bytes than the log are written
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *grepWriter) Write(data []byte) (int, error) {
+	if !w.filter {
+		return w.ResponseWriter.Write(data)
+	}
+	w.partial = append(w.partial, data...)
+	for {
+		end := bytes.IndexByte(w.partial, '\n')
+		if end < 0 {
+			return len(data), nil
+		}
+		line := w.partial[:end+1]
+		if w.grep.Match(bytes.TrimRight(line, "\r\n")) {
+			if _, err := w.ResponseWriter.Write(line); err != nil {
+				return 0, err
+			}
+		}
+		w.partial = w.partial[end+1:]
+	}
+}
+
+func (w *grepWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *grepWriter) close() {
+	if len(w.partial) > 0 && w.grep.Match(w.partial) {
+		w.ResponseWriter.Write(w.partial)
+	}
+	w.partial = nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// GET TaskRun container logs streams a sidecar's log by container name
+func TestGETTaskRunContainerLogs(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{"sidecar-registry": "listening on :5000\n"})()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "push", "1")
+	unstructured.SetNestedField(taskRun.Object, "push-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "push-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-push"}, {Name: "sidecar-registry"}},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/push/containers/sidecar-registry/logs?follow=true&tailLines=10", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting container logs: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	if string(body) != "listening on :5000\n" {
+		t.Errorf("Expected sidecar log, actual %q", body)
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/push/containers/missing/logs", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting container logs: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected statusCode %d for unknown container, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
+
+// GET TaskRun container logs with grep only streams the matching lines, and
+// rejects invalid expressions
+func TestGETTaskRunContainerLogsGrep(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{"step-test": "=== RUN TestA\n--- PASS: TestA\n=== RUN TestB\nERROR: connection refused\n--- FAIL: TestB\nerror summary"})()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "unit", "1")
+	unstructured.SetNestedField(taskRun.Object, "unit-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "unit-pod", Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "step-test"}}},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	getLogs := func(grep string) *http.Response {
+		query := url.Values{"follow": {"true"}, "grep": {grep}}
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/unit/containers/step-test/logs?%s", server.URL, namespace, query.Encode()), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting container logs: %v", err)
+		}
+		return response
+	}
+
+	response := getLogs("(?i)error|FAIL")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	if expected := "ERROR: connection refused\n--- FAIL: TestB\nerror summary"; string(body) != expected {
+		t.Errorf("Expected matching lines %q, actual %q", expected, body)
+	}
+
+	if response := getLogs("(unclosed"); response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected statusCode %d for an invalid grep, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
+
+// Pod logs read through the proxy only return the lines matching grep, which
+// is not passed on to the Kubernetes API
+func TestProxyPodLogsGrep(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	var forwarded url.Values
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		forwarded = req.URL.Query()
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "=== RUN TestA\nERROR: connection refused\n--- PASS: TestA\nerror summary")
+	}))
+	defer apiServer.Close()
+	r.Config = &rest.Config{Host: apiServer.URL}
+	r.HttpClient = http.DefaultClient
+	server.Config.Handler = router.Register(*r)
+
+	getLogs := func(grep string) *http.Response {
+		query := url.Values{"container": {"step-test"}, "grep": {grep}}
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/proxy/api/v1/namespaces/%s/pods/unit-pod/log?%s", server.URL, namespace, query.Encode()), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting pod logs: %v", err)
+		}
+		return response
+	}
+
+	response := getLogs("(?i)error")
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	if expected := "ERROR: connection refused\nerror summary"; string(body) != expected {
+		t.Errorf("Expected matching lines %q, actual %q", expected, body)
+	}
+	if _, ok := forwarded["grep"]; ok || forwarded.Get("container") != "step-test" {
+		t.Errorf("Expected the query without grep forwarded, actual %v", forwarded)
+	}
+
+	invalid := getLogs("(unclosed")
+	invalid.Body.Close()
+	if invalid.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected statusCode %d for an invalid grep, actual %d", http.StatusBadRequest, invalid.StatusCode)
+	}
+}
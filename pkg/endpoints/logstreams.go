@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	corev1 "k8s.io/api/core/v1"
+	k8sclientset "k8s.io/client-go/kubernetes"
+)
+
+// logStreamRetryAfter is how long clients are asked to wait before retrying
+// when all log streams are in use
+const logStreamRetryAfter = 5 * time.Second
+
+// errLogStreamsExhausted is returned when a log is read while all log streams
+// are in use
+var errLogStreamsExhausted = errors.New("too many concurrent log streams")
+
+// StreamLimiter limits the number of pod log streams open at the same time
+// across all clients
+type StreamLimiter struct {
+	mutex sync.Mutex
+	// Maximum number of streams, 0 means unlimited
+	limit  int
+	active int
+}
+
+// LogStreams limits the pod log streams opened by the log endpoints
+var LogStreams = &StreamLimiter{}
+
+// SetLimit sets the maximum number of concurrent streams, 0 means unlimited.
+// Streams already open are not affected
+func (l *StreamLimiter) SetLimit(limit int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limit = limit
+}
+
+// Active returns the number of streams currently open
+func (l *StreamLimiter) Active() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.active
+}
+
+// acquire reserves a stream, returning false if the limit has been reached
+func (l *StreamLimiter) acquire() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.limit > 0 && l.active >= l.limit {
+		return false
+	}
+	l.active++
+	return true
+}
+
+// release frees a stream reserved with acquire
+func (l *StreamLimiter) release() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.active--
+}
+
+// acquireLogStream reserves a log stream, responding with 429 and a
+// Retry-After header if none is available. The stream must be released
+// once closed
+func acquireLogStream(request *restful.Request, response *restful.Response) bool {
+	if LogStreams.acquire() {
+		return true
+	}
+	response.AddHeader("Retry-After", strconv.Itoa(int(logStreamRetryAfter.Seconds())))
+	message := fmt.Sprintf("too many concurrent log streams, retry in %s", logStreamRetryAfter)
+	if websocket.IsUpgradeRequest(request.Request) {
+		websocket.RespondUpgradeError(response, http.StatusTooManyRequests, message)
+		return false
+	}
+	utils.RespondErrorMessage(response, message, http.StatusTooManyRequests)
+	return false
+}
+
+// limitedStream is a pod log stream releasing its reservation once closed
+type limitedStream struct {
+	io.ReadCloser
+	once sync.Once
+}
+
+func (s *limitedStream) Close() error {
+	err := s.ReadCloser.Close()
+	s.once.Do(LogStreams.release)
+	return err
+}
+
+// openPodLogs opens a pod log stream counted against LogStreams, for logs read
+// outside of a request. It fails with errLogStreamsExhausted if none is
+// available
+func openPodLogs(client k8sclientset.Interface, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+	if !LogStreams.acquire() {
+		return nil, errLogStreamsExhausted
+	}
+	stream, err := PodLogs(client, namespace, pod, options)
+	if err != nil {
+		LogStreams.release()
+		return nil, err
+	}
+	return &limitedStream{ReadCloser: stream}, nil
+}
+
+// podLogPath reports whether a Kubernetes API path is the log subresource of
+// a pod, e.g. api/v1/namespaces/default/pods/build-pod/log
+func podLogPath(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	return len(segments) == 7 && segments[0] == "api" && segments[2] == "namespaces" && segments[4] == "pods" && segments[6] == "log"
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclientset "k8s.io/client-go/kubernetes"
+)
+
+// Log requests over the stream limit are rejected until a stream closes
+func TestLogStreamLimit(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	LogStreams.SetLimit(2)
+	defer LogStreams.SetLimit(0)
+
+	// Each stream stays open until its writer is closed
+	writers := make(chan *io.PipeWriter, 3)
+	original := PodLogs
+	PodLogs = func(client k8sclientset.Interface, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+		reader, writer := io.Pipe()
+		writers <- writer
+		go writer.Write([]byte("started\n"))
+		return reader, nil
+	}
+	defer func() {
+		PodLogs = original
+	}()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}}},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	logsURL := fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/containers/step-build/logs?follow=true", server.URL, namespace)
+	getLogs := func() *http.Response {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", logsURL, nil))
+		if err != nil {
+			t.Fatalf("Error getting logs: %v", err)
+		}
+		return response
+	}
+	var open []*http.Response
+	for i := 0; i < 2; i++ {
+		response := getLogs()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d within the limit, actual %d", http.StatusOK, response.StatusCode)
+		}
+		open = append(open, response)
+	}
+
+	rejected := getLogs()
+	if rejected.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected statusCode %d over the limit, actual %d", http.StatusTooManyRequests, rejected.StatusCode)
+	}
+	if rejected.Header.Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+
+	// Ending a stream frees it for the next request
+	(<-writers).Close()
+	ioutil.ReadAll(open[0].Body)
+	if response := getLogs(); response.StatusCode != http.StatusOK {
+		t.Errorf("Expected statusCode %d once a stream closed, actual %d", http.StatusOK, response.StatusCode)
+	}
+	close(writers)
+	for writer := range writers {
+		writer.Close()
+	}
+	for _, response := range open {
+		response.Body.Close()
+	}
+}
+
+// Pod logs read through the Kubernetes API proxy and for reports count
+// against the stream limit too
+func TestLogStreamLimitProxyAndReport(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	LogStreams.SetLimit(1)
+	defer LogStreams.SetLimit(0)
+
+	writers := make(chan *io.PipeWriter, 1)
+	original := PodLogs
+	PodLogs = func(client k8sclientset.Interface, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+		reader, writer := io.Pipe()
+		writers <- writer
+		go writer.Write([]byte("started\n"))
+		return reader, nil
+	}
+	defer func() {
+		PodLogs = original
+	}()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "release", "1")
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	taskRun.SetLabels(map[string]string{PipelineRunLabel: "release"})
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "step-build"}}},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	get := func(url string) *http.Response {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+		if err != nil {
+			t.Fatalf("Error getting %s: %v", url, err)
+		}
+		return response
+	}
+	open := get(fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/containers/step-build/logs?follow=true", server.URL, namespace))
+	if open.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d within the limit, actual %d", http.StatusOK, open.StatusCode)
+	}
+	defer open.Body.Close()
+	defer func() {
+		(<-writers).Close()
+	}()
+
+	proxied := get(fmt.Sprintf("%s/proxy/api/v1/namespaces/%s/pods/build-pod/log?container=step-build", server.URL, namespace))
+	proxied.Body.Close()
+	if proxied.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected statusCode %d for the proxied log over the limit, actual %d", http.StatusTooManyRequests, proxied.StatusCode)
+	}
+
+	report := get(fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/release/report", server.URL, namespace))
+	defer report.Body.Close()
+	if report.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d for the report, actual %d", http.StatusOK, report.StatusCode)
+	}
+	if log := readReport(t, report)["release/logs/build/build.log"]; !strings.HasPrefix(log, "unavailable: ") {
+		t.Errorf("Expected placeholder for the log over the limit, actual %q", log)
+	}
+	if active := LogStreams.Active(); active != 1 {
+		t.Errorf("Expected the report and proxy to release their streams, actual %d active", active)
+	}
+}
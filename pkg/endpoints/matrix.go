@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MatrixFanOut is the TaskRuns a matrixed pipeline task of a PipelineRun fanned
+// out to, Params are the names of the params of its matrix
+type MatrixFanOut struct {
+	PipelineTask string          `json:"pipelineTask"`
+	Params       []string        `json:"params"`
+	TaskRuns     []MatrixTaskRun `json:"taskRuns"`
+}
+
+// MatrixTaskRun is a TaskRun of a matrix with the combination of params it ran
+type MatrixTaskRun struct {
+	TaskRun string                 `json:"taskRun"`
+	Params  map[string]interface{} `json:"params"`
+	Status  string                 `json:"status"`
+}
+
+// GetPipelineRunMatrix returns the TaskRuns of a matrixed pipeline task of a
+// PipelineRun, in the order Tekton generated them, with their combination
+func (r Resource) GetPipelineRunMatrix(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	taskName := request.PathParameter("task")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	task, ok := findPipelineTask(pipelineSpec, taskName)
+	if !ok {
+		utils.RespondErrorMessage(response, fmt.Sprintf("pipeline task %s not found in PipelineRun %s", taskName, name), http.StatusNotFound)
+		return
+	}
+	matrix, found, _ := unstructured.NestedMap(task, "matrix")
+	if !found {
+		utils.RespondErrorMessage(response, fmt.Sprintf("pipeline task %s is not matrixed", taskName), http.StatusBadRequest)
+		return
+	}
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%s", PipelineRunLabel, name, PipelineTaskLabel, taskName),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(taskRuns.Items, func(i, j int) bool {
+		return matrixIndexLess(taskRuns.Items[i].GetName(), taskRuns.Items[j].GetName())
+	})
+
+	params := matrixParams(matrix)
+	fanOut := MatrixFanOut{PipelineTask: taskName, Params: params, TaskRuns: []MatrixTaskRun{}}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		combination := map[string]interface{}{}
+		specParams, _, _ := unstructured.NestedSlice(taskRun.Object, "spec", "params")
+		for _, p := range specParams {
+			param, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			paramName, _ := param["name"].(string)
+			if containsString(params, paramName) {
+				combination[paramName] = param["value"]
+			}
+		}
+		fanOut.TaskRuns = append(fanOut.TaskRuns, MatrixTaskRun{TaskRun: taskRun.GetName(), Params: combination, Status: runStatus(taskRun)})
+	}
+	response.WriteEntity(fanOut)
+}
+
+// findPipelineTask returns a task or finally task of a Pipeline spec by name
+func findPipelineTask(pipelineSpec map[string]interface{}, name string) (map[string]interface{}, bool) {
+	for _, field := range []string{"tasks", "finally"} {
+		tasks, _, _ := unstructured.NestedSlice(pipelineSpec, field)
+		for _, t := range tasks {
+			if task, ok := t.(map[string]interface{}); ok && task["name"] == name {
+				return task, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// matrixParams returns the names of the params of a matrix, those it fans out
+// on followed by those its include entries add, without duplicates
+func matrixParams(matrix map[string]interface{}) []string {
+	names := []string{}
+	addParams := func(params []interface{}) {
+		for _, p := range params {
+			param, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, _ := param["name"].(string); name != "" && !containsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	params, _, _ := unstructured.NestedSlice(matrix, "params")
+	addParams(params)
+	includes, _, _ := unstructured.NestedSlice(matrix, "include")
+	for _, i := range includes {
+		if include, ok := i.(map[string]interface{}); ok {
+			includeParams, _, _ := unstructured.NestedSlice(include, "params")
+			addParams(includeParams)
+		}
+	}
+	return names
+}
+
+// matrixIndexLess orders the TaskRuns of a matrix by the index Tekton suffixes
+// their names with, falling back to their names
+func matrixIndexLess(a, b string) bool {
+	indexA, errA := strconv.Atoi(a[strings.LastIndex(a, "-")+1:])
+	indexB, errB := strconv.Atoi(b[strings.LastIndex(b, "-")+1:])
+	if errA == nil && errB == nil && indexA != indexB {
+		return indexA < indexB
+	}
+	return a < b
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
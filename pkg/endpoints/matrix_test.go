@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun matrix reports the combination each TaskRun of a matrixed
+// task ran, and rejects tasks that are not matrixed
+func TestGETPipelineRunMatrix(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "release", "1")
+	unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+		map[string]interface{}{
+			"name":    "build",
+			"taskRef": map[string]interface{}{"name": "go-build"},
+			"matrix": map[string]interface{}{
+				"params": []interface{}{map[string]interface{}{"name": "platform", "value": []interface{}{"linux", "darwin"}}},
+			},
+		},
+		map[string]interface{}{"name": "publish", "taskRef": map[string]interface{}{"name": "upload"}},
+	}, "spec", "pipelineSpec", "tasks")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for _, run := range []struct {
+		name     string
+		platform string
+		status   string
+	}{
+		{"release-build-1", "darwin", "False"},
+		{"release-build-0", "linux", "True"},
+	} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, run.name, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "release", PipelineTaskLabel: "build"})
+		unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+			map[string]interface{}{"name": "platform", "value": run.platform},
+			map[string]interface{}{"name": "version", "value": "1.2.0"},
+		}, "spec", "params")
+		unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	getMatrix := func(task string) *http.Response {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/release/matrix/%s", server.URL, namespace, task), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting matrix: %v", err)
+		}
+		return response
+	}
+
+	response := getMatrix("build")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var fanOut MatrixFanOut
+	if err := json.NewDecoder(response.Body).Decode(&fanOut); err != nil {
+		t.Fatalf("Error decoding matrix: %v", err)
+	}
+	expected := MatrixFanOut{
+		PipelineTask: "build",
+		Params:       []string{"platform"},
+		TaskRuns: []MatrixTaskRun{
+			{TaskRun: "release-build-0", Params: map[string]interface{}{"platform": "linux"}, Status: RunSucceeded},
+			{TaskRun: "release-build-1", Params: map[string]interface{}{"platform": "darwin"}, Status: RunFailed},
+		},
+	}
+	if !reflect.DeepEqual(fanOut, expected) {
+		t.Errorf("Expected matrix %+v, actual %+v", expected, fanOut)
+	}
+
+	if response := getMatrix("publish"); response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected statusCode %d for a task that is not matrixed, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+	if response := getMatrix("missing"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d for a missing task, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
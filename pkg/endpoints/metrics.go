@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// podMetricsGVR is the resource metrics-server serves pod usage as
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// PodUsage is the current resource usage of the containers of a TaskRun's
+// pod, measured by metrics-server over Window seconds ending at Timestamp
+type PodUsage struct {
+	Pod        string           `json:"pod"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Window     float64          `json:"windowSeconds"`
+	Containers []ContainerUsage `json:"containers"`
+}
+
+// ContainerUsage is the CPU and memory used by a container, with its requests
+// and limits. Usage is empty for containers not measured, such as those that
+// have terminated
+type ContainerUsage struct {
+	Name     string              `json:"name"`
+	Usage    corev1.ResourceList `json:"usage"`
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+	Limits   corev1.ResourceList `json:"limits,omitempty"`
+}
+
+// GetTaskRunMetrics returns the CPU and memory usage of each container of the
+// pod of a TaskRun from the metrics API, or 501 if metrics-server is not
+// installed
+func (r Resource) GetTaskRunMetrics(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	if !r.servedResource(podMetricsGVR) {
+		utils.RespondErrorMessage(response, "pod metrics are not available, metrics-server is not installed", http.StatusNotImplemented)
+		return
+	}
+	metrics, err := r.DynamicClient.Resource(podMetricsGVR).Namespace(namespace).Get(pod.Name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	usage := PodUsage{Pod: pod.Name, Containers: []ContainerUsage{}}
+	usage.Timestamp, _ = nestedTime(metrics, "timestamp")
+	if window, _, _ := unstructured.NestedString(metrics.Object, "window"); window != "" {
+		if duration, err := time.ParseDuration(window); err == nil {
+			usage.Window = duration.Seconds()
+		}
+	}
+	measured := map[string]corev1.ResourceList{}
+	containers, _, _ := unstructured.NestedSlice(metrics.Object, "containers")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		containerName, _ := container["name"].(string)
+		values, _, _ := unstructured.NestedStringMap(container, "usage")
+		measured[containerName] = resourceList(values)
+	}
+	for _, container := range pod.Spec.Containers {
+		containerUsage := ContainerUsage{
+			Name:     container.Name,
+			Usage:    measured[container.Name],
+			Requests: container.Resources.Requests,
+			Limits:   container.Resources.Limits,
+		}
+		if containerUsage.Usage == nil {
+			containerUsage.Usage = corev1.ResourceList{}
+		}
+		usage.Containers = append(usage.Containers, containerUsage)
+	}
+	response.WriteEntity(usage)
+}
+
+// resourceList parses the quantities of a resource list, skipping invalid
+// ones
+func resourceList(values map[string]string) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	for name, value := range values {
+		if quantity, err := resource.ParseQuantity(value); err == nil {
+			list[corev1.ResourceName(name)] = quantity
+		}
+	}
+	return list
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+// GET TaskRun metrics returns the usage of each container of the pod
+func TestGETTaskRunMetrics(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "taskrun", "1")
+	unstructured.SetNestedField(taskRun.Object, "taskrun-pod", "status", "podName")
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "step-build",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+					},
+				},
+				{Name: "step-push"},
+			},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/namespaces/%s/taskruns/taskrun/metrics", server.URL, namespace)
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting TaskRun metrics: %v", err)
+	}
+	if response.StatusCode != http.StatusNotImplemented {
+		t.Fatalf("Expected statusCode %d without metrics-server, actual %d", http.StatusNotImplemented, response.StatusCode)
+	}
+
+	r.K8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "metrics.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "pods"}},
+	}}
+	metrics := testutils.GetObject("metrics.k8s.io/v1beta1", "PodMetrics", namespace, "taskrun-pod", "1")
+	unstructured.SetNestedField(metrics.Object, "2021-01-01T00:00:00Z", "timestamp")
+	unstructured.SetNestedField(metrics.Object, "30s", "window")
+	unstructured.SetNestedSlice(metrics.Object, []interface{}{
+		map[string]interface{}{"name": "step-build", "usage": map[string]interface{}{"cpu": "250m", "memory": "128Mi"}},
+	}, "containers")
+	metricsGVR := schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	if _, err := r.DynamicClient.Resource(metricsGVR).Namespace(namespace).Create(metrics, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pod metrics: %v", err)
+	}
+
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting TaskRun metrics: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var usage PodUsage
+	if err := json.NewDecoder(response.Body).Decode(&usage); err != nil {
+		t.Fatalf("Error decoding usage: %v", err)
+	}
+	if usage.Pod != "taskrun-pod" || usage.Window != 30 {
+		t.Errorf("Expected pod 'taskrun-pod' over 30s, actual '%s' over %vs", usage.Pod, usage.Window)
+	}
+	if len(usage.Containers) != 2 {
+		t.Fatalf("Expected 2 containers, actual %+v", usage.Containers)
+	}
+	build := usage.Containers[0]
+	cpu, memory := build.Usage[corev1.ResourceCPU], build.Usage[corev1.ResourceMemory]
+	if build.Name != "step-build" || cpu.String() != "250m" || memory.String() != "128Mi" {
+		t.Errorf("Expected step-build using 250m CPU and 128Mi memory, actual %+v", build)
+	}
+	request, limit := build.Requests[corev1.ResourceCPU], build.Limits[corev1.ResourceMemory]
+	if request.String() != "100m" || limit.String() != "512Mi" {
+		t.Errorf("Expected step-build requesting 100m CPU limited to 512Mi, actual %+v", build)
+	}
+	if push := usage.Containers[1]; push.Name != "step-push" || len(push.Usage) != 0 {
+		t.Errorf("Expected step-push without usage, actual %+v", push)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// MIMENDJSON is the content type of newline delimited JSON, list endpoints
+// stream one object per line when it is accepted
+const MIMENDJSON = "application/x-ndjson"
+
+// ndjsonPageSize is how many objects are listed at a time when streaming
+const ndjsonPageSize = 100
+
+// acceptsNDJSON is true when the client asked for newline delimited JSON
+func acceptsNDJSON(request *restful.Request) bool {
+	return strings.Contains(request.HeaderParameter("Accept"), MIMENDJSON)
+}
+
+// ndjsonWriter writes objects as newline delimited JSON, flushing each one so
+// clients can process them as they arrive
+type ndjsonWriter struct {
+	response *restful.Response
+	encoder  *json.Encoder
+	started  bool
+}
+
+func newNDJSONWriter(response *restful.Response) *ndjsonWriter {
+	return &ndjsonWriter{response: response, encoder: json.NewEncoder(response)}
+}
+
+// start writes the headers, once
+func (w *ndjsonWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.response.Header().Set("Content-Type", MIMENDJSON)
+	w.response.WriteHeader(http.StatusOK)
+}
+
+// write sends an object on its own line
+func (w *ndjsonWriter) write(object interface{}) error {
+	w.start()
+	if err := w.encoder.Encode(object); err != nil {
+		return err
+	}
+	w.response.Flush()
+	return nil
+}
+
+// fail reports an error, as the response status if nothing was written yet
+// otherwise by ending the stream early
+func (w *ndjsonWriter) fail(err error) {
+	if !w.started {
+		utils.RespondError(w.response, err, http.StatusInternalServerError)
+		return
+	}
+	logging.Log.Errorf("Error streaming list: %s", err)
+}
+
+// writeNDJSON streams a list of objects already in memory
+func writeNDJSON(response *restful.Response, objects []*unstructured.Unstructured) {
+	writer := newNDJSONWriter(response)
+	writer.start()
+	for _, object := range objects {
+		if err := writer.write(object); err != nil {
+			writer.fail(err)
+			return
+		}
+	}
+}
+
+// streamNDJSON lists objects a page at a time, writing each object as soon as
+// its page is read
+func streamNDJSON(response *restful.Response, client dynamic.ResourceInterface, options metav1.ListOptions) {
+	writer := newNDJSONWriter(response)
+	options.Limit = ndjsonPageSize
+	for {
+		list, err := client.List(options)
+		if err != nil {
+			writer.fail(err)
+			return
+		}
+		writer.start()
+		for i := range list.Items {
+			if err := writer.write(&list.Items[i]); err != nil {
+				writer.fail(err)
+				return
+			}
+		}
+		if options.Continue = list.GetContinue(); options.Continue == "" {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// How many of the most recent PipelineRuns are grouped by default and at most
+const (
+	defaultOverviewRuns = 100
+	maxOverviewRuns     = 500
+)
+
+// CIGroup summarises the recent PipelineRuns of a repository triggered by an
+// EventListener. Runs without trigger or repository metadata are grouped
+// together with Untriggered set
+type CIGroup struct {
+	Repository    string         `json:"repository,omitempty"`
+	EventListener string         `json:"eventListener,omitempty"`
+	Untriggered   bool           `json:"untriggered,omitempty"`
+	Latest        CIRun          `json:"latest"`
+	Total         int            `json:"total"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// CIRun is the latest PipelineRun of a CIGroup
+type CIRun struct {
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Revision string    `json:"revision,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+type ciGroupKey struct {
+	repository    string
+	eventListener string
+}
+
+// GetCIOverview groups the most recent PipelineRuns of a namespace by their
+// source repository and triggering EventListener, most recently active first
+func (r Resource) GetCIOverview(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	runs, err := intParameter(request, "runs", defaultOverviewRuns, maxOverviewRuns)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	groups := []*CIGroup{}
+	byKey := map[ciGroupKey]*CIGroup{}
+	// Runs are newest first so the first run seen in a group is its latest
+	for _, pipelineRun := range recentRuns(pipelineRuns.Items, runs) {
+		source := runSource(pipelineRun)
+		key := ciGroupKey{repository: source.URL, eventListener: source.EventListener}
+		status := runStatus(pipelineRun)
+		group, ok := byKey[key]
+		if !ok {
+			group = &CIGroup{
+				Repository:    source.URL,
+				EventListener: source.EventListener,
+				Untriggered:   key == ciGroupKey{},
+				Latest: CIRun{
+					Name:     pipelineRun.GetName(),
+					Status:   status,
+					Revision: source.Revision,
+					Branch:   source.Branch,
+					Created:  pipelineRun.GetCreationTimestamp().Time,
+				},
+				Counts: map[string]int{},
+			}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.Total++
+		group.Counts[status]++
+	}
+
+	overview := make([]CIGroup, len(groups))
+	for i, group := range groups {
+		overview[i] = *group
+	}
+	response.WriteEntity(overview)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET CI overview groups runs by repository and EventListener
+func TestGETCIOverview(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	now := time.Now().Truncate(time.Second)
+	runs := []struct {
+		name   string
+		repo   string
+		age    time.Duration
+		status string
+	}{
+		{"dashboard-1", "https://github.com/tektoncd/dashboard", 3 * time.Hour, "True"},
+		{"pipeline-1", "https://github.com/tektoncd/pipeline", 2 * time.Hour, "True"},
+		{"dashboard-2", "https://github.com/tektoncd/dashboard", time.Hour, "False"},
+		{"manual", "", 30 * time.Minute, "True"},
+	}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetCreationTimestamp(metav1.NewTime(now.Add(-run.age)))
+		if run.repo != "" {
+			pipelineRun.SetAnnotations(map[string]string{"tekton.dev/git-url": run.repo})
+			pipelineRun.SetLabels(map[string]string{EventListenerLabel: "github-listener"})
+		}
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/ci-overview", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting CI overview: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var groups []CIGroup
+	if err := json.NewDecoder(response.Body).Decode(&groups); err != nil {
+		t.Fatalf("Error decoding CI overview: %v", err)
+	}
+
+	expected := []CIGroup{
+		{
+			Untriggered: true,
+			Latest:      CIRun{Name: "manual", Status: RunSucceeded, Created: now.Add(-30 * time.Minute)},
+			Total:       1,
+			Counts:      map[string]int{RunSucceeded: 1},
+		},
+		{
+			Repository:    "https://github.com/tektoncd/dashboard",
+			EventListener: "github-listener",
+			Latest:        CIRun{Name: "dashboard-2", Status: RunFailed, Created: now.Add(-time.Hour)},
+			Total:         2,
+			Counts:        map[string]int{RunSucceeded: 1, RunFailed: 1},
+		},
+		{
+			Repository:    "https://github.com/tektoncd/pipeline",
+			EventListener: "github-listener",
+			Latest:        CIRun{Name: "pipeline-1", Status: RunSucceeded, Created: now.Add(-2 * time.Hour)},
+			Total:         1,
+			Counts:        map[string]int{RunSucceeded: 1},
+		},
+	}
+	if len(groups) != len(expected) {
+		t.Fatalf("Expected %d groups, actual %+v", len(expected), groups)
+	}
+	for i := range expected {
+		if !groups[i].Latest.Created.Equal(expected[i].Latest.Created) {
+			t.Errorf("Group %d: expected latest created %s, actual %s", i, expected[i].Latest.Created, groups[i].Latest.Created)
+		}
+		groups[i].Latest.Created = expected[i].Latest.Created
+		if !reflect.DeepEqual(groups[i], expected[i]) {
+			t.Errorf("Group %d: expected %+v, actual %+v", i, expected[i], groups[i])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	restful "github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Sources of an effective param value
+const (
+	ParamExplicit = "explicit"
+	ParamDefault  = "default"
+	ParamUnset    = "unset"
+)
+
+// EffectiveParam is the value a TaskRun or PipelineRun param resolved to and
+// whether it was set on the run or taken from the declared default
+type EffectiveParam struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// GetTaskRunParams returns the params of a TaskRun merged over the defaults
+// declared by its inline taskSpec or referenced Task or ClusterTask
+func (r Resource) GetTaskRunParams(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	taskRun, err := r.getTektonResource("taskruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	declared, err := r.declaredParams(taskRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	specParams, _, _ := unstructured.NestedSlice(taskRun.Object, "spec", "params")
+	response.WriteEntity(effectiveParams(declared, specParams))
+}
+
+// GetPipelineRunParams returns the params of a PipelineRun merged over the
+// defaults declared by its inline pipelineSpec or referenced Pipeline
+func (r Resource) GetPipelineRunParams(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	declared := []map[string]interface{}{}
+	params, _, _ := unstructured.NestedSlice(pipelineSpec, "params")
+	for _, p := range params {
+		if param, ok := p.(map[string]interface{}); ok {
+			declared = append(declared, param)
+		}
+	}
+	specParams, _, _ := unstructured.NestedSlice(pipelineRun.Object, "spec", "params")
+	response.WriteEntity(effectiveParams(declared, specParams))
+}
+
+// effectiveParams merges the params set on a run over the declared params
+// and their defaults, in declaration order
+func effectiveParams(declared []map[string]interface{}, specParams []interface{}) []EffectiveParam {
+	explicit := map[string]interface{}{}
+	var explicitOrder []string
+	for _, p := range specParams {
+		param, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paramName, _ := param["name"].(string)
+		explicit[paramName] = param["value"]
+		explicitOrder = append(explicitOrder, paramName)
+	}
+
+	params := []EffectiveParam{}
+	seen := map[string]bool{}
+	for _, param := range declared {
+		paramName, _ := param["name"].(string)
+		seen[paramName] = true
+		effective := EffectiveParam{Name: paramName, Source: ParamUnset}
+		if value, ok := explicit[paramName]; ok {
+			effective.Value, effective.Source = value, ParamExplicit
+		} else if value, ok := param["default"]; ok {
+			effective.Value, effective.Source = value, ParamDefault
+		}
+		params = append(params, effective)
+	}
+	// Params that are not declared are still reported as passed
+	for _, paramName := range explicitOrder {
+		if !seen[paramName] {
+			params = append(params, EffectiveParam{Name: paramName, Value: explicit[paramName], Source: ParamExplicit})
+		}
+	}
+	return params
+}
+
+// declaredParams returns the params declared by the inline taskSpec of a
+// TaskRun or by the Task or ClusterTask it references
+func (r Resource) declaredParams(taskRun *unstructured.Unstructured) ([]map[string]interface{}, error) {
+	taskSpec, err := r.taskRunTaskSpec(taskRun)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := []map[string]interface{}{}
+	params, _, _ := unstructured.NestedSlice(taskSpec, "params")
+	for _, p := range params {
+		if param, ok := p.(map[string]interface{}); ok {
+			declared = append(declared, param)
+		}
+	}
+	return declared, nil
+}
+
+// taskRunTaskSpec returns the inline taskSpec of a TaskRun or the spec of the
+// Task or ClusterTask it references
+func (r Resource) taskRunTaskSpec(taskRun *unstructured.Unstructured) (map[string]interface{}, error) {
+	if taskSpec, found, _ := unstructured.NestedMap(taskRun.Object, "spec", "taskSpec"); found {
+		return taskSpec, nil
+	}
+	refName, _, _ := unstructured.NestedString(taskRun.Object, "spec", "taskRef", "name")
+	refKind, _, _ := unstructured.NestedString(taskRun.Object, "spec", "taskRef", "kind")
+	var task *unstructured.Unstructured
+	var err error
+	if refKind == "ClusterTask" {
+		gvr := schema.GroupVersionResource{Group: tektonGroup, Version: "v1beta1", Resource: "clustertasks"}
+		task, err = r.DynamicClient.Resource(gvr).Get(refName, metav1.GetOptions{})
+	} else {
+		task, err = r.getTektonResource("tasks", taskRun.GetNamespace(), refName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	taskSpec, _, _ := unstructured.NestedMap(task.Object, "spec")
+	return taskSpec, nil
+}
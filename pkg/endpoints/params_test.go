@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET TaskRun params merges explicit values over the Task's defaults
+func TestGETTaskRunParams(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	declared := []interface{}{
+		map[string]interface{}{"name": "image", "default": "alpine"},
+		map[string]interface{}{"name": "tag", "default": "latest"},
+	}
+	task := testutils.GetObject("v1beta1", "Task", namespace, "build", "1")
+	task.Object["spec"] = map[string]interface{}{"params": declared}
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	overrides := []interface{}{map[string]interface{}{"name": "tag", "value": "v1.0"}}
+	referenced := testutils.GetObject("v1beta1", "TaskRun", namespace, "referenced", "1")
+	referenced.Object["spec"] = map[string]interface{}{
+		"taskRef": map[string]interface{}{"name": "build"},
+		"params":  overrides,
+	}
+	inline := testutils.GetObject("v1beta1", "TaskRun", namespace, "inline", "1")
+	inline.Object["spec"] = map[string]interface{}{
+		"taskSpec": map[string]interface{}{"params": declared},
+		"params":   overrides,
+	}
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for _, object := range []*unstructured.Unstructured{referenced, inline} {
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+		taskRun := object.GetName()
+
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/%s/params", server.URL, namespace, taskRun), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting params: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected statusCode %d, actual %d", taskRun, http.StatusOK, response.StatusCode)
+		}
+		var params []EffectiveParam
+		if err := json.NewDecoder(response.Body).Decode(&params); err != nil {
+			t.Fatalf("Error decoding params: %v", err)
+		}
+		expected := []EffectiveParam{
+			{Name: "image", Value: "alpine", Source: ParamDefault},
+			{Name: "tag", Value: "v1.0", Source: ParamExplicit},
+		}
+		if !reflect.DeepEqual(params, expected) {
+			t.Errorf("%s: expected params %+v, actual %+v", taskRun, expected, params)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/missing/params", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting params: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
+
+// GET PipelineRun effective params merges explicit values over the Pipeline's
+// defaults
+func TestGETPipelineRunParams(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	declared := []interface{}{
+		map[string]interface{}{"name": "revision", "default": "main"},
+		map[string]interface{}{"name": "registry", "default": "docker.io"},
+		map[string]interface{}{"name": "token"},
+	}
+	pipeline := testutils.GetObject("v1beta1", "Pipeline", namespace, "release", "1")
+	pipeline.Object["spec"] = map[string]interface{}{"params": declared}
+	pipelines := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	if _, err := r.DynamicClient.Resource(pipelines).Namespace(namespace).Create(pipeline, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipeline: %v", err)
+	}
+
+	overrides := []interface{}{map[string]interface{}{"name": "revision", "value": "v1.2.0"}}
+	referenced := testutils.GetObject("v1beta1", "PipelineRun", namespace, "referenced", "1")
+	referenced.Object["spec"] = map[string]interface{}{
+		"pipelineRef": map[string]interface{}{"name": "release"},
+		"params":      overrides,
+	}
+	inline := testutils.GetObject("v1beta1", "PipelineRun", namespace, "inline", "1")
+	inline.Object["spec"] = map[string]interface{}{
+		"pipelineSpec": map[string]interface{}{"params": declared},
+		"params":       overrides,
+	}
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, object := range []*unstructured.Unstructured{referenced, inline} {
+		if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+		pipelineRun := object.GetName()
+
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/%s/effective-params", server.URL, namespace, pipelineRun), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting params: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected statusCode %d, actual %d", pipelineRun, http.StatusOK, response.StatusCode)
+		}
+		var params []EffectiveParam
+		if err := json.NewDecoder(response.Body).Decode(&params); err != nil {
+			t.Fatalf("Error decoding params: %v", err)
+		}
+		expected := []EffectiveParam{
+			{Name: "revision", Value: "v1.2.0", Source: ParamExplicit},
+			{Name: "registry", Value: "docker.io", Source: ParamDefault},
+			{Name: "token", Source: ParamUnset},
+		}
+		if !reflect.DeepEqual(params, expected) {
+			t.Errorf("%s: expected params %+v, actual %+v", pipelineRun, expected, params)
+		}
+	}
+}
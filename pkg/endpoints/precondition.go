@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IfMatchHeader carries the resourceVersion of the object a client expects to
+// update, as read from the object it is editing
+const IfMatchHeader = "If-Match"
+
+const jsonPatchType = "application/json-patch+json"
+
+// ifMatchVersion returns the resourceVersion a request is conditional on,
+// accepting plain, quoted and weak ETag forms. A wildcard matches any version
+func ifMatchVersion(request *http.Request) string {
+	value := strings.TrimSpace(request.Header.Get(IfMatchHeader))
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	if value == "*" {
+		return ""
+	}
+	return value
+}
+
+// withResourceVersion sets the resourceVersion in the body of an update or
+// patch so the API server rejects the write with a Conflict if the object has
+// changed since. JSON patches get an extra operation, other bodies are merged
+func withResourceVersion(body []byte, contentType, resourceVersion string) ([]byte, error) {
+	if strings.HasPrefix(contentType, jsonPatchType) {
+		var operations []interface{}
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch: %v", err)
+		}
+		operations = append(operations, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/resourceVersion",
+			"value": resourceVersion,
+		})
+		return json.Marshal(operations)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = resourceVersion
+	return json.Marshal(object)
+}
+
+// preconditionWriter reports a Conflict from the API server as 412
+// Precondition Failed, as the write was conditional on If-Match
+type preconditionWriter struct {
+	http.ResponseWriter
+}
+
+func (w preconditionWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusConflict {
+		statusCode = http.StatusPreconditionFailed
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w preconditionWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	"k8s.io/client-go/rest"
+)
+
+// Updates through the proxy conditional on a stale resourceVersion fail with 412
+func TestProxyIfMatch(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	// The API server only accepts writes of the current resourceVersion
+	const currentVersion = "2"
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var object struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+		}
+		json.NewDecoder(req.Body).Decode(&object)
+		if version := object.Metadata.ResourceVersion; version != "" && version != currentVersion {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiServer.Close()
+	r.Config = &rest.Config{Host: apiServer.URL}
+	r.HttpClient = http.DefaultClient
+	server.Config.Handler = router.Register(*r)
+
+	tests := []struct {
+		ifMatch  string
+		expected int
+	}{
+		{"", http.StatusOK},
+		{`"2"`, http.StatusOK},
+		{"1", http.StatusPreconditionFailed},
+	}
+	for _, test := range tests {
+		url := fmt.Sprintf("%s/proxy/apis/tekton.dev/v1beta1/namespaces/%s/pipelines/build", server.URL, namespace)
+		httpReq := testutils.DummyHTTPRequest("PUT", url, strings.NewReader(`{"metadata": {"name": "build"}}`))
+		if test.ifMatch != "" {
+			httpReq.Header.Set(IfMatchHeader, test.ifMatch)
+		}
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error updating through the proxy: %v", err)
+		}
+		if response.StatusCode != test.expected {
+			t.Errorf("If-Match '%s': expected statusCode %d, actual %d", test.ifMatch, test.expected, response.StatusCode)
+		}
+	}
+}
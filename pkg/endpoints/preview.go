@@ -0,0 +1,291 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// implicitVolumeMounts are the volumes Tekton mounts in every step container
+var implicitVolumeMounts = []corev1.VolumeMount{
+	{Name: "tekton-internal-workspace", MountPath: "/workspace"},
+	{Name: "tekton-internal-home", MountPath: "/tekton/home"},
+	{Name: "tekton-internal-results", MountPath: "/tekton/results"},
+	{Name: "tekton-internal-steps", MountPath: "/tekton/steps"},
+	{Name: "tekton-internal-tools", MountPath: "/tekton/tools"},
+	{Name: "tekton-internal-downward", MountPath: "/tekton/downward", ReadOnly: true},
+}
+
+const scriptsVolumeName = "tekton-internal-scripts"
+
+// PreviewTaskRunPod dry-run creates the TaskRun in the body, so it is
+// defaulted and validated by the admission webhooks, and returns the pod
+// Tekton would create for it. Tekton does not create pods for dry-run
+// TaskRuns so the pod is synthesized from the TaskRun and its Task, with the
+// steps, workspaces and volumes Tekton injects
+func (r Resource) PreviewTaskRunPod(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	taskRun := &unstructured.Unstructured{}
+	if err := request.ReadEntity(&taskRun.Object); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if _, found := taskRun.Object["spec"]; !found {
+		utils.RespondErrorMessage(response, "the TaskRun must have a spec", http.StatusBadRequest)
+		return
+	}
+	taskRun.SetAPIVersion(tektonGroup + "/" + r.Options.GetTektonVersion())
+	taskRun.SetKind("TaskRun")
+	taskRun.SetNamespace(namespace)
+	if taskRun.GetName() == "" && taskRun.GetGenerateName() == "" {
+		taskRun.SetGenerateName("preview-")
+	}
+
+	defaulted, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).Create(taskRun, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+			status = http.StatusBadRequest
+		case k8serrors.IsAlreadyExists(err):
+			status = http.StatusConflict
+		}
+		utils.RespondError(response, err, status)
+		return
+	}
+	taskSpec, err := r.taskRunTaskSpec(defaulted)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pod, err := previewPod(defaulted, taskSpec)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	response.WriteEntity(pod)
+}
+
+// previewPod builds the pod of a TaskRun running the given Task spec
+func previewPod(taskRun *unstructured.Unstructured, taskSpec map[string]interface{}) (*corev1.Pod, error) {
+	name := taskRun.GetName()
+	if name == "" {
+		name = taskRun.GetGenerateName()
+	}
+	workspacePaths := map[string]string{}
+	declaredWorkspaces, _, _ := unstructured.NestedSlice(taskSpec, "workspaces")
+	for _, w := range declaredWorkspaces {
+		workspace, _ := w.(map[string]interface{})
+		workspaceName, _ := workspace["name"].(string)
+		mountPath, _ := workspace["mountPath"].(string)
+		if mountPath == "" {
+			mountPath = "/workspace/" + workspaceName
+		}
+		workspacePaths[workspaceName] = mountPath
+	}
+	taskSpec, err := substituteVariables(taskRun, taskSpec, name, workspacePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec corev1.PodSpec
+	if podTemplate, found, _ := unstructured.NestedMap(taskRun.Object, "spec", "podTemplate"); found {
+		if err := convertMap(podTemplate, &spec); err != nil {
+			return nil, fmt.Errorf("invalid podTemplate: %v", err)
+		}
+	}
+	spec.RestartPolicy = corev1.RestartPolicyNever
+	spec.ServiceAccountName, _, _ = unstructured.NestedString(taskRun.Object, "spec", "serviceAccountName")
+	if spec.ServiceAccountName == "" {
+		spec.ServiceAccountName = "default"
+	}
+	for _, mount := range implicitVolumeMounts {
+		volume := corev1.Volume{Name: mount.Name, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+		if mount.Name == "tekton-internal-downward" {
+			volume.VolumeSource = corev1.VolumeSource{DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{{Path: "ready", FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations['tekton.dev/ready']"}}},
+			}}
+		}
+		spec.Volumes = append(spec.Volumes, volume)
+	}
+
+	// Workspaces are mounted in every step from the volumes bound by the TaskRun
+	var workspaceMounts []corev1.VolumeMount
+	bindings, _, _ := unstructured.NestedSlice(taskRun.Object, "spec", "workspaces")
+	for _, b := range bindings {
+		binding, _ := b.(map[string]interface{})
+		workspaceName, _ := binding["name"].(string)
+		mountPath, declared := workspacePaths[workspaceName]
+		if !declared {
+			continue
+		}
+		volume := corev1.Volume{Name: "ws-" + workspaceName}
+		if err := convertMap(binding, &volume.VolumeSource); err != nil {
+			return nil, fmt.Errorf("invalid workspace '%s': %v", workspaceName, err)
+		}
+		subPath, _ := binding["subPath"].(string)
+		spec.Volumes = append(spec.Volumes, volume)
+		workspaceMounts = append(workspaceMounts, corev1.VolumeMount{Name: volume.Name, MountPath: mountPath, SubPath: subPath})
+	}
+	taskVolumes, _, _ := unstructured.NestedSlice(taskSpec, "volumes")
+	for _, v := range taskVolumes {
+		var volume corev1.Volume
+		if err := convertMap(v, &volume); err != nil {
+			return nil, fmt.Errorf("invalid volume: %v", err)
+		}
+		spec.Volumes = append(spec.Volumes, volume)
+	}
+
+	steps, _, _ := unstructured.NestedSlice(taskSpec, "steps")
+	hasScripts := false
+	for i, s := range steps {
+		step, _ := s.(map[string]interface{})
+		container, err := stepContainer(step, "step", i)
+		if err != nil {
+			return nil, err
+		}
+		if script, _ := step["script"].(string); script != "" {
+			hasScripts = true
+			container.Command = []string{fmt.Sprintf("/tekton/scripts/script-%d", i)}
+			container.Args = nil
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: scriptsVolumeName, MountPath: "/tekton/scripts"})
+		}
+		if container.WorkingDir == "" {
+			container.WorkingDir = "/workspace"
+		}
+		container.VolumeMounts = append(container.VolumeMounts, implicitVolumeMounts...)
+		container.VolumeMounts = append(container.VolumeMounts, workspaceMounts...)
+		spec.Containers = append(spec.Containers, container)
+	}
+	if hasScripts {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{Name: scriptsVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+	}
+	sidecars, _, _ := unstructured.NestedSlice(taskSpec, "sidecars")
+	for i, s := range sidecars {
+		step, _ := s.(map[string]interface{})
+		container, err := stepContainer(step, "sidecar", i)
+		if err != nil {
+			return nil, err
+		}
+		spec.Containers = append(spec.Containers, container)
+	}
+
+	labels := map[string]string{}
+	for key, value := range taskRun.GetLabels() {
+		labels[key] = value
+	}
+	labels["app.kubernetes.io/managed-by"] = "tekton-pipelines"
+	labels["tekton.dev/taskRun"] = name
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: taskRun.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: spec,
+	}, nil
+}
+
+// stepContainer converts a step or sidecar to the container running it,
+// named with the prefix Tekton gives it
+func stepContainer(step map[string]interface{}, prefix string, index int) (corev1.Container, error) {
+	var container corev1.Container
+	if err := convertMap(step, &container); err != nil {
+		return container, fmt.Errorf("invalid %s %d: %v", prefix, index, err)
+	}
+	// v1 steps declare their resources as computeResources
+	if resources, found := step["computeResources"]; found {
+		if err := convertMap(resources, &container.Resources); err != nil {
+			return container, fmt.Errorf("invalid %s %d resources: %v", prefix, index, err)
+		}
+	}
+	if container.Name == "" {
+		container.Name = fmt.Sprintf("unnamed-%d", index)
+	}
+	container.Name = prefix + "-" + container.Name
+	return container, nil
+}
+
+// substituteVariables replaces the params, workspace paths and TaskRun
+// context variables in a Task spec with their values for the TaskRun. Array
+// and object params are left as they are
+func substituteVariables(taskRun *unstructured.Unstructured, taskSpec map[string]interface{}, name string, workspacePaths map[string]string) (map[string]interface{}, error) {
+	values := map[string]string{
+		"context.taskRun.name":      name,
+		"context.taskRun.namespace": taskRun.GetNamespace(),
+	}
+	declared, _, _ := unstructured.NestedSlice(taskSpec, "params")
+	for _, p := range declared {
+		param, _ := p.(map[string]interface{})
+		paramName, _ := param["name"].(string)
+		if value, ok := param["default"].(string); ok {
+			values["params."+paramName] = value
+		}
+	}
+	params, _, _ := unstructured.NestedSlice(taskRun.Object, "spec", "params")
+	for _, p := range params {
+		param, _ := p.(map[string]interface{})
+		paramName, _ := param["name"].(string)
+		if value, ok := param["value"].(string); ok {
+			values["params."+paramName] = value
+		}
+	}
+	for workspaceName, path := range workspacePaths {
+		values["workspaces."+workspaceName+".path"] = path
+	}
+
+	encoded, err := json.Marshal(taskSpec)
+	if err != nil {
+		return nil, err
+	}
+	substituted := string(encoded)
+	for variable, value := range values {
+		escaped, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		// Strip the quotes of the JSON string to substitute within strings
+		escapedValue := string(escaped[1 : len(escaped)-1])
+		substituted = strings.ReplaceAll(substituted, "$("+variable+")", escapedValue)
+		if strings.HasPrefix(variable, "params.") {
+			substituted = strings.ReplaceAll(substituted, "$(inputs."+variable+")", escapedValue)
+		}
+	}
+	result := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(substituted), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// convertMap decodes an unstructured value into a typed object through JSON
+func convertMap(value interface{}, into interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, into)
+}
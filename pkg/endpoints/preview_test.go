@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// POST TaskRun pod preview returns a container per step of the Task with its
+// params and workspaces resolved
+func TestPOSTPreviewTaskRunPod(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	task := testutils.GetObject("tekton.dev/v1beta1", "Task", namespace, "build", "1")
+	task.Object["spec"] = map[string]interface{}{
+		"params":     []interface{}{map[string]interface{}{"name": "image", "default": "golang:1.15"}},
+		"workspaces": []interface{}{map[string]interface{}{"name": "source"}},
+		"steps": []interface{}{
+			map[string]interface{}{"name": "compile", "image": "$(params.image)", "command": []interface{}{"go"}, "args": []interface{}{"build", "$(workspaces.source.path)/..."}},
+			map[string]interface{}{"name": "report", "image": "alpine", "script": "echo done"},
+		},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	body := []byte(`{
+		"metadata": {"name": "build-preview"},
+		"spec": {
+			"taskRef": {"name": "build"},
+			"params": [{"name": "image", "value": "golang:1.16"}],
+			"workspaces": [{"name": "source", "persistentVolumeClaim": {"claimName": "sources"}}],
+			"podTemplate": {"nodeSelector": {"disktype": "ssd"}}
+		}
+	}`)
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/preview-pod", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error previewing pod: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var pod corev1.Pod
+	if err := json.NewDecoder(response.Body).Decode(&pod); err != nil {
+		t.Fatalf("Error decoding pod: %v", err)
+	}
+
+	if pod.Name != "build-preview-pod" || pod.Labels["tekton.dev/taskRun"] != "build-preview" {
+		t.Errorf("Expected pod build-preview-pod labelled with its TaskRun, actual %s %v", pod.Name, pod.Labels)
+	}
+	if len(pod.Spec.Containers) != 2 {
+		t.Fatalf("Expected 2 step containers, actual %+v", pod.Spec.Containers)
+	}
+	compile := pod.Spec.Containers[0]
+	if compile.Name != "step-compile" || compile.Image != "golang:1.16" {
+		t.Errorf("Expected step-compile with image golang:1.16, actual %s %s", compile.Name, compile.Image)
+	}
+	if expected := []string{"build", "/workspace/source/..."}; !reflect.DeepEqual(compile.Args, expected) {
+		t.Errorf("Expected args %v, actual %v", expected, compile.Args)
+	}
+	if report := pod.Spec.Containers[1]; report.Name != "step-report" || !reflect.DeepEqual(report.Command, []string{"/tekton/scripts/script-1"}) {
+		t.Errorf("Expected step-report running its script, actual %s %v", report.Name, report.Command)
+	}
+	mounted := false
+	for _, mount := range compile.VolumeMounts {
+		if mount.Name == "ws-source" && mount.MountPath == "/workspace/source" {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("Expected the source workspace mounted at /workspace/source, actual %+v", compile.VolumeMounts)
+	}
+	claimed := false
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == "ws-source" && volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == "sources" {
+			claimed = true
+		}
+	}
+	if !claimed {
+		t.Errorf("Expected a ws-source volume for claim sources, actual %+v", pod.Spec.Volumes)
+	}
+	if pod.Spec.NodeSelector["disktype"] != "ssd" || pod.Spec.RestartPolicy != corev1.RestartPolicyNever || pod.Spec.ServiceAccountName != "default" {
+		t.Errorf("Expected the podTemplate node selector, restart policy Never and the default service account, actual %+v", pod.Spec)
+	}
+}
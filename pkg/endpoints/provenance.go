@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+// ChainsSignedAnnotation is set by Tekton Chains once a run has been signed
+const ChainsSignedAnnotation = "chains.tekton.dev/signed"
+
+// ChainsPayloadAnnotationPrefix prefixes the annotations Tekton Chains uses to
+// store the base64 encoded attestation payloads of a run. Signatures and
+// certificates use different prefixes and are never read here
+const ChainsPayloadAnnotationPrefix = "chains.tekton.dev/payload-"
+
+// Provenance is the decoded Tekton Chains attestation of a run
+type Provenance struct {
+	Signed    bool            `json:"signed"`
+	Statement json.RawMessage `json:"statement"`
+}
+
+// dsseEnvelope is the DSSE wrapper Chains may store around the in-toto statement
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+}
+
+// GetTaskRunProvenance returns the in-toto statement Tekton Chains attached to
+// a TaskRun, or 404 if the TaskRun has no provenance
+func (r Resource) GetTaskRunProvenance(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	taskRun, err := r.getTektonResource("taskruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	annotations := taskRun.GetAnnotations()
+	var payloadKeys []string
+	for key := range annotations {
+		if strings.HasPrefix(key, ChainsPayloadAnnotationPrefix) {
+			payloadKeys = append(payloadKeys, key)
+		}
+	}
+	if len(payloadKeys) == 0 {
+		utils.RespondError(response, fmt.Errorf("no provenance found for TaskRun %s", name), http.StatusNotFound)
+		return
+	}
+	// Annotation iteration order is random, always report the same payload
+	sort.Strings(payloadKeys)
+
+	statement, err := decodeAttestation(annotations[payloadKeys[0]])
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	response.WriteEntity(Provenance{
+		Signed:    annotations[ChainsSignedAnnotation] == "true",
+		Statement: statement,
+	})
+}
+
+// decodeAttestation decodes a base64 attestation payload, unwrapping a DSSE
+// envelope if present, and returns the in-toto statement
+func decodeAttestation(encoded string) (json.RawMessage, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding provenance payload: %w", err)
+	}
+	if !json.Valid(decoded) {
+		return nil, errors.New("provenance payload is not valid JSON")
+	}
+
+	var envelope dsseEnvelope
+	if err := json.Unmarshal(decoded, &envelope); err == nil && envelope.PayloadType != "" && envelope.Payload != "" {
+		return decodeAttestation(envelope.Payload)
+	}
+	return json.RawMessage(decoded), nil
+}
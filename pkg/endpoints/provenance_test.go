@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET TaskRun provenance decodes the Chains attestation annotation
+func TestGETTaskRunProvenance(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2"}`
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "signed", "1")
+	taskRun.SetAnnotations(map[string]string{
+		ChainsSignedAnnotation:                          "true",
+		ChainsPayloadAnnotationPrefix + "taskrun-12345": base64.StdEncoding.EncodeToString([]byte(statement)),
+		"chains.tekton.dev/signature-taskrun-12345":     "c2lnbmF0dXJl",
+	})
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	unsigned := testutils.GetObject("v1beta1", "TaskRun", namespace, "unsigned", "1")
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(unsigned, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/signed/provenance", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting provenance: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var provenance Provenance
+	if err := json.NewDecoder(response.Body).Decode(&provenance); err != nil {
+		t.Fatalf("Error decoding provenance: %v", err)
+	}
+	if !provenance.Signed {
+		t.Error("Expected provenance to be reported as signed")
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, provenance.Statement); err != nil {
+		t.Fatalf("Error compacting statement: %v", err)
+	}
+	if compacted.String() != statement {
+		t.Errorf("Expected statement %s, actual %s", statement, compacted.String())
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/unsigned/provenance", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting provenance: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected statusCode %d for TaskRun without provenance, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
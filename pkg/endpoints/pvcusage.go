@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TaskRunLabel is the label Tekton sets on the pods and workspace volume claims
+// created for a TaskRun
+const TaskRunLabel = "tekton.dev/taskRun"
+
+// PVCUsage is the storage requested by the volume claims Tekton created for
+// the workspaces of runs in a namespace
+type PVCUsage struct {
+	Requested      string           `json:"requested"`
+	RequestedBytes int64            `json:"requestedBytes"`
+	Runs           []RunPVCUsage    `json:"runs"`
+	Claims         []WorkspaceClaim `json:"claims"`
+}
+
+// RunPVCUsage is the storage requested for the workspaces of a run, TaskRuns
+// of a PipelineRun are counted against the PipelineRun
+type RunPVCUsage struct {
+	Kind           string   `json:"kind"`
+	Name           string   `json:"name"`
+	Requested      string   `json:"requested"`
+	RequestedBytes int64    `json:"requestedBytes"`
+	Claims         []string `json:"claims"`
+	requested      resource.Quantity
+}
+
+// WorkspaceClaim is a volume claim created for a workspace of a run. Orphaned
+// claims outlived the run that created them
+type WorkspaceClaim struct {
+	Name           string `json:"name"`
+	OwnerKind      string `json:"ownerKind"`
+	Owner          string `json:"owner"`
+	Requested      string `json:"requested"`
+	RequestedBytes int64  `json:"requestedBytes"`
+	Phase          string `json:"phase,omitempty"`
+	Orphaned       bool   `json:"orphaned"`
+}
+
+// GetPVCUsage sums the storage requested by the workspace volume claims of the
+// runs in a namespace, grouped by the PipelineRun or TaskRun owning them
+func (r Resource) GetPVCUsage(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+
+	claims, err := r.K8sClient.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	existingPipelineRuns := map[string]bool{}
+	for _, pipelineRun := range pipelineRuns.Items {
+		existingPipelineRuns[pipelineRun.GetName()] = true
+	}
+	// PipelineRuns of the existing TaskRuns, empty for standalone TaskRuns
+	existingTaskRuns := map[string]string{}
+	for _, taskRun := range taskRuns.Items {
+		existingTaskRuns[taskRun.GetName()] = taskRun.GetLabels()[PipelineRunLabel]
+	}
+
+	usage := PVCUsage{Runs: []RunPVCUsage{}, Claims: []WorkspaceClaim{}}
+	var total resource.Quantity
+	runs := map[[2]string]*RunPVCUsage{}
+	sort.Slice(claims.Items, func(i, j int) bool {
+		return claims.Items[i].Name < claims.Items[j].Name
+	})
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		ownerKind, owner, ok := workspaceClaimOwner(pvc)
+		if !ok {
+			continue
+		}
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		claim := WorkspaceClaim{
+			Name:           pvc.Name,
+			OwnerKind:      ownerKind,
+			Owner:          owner,
+			Requested:      requested.String(),
+			RequestedBytes: requested.Value(),
+			Phase:          string(pvc.Status.Phase),
+		}
+		total.Add(requested)
+
+		runKind, run := ownerKind, owner
+		switch ownerKind {
+		case "PipelineRun":
+			claim.Orphaned = !existingPipelineRuns[owner]
+		case "TaskRun":
+			pipelineRun, exists := existingTaskRuns[owner]
+			claim.Orphaned = !exists
+			if pipelineRun != "" && existingPipelineRuns[pipelineRun] {
+				runKind, run = "PipelineRun", pipelineRun
+			}
+		}
+		usage.Claims = append(usage.Claims, claim)
+		if claim.Orphaned {
+			continue
+		}
+		key := [2]string{runKind, run}
+		if runs[key] == nil {
+			runs[key] = &RunPVCUsage{Kind: runKind, Name: run, Claims: []string{}}
+		}
+		runs[key].requested.Add(requested)
+		runs[key].Claims = append(runs[key].Claims, pvc.Name)
+	}
+
+	for _, run := range runs {
+		run.Requested = run.requested.String()
+		run.RequestedBytes = run.requested.Value()
+		usage.Runs = append(usage.Runs, *run)
+	}
+	sort.Slice(usage.Runs, func(i, j int) bool {
+		a, b := usage.Runs[i], usage.Runs[j]
+		if a.RequestedBytes != b.RequestedBytes {
+			return a.RequestedBytes > b.RequestedBytes
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	usage.Requested = total.String()
+	usage.RequestedBytes = total.Value()
+
+	response.WriteEntity(usage)
+}
+
+// workspaceClaimOwner returns the run a volume claim was created for, from its
+// Tekton owner reference or labels
+func workspaceClaimOwner(pvc *corev1.PersistentVolumeClaim) (kind, name string, ok bool) {
+	for _, owner := range pvc.OwnerReferences {
+		groupVersion, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil || groupVersion.Group != tektonGroup {
+			continue
+		}
+		if owner.Kind == "PipelineRun" || owner.Kind == "TaskRun" {
+			return owner.Kind, owner.Name, true
+		}
+	}
+	if pipelineRun := pvc.Labels[PipelineRunLabel]; pipelineRun != "" {
+		return "PipelineRun", pipelineRun, true
+	}
+	if taskRun := pvc.Labels[TaskRunLabel]; taskRun != "" {
+		return "TaskRun", taskRun, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PVC usage sums workspace claims by run and flags orphaned claims
+func TestGETPVCUsage(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(testutils.GetObject("v1beta1", "PipelineRun", namespace, "build", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	fetch := testutils.GetObject("v1beta1", "TaskRun", namespace, "build-fetch", "1")
+	fetch.SetLabels(map[string]string{PipelineRunLabel: "build"})
+	for _, taskRun := range []*unstructured.Unstructured{fetch, testutils.GetObject("v1beta1", "TaskRun", namespace, "solo", "1")} {
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	claim := func(name, size string, owner *metav1.OwnerReference, labels map[string]string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+		if owner != nil {
+			pvc.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		return pvc
+	}
+	owner := func(kind, name string) *metav1.OwnerReference {
+		return &metav1.OwnerReference{APIVersion: "tekton.dev/v1beta1", Kind: kind, Name: name}
+	}
+	for _, pvc := range []*corev1.PersistentVolumeClaim{
+		claim("pvc-build", "1Gi", owner("PipelineRun", "build"), nil),
+		claim("pvc-fetch", "512Mi", nil, map[string]string{TaskRunLabel: "build-fetch"}),
+		claim("pvc-solo", "2Gi", owner("TaskRun", "solo"), nil),
+		claim("pvc-gone", "5Gi", owner("PipelineRun", "gone"), nil),
+		claim("data", "10Gi", nil, nil),
+	} {
+		if _, err := r.K8sClient.CoreV1().PersistentVolumeClaims(namespace).Create(pvc); err != nil {
+			t.Fatalf("Error creating pvc: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pvc-usage", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting pvc usage: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var usage PVCUsage
+	if err := json.NewDecoder(response.Body).Decode(&usage); err != nil {
+		t.Fatalf("Error decoding pvc usage: %v", err)
+	}
+	const mi = 1024 * 1024
+	expected := PVCUsage{
+		Requested:      "8704Mi",
+		RequestedBytes: 8704 * mi,
+		Runs: []RunPVCUsage{
+			{Kind: "TaskRun", Name: "solo", Requested: "2Gi", RequestedBytes: 2048 * mi, Claims: []string{"pvc-solo"}},
+			{Kind: "PipelineRun", Name: "build", Requested: "1536Mi", RequestedBytes: 1536 * mi, Claims: []string{"pvc-build", "pvc-fetch"}},
+		},
+		Claims: []WorkspaceClaim{
+			{Name: "pvc-build", OwnerKind: "PipelineRun", Owner: "build", Requested: "1Gi", RequestedBytes: 1024 * mi, Phase: "Bound"},
+			{Name: "pvc-fetch", OwnerKind: "TaskRun", Owner: "build-fetch", Requested: "512Mi", RequestedBytes: 512 * mi, Phase: "Bound"},
+			{Name: "pvc-gone", OwnerKind: "PipelineRun", Owner: "gone", Requested: "5Gi", RequestedBytes: 5120 * mi, Phase: "Bound", Orphaned: true},
+			{Name: "pvc-solo", OwnerKind: "TaskRun", Owner: "solo", Requested: "2Gi", RequestedBytes: 2048 * mi, Phase: "Bound"},
+		},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("Expected usage %+v, actual %+v", expected, usage)
+	}
+}
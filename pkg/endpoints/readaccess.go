@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+)
+
+// readAccess caches whether a user may get the objects of a kind in a
+// namespace for the lifetime of a websocket subscription
+type readAccess struct {
+	resource Resource
+	ctx      context.Context
+	user     string
+	mutex    sync.Mutex
+	allowed  map[string]bool
+}
+
+// readAccessFilter returns a websocket filter only accepting the events of
+// objects the user may get, so the event stream does not bypass RBAC. Events
+// of namespaced objects whose kind cannot be checked are withheld, as are
+// messages the filter does not know. OwnershipChanged messages are checked
+// against the object they describe. Cluster scoped objects are checked
+// without a namespace. Events of extensions, and ResyncRequired messages,
+// which only name a kind, are accepted
+func (r Resource) readAccessFilter(ctx context.Context, user string) broadcaster.Filter[broadcaster.SocketData] {
+	access := &readAccess{resource: r, ctx: ctx, user: user, allowed: map[string]bool{}}
+	return access.accepts
+}
+
+func (a *readAccess) accepts(data broadcaster.SocketData) bool {
+	if change, ok := data.Payload.(broadcaster.OwnershipChange); ok {
+		return a.check(change.Kind, change.Namespace, change.Name)
+	}
+	if _, ok := data.Payload.(broadcaster.ResyncMarker); ok {
+		return true
+	}
+	if extensionEvent(data.MessageType) {
+		// Extensions are listed to every user
+		return true
+	}
+	kind, ok := eventKind(data.MessageType)
+	if !ok {
+		return false
+	}
+	var namespace string
+	if reference, isReference := data.Payload.(broadcaster.ObjectReference); isReference {
+		namespace = reference.Namespace
+	} else if object, hasMeta := payloadMeta(data.Payload); hasMeta {
+		namespace = object.GetNamespace()
+	} else {
+		return false
+	}
+	return a.check(kind, namespace, "")
+}
+
+// check asks the Authorizer once per kind, namespace and name, an empty name
+// checks the whole namespace and an empty namespace a cluster scoped kind.
+// The Authorizer is called without holding the lock, so a slow review does
+// not hold up other checks. Errors are not cached so the next event asks
+// again
+func (a *readAccess) check(kind, namespace, name string) bool {
+	key := kind + "/" + namespace + "/" + name
+	a.mutex.Lock()
+	allowed, found := a.allowed[key]
+	a.mutex.Unlock()
+	if found {
+		return allowed
+	}
+	resourceKind, known := lookupAnyKind(a.resource.Options, kind)
+	if !known {
+		a.store(key, false)
+		return false
+	}
+	allowed, err := a.resource.Authorizer.Authorize(a.ctx, a.user, "get", resourceKind.Kind, namespace, name)
+	if err != nil {
+		logging.Log.Errorf("Error authorizing get %s for user '%s': %s", resourceKind.Kind, a.user, err)
+		return false
+	}
+	a.store(key, allowed)
+	return allowed
+}
+
+func (a *readAccess) store(key string, allowed bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.allowed[key] = allowed
+}
+
+// eventKind returns the kind of the object of a Created, Updated or Deleted
+// event
+func eventKind(messageType broadcaster.MessageType) (string, bool) {
+	for _, suffix := range []string{"Created", "Updated", "Deleted"} {
+		if kind := strings.TrimSuffix(string(messageType), suffix); kind != string(messageType) {
+			return kind, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const defaultRecentWindow = 10 * time.Minute
+
+// RecentChange is an object modified within the requested window, tagged with its kind
+type RecentChange struct {
+	Kind     string                     `json:"kind"`
+	Modified metav1.Time                `json:"modified"`
+	Object   *unstructured.Unstructured `json:"object"`
+}
+
+// GetRecentChanges returns the Tekton resources in a namespace modified within
+// the window given by the since query parameter, most recent first. Objects
+// are read from the informer caches, kinds without one such as
+// ResolutionRequests are listed from the API server
+func (r Resource) GetRecentChanges(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	window, err := durationParameter(request, "since", defaultRecentWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	cutoff := time.Now().Add(-window)
+
+	changes := []RecentChange{}
+	for _, kind := range namespacedKinds(r.Options) {
+		if allowed, _ := r.authorized(request, "list", kind.Kind, namespace, ""); !allowed {
+			continue
+		}
+		objects, err := r.listKind(kind, namespace)
+		if err != nil {
+			// Optional components such as Triggers may not be installed
+			logging.Log.Debugf("Skipping %s for recent changes: %s", kind.Kind, err)
+			continue
+		}
+		for _, object := range objects {
+			modified := lastModified(object)
+			if modified.Before(cutoff) {
+				continue
+			}
+			changes = append(changes, RecentChange{
+				Kind:     kind.Kind,
+				Modified: metav1.NewTime(modified),
+				Object:   object,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].Modified.Equal(&changes[j].Modified) {
+			return changes[i].Modified.After(changes[j].Modified.Time)
+		}
+		return resourceVersionAfter(changes[i].Object.GetResourceVersion(), changes[j].Object.GetResourceVersion())
+	})
+
+	response.WriteEntity(changes)
+}
+
+// lastModified returns the latest managedFields timestamp of an object,
+// falling back to its creationTimestamp
+func lastModified(object metav1.Object) time.Time {
+	modified := object.GetCreationTimestamp().Time
+	for _, entry := range object.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(modified) {
+			modified = entry.Time.Time
+		}
+	}
+	return modified
+}
+
+// resourceVersionAfter reports whether resourceVersion a is newer than b.
+// Resource versions are opaque but numeric in practice, non-numeric values
+// are compared as strings
+func resourceVersionAfter(a, b string) bool {
+	aVersion, aErr := strconv.ParseUint(a, 10, 64)
+	bVersion, bErr := strconv.ParseUint(b, 10, 64)
+	if aErr != nil || bErr != nil {
+		return a > b
+	}
+	return aVersion > bVersion
+}
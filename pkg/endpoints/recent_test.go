@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET recent changes only returns objects modified within the window
+func TestGETRecentChanges(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	create := func(resource, kind, name string, created time.Time) {
+		object := testutils.GetObject("v1beta1", kind, namespace, name, "1")
+		object.SetCreationTimestamp(metav1.NewTime(created))
+		gvr := schema.GroupVersionResource{
+			Group:    "tekton.dev",
+			Version:  "v1beta1",
+			Resource: resource,
+		}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating %s: %v", kind, err)
+		}
+	}
+	now := time.Now()
+	create("tasks", "Task", "old-task", now.Add(-time.Hour))
+	create("tasks", "Task", "recent-task", now.Add(-2*time.Minute))
+	create("pipelineruns", "PipelineRun", "recent-run", now.Add(-time.Minute))
+	// Recent changes are read from the informer caches
+	awaitFatal(func() bool {
+		tasks, _ := r.Listers["Task"].ByNamespace(namespace).List(labels.Everything())
+		runs, _ := r.Listers["PipelineRun"].ByNamespace(namespace).List(labels.Everything())
+		return len(tasks) == 2 && len(runs) == 1
+	}, t, "Expected objects in the informer caches")
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/recent?since=10m", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting recent changes: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var changes []RecentChange
+	if err := json.NewDecoder(response.Body).Decode(&changes); err != nil {
+		t.Fatalf("Error decoding recent changes: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 recent changes, actual %d", len(changes))
+	}
+	if changes[0].Kind != "PipelineRun" || changes[0].Object.GetName() != "recent-run" {
+		t.Errorf("Expected most recent change to be PipelineRun recent-run, actual %s %s", changes[0].Kind, changes[0].Object.GetName())
+	}
+	if changes[1].Kind != "Task" || changes[1].Object.GetName() != "recent-task" {
+		t.Errorf("Expected second change to be Task recent-task, actual %s %s", changes[1].Kind, changes[1].Object.GetName())
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/recent?since=soon", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting recent changes: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d for invalid since, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
+
+// GET recent changes across all namespaces leaves out denied namespaces
+func TestGETRecentChangesDeniedNamespace(t *testing.T) {
+	server, r, namespace := testutils.DummyServerWithOptions(Options{DeniedNamespaces: []string{"hidden"}})
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	for _, task := range []*unstructured.Unstructured{
+		testutils.GetObject("v1beta1", "Task", namespace, "visible-task", "1"),
+		testutils.GetObject("v1beta1", "Task", "hidden", "hidden-task", "1"),
+	} {
+		task.SetCreationTimestamp(metav1.Now())
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(task.GetNamespace()).Create(task, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+	awaitFatal(func() bool {
+		tasks, _ := r.Listers["Task"].List(labels.Everything())
+		return len(tasks) == 2
+	}, t, "Expected objects in the informer caches")
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/*/recent", server.URL), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting recent changes: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var changes []RecentChange
+	if err := json.NewDecoder(response.Body).Decode(&changes); err != nil {
+		t.Fatalf("Error decoding recent changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Object.GetName() != "visible-task" {
+		t.Errorf("Expected only visible-task, actual %+v", changes)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/redact"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+)
+
+// RedactResponses is a filter masking the fields of the objects in JSON
+// responses with the Resource's Redactor. Websocket upgrades are left alone,
+// their events are redacted by the broadcasters
+func (r Resource) RedactResponses(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+	if r.Redactor.Empty() || websocket.IsUpgradeRequest(request.Request) {
+		chain.ProcessFilter(request, response)
+		return
+	}
+	watch, _ := strconv.ParseBool(request.QueryParameter("watch"))
+	writer := &redactingWriter{ResponseWriter: response.ResponseWriter, redactor: r.Redactor, watch: watch}
+	response.ResponseWriter = writer
+	chain.ProcessFilter(request, response)
+	writer.finish()
+}
+
+// redactingWriter buffers JSON bodies to redact them once complete, or each
+// line of a watch or NDJSON stream as it is written. Other bodies are written as they are
+type redactingWriter struct {
+	http.ResponseWriter
+	redactor    *redact.Redactor
+	watch       bool
+	wroteHeader bool
+	json        bool
+	buffer      bytes.Buffer
+}
+
+func (w *redactingWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	contentType := w.Header().Get("Content-Type")
+	w.json = strings.Contains(contentType, "json")
+	// NDJSON lists are streamed like watches, one object per line
+	w.watch = w.watch || strings.Contains(contentType, MIMENDJSON)
+	if w.json {
+		// The length changes with the redacted values
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *redactingWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.json {
+		return w.ResponseWriter.Write(data)
+	}
+	w.buffer.Write(data)
+	if w.watch {
+		if err := w.writeLines(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// writeLines redacts and writes the complete lines of a watch stream, each is
+// an event
+func (w *redactingWriter) writeLines() error {
+	for {
+		end := bytes.IndexByte(w.buffer.Bytes(), '\n')
+		if end < 0 {
+			return nil
+		}
+		line := w.buffer.Next(end + 1)
+		if _, err := w.ResponseWriter.Write(w.redactor.JSON(line)); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *redactingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish writes what is left of a buffered body
+func (w *redactingWriter) finish() {
+	if w.buffer.Len() > 0 {
+		w.ResponseWriter.Write(w.redactor.JSON(w.buffer.Bytes()))
+	}
+}
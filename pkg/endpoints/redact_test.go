@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/redact"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Values at the redacted paths are masked in REST responses and websocket
+// events
+func TestRedactPaths(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Redactor = redact.NewRedactor([]string{"spec.env.value"})
+	server.Config.Handler = router.Register(*r)
+	ResourcesBroadcaster.SetRedactor(r.Redactor)
+	defer ResourcesBroadcaster.SetRedactor(nil)
+
+	websocketURL := url.URL{
+		Scheme: "ws",
+		Host:   strings.TrimPrefix(server.URL, "http://"),
+		Path:   "/v1/websockets/resources",
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected resources client within pool")
+
+	stepAction := testutils.GetObject("v1beta1", "StepAction", namespace, "deploy", "1")
+	unstructured.SetNestedSlice(stepAction.Object, []interface{}{
+		map[string]interface{}{"name": "TOKEN", "value": "s3cr3t"},
+	}, "spec", "env")
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "stepactions"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(stepAction, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating stepAction: %v", err)
+	}
+
+	redactedEnv := func(object map[string]interface{}) (string, string) {
+		env, _, _ := unstructured.NestedSlice(object, "spec", "env")
+		if len(env) != 1 {
+			return "", ""
+		}
+		variable, _ := env[0].(map[string]interface{})
+		name, _ := variable["name"].(string)
+		value, _ := variable["value"].(string)
+		return name, value
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading StepAction event: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		if socketData.MessageType != broadcaster.StepActionCreated || payloadName(socketData) != "deploy" {
+			continue
+		}
+		payload, _ := socketData.Payload.(map[string]interface{})
+		if name, value := redactedEnv(payload); name != "TOKEN" || value != redact.Marker {
+			t.Errorf("Expected websocket event with env TOKEN=%s, actual %s=%s", redact.Marker, name, value)
+		}
+		break
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Expected empty pool after closing")
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/stepactions/deploy", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting stepAction: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding stepAction: %v", err)
+	}
+	if name, value := redactedEnv(body); name != "TOKEN" || value != redact.Marker {
+		t.Errorf("Expected REST response with env TOKEN=%s, actual %s=%s", redact.Marker, name, value)
+	}
+
+	// The stored object is left unchanged
+	stored, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Get("deploy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting stepAction: %v", err)
+	}
+	if _, value := redactedEnv(stored.Object); value != "s3cr3t" {
+		t.Errorf("Expected stored env value s3cr3t, actual %s", value)
+	}
+}
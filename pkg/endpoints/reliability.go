@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TaskLabel is set by Tekton on the TaskRuns of a Task to its name
+const TaskLabel = "tekton.dev/task"
+
+const defaultReliabilityWindow = 7 * 24 * time.Hour
+
+// TaskReliability is how often the TaskRuns of a Task completed within a
+// window succeeded. SuccessRate is a percentage of the succeeded and failed
+// runs, unset when there are none. Cancelled runs do not count towards it
+type TaskReliability struct {
+	Task            string   `json:"task"`
+	Succeeded       int      `json:"succeeded"`
+	Failed          int      `json:"failed"`
+	Cancelled       int      `json:"cancelled"`
+	SuccessRate     *float64 `json:"successRate,omitempty"`
+	AverageDuration float64  `json:"averageDurationSeconds"`
+}
+
+// GetTaskReliability counts the outcomes of the TaskRuns of a Task that
+// completed within the window, to find flaky Tasks. Runs in progress are
+// skipped
+func (r Resource) GetTaskReliability(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	window, err := durationParameter(request, "window", defaultReliabilityWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: TaskLabel + "=" + name}
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(listOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	cutoff := time.Now().Add(-window)
+	reliability := TaskReliability{Task: name}
+	var total time.Duration
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		completed, ok := nestedTime(taskRun, "status", "completionTime")
+		if !ok || completed.Before(cutoff) {
+			continue
+		}
+		switch runStatus(taskRun) {
+		case RunSucceeded:
+			reliability.Succeeded++
+		case RunFailed:
+			reliability.Failed++
+		case RunCancelled:
+			reliability.Cancelled++
+			continue
+		default:
+			continue
+		}
+		total += runDuration(taskRun)
+	}
+	if runs := reliability.Succeeded + reliability.Failed; runs > 0 {
+		rate := 100 * float64(reliability.Succeeded) / float64(runs)
+		reliability.SuccessRate = &rate
+		reliability.AverageDuration = (total / time.Duration(runs)).Seconds()
+	}
+
+	response.WriteEntity(reliability)
+}
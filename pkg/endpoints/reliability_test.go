@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET Task reliability counts the outcomes of the Task's completed TaskRuns
+func TestGETTaskReliability(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	now := time.Now()
+	taskRuns := []struct {
+		name      string
+		task      string
+		status    string
+		reason    string
+		duration  time.Duration
+		completed time.Time
+	}{
+		{"succeeded-1", "build", "True", "Succeeded", time.Minute, now.Add(-time.Hour)},
+		{"succeeded-2", "build", "True", "Succeeded", 2 * time.Minute, now.Add(-2 * time.Hour)},
+		{"failed", "build", "False", "Failed", 90 * time.Second, now.Add(-3 * time.Hour)},
+		{"cancelled", "build", "False", "TaskRunCancelled", time.Minute, now.Add(-time.Hour)},
+		{"running", "build", "Unknown", "Running", time.Minute, time.Time{}},
+		{"succeeded-old", "build", "True", "Succeeded", time.Minute, now.Add(-8 * 24 * time.Hour)},
+		{"other", "test", "False", "Failed", time.Minute, now.Add(-time.Hour)},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for _, run := range taskRuns {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, run.name, "1")
+		taskRun.SetLabels(map[string]string{TaskLabel: run.task})
+		end := run.completed
+		if end.IsZero() {
+			end = now
+		} else {
+			unstructured.SetNestedField(taskRun.Object, run.completed.UTC().Format(time.RFC3339), "status", "completionTime")
+		}
+		unstructured.SetNestedField(taskRun.Object, end.Add(-run.duration).UTC().Format(time.RFC3339), "status", "startTime")
+		unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status, "reason": run.reason},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	url := fmt.Sprintf("%s/v1/namespaces/%s/tasks/build/reliability?window=7d", server.URL, namespace)
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting Task reliability: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var reliability TaskReliability
+	if err := json.NewDecoder(response.Body).Decode(&reliability); err != nil {
+		t.Fatalf("Error decoding reliability: %v", err)
+	}
+	if reliability.Succeeded != 2 || reliability.Failed != 1 || reliability.Cancelled != 1 {
+		t.Errorf("Expected 2 succeeded, 1 failed and 1 cancelled, actual %+v", reliability)
+	}
+	if reliability.SuccessRate == nil || math.Abs(*reliability.SuccessRate-200.0/3) > 0.01 {
+		t.Errorf("Expected a success rate of 66.67%%, actual %v", reliability.SuccessRate)
+	}
+	if reliability.AverageDuration != 90 {
+		t.Errorf("Expected an average duration of 90s, actual %vs", reliability.AverageDuration)
+	}
+
+	url = fmt.Sprintf("%s/v1/namespaces/%s/tasks/deploy/reliability", server.URL, namespace)
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", url, nil))
+	if err != nil {
+		t.Fatalf("Error getting Task reliability: %v", err)
+	}
+	reliability = TaskReliability{}
+	if err := json.NewDecoder(response.Body).Decode(&reliability); err != nil {
+		t.Fatalf("Error decoding reliability: %v", err)
+	}
+	if reliability.SuccessRate != nil {
+		t.Errorf("Expected no success rate without runs, actual %v", *reliability.SuccessRate)
+	}
+}
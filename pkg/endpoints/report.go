@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// reportResults are the results of a PipelineRun and of each of its TaskRuns
+type reportResults struct {
+	PipelineRun interface{}            `json:"pipelineRun"`
+	TaskRuns    map[string]interface{} `json:"taskRuns"`
+}
+
+// reportFile is a file of a report whose content is only read when it is
+// written to the bundle
+type reportFile struct {
+	name    string
+	content func() ([]byte, error)
+}
+
+// reportBundle writes the files of a report as a gzipped tarball. Files are
+// written under a directory named after the run
+type reportBundle struct {
+	root    string
+	modTime time.Time
+	gzip    *gzip.Writer
+	tar     *tar.Writer
+}
+
+func newReportBundle(writer io.Writer, root string) *reportBundle {
+	gzipWriter := gzip.NewWriter(writer)
+	return &reportBundle{
+		root:    root,
+		modTime: time.Now(),
+		gzip:    gzipWriter,
+		tar:     tar.NewWriter(gzipWriter),
+	}
+}
+
+func (b *reportBundle) add(name string, content []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(b.root, name),
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: b.modTime,
+	}
+	if err := b.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := b.tar.Write(content)
+	return err
+}
+
+// addOrPlaceholder adds the file, or a placeholder explaining why its content
+// is missing so one unavailable piece does not fail the whole report
+func (b *reportBundle) addOrPlaceholder(name string, content []byte, err error) error {
+	if err != nil {
+		logging.Log.Debugf("Report file %s unavailable: %s", name, err)
+		content = []byte(fmt.Sprintf("unavailable: %s\n", err))
+	}
+	return b.add(name, content)
+}
+
+func (b *reportBundle) close() error {
+	if err := b.tar.Close(); err != nil {
+		return err
+	}
+	return b.gzip.Close()
+}
+
+// GetPipelineRunReport returns a tar.gz bundle of a PipelineRun for support
+// tickets, containing the run YAML, the results, the logs of each step of its
+// TaskRuns and the related events
+func (r Resource) GetPipelineRunReport(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	taskRuns, taskRunsErr := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: PipelineRunLabel + "=" + name,
+	})
+	if taskRunsErr != nil {
+		taskRuns = &unstructured.UnstructuredList{}
+	}
+
+	// The archive is not JSON so RedactResponses leaves it alone, the objects
+	// are redacted here instead. Copies are redacted as the logs are found
+	// from the original TaskRuns
+	redactedRun := pipelineRun.DeepCopy()
+	r.Redactor.Object(redactedRun.Object)
+	redactedTaskRuns := make([]unstructured.Unstructured, len(taskRuns.Items))
+	for i := range taskRuns.Items {
+		taskRuns.Items[i].DeepCopyInto(&redactedTaskRuns[i])
+		r.Redactor.Object(redactedTaskRuns[i].Object)
+	}
+
+	// The bundle is streamed to the client so a failure after this point can
+	// only be logged, the archive is then truncated
+	response.AddHeader("Content-Type", "application/gzip")
+	response.AddHeader("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-%s-report.tar.gz\"", namespace, name))
+	bundle := newReportBundle(response, name)
+	files := []reportFile{
+		{"pipelinerun.yaml", func() ([]byte, error) {
+			return yaml.Marshal(redactedRun.Object)
+		}},
+		{"results.json", func() ([]byte, error) {
+			if taskRunsErr != nil {
+				return nil, taskRunsErr
+			}
+			return json.MarshalIndent(collectResults(redactedRun, redactedTaskRuns), "", "  ")
+		}},
+		{"events.json", func() ([]byte, error) {
+			return r.relatedEvents(namespace, pipelineRun, taskRuns.Items)
+		}},
+	}
+	if err := r.writeReport(bundle, files, taskRunsErr, taskRuns.Items); err != nil {
+		logging.Log.Errorf("Error writing report for PipelineRun %s/%s: %s", namespace, name, err)
+	}
+}
+
+// writeReport writes the files, the TaskRun logs and the end of the archive
+// to the bundle
+func (r Resource) writeReport(bundle *reportBundle, files []reportFile, taskRunsErr error, taskRuns []unstructured.Unstructured) error {
+	for _, file := range files {
+		content, err := file.content()
+		if err := bundle.addOrPlaceholder(file.name, content, err); err != nil {
+			return err
+		}
+	}
+	if taskRunsErr != nil {
+		if err := bundle.addOrPlaceholder("logs/taskruns.log", nil, taskRunsErr); err != nil {
+			return err
+		}
+	}
+	for i := range taskRuns {
+		if err := r.addTaskRunLogs(bundle, &taskRuns[i]); err != nil {
+			return err
+		}
+	}
+	return bundle.close()
+}
+
+// collectResults collects the results of a PipelineRun and its TaskRuns
+func collectResults(pipelineRun *unstructured.Unstructured, taskRuns []unstructured.Unstructured) reportResults {
+	results := reportResults{TaskRuns: map[string]interface{}{}}
+	results.PipelineRun = runResults(pipelineRun)
+	for i := range taskRuns {
+		results.TaskRuns[taskRuns[i].GetName()] = runResults(&taskRuns[i])
+	}
+	return results
+}
+
+// relatedEvents returns the redacted events involving the PipelineRun, its
+// TaskRuns or their pods
+func (r Resource) relatedEvents(namespace string, pipelineRun *unstructured.Unstructured, taskRuns []unstructured.Unstructured) ([]byte, error) {
+	involved := map[string]bool{pipelineRun.GetName(): true}
+	for i := range taskRuns {
+		involved[taskRuns[i].GetName()] = true
+		if podName, _, _ := unstructured.NestedString(taskRuns[i].Object, "status", "podName"); podName != "" {
+			involved[podName] = true
+		}
+	}
+	events, err := r.K8sClient.CoreV1().Events(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	related := []interface{}{}
+	for i := range events.Items {
+		if involved[events.Items[i].InvolvedObject.Name] {
+			related = append(related, r.Redactor.Payload(&events.Items[i]))
+		}
+	}
+	return json.MarshalIndent(related, "", "  ")
+}
+
+// addTaskRunLogs adds the log of each step of the TaskRun as
+// logs/<taskrun>/<step>.log
+func (r Resource) addTaskRunLogs(bundle *reportBundle, taskRun *unstructured.Unstructured) error {
+	logDir := path.Join("logs", taskRun.GetName())
+	pod, err := r.taskRunPod(taskRun)
+	if err != nil {
+		return bundle.addOrPlaceholder(logDir+".log", nil, err)
+	}
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+			continue
+		}
+		content, err := r.readContainerLogs(pod.Namespace, pod.Name, container.Name)
+		fileName := path.Join(logDir, strings.TrimPrefix(container.Name, stepContainerPrefix)+".log")
+		if err := bundle.addOrPlaceholder(fileName, content, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readContainerLogs reads the complete log of a container
+func (r Resource) readContainerLogs(namespace, pod, container string) ([]byte, error) {
+	stream, err := openPodLogs(r.K8sClient, namespace, pod, &corev1.PodLogOptions{Container: container})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return ioutil.ReadAll(stream)
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/redact"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclientset "k8s.io/client-go/kubernetes"
+)
+
+// stubPodLogs serves the given logs keyed by container name until the
+// returned function is called
+func stubPodLogs(logs map[string]string) func() {
+	original := PodLogs
+	PodLogs = func(client k8sclientset.Interface, namespace, pod string, options *corev1.PodLogOptions) (io.ReadCloser, error) {
+		log, ok := logs[options.Container]
+		if !ok {
+			return nil, errors.New("container not found")
+		}
+		return ioutil.NopCloser(strings.NewReader(log)), nil
+	}
+	return func() {
+		PodLogs = original
+	}
+}
+
+// readReport returns the content of each file of a report keyed by path
+func readReport(t *testing.T, response *http.Response) map[string]string {
+	gzipReader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Fatalf("Error reading gzip: %v", err)
+	}
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Error reading tar: %v", err)
+		}
+		content, _ := ioutil.ReadAll(tarReader)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+// GET PipelineRun report bundles the run, results, events and step logs
+func TestGETPipelineRunReport(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{"step-build": "building\n"})()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "release", "1")
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	taskRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for _, name := range []string{"release-build", "release-pending"} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "release"})
+		if name == "release-build" {
+			unstructured.SetNestedField(taskRun.Object, "release-build-pod", "status", "podName")
+		}
+		if _, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "release-build-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-build"}, {Name: "step-push"}},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/release/report", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting report: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	expectedDisposition := fmt.Sprintf("attachment; filename=\"%s-release-report.tar.gz\"", namespace)
+	if disposition := response.Header.Get("Content-Disposition"); disposition != expectedDisposition {
+		t.Errorf("Expected Content-Disposition %s, actual %s", expectedDisposition, disposition)
+	}
+
+	files := readReport(t, response)
+	for _, name := range []string{
+		"release/pipelinerun.yaml",
+		"release/results.json",
+		"release/events.json",
+		"release/logs/release-build/build.log",
+		"release/logs/release-build/push.log",
+		"release/logs/release-pending.log",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in report, found %v", name, files)
+		}
+	}
+	if log := files["release/logs/release-build/build.log"]; log != "building\n" {
+		t.Errorf("Expected build step log, actual %q", log)
+	}
+	if placeholder := files["release/logs/release-build/push.log"]; !strings.HasPrefix(placeholder, "unavailable: ") {
+		t.Errorf("Expected placeholder for missing log, actual %q", placeholder)
+	}
+}
+
+// Values at the redacted paths are masked in the run, results and events of
+// the report
+func TestGETPipelineRunReportRedacted(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Redactor = redact.NewRedactor([]string{"spec.params.value", "status.pipelineResults.value", "status.taskResults.value", "message"})
+	server.Config.Handler = router.Register(*r)
+	defer stubPodLogs(map[string]string{})()
+
+	secret := "s3cr3t"
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "deploy", "1")
+	unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+		map[string]interface{}{"name": "token", "value": secret},
+	}, "spec", "params")
+	unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+		map[string]interface{}{"name": "token", "value": secret},
+	}, "status", "pipelineResults")
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "deploy-login", "1")
+	taskRun.SetLabels(map[string]string{PipelineRunLabel: "deploy"})
+	unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+		map[string]interface{}{"name": "token", "value": secret},
+	}, "status", "taskResults")
+	taskRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	event := corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "deploy-event", Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "PipelineRun", Namespace: namespace, Name: "deploy"},
+		Message:        "logged in with " + secret,
+	}
+	if _, err := r.K8sClient.CoreV1().Events(namespace).Create(&event); err != nil {
+		t.Fatalf("Error creating event: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/deploy/report", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting report: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	files := readReport(t, response)
+	for _, name := range []string{"deploy/pipelinerun.yaml", "deploy/results.json", "deploy/events.json"} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("Expected %s in report, found %v", name, files)
+			continue
+		}
+		if strings.Contains(content, secret) {
+			t.Errorf("Expected %s redacted, actual %s", name, content)
+		}
+		if !strings.Contains(content, redact.Marker) {
+			t.Errorf("Expected %s in %s, actual %s", redact.Marker, name, content)
+		}
+	}
+}
+
+// Results of v1 runs, which are in status.results, are reported too
+func TestGETPipelineRunReportV1Results(t *testing.T) {
+	server, r, namespace := testutils.DummyServerWithOptions(Options{TektonVersion: "v1"})
+	defer server.Close()
+	defer stubPodLogs(map[string]string{})()
+
+	pipelineRun := testutils.GetObject("v1", "PipelineRun", namespace, "release", "1")
+	unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+		map[string]interface{}{"name": "version", "value": "1.2.3"},
+	}, "status", "results")
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	taskRun := testutils.GetObject("v1", "TaskRun", namespace, "release-build", "1")
+	taskRun.SetLabels(map[string]string{PipelineRunLabel: "release"})
+	unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+		map[string]interface{}{"name": "digest", "value": "sha256:abc"},
+	}, "status", "results")
+	taskRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/release/report", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting report: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var results struct {
+		PipelineRun []map[string]string            `json:"pipelineRun"`
+		TaskRuns    map[string][]map[string]string `json:"taskRuns"`
+	}
+	if err := json.Unmarshal([]byte(readReport(t, response)["release/results.json"]), &results); err != nil {
+		t.Fatalf("Error decoding results: %v", err)
+	}
+	if len(results.PipelineRun) != 1 || results.PipelineRun[0]["value"] != "1.2.3" {
+		t.Errorf("Expected PipelineRun result version, actual %v", results.PipelineRun)
+	}
+	if taskResults := results.TaskRuns["release-build"]; len(taskResults) != 1 || taskResults[0]["value"] != "sha256:abc" {
+		t.Errorf("Expected TaskRun result digest, actual %v", results.TaskRuns)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const defaultRerunWindow = time.Hour
+
+// Reruns are named after the run they rerun followed by rerunIdentifier, and
+// labelled with its name, as done by the frontend
+const (
+	rerunIdentifier = "-r-"
+	RerunsLabel     = "reruns"
+)
+
+// RerunResult is the outcome of rerunning a PipelineRun, Rerun is the name
+// of the PipelineRun created
+type RerunResult struct {
+	Source string `json:"source"`
+	Rerun  string `json:"rerun,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RerunFailedPipelineRuns reruns the PipelineRuns of a namespace that failed
+// within the window, reporting the outcome per run. Cancelled runs are not
+// rerun. The confirm query parameter must be true so many runs are not
+// started by accident
+func (r Resource) RerunFailedPipelineRuns(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	window, err := durationParameter(request, "window", defaultRerunWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if request.QueryParameter("confirm") != "true" {
+		utils.RespondErrorMessage(response, "confirm=true is required to rerun failed PipelineRuns", http.StatusBadRequest)
+		return
+	}
+
+	pipelineRuns := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace)
+	list, err := pipelineRuns.List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	cutoff := time.Now().Add(-window)
+	results := []RerunResult{}
+	for i := range list.Items {
+		pipelineRun := &list.Items[i]
+		if runStatus(pipelineRun) != RunFailed {
+			continue
+		}
+		completed, ok := nestedTime(pipelineRun, "status", "completionTime")
+		if !ok {
+			completed = pipelineRun.GetCreationTimestamp().Time
+		}
+		if completed.Before(cutoff) {
+			continue
+		}
+		result := RerunResult{Source: pipelineRun.GetName(), Status: http.StatusCreated}
+		created, err := pipelineRuns.Create(newRerun(pipelineRun), metav1.CreateOptions{})
+		if err != nil {
+			result.Status = errorStatus(err)
+			result.Error = err.Error()
+		} else {
+			result.Rerun = created.GetName()
+		}
+		results = append(results, result)
+	}
+
+	response.WriteEntity(results)
+}
+
+// newRerun returns a PipelineRun with the spec of a previous run, named after
+// it and keeping its labels and annotations
+func newRerun(pipelineRun *unstructured.Unstructured) *unstructured.Unstructured {
+	rerun := pipelineRun.DeepCopy()
+	labels := pipelineRun.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	// Set by Tekton from the pipelineRef
+	delete(labels, PipelineLabel)
+	labels[RerunsLabel] = pipelineRun.GetName()
+
+	root := pipelineRun.GetName()
+	if i := strings.LastIndex(root, rerunIdentifier); i != -1 {
+		root = root[:i]
+	}
+	rerun.Object["metadata"] = map[string]interface{}{}
+	rerun.SetNamespace(pipelineRun.GetNamespace())
+	rerun.SetGenerateName(root + rerunIdentifier)
+	rerun.SetLabels(labels)
+	rerun.SetAnnotations(pipelineRun.GetAnnotations())
+	delete(rerun.Object, "status")
+	unstructured.RemoveNestedField(rerun.Object, "spec", "status")
+	return rerun
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamicclientset "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// POST rerun-failed reruns only the PipelineRuns that failed in the window
+func TestRerunFailedPipelineRuns(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	// The fake client does not generate names
+	r.DynamicClient.(*fakedynamicclientset.FakeDynamicClient).PrependReactor("create", "pipelineruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		object := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if object.GetName() == "" {
+			object.SetName(object.GetGenerateName() + "generated")
+		}
+		return false, nil, nil
+	})
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	now := time.Now()
+	runs := []struct {
+		name      string
+		status    string
+		reason    string
+		completed time.Time
+	}{
+		{"build", "False", "Failed", now.Add(-10 * time.Minute)},
+		{"build-old", "False", "Failed", now.Add(-2 * time.Hour)},
+		{"deploy-r-abcde", "False", "Failed", now.Add(-5 * time.Minute)},
+		{"release", "True", "Succeeded", now.Add(-10 * time.Minute)},
+		{"test", "False", "PipelineRunCancelled", now.Add(-10 * time.Minute)},
+	}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("tekton.dev/v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetLabels(map[string]string{PipelineLabel: "pipeline", "app": "dashboard"})
+		unstructured.SetNestedField(pipelineRun.Object, "pipeline", "spec", "pipelineRef", "name")
+		unstructured.SetNestedField(pipelineRun.Object, run.completed.UTC().Format(time.RFC3339), "status", "completionTime")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status, "reason": run.reason},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	url := fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/rerun-failed?window=1h", server.URL, namespace)
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("POST", url, nil))
+	if err != nil {
+		t.Fatalf("Error rerunning failed PipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d without confirm, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("POST", url+"&confirm=true", nil))
+	if err != nil {
+		t.Fatalf("Error rerunning failed PipelineRuns: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var results []RerunResult
+	if err := json.NewDecoder(response.Body).Decode(&results); err != nil {
+		t.Fatalf("Error decoding results: %v", err)
+	}
+	expected := []RerunResult{
+		{Source: "build", Rerun: "build-r-generated", Status: http.StatusCreated},
+		{Source: "deploy-r-abcde", Rerun: "deploy-r-generated", Status: http.StatusCreated},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %+v, actual %+v", expected, results)
+	}
+
+	rerun, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Get("build-r-generated", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting rerun: %v", err)
+	}
+	expectedLabels := map[string]string{"app": "dashboard", RerunsLabel: "build"}
+	if !reflect.DeepEqual(rerun.GetLabels(), expectedLabels) {
+		t.Errorf("Expected labels %v, actual %v", expectedLabels, rerun.GetLabels())
+	}
+	if _, found := rerun.Object["status"]; found {
+		t.Errorf("Expected rerun without status, actual %v", rerun.Object["status"])
+	}
+	if ref, _, _ := unstructured.NestedString(rerun.Object, "spec", "pipelineRef", "name"); ref != "pipeline" {
+		t.Errorf("Expected rerun of Pipeline 'pipeline', actual '%s'", ref)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resolutionRequestGVR is the resource of the requests made to remote
+// resolvers for the Tasks and Pipelines referenced by runs
+var resolutionRequestGVR = schema.GroupVersionResource{Group: "resolution.tekton.dev", Version: "v1beta1", Resource: "resolutionrequests"}
+
+// RetryResolutionRequest replaces a ResolutionRequest that failed or is stuck
+// with a fresh copy of it, so its resolver tries again. The copy keeps the
+// params, labels and owners of the original
+func (r Resource) RetryResolutionRequest(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	if allowed, err := r.authorized(request, "create", "ResolutionRequest", namespace, ""); !allowed {
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		utils.RespondErrorMessage(response, fmt.Sprintf("user '%s' cannot create ResolutionRequest in namespace %s", request.HeaderParameter(UserHeader), namespace), http.StatusForbidden)
+		return
+	}
+
+	resolutionRequests := r.DynamicClient.Resource(resolutionRequestGVR).Namespace(namespace)
+	original, err := resolutionRequests.Get(name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	if status, _, _, _ := succeededCondition(original); status == "True" {
+		utils.RespondErrorMessage(response, fmt.Sprintf("ResolutionRequest %s is already resolved", name), http.StatusConflict)
+		return
+	}
+
+	retry := freshCopy(original, name, namespace)
+	retry.SetOwnerReferences(original.GetOwnerReferences())
+	uid := original.GetUID()
+	// The precondition fails if the request was replaced in the meantime
+	if err := resolutionRequests.Delete(name, &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}); err != nil && !k8serrors.IsNotFound(err) {
+		if k8serrors.IsConflict(err) {
+			utils.RespondErrorMessage(response, fmt.Sprintf("ResolutionRequest %s was replaced while retrying", name), http.StatusConflict)
+			return
+		}
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	created, err := resolutionRequests.Create(retry, metav1.CreateOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.WriteHeaderAndEntity(http.StatusCreated, created)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// POST retry ResolutionRequest replaces a failed request with a fresh copy,
+// and refuses to retry a resolved one
+func TestPOSTRetryResolutionRequest(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "resolution.tekton.dev", Version: "v1beta1", Resource: "resolutionrequests"}
+	resolutionRequests := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	params := []interface{}{
+		map[string]interface{}{"name": "url", "value": "https://github.com/tektoncd/catalog.git"},
+		map[string]interface{}{"name": "pathInRepo", "value": "task/git-clone/0.9/git-clone.yaml"},
+	}
+	owners := []metav1.OwnerReference{{APIVersion: "tekton.dev/v1beta1", Kind: "TaskRun", Name: "clone", UID: types.UID("taskrun-uid")}}
+	for _, request := range []struct {
+		name   string
+		status string
+	}{
+		{"git-failed", "False"},
+		{"git-resolved", "True"},
+	} {
+		resolutionRequest := testutils.GetObject("resolution.tekton.dev/v1beta1", "ResolutionRequest", namespace, request.name, "1")
+		resolutionRequest.SetUID(types.UID(request.name + "-uid"))
+		resolutionRequest.SetLabels(map[string]string{"resolution.tekton.dev/type": "git"})
+		resolutionRequest.SetOwnerReferences(owners)
+		unstructured.SetNestedSlice(resolutionRequest.Object, params, "spec", "params")
+		unstructured.SetNestedSlice(resolutionRequest.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": request.status, "reason": "ResolutionFailed"},
+		}, "status", "conditions")
+		if _, err := resolutionRequests.Create(resolutionRequest, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating resolutionRequest: %v", err)
+		}
+	}
+
+	retry := func(name string) int {
+		httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/resolutionrequests/%s/retry", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error retrying resolutionRequest: %v", err)
+		}
+		return response.StatusCode
+	}
+
+	if status := retry("git-failed"); status != http.StatusCreated {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusCreated, status)
+	}
+	fresh, err := resolutionRequests.Get("git-failed", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting retried resolutionRequest: %v", err)
+	}
+	if fresh.GetUID() == "git-failed-uid" {
+		t.Errorf("Expected a new resolutionRequest, actual uid %s", fresh.GetUID())
+	}
+	if _, found := fresh.Object["status"]; found {
+		t.Errorf("Expected no status, actual %v", fresh.Object["status"])
+	}
+	if freshParams, _, _ := unstructured.NestedSlice(fresh.Object, "spec", "params"); !reflect.DeepEqual(freshParams, params) {
+		t.Errorf("Expected params %v, actual %v", params, freshParams)
+	}
+	if !reflect.DeepEqual(fresh.GetOwnerReferences(), owners) {
+		t.Errorf("Expected owners %v, actual %v", owners, fresh.GetOwnerReferences())
+	}
+	if expected := map[string]string{"resolution.tekton.dev/type": "git"}; !reflect.DeepEqual(fresh.GetLabels(), expected) {
+		t.Errorf("Expected labels %v, actual %v", expected, fresh.GetLabels())
+	}
+
+	if status := retry("git-resolved"); status != http.StatusConflict {
+		t.Errorf("Expected statusCode %d retrying a resolved request, actual %d", http.StatusConflict, status)
+	}
+	if status := retry("missing"); status != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d retrying a missing request, actual %d", http.StatusNotFound, status)
+	}
+}
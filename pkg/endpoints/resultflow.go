@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// namedResultReference matches a reference to a result of another pipeline
+// task, capturing the task and result names
+var namedResultReference = regexp.MustCompile(`\$\(tasks\.([^.)]+)\.results\.([^.)\[]+)`)
+
+// ResultFlow is a result of a pipeline task consumed by another, with the
+// value its TaskRun emitted. Pending is set until the result is produced
+type ResultFlow struct {
+	Consumer string      `json:"consumer"`
+	Producer string      `json:"producer"`
+	Result   string      `json:"result"`
+	TaskRun  string      `json:"taskRun,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Pending  bool        `json:"pending"`
+}
+
+// GetPipelineRunResultFlow resolves each result reference between the tasks
+// of a PipelineRun to the value produced by the referenced task's TaskRun
+func (r Resource) GetPipelineRunResultFlow(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	producers := map[string]*unstructured.Unstructured{}
+	for i := range taskRuns.Items {
+		producers[taskRuns.Items[i].GetLabels()[PipelineTaskLabel]] = &taskRuns.Items[i]
+	}
+
+	flows := []ResultFlow{}
+	for _, field := range []string{"tasks", "finally"} {
+		tasks, _, _ := unstructured.NestedSlice(pipelineSpec, field)
+		for _, t := range tasks {
+			task, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			consumer, _ := task["name"].(string)
+			for _, reference := range referencedResults(task) {
+				flow := ResultFlow{Consumer: consumer, Producer: reference[0], Result: reference[1], Pending: true}
+				if taskRun, ok := producers[flow.Producer]; ok {
+					var produced bool
+					flow.TaskRun = taskRun.GetName()
+					flow.Value, produced = runResult(taskRun, flow.Result)
+					flow.Pending = !produced
+				}
+				flows = append(flows, flow)
+			}
+		}
+	}
+	response.WriteEntity(flows)
+}
+
+// referencedResults returns the distinct task and result names of the result
+// references in a pipeline task, in order of appearance
+func referencedResults(task map[string]interface{}) [][2]string {
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return nil
+	}
+	var references [][2]string
+	seen := map[[2]string]bool{}
+	for _, match := range namedResultReference.FindAllStringSubmatch(string(encoded), -1) {
+		reference := [2]string{match[1], match[2]}
+		if !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+	return references
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun result flow reports the values passed between tasks
+func TestGETPipelineRunResultFlow(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "release", "1")
+	unstructured.SetNestedMap(pipelineRun.Object, map[string]interface{}{
+		"tasks": []interface{}{
+			map[string]interface{}{"name": "build"},
+			map[string]interface{}{"name": "deploy", "params": []interface{}{
+				map[string]interface{}{"name": "image", "value": "$(tasks.build.results.image)@$(tasks.build.results.digest)"},
+				map[string]interface{}{"name": "tag", "value": "$(tasks.build.results.image)"},
+			}},
+			map[string]interface{}{"name": "notify", "when": []interface{}{
+				map[string]interface{}{"input": "$(tasks.deploy.results.url)", "operator": "notin", "values": []interface{}{""}},
+			}},
+		},
+	}, "status", "pipelineSpec")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "release-build", "1")
+	taskRun.SetLabels(map[string]string{PipelineRunLabel: "release", PipelineTaskLabel: "build"})
+	unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+		map[string]interface{}{"name": "image", "value": "registry/app"},
+		map[string]interface{}{"name": "digest", "value": "sha256:abc"},
+	}, "status", "taskResults")
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/release/result-flow", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting result flow: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var flows []ResultFlow
+	if err := json.NewDecoder(response.Body).Decode(&flows); err != nil {
+		t.Fatalf("Error decoding result flow: %v", err)
+	}
+	expected := []ResultFlow{
+		{Consumer: "deploy", Producer: "build", Result: "image", TaskRun: "release-build", Value: "registry/app"},
+		{Consumer: "deploy", Producer: "build", Result: "digest", TaskRun: "release-build", Value: "sha256:abc"},
+		{Consumer: "notify", Producer: "deploy", Result: "url", Pending: true},
+	}
+	if !reflect.DeepEqual(flows, expected) {
+		t.Errorf("Expected result flow %+v, actual %+v", expected, flows)
+	}
+}
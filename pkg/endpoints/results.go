@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Ways resultValue is compared with the values of results
+const (
+	resultMatchExact  = "exact"
+	resultMatchPrefix = "prefix"
+)
+
+// GetPipelineRuns lists the PipelineRuns of a namespace, newest first. With
+// resultName only the runs that emitted that result are listed, and with
+// resultValue only those whose value matches it, exactly or as a prefix with
+// match=prefix, to find the run that produced an artifact. The runs are
+// written one per line when NDJSON is accepted
+func (r Resource) GetPipelineRuns(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	resultName := request.QueryParameter("resultName")
+	resultValue := request.QueryParameter("resultValue")
+	match := request.QueryParameter("match")
+	if match == "" {
+		match = resultMatchExact
+	}
+	if match != resultMatchExact && match != resultMatchPrefix {
+		utils.RespondErrorMessage(response, fmt.Sprintf("invalid match '%s', must be exact or prefix", match), http.StatusBadRequest)
+		return
+	}
+	if resultValue != "" && resultName == "" {
+		utils.RespondErrorMessage(response, "resultValue requires resultName", http.StatusBadRequest)
+		return
+	}
+
+	kind, _ := lookupKind(r.Options, "PipelineRun")
+	pipelineRuns, err := r.listKind(kind, namespace)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	matching := []*unstructured.Unstructured{}
+	for _, pipelineRun := range pipelineRuns {
+		if resultName != "" {
+			value, ok := runResult(pipelineRun, resultName)
+			if !ok || !resultMatches(value, resultValue, match) {
+				continue
+			}
+		}
+		matching = append(matching, pipelineRun)
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		a, b := matching[i].GetCreationTimestamp(), matching[j].GetCreationTimestamp()
+		if !a.Equal(&b) {
+			return b.Before(&a)
+		}
+		return matching[i].GetName() < matching[j].GetName()
+	})
+	if acceptsNDJSON(request) {
+		writeNDJSON(response, matching)
+		return
+	}
+	response.WriteEntity(matching)
+}
+
+// resultMatches compares the value of a string result, an empty expected
+// value matches any value. Array and object results only match when no value
+// is expected
+func resultMatches(value interface{}, expected, match string) bool {
+	if expected == "" {
+		return true
+	}
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if match == resultMatchPrefix {
+		return strings.HasPrefix(s, expected)
+	}
+	return s == expected
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRuns filtered by result only lists the runs that emitted a
+// matching value
+func TestGETPipelineRunsByResult(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRuns := []struct {
+		name    string
+		field   string
+		results map[string]interface{}
+	}{
+		{"build-1", "pipelineResults", map[string]interface{}{"IMAGE_DIGEST": "sha256:aaaa1111", "IMAGE_URL": "registry/app"}},
+		{"build-2", "pipelineResults", map[string]interface{}{"IMAGE_DIGEST": "sha256:bbbb2222"}},
+		{"build-v1", "results", map[string]interface{}{"IMAGE_DIGEST": "sha256:aaaa3333"}},
+		{"lint", "pipelineResults", map[string]interface{}{"FINDINGS": []interface{}{"sha256:aaaa1111"}}},
+		{"pending", "", nil},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, run := range pipelineRuns {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		var results []interface{}
+		for name, value := range run.results {
+			results = append(results, map[string]interface{}{"name": name, "value": value})
+		}
+		if run.field != "" {
+			unstructured.SetNestedSlice(pipelineRun.Object, results, "status", run.field)
+		}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query    url.Values
+		expected []string
+	}{
+		{url.Values{"resultName": {"IMAGE_DIGEST"}, "resultValue": {"sha256:bbbb2222"}}, []string{"build-2"}},
+		{url.Values{"resultName": {"IMAGE_DIGEST"}, "resultValue": {"sha256:aaaa"}}, []string{}},
+		{url.Values{"resultName": {"IMAGE_DIGEST"}, "resultValue": {"sha256:aaaa"}, "match": {"prefix"}}, []string{"build-1", "build-v1"}},
+		{url.Values{"resultName": {"IMAGE_URL"}}, []string{"build-1"}},
+		{url.Values{}, []string{"build-1", "build-2", "build-v1", "lint", "pending"}},
+	}
+	for _, test := range tests {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns?%s", server.URL, namespace, test.query.Encode()), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error listing pipelineRuns: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var listed []unstructured.Unstructured
+		if err := json.NewDecoder(response.Body).Decode(&listed); err != nil {
+			t.Fatalf("Error decoding pipelineRuns: %v", err)
+		}
+		names := []string{}
+		for _, pipelineRun := range listed {
+			names = append(names, pipelineRun.GetName())
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Expected %v for %s, actual %v", test.expected, test.query.Encode(), names)
+		}
+	}
+
+	for _, query := range []string{"resultValue=sha256:bbbb2222", "resultName=IMAGE_DIGEST&match=suffix"} {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns?%s", server.URL, namespace, query), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error listing pipelineRuns: %v", err)
+		}
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected statusCode %d for %s, actual %d", http.StatusBadRequest, query, response.StatusCode)
+		}
+	}
+}
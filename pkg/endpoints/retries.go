@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RunRetries is how often the tasks of a PipelineRun were retried
+type RunRetries struct {
+	Total int           `json:"total"`
+	Tasks []TaskRetries `json:"tasks"`
+}
+
+// TaskRetries are the retries of the TaskRuns of a pipeline task, out of the
+// retries configured in the Pipeline
+type TaskRetries struct {
+	PipelineTask string         `json:"pipelineTask"`
+	TaskRuns     []string       `json:"taskRuns"`
+	Configured   int64          `json:"configured"`
+	Count        int            `json:"count"`
+	Attempts     []RetryAttempt `json:"attempts"`
+}
+
+// RetryAttempt is a failed attempt of a TaskRun that was retried
+type RetryAttempt struct {
+	TaskRun        string     `json:"taskRun"`
+	Reason         string     `json:"reason"`
+	Message        string     `json:"message,omitempty"`
+	StartTime      *time.Time `json:"startTime,omitempty"`
+	CompletionTime *time.Time `json:"completionTime,omitempty"`
+}
+
+// GetPipelineRunRetries returns the retries of each task of a PipelineRun, in
+// the order the Pipeline declares them, from the retriesStatus of their
+// TaskRuns
+func (r Resource) GetPipelineRunRetries(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	pipelineSpec, err := r.pipelineRunSpec(pipelineRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(taskRuns.Items, func(i, j int) bool {
+		return taskRuns.Items[i].GetName() < taskRuns.Items[j].GetName()
+	})
+
+	retries := RunRetries{Tasks: []TaskRetries{}}
+	tasks := map[string]*TaskRetries{}
+	for _, field := range []string{"tasks", "finally"} {
+		pipelineTasks, _, _ := unstructured.NestedSlice(pipelineSpec, field)
+		for _, t := range pipelineTasks {
+			task, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			pipelineTask, _ := task["name"].(string)
+			configured, _, _ := unstructured.NestedInt64(task, "retries")
+			retries.Tasks = append(retries.Tasks, TaskRetries{PipelineTask: pipelineTask, TaskRuns: []string{}, Configured: configured, Attempts: []RetryAttempt{}})
+		}
+	}
+	for i := range retries.Tasks {
+		tasks[retries.Tasks[i].PipelineTask] = &retries.Tasks[i]
+	}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		task, ok := tasks[taskRun.GetLabels()[PipelineTaskLabel]]
+		if !ok {
+			continue
+		}
+		task.TaskRuns = append(task.TaskRuns, taskRun.GetName())
+		attempts := retryAttempts(taskRun)
+		task.Attempts = append(task.Attempts, attempts...)
+		task.Count += len(attempts)
+		retries.Total += len(attempts)
+	}
+	response.WriteEntity(retries)
+}
+
+// retryAttempts returns the failed attempts recorded in a TaskRun's
+// retriesStatus, oldest first
+func retryAttempts(taskRun *unstructured.Unstructured) []RetryAttempt {
+	statuses, _, _ := unstructured.NestedSlice(taskRun.Object, "status", "retriesStatus")
+	attempts := []RetryAttempt{}
+	for _, s := range statuses {
+		status, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Each entry is a previous TaskRun status
+		previous := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+		_, reason, message, _ := succeededCondition(previous)
+		attempt := RetryAttempt{TaskRun: taskRun.GetName(), Reason: reason, Message: message}
+		if started, ok := nestedTime(previous, "status", "startTime"); ok {
+			attempt.StartTime = &started
+		}
+		if completed, ok := nestedTime(previous, "status", "completionTime"); ok {
+			attempt.CompletionTime = &completed
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun retries counts the retriesStatus of each task's TaskRuns
+func TestGETPipelineRunRetries(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "flaky", "1")
+	unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+		map[string]interface{}{"name": "test", "retries": int64(2)},
+		map[string]interface{}{"name": "build"},
+	}, "spec", "pipelineSpec", "tasks")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Minute)
+	taskRuns := map[string]map[string]interface{}{
+		"test": {
+			"retriesStatus": []interface{}{map[string]interface{}{
+				"startTime":      start.Format(time.RFC3339),
+				"completionTime": end.Format(time.RFC3339),
+				"conditions": []interface{}{map[string]interface{}{
+					"type": "Succeeded", "status": "False", "reason": "Failed", "message": "step test exited with 1",
+				}},
+			}},
+		},
+		"build": {},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for pipelineTask, status := range taskRuns {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "flaky-"+pipelineTask, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "flaky", PipelineTaskLabel: pipelineTask})
+		unstructured.SetNestedField(taskRun.Object, status, "status")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/flaky/retries", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting retries: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var retries RunRetries
+	if err := json.NewDecoder(response.Body).Decode(&retries); err != nil {
+		t.Fatalf("Error decoding retries: %v", err)
+	}
+	expected := RunRetries{
+		Total: 1,
+		Tasks: []TaskRetries{
+			{
+				PipelineTask: "test",
+				TaskRuns:     []string{"flaky-test"},
+				Configured:   2,
+				Count:        1,
+				Attempts: []RetryAttempt{{
+					TaskRun:        "flaky-test",
+					Reason:         "Failed",
+					Message:        "step test exited with 1",
+					StartTime:      &start,
+					CompletionTime: &end,
+				}},
+			},
+			{PipelineTask: "build", TaskRuns: []string{"flaky-build"}, Attempts: []RetryAttempt{}},
+		},
+	}
+	if !reflect.DeepEqual(retries, expected) {
+		t.Errorf("Expected retries %+v, actual %+v", expected, retries)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RunPipeline is the Pipeline a PipelineRun was created from. Inline is true
+// when the run embeds its spec, otherwise Name is the referenced Pipeline and
+// Spec its current spec
+type RunPipeline struct {
+	Inline bool                   `json:"inline"`
+	Name   string                 `json:"name,omitempty"`
+	Spec   map[string]interface{} `json:"spec"`
+}
+
+// GetPipelineRunPipeline returns the current spec of the Pipeline referenced
+// by a PipelineRun, or the spec embedded in the run
+func (r Resource) GetPipelineRunPipeline(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	if spec, found, _ := unstructured.NestedMap(pipelineRun.Object, "spec", "pipelineSpec"); found {
+		response.WriteEntity(RunPipeline{Inline: true, Spec: spec})
+		return
+	}
+	refName, _, _ := unstructured.NestedString(pipelineRun.Object, "spec", "pipelineRef", "name")
+	if refName == "" {
+		utils.RespondErrorMessage(response, fmt.Sprintf("PipelineRun '%s' has neither a pipelineRef nor a pipelineSpec", name), http.StatusNotFound)
+		return
+	}
+	pipeline, err := r.getTektonResource("pipelines", namespace, refName)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	spec, _, _ := unstructured.NestedMap(pipeline.Object, "spec")
+	response.WriteEntity(RunPipeline{Name: refName, Spec: spec})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun pipeline returns the referenced Pipeline or the inline spec
+func TestGETPipelineRunPipeline(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	spec := func(task string) map[string]interface{} {
+		return map[string]interface{}{"tasks": []interface{}{map[string]interface{}{"name": task}}}
+	}
+	pipeline := testutils.GetObject("v1beta1", "Pipeline", namespace, "release", "1")
+	unstructured.SetNestedField(pipeline.Object, spec("publish"), "spec")
+	pipelines := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelines"}
+	if _, err := r.DynamicClient.Resource(pipelines).Namespace(namespace).Create(pipeline, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipeline: %v", err)
+	}
+
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	runs := map[string]func(*unstructured.Unstructured){
+		"referenced": func(run *unstructured.Unstructured) {
+			unstructured.SetNestedField(run.Object, "release", "spec", "pipelineRef", "name")
+		},
+		"inline": func(run *unstructured.Unstructured) {
+			unstructured.SetNestedField(run.Object, spec("lint"), "spec", "pipelineSpec")
+		},
+		"deleted": func(run *unstructured.Unstructured) {
+			unstructured.SetNestedField(run.Object, "removed", "spec", "pipelineRef", "name")
+		},
+	}
+	for name, setSpec := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, name, "1")
+		setSpec(pipelineRun)
+		if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	getPipeline := func(name string) *http.Response {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/%s/pipeline", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting pipeline: %v", err)
+		}
+		return response
+	}
+	tests := map[string]RunPipeline{
+		"referenced": {Name: "release", Spec: spec("publish")},
+		"inline":     {Inline: true, Spec: spec("lint")},
+	}
+	for name, expected := range tests {
+		response := getPipeline(name)
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected statusCode %d, actual %d", name, http.StatusOK, response.StatusCode)
+		}
+		var runPipeline RunPipeline
+		if err := json.NewDecoder(response.Body).Decode(&runPipeline); err != nil {
+			t.Fatalf("Error decoding pipeline: %v", err)
+		}
+		if !reflect.DeepEqual(runPipeline, expected) {
+			t.Errorf("%s: expected %+v, actual %+v", name, expected, runPipeline)
+		}
+	}
+
+	if response := getPipeline("deleted"); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d for a deleted Pipeline, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
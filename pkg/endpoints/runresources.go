@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sidecarContainerPrefix is prepended by Tekton to the name of each sidecar container
+const sidecarContainerPrefix = "sidecar-"
+
+// RunResources is the compute resources requested by the step and sidecar
+// containers of a PipelineRun's pods, in total and by TaskRun. TaskRuns
+// without a pod yet are listed as pending
+type RunResources struct {
+	Requests corev1.ResourceList `json:"requests"`
+	Limits   corev1.ResourceList `json:"limits"`
+	TaskRuns []TaskRunResources  `json:"taskRuns"`
+	Pending  []string            `json:"pending"`
+}
+
+// TaskRunResources is the compute resources requested by the pod of a TaskRun
+type TaskRunResources struct {
+	TaskRun      string              `json:"taskRun"`
+	PipelineTask string              `json:"pipelineTask"`
+	Pod          string              `json:"pod"`
+	Requests     corev1.ResourceList `json:"requests"`
+	Limits       corev1.ResourceList `json:"limits"`
+}
+
+// GetPipelineRunResources sums the resource requests and limits of the step
+// and sidecar containers of all pods of a PipelineRun
+func (r Resource) GetPipelineRunResources(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	if _, err := r.getTektonResource("pipelineruns", namespace, name); err != nil {
+		respondGetError(response, err)
+		return
+	}
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	resources := RunResources{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+		TaskRuns: []TaskRunResources{},
+		Pending:  []string{},
+	}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		pod, err := r.taskRunPod(taskRun)
+		if k8serrors.IsNotFound(err) {
+			resources.Pending = append(resources.Pending, taskRun.GetName())
+			continue
+		}
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+
+		taskRunResources := TaskRunResources{
+			TaskRun:      taskRun.GetName(),
+			PipelineTask: taskRun.GetLabels()[PipelineTaskLabel],
+			Pod:          pod.Name,
+			Requests:     corev1.ResourceList{},
+			Limits:       corev1.ResourceList{},
+		}
+		for _, container := range pod.Spec.Containers {
+			if !strings.HasPrefix(container.Name, stepContainerPrefix) && !strings.HasPrefix(container.Name, sidecarContainerPrefix) {
+				continue
+			}
+			addResources(taskRunResources.Requests, container.Resources.Requests)
+			addResources(taskRunResources.Limits, container.Resources.Limits)
+		}
+		addResources(resources.Requests, taskRunResources.Requests)
+		addResources(resources.Limits, taskRunResources.Limits)
+		resources.TaskRuns = append(resources.TaskRuns, taskRunResources)
+	}
+	sort.Slice(resources.TaskRuns, func(i, j int) bool {
+		return resources.TaskRuns[i].TaskRun < resources.TaskRuns[j].TaskRun
+	})
+	sort.Strings(resources.Pending)
+
+	response.WriteEntity(resources)
+}
+
+// addResources adds each quantity of resources to total
+func addResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun resources sums the requests and limits of its pods
+func TestGETPipelineRunResources(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "ci", "1")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	container := func(name, cpu, memory string) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+		}}
+	}
+	pods := map[string][]corev1.Container{
+		"build": {container("step-compile", "500m", "1Gi"), container("sidecar-docker", "250m", "512Mi")},
+		"test":  {container("step-unit", "1", "256Mi"), container("istio-proxy", "2", "1Gi")},
+		// The deploy TaskRun has no pod yet
+		"deploy": nil,
+	}
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for pipelineTask, containers := range pods {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "ci-"+pipelineTask, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "ci", PipelineTaskLabel: pipelineTask})
+		if containers != nil {
+			unstructured.SetNestedField(taskRun.Object, "ci-"+pipelineTask+"-pod", "status", "podName")
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "ci-" + pipelineTask + "-pod", Namespace: namespace},
+				Spec:       corev1.PodSpec{Containers: containers},
+			}
+			if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+				t.Fatalf("Error creating pod: %v", err)
+			}
+		}
+		if _, err := r.DynamicClient.Resource(taskRuns).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/ci/resources", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting resources: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var resources RunResources
+	if err := json.NewDecoder(response.Body).Decode(&resources); err != nil {
+		t.Fatalf("Error decoding resources: %v", err)
+	}
+
+	expectQuantity := func(description string, list corev1.ResourceList, name corev1.ResourceName, expected string) {
+		quantity := list[name]
+		if quantity.Cmp(resource.MustParse(expected)) != 0 {
+			t.Errorf("Expected %s %s of %s, actual %s", description, name, expected, quantity.String())
+		}
+	}
+	// Containers other than steps and sidecars are not counted
+	expectQuantity("total requested", resources.Requests, corev1.ResourceCPU, "1750m")
+	expectQuantity("total requested", resources.Requests, corev1.ResourceMemory, "1792Mi")
+	expectQuantity("total limited", resources.Limits, corev1.ResourceMemory, "1792Mi")
+	if len(resources.TaskRuns) != 2 || resources.TaskRuns[0].TaskRun != "ci-build" || resources.TaskRuns[1].TaskRun != "ci-test" {
+		t.Fatalf("Expected build and test TaskRuns, actual %+v", resources.TaskRuns)
+	}
+	expectQuantity("build requested", resources.TaskRuns[0].Requests, corev1.ResourceCPU, "750m")
+	expectQuantity("test requested", resources.TaskRuns[1].Requests, corev1.ResourceMemory, "256Mi")
+	if len(resources.Pending) != 1 || resources.Pending[0] != "ci-deploy" {
+		t.Errorf("Expected ci-deploy pending, actual %v", resources.Pending)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Bounds and default of the interval clients may request stats at
+const (
+	defaultRunStatsInterval = 10 * time.Second
+	minRunStatsInterval     = time.Second
+	maxRunStatsInterval     = 5 * time.Minute
+)
+
+// RunStats counts PipelineRuns and TaskRuns by status
+type RunStats struct {
+	PipelineRuns map[string]int `json:"pipelineRuns"`
+	TaskRuns     map[string]int `json:"taskRuns"`
+}
+
+// EstablishRunStatsWebsocket pushes a RunStats message on the interval given
+// by the interval query parameter, an overview is cheaper to keep up to date
+// this way than by streaming every run event. With an Authorizer the user must
+// be allowed to list the runs counted
+func (r Resource) EstablishRunStatsWebsocket(request *restful.Request, response *restful.Response) {
+	for _, kind := range []string{"PipelineRun", "TaskRun"} {
+		if !r.checkAuthorized(request, response, "list", kind, r.Options.TenantNamespace, "") {
+			return
+		}
+	}
+	interval, err := durationParameter(request, "interval", defaultRunStatsInterval)
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	if interval < minRunStatsInterval || interval > maxRunStatsInterval {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, fmt.Sprintf("interval must be between %s and %s", minRunStatsInterval, maxRunStatsInterval))
+		return
+	}
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+	websocket.WritePeriodic(connection, interval, func() (broadcaster.SocketData, bool) {
+		stats, err := r.runStats()
+		if err != nil {
+			logging.Log.Errorf("Error computing run stats: %s", err)
+			return broadcaster.SocketData{}, false
+		}
+		return broadcaster.SocketData{MessageType: broadcaster.RunStats, Payload: stats}, true
+	})
+}
+
+// runStats counts runs in the tenant namespace, or across the cluster when the
+// dashboard is not restricted to one
+func (r Resource) runStats() (RunStats, error) {
+	pipelineRuns, err := r.countRunStatuses("pipelineruns")
+	if err != nil {
+		return RunStats{}, err
+	}
+	taskRuns, err := r.countRunStatuses("taskruns")
+	if err != nil {
+		return RunStats{}, err
+	}
+	return RunStats{PipelineRuns: pipelineRuns, TaskRuns: taskRuns}, nil
+}
+
+func (r Resource) countRunStatuses(resource string) (map[string]int, error) {
+	list, err := r.DynamicClient.Resource(r.tektonGVR(resource)).Namespace(r.Options.TenantNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for i := range list.Items {
+		if !r.Options.hides(&list.Items[i]) {
+			counts[runStatus(&list.Items[i])]++
+		}
+	}
+	return counts, nil
+}
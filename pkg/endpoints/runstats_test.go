@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The stats websocket pushes run counts by status on every interval
+func TestRunStatsWebsocket(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	runs := []struct {
+		kind, resource, name, status, reason string
+	}{
+		{"PipelineRun", "pipelineruns", "pending", "", ""},
+		{"PipelineRun", "pipelineruns", "succeeded", "True", "Succeeded"},
+		{"PipelineRun", "pipelineruns", "cancelled", "False", "PipelineRunCancelled"},
+		{"TaskRun", "taskruns", "running", "Unknown", "Running"},
+		{"TaskRun", "taskruns", "failed", "False", "Failed"},
+		{"TaskRun", "taskruns", "failed-again", "False", "Failed"},
+	}
+	for _, run := range runs {
+		object := testutils.GetObject("v1beta1", run.kind, namespace, run.name, "1")
+		if run.status != "" {
+			unstructured.SetNestedSlice(object.Object, []interface{}{
+				map[string]interface{}{"type": "Succeeded", "status": run.status, "reason": run.reason},
+			}, "status", "conditions")
+		}
+		gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: run.resource}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating %s: %v", run.kind, err)
+		}
+	}
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/stats",
+		RawQuery: "interval=1s",
+	}
+	messages := 0
+	for socketData := range clientWebsocket(websocketURL.String(), 2500*time.Millisecond, t) {
+		if socketData.MessageType != broadcaster.RunStats {
+			t.Fatalf("Expected %s message, actual %s", broadcaster.RunStats, socketData.MessageType)
+		}
+		payload, _ := json.Marshal(socketData.Payload)
+		var stats RunStats
+		if err := json.Unmarshal(payload, &stats); err != nil {
+			t.Fatalf("Error decoding run stats: %v", err)
+		}
+		expected := RunStats{
+			PipelineRuns: map[string]int{RunPending: 1, RunSucceeded: 1, RunCancelled: 1},
+			TaskRuns:     map[string]int{RunRunning: 1, RunFailed: 2},
+		}
+		if !reflect.DeepEqual(stats, expected) {
+			t.Errorf("Expected stats %+v, actual %+v", expected, stats)
+		}
+		messages++
+	}
+	if messages < 2 {
+		t.Errorf("Expected periodic stats messages, received %d", messages)
+	}
+}
+
+// With an Authorizer, stats are only pushed to users who may list the runs
+// counted
+func TestRunStatsWebsocketAuthorization(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = denyKindAuthorizer{kind: "TaskRun"}
+	server.Config.Handler = router.Register(*r)
+
+	header := http.Header{}
+	header.Set(UserHeader, "alice")
+	endpoint := "ws://" + strings.TrimPrefix(server.URL, "http://") + "/v1/websockets/stats"
+	if status, _ := dialUpgradeError(endpoint, header, t); status != http.StatusForbidden {
+		t.Errorf("Expected status %d, actual %d", http.StatusForbidden, status)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// schedulingReasons are the event reasons explaining why a pod is not
+// scheduled yet, besides events reported by the scheduler itself
+var schedulingReasons = map[string]bool{
+	"FailedScheduling":  true,
+	"NotTriggerScaleUp": true,
+	"TriggeredScaleUp":  true,
+}
+
+// SchedulingStatus explains why the pod of a TaskRun has not been scheduled,
+// with the reason and message of the latest scheduling event
+type SchedulingStatus struct {
+	Pod     string         `json:"pod"`
+	Reason  string         `json:"reason,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Events  []corev1.Event `json:"events"`
+}
+
+// GetTaskRunScheduling returns the scheduling events of the pod of a pending
+// TaskRun, or 204 if the pod has been scheduled
+func (r Resource) GetTaskRunScheduling(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	status := SchedulingStatus{Pod: pod.Name, Events: []corev1.Event{}}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			response.WriteHeader(http.StatusNoContent)
+			return
+		}
+		status.Reason, status.Message = condition.Reason, condition.Message
+	}
+
+	events, err := r.K8sClient.CoreV1().Events(namespace).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", pod.Name).String(),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != pod.Name {
+			continue
+		}
+		if schedulingReasons[event.Reason] || event.Source.Component == corev1.DefaultSchedulerName {
+			status.Events = append(status.Events, event)
+		}
+	}
+	sort.SliceStable(status.Events, func(i, j int) bool {
+		return status.Events[i].LastTimestamp.Before(&status.Events[j].LastTimestamp)
+	})
+	if len(status.Events) > 0 {
+		latest := status.Events[len(status.Events)-1]
+		status.Reason, status.Message = latest.Reason, latest.Message
+	}
+
+	response.WriteEntity(status)
+}
+
+// AffinityAssistantAnnotation names the affinity assistant Tekton schedules
+// the pods of a PipelineRun sharing a workspace next to
+const AffinityAssistantAnnotation = "pipeline.tekton.dev/affinity-assistant"
+
+// SchedulingConstraints are the constraints Tekton set on the pod of a
+// TaskRun, Pending when the pod does not exist yet
+type SchedulingConstraints struct {
+	Pod               string              `json:"pod,omitempty"`
+	Pending           bool                `json:"pending,omitempty"`
+	NodeName          string              `json:"nodeName,omitempty"`
+	NodeSelector      map[string]string   `json:"nodeSelector,omitempty"`
+	Affinity          *corev1.Affinity    `json:"affinity,omitempty"`
+	Tolerations       []corev1.Toleration `json:"tolerations"`
+	AffinityAssistant *AffinityAssistant  `json:"affinityAssistant,omitempty"`
+}
+
+// AffinityAssistant is the placeholder pod a TaskRun's pod is scheduled with,
+// Pod is empty if it is not running
+type AffinityAssistant struct {
+	Name     string `json:"name"`
+	Pod      string `json:"pod,omitempty"`
+	NodeName string `json:"nodeName,omitempty"`
+}
+
+// GetTaskRunSchedulingConstraints returns the node selector, affinity and
+// tolerations of the pod of a TaskRun, and the affinity assistant it is
+// scheduled with if any
+func (r Resource) GetTaskRunSchedulingConstraints(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	taskRun, err := r.getTektonResource("taskruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	constraints := SchedulingConstraints{Tolerations: []corev1.Toleration{}}
+	if assistant := taskRun.GetAnnotations()[AffinityAssistantAnnotation]; assistant != "" {
+		constraints.AffinityAssistant = &AffinityAssistant{Name: assistant}
+		pods, err := r.K8sClient.CoreV1().Pods(namespace).List(metav1.ListOptions{
+			LabelSelector: labels.Set{"app.kubernetes.io/component": "affinity-assistant", "app.kubernetes.io/instance": assistant}.String(),
+		})
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		if len(pods.Items) > 0 {
+			constraints.AffinityAssistant.Pod = pods.Items[0].Name
+			constraints.AffinityAssistant.NodeName = pods.Items[0].Spec.NodeName
+		}
+	}
+
+	pod, err := r.taskRunPod(taskRun)
+	if k8serrors.IsNotFound(err) {
+		constraints.Pending = true
+		response.WriteEntity(constraints)
+		return
+	}
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	constraints.Pod = pod.Name
+	constraints.NodeName = pod.Spec.NodeName
+	constraints.NodeSelector = pod.Spec.NodeSelector
+	constraints.Affinity = pod.Spec.Affinity
+	if pod.Spec.Tolerations != nil {
+		constraints.Tolerations = pod.Spec.Tolerations
+	}
+	response.WriteEntity(constraints)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET TaskRun scheduling reports why a pending pod is not scheduled
+func TestGETTaskRunScheduling(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	pods := map[string]corev1.ConditionStatus{"pending": corev1.ConditionFalse, "scheduled": corev1.ConditionTrue}
+	for name, scheduled := range pods {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name, "1")
+		unstructured.SetNestedField(taskRun.Object, name+"-pod", "status", "podName")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-pod", Namespace: namespace},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: scheduled, Reason: "Unschedulable"}},
+			},
+		}
+		if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+			t.Fatalf("Error creating pod: %v", err)
+		}
+	}
+
+	now := time.Now()
+	events := []corev1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "pending-pod.1", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pending-pod"},
+			Reason:         "FailedScheduling",
+			Message:        "0/3 nodes are available: 3 Insufficient cpu.",
+			Source:         corev1.EventSource{Component: corev1.DefaultSchedulerName},
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "pending-pod.2", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pending-pod"},
+			Reason:         "NotTriggerScaleUp",
+			Message:        "pod didn't trigger scale-up",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "scheduled-pod.1", Namespace: namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "scheduled-pod"},
+			Reason:         "Pulled",
+		},
+	}
+	for i := range events {
+		if _, err := r.K8sClient.CoreV1().Events(namespace).Create(&events[i]); err != nil {
+			t.Fatalf("Error creating event: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/pending/scheduling", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting scheduling: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var status SchedulingStatus
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		t.Fatalf("Error decoding scheduling: %v", err)
+	}
+	if status.Pod != "pending-pod" || status.Reason != "FailedScheduling" || status.Message != "0/3 nodes are available: 3 Insufficient cpu." {
+		t.Errorf("Expected latest FailedScheduling event, actual %+v", status)
+	}
+	if len(status.Events) != 2 || status.Events[0].Reason != "NotTriggerScaleUp" {
+		t.Errorf("Expected both scheduling events oldest first, actual %+v", status.Events)
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/scheduled/scheduling", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting scheduling: %v", err)
+	}
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected statusCode %d for a scheduled pod, actual %d", http.StatusNoContent, response.StatusCode)
+	}
+}
+
+// GET TaskRun scheduling constraints reports the node selector and
+// tolerations of its pod and its affinity assistant, or pending without a pod
+func TestGETTaskRunSchedulingConstraints(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for name, podName := range map[string]string{"build": "build-pod", "queued": ""} {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, name, "1")
+		taskRun.SetAnnotations(map[string]string{AffinityAssistantAnnotation: "affinity-assistant-1a2b"})
+		if podName != "" {
+			unstructured.SetNestedField(taskRun.Object, podName, "status", "podName")
+		}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+	toleration := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "ci", Effect: corev1.TaintEffectNoSchedule}
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+			Spec: corev1.PodSpec{
+				NodeName:     "node-a",
+				NodeSelector: map[string]string{"disktype": "ssd"},
+				Tolerations:  []corev1.Toleration{toleration},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "affinity-assistant-1a2b-0",
+				Namespace: namespace,
+				Labels:    map[string]string{"app.kubernetes.io/component": "affinity-assistant", "app.kubernetes.io/instance": "affinity-assistant-1a2b"},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-a"},
+		},
+	}
+	for i := range pods {
+		if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pods[i]); err != nil {
+			t.Fatalf("Error creating pod: %v", err)
+		}
+	}
+
+	getConstraints := func(name string) SchedulingConstraints {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/%s/scheduling-constraints", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting scheduling constraints: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var constraints SchedulingConstraints
+		if err := json.NewDecoder(response.Body).Decode(&constraints); err != nil {
+			t.Fatalf("Error decoding scheduling constraints: %v", err)
+		}
+		return constraints
+	}
+
+	constraints := getConstraints("build")
+	if constraints.Pending || constraints.Pod != "build-pod" || constraints.NodeName != "node-a" {
+		t.Errorf("Expected pod build-pod on node-a, actual %+v", constraints)
+	}
+	if !reflect.DeepEqual(constraints.NodeSelector, map[string]string{"disktype": "ssd"}) {
+		t.Errorf("Expected node selector disktype=ssd, actual %v", constraints.NodeSelector)
+	}
+	if !reflect.DeepEqual(constraints.Tolerations, []corev1.Toleration{toleration}) {
+		t.Errorf("Expected tolerations %+v, actual %+v", []corev1.Toleration{toleration}, constraints.Tolerations)
+	}
+	expectedAssistant := &AffinityAssistant{Name: "affinity-assistant-1a2b", Pod: "affinity-assistant-1a2b-0", NodeName: "node-a"}
+	if !reflect.DeepEqual(constraints.AffinityAssistant, expectedAssistant) {
+		t.Errorf("Expected affinity assistant %+v, actual %+v", expectedAssistant, constraints.AffinityAssistant)
+	}
+
+	if queued := getConstraints("queued"); !queued.Pending || queued.Pod != "" {
+		t.Errorf("Expected pending constraints without a pod, actual %+v", queued)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SidecarStatus is the state of a sidecar container of a TaskRun.
+// StoppedByTekton is set once Tekton has replaced the sidecar's image with
+// its nop image to stop it after the steps completed
+type SidecarStatus struct {
+	Name            string                `json:"name"`
+	Container       string                `json:"container"`
+	Image           string                `json:"image,omitempty"`
+	ImageID         string                `json:"imageID,omitempty"`
+	State           corev1.ContainerState `json:"state"`
+	Ready           bool                  `json:"ready"`
+	RestartCount    int32                 `json:"restartCount"`
+	StoppedByTekton bool                  `json:"stoppedByTekton"`
+}
+
+// taskRunSidecarState is an entry of a TaskRun's status.sidecars
+type taskRunSidecarState struct {
+	corev1.ContainerState
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	ImageID   string `json:"imageID"`
+}
+
+// GetTaskRunSidecars returns the state of each sidecar of a TaskRun from its
+// status, updated from the backing pod while it exists
+func (r Resource) GetTaskRunSidecars(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	taskRun, err := r.getTektonResource("taskruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	sidecars := []SidecarStatus{}
+	if states, found, _ := unstructured.NestedSlice(taskRun.Object, "status", "sidecars"); found {
+		encoded, err := json.Marshal(states)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		var decoded []taskRunSidecarState
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		for _, state := range decoded {
+			sidecars = append(sidecars, SidecarStatus{
+				Name:      state.Name,
+				Container: state.Container,
+				ImageID:   state.ImageID,
+				State:     state.ContainerState,
+			})
+		}
+	}
+
+	pod, err := r.taskRunPod(taskRun)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	if err == nil {
+		declared := declaredSidecarImages(taskRun)
+		for i := range sidecars {
+			updateFromPod(&sidecars[i], pod, declared)
+		}
+	}
+
+	response.WriteEntity(sidecars)
+}
+
+// declaredSidecarImages returns the images of the sidecars in the TaskRun's
+// resolved Task spec by name
+func declaredSidecarImages(taskRun *unstructured.Unstructured) map[string]string {
+	images := map[string]string{}
+	declared, _, _ := unstructured.NestedSlice(taskRun.Object, "status", "taskSpec", "sidecars")
+	for _, d := range declared {
+		sidecar, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := sidecar["name"].(string)
+		images[name], _ = sidecar["image"].(string)
+	}
+	return images
+}
+
+// updateFromPod sets the live state of a sidecar from its pod container
+func updateFromPod(sidecar *SidecarStatus, pod *corev1.Pod, declared map[string]string) {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != sidecar.Container {
+			continue
+		}
+		sidecar.Image = container.Image
+		if image, ok := declared[sidecar.Name]; ok && image != "" {
+			sidecar.StoppedByTekton = image != container.Image
+		} else {
+			sidecar.StoppedByTekton = isNopImage(container.Image)
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != sidecar.Container {
+			continue
+		}
+		sidecar.State = status.State
+		sidecar.Ready = status.Ready
+		sidecar.RestartCount = status.RestartCount
+		if status.ImageID != "" {
+			sidecar.ImageID = status.ImageID
+		}
+	}
+}
+
+// isNopImage reports whether an image is Tekton's nop image, which sidecars
+// are switched to when they are stopped
+func isNopImage(image string) bool {
+	image = strings.SplitN(image, "@", 2)[0]
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	return strings.SplitN(image, ":", 2)[0] == "nop"
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func getSidecars(t *testing.T, url string) []SidecarStatus {
+	httpReq := testutils.DummyHTTPRequest("GET", url, nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting sidecars: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var sidecars []SidecarStatus
+	if err := json.NewDecoder(response.Body).Decode(&sidecars); err != nil {
+		t.Fatalf("Error decoding sidecars: %v", err)
+	}
+	return sidecars
+}
+
+// GET TaskRun sidecars reports the live state of each sidecar
+func TestGETTaskRunSidecars(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "integration", "1")
+	unstructured.SetNestedField(taskRun.Object, map[string]interface{}{
+		"podName": "integration-pod",
+		"sidecars": []interface{}{
+			map[string]interface{}{"name": "dind", "container": "sidecar-dind", "imageID": "docker://dind", "running": map[string]interface{}{}},
+			map[string]interface{}{"name": "proxy", "container": "sidecar-proxy", "running": map[string]interface{}{}},
+		},
+		"taskSpec": map[string]interface{}{
+			"sidecars": []interface{}{
+				map[string]interface{}{"name": "dind", "image": "docker:dind"},
+				map[string]interface{}{"name": "proxy", "image": "envoy:latest"},
+			},
+		},
+	}, "status")
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	started := metav1.Unix(1600000000, 0)
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "integration-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "step-test", Image: "golang"},
+			{Name: "sidecar-dind", Image: "docker:dind"},
+			{Name: "sidecar-proxy", Image: "gcr.io/tekton-releases/github.com/tektoncd/pipeline/cmd/nop@sha256:1234"},
+		}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "sidecar-dind", Ready: true, ImageID: "docker-pullable://dind@sha256:abcd", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}}},
+			{Name: "sidecar-proxy", RestartCount: 1, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}},
+		}},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	sidecars := getSidecars(t, fmt.Sprintf("%s/v1/namespaces/%s/taskruns/integration/sidecars", server.URL, namespace))
+	expected := []SidecarStatus{
+		{
+			Name:      "dind",
+			Container: "sidecar-dind",
+			Image:     "docker:dind",
+			ImageID:   "docker-pullable://dind@sha256:abcd",
+			State:     corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: started}},
+			Ready:     true,
+		},
+		{
+			Name:            "proxy",
+			Container:       "sidecar-proxy",
+			Image:           "gcr.io/tekton-releases/github.com/tektoncd/pipeline/cmd/nop@sha256:1234",
+			State:           corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+			RestartCount:    1,
+			StoppedByTekton: true,
+		},
+	}
+	if !reflect.DeepEqual(sidecars, expected) {
+		t.Errorf("Expected sidecars %+v, actual %+v", expected, sidecars)
+	}
+}
+
+// GET TaskRun sidecars returns an empty list for TaskRuns without sidecars
+func TestGETTaskRunSidecarsNone(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(testutils.GetObject("v1beta1", "TaskRun", namespace, "plain", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	if sidecars := getSidecars(t, fmt.Sprintf("%s/v1/namespaces/%s/taskruns/plain/sidecars", server.URL, namespace)); len(sidecars) != 0 {
+		t.Errorf("Expected no sidecars, actual %+v", sidecars)
+	}
+}
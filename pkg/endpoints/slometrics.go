@@ -0,0 +1,189 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MIMEPrometheusText is the content type of the Prometheus text exposition
+// format
+const MIMEPrometheusText = "text/plain; version=0.0.4"
+
+// pipelineRunDurationBuckets are the upper bounds in seconds of the buckets of
+// the PipelineRun duration histograms
+var pipelineRunDurationBuckets = []float64{30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400}
+
+// PipelineRunMetrics counts the PipelineRuns that complete by Pipeline and
+// status, with histograms of their durations. Runs are only labelled with
+// their namespace and Pipeline so the number of series stays bounded
+type PipelineRunMetrics struct {
+	mutex     sync.Mutex
+	totals    map[pipelineRunStatus]int
+	durations map[pipelineKey]*durationHistogram
+}
+
+type pipelineKey struct {
+	namespace string
+	pipeline  string
+}
+
+type pipelineRunStatus struct {
+	pipelineKey
+	status string
+}
+
+type durationHistogram struct {
+	// Cumulative counts of observations no greater than each bucket bound
+	buckets []int
+	count   int
+	sum     float64
+}
+
+// RunMetrics holds the metrics of the PipelineRuns completed since the
+// dashboard started, see ObservePipelineRuns
+var RunMetrics = NewPipelineRunMetrics()
+
+// NewPipelineRunMetrics returns empty PipelineRun metrics
+func NewPipelineRunMetrics() *PipelineRunMetrics {
+	return &PipelineRunMetrics{
+		totals:    map[pipelineRunStatus]int{},
+		durations: map[pipelineKey]*durationHistogram{},
+	}
+}
+
+// ObservePipelineRuns records each PipelineRun that completes from now on in
+// RunMetrics, until stopCh is closed
+func ObservePipelineRuns(stopCh <-chan struct{}) {
+	// Runs already complete on startup are not counted when updated again
+	started := time.Now().Truncate(time.Second)
+	subscriber, _ := ResourcesBroadcaster.Subscribe(broadcaster.WithSendTimeout[broadcaster.SocketData](0), broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		return data.MessageType == broadcaster.PipelineRunUpdated || data.MessageType == broadcaster.PipelineRunDeleted
+	}))
+
+	go func() {
+		defer ResourcesBroadcaster.Unsubscribe(subscriber)
+		completed := map[string]bool{}
+		for {
+			select {
+			case data := <-subscriber.SubChan():
+				if completion, ok := runCompleted(data, completed, started); ok {
+					pipeline := data.Payload.(*unstructured.Unstructured).GetLabels()[PipelineLabel]
+					RunMetrics.observe(completion.Namespace, pipeline, completion.Status, completion.Duration)
+				}
+			case <-subscriber.UnsubChan():
+				return
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// observe records a completed run of a Pipeline
+func (m *PipelineRunMetrics) observe(namespace, pipeline, status string, duration float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := pipelineKey{namespace: namespace, pipeline: pipeline}
+	m.totals[pipelineRunStatus{pipelineKey: key, status: status}]++
+	histogram, ok := m.durations[key]
+	if !ok {
+		histogram = &durationHistogram{buckets: make([]int, len(pipelineRunDurationBuckets))}
+		m.durations[key] = histogram
+	}
+	for i, bound := range pipelineRunDurationBuckets {
+		if duration <= bound {
+			histogram.buckets[i]++
+		}
+	}
+	histogram.count++
+	histogram.sum += duration
+}
+
+// writeText writes the metrics in the Prometheus text exposition format,
+// series sorted by their labels
+func (m *PipelineRunMetrics) writeText(buffer *bytes.Buffer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	buffer.WriteString("# HELP dashboard_pipeline_run_total Number of PipelineRuns completed by Pipeline and status.\n")
+	buffer.WriteString("# TYPE dashboard_pipeline_run_total counter\n")
+	totals := make([]pipelineRunStatus, 0, len(m.totals))
+	for key := range m.totals {
+		totals = append(totals, key)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].pipelineKey != totals[j].pipelineKey {
+			return totals[i].pipelineKey.less(totals[j].pipelineKey)
+		}
+		return totals[i].status < totals[j].status
+	})
+	for _, key := range totals {
+		fmt.Fprintf(buffer, "dashboard_pipeline_run_total{%s,status=%s} %d\n", key.labels(), quoteLabel(key.status), m.totals[key])
+	}
+
+	buffer.WriteString("# HELP dashboard_pipeline_run_duration_seconds Duration of the completed PipelineRuns by Pipeline.\n")
+	buffer.WriteString("# TYPE dashboard_pipeline_run_duration_seconds histogram\n")
+	pipelines := make([]pipelineKey, 0, len(m.durations))
+	for key := range m.durations {
+		pipelines = append(pipelines, key)
+	}
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].less(pipelines[j])
+	})
+	for _, key := range pipelines {
+		histogram := m.durations[key]
+		for i, bound := range pipelineRunDurationBuckets {
+			fmt.Fprintf(buffer, "dashboard_pipeline_run_duration_seconds_bucket{%s,le=\"%s\"} %d\n", key.labels(), strconv.FormatFloat(bound, 'g', -1, 64), histogram.buckets[i])
+		}
+		fmt.Fprintf(buffer, "dashboard_pipeline_run_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key.labels(), histogram.count)
+		fmt.Fprintf(buffer, "dashboard_pipeline_run_duration_seconds_sum{%s} %s\n", key.labels(), strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+		fmt.Fprintf(buffer, "dashboard_pipeline_run_duration_seconds_count{%s} %d\n", key.labels(), histogram.count)
+	}
+}
+
+func (k pipelineKey) less(other pipelineKey) bool {
+	if k.namespace != other.namespace {
+		return k.namespace < other.namespace
+	}
+	return k.pipeline < other.pipeline
+}
+
+func (k pipelineKey) labels() string {
+	return "namespace=" + quoteLabel(k.namespace) + ",pipeline=" + quoteLabel(k.pipeline)
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines
+func quoteLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// GetMetrics serves the PipelineRun metrics for Prometheus to scrape
+func (r Resource) GetMetrics(request *restful.Request, response *restful.Response) {
+	var buffer bytes.Buffer
+	RunMetrics.writeText(&buffer)
+	response.AddHeader("Content-Type", MIMEPrometheusText)
+	response.Write(buffer.Bytes())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Completed PipelineRuns are counted and their durations observed in the
+// metrics scrape
+func TestGETMetrics(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	ObservePipelineRuns(stopCh)
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	now := time.Now()
+	runs := []struct {
+		name     string
+		status   string
+		reason   string
+		duration time.Duration
+	}{
+		{"slo-1", "True", "Succeeded", 45 * time.Second},
+		{"slo-2", "True", "Succeeded", 90 * time.Second},
+		{"slo-3", "False", "Failed", 10 * time.Minute},
+	}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("tekton.dev/v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetLabels(map[string]string{PipelineLabel: "slo"})
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+		pipelineRun.SetResourceVersion("2")
+		unstructured.SetNestedField(pipelineRun.Object, now.Add(-run.duration).UTC().Format(time.RFC3339), "status", "startTime")
+		unstructured.SetNestedField(pipelineRun.Object, now.UTC().Format(time.RFC3339), "status", "completionTime")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status, "reason": run.reason},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Update(pipelineRun, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Error completing pipelineRun: %v", err)
+		}
+	}
+
+	labels := fmt.Sprintf(`namespace="%s",pipeline="slo"`, namespace)
+	expected := []string{
+		fmt.Sprintf(`dashboard_pipeline_run_total{%s,status="Failed"} 1`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_total{%s,status="Succeeded"} 2`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_bucket{%s,le="30"} 0`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_bucket{%s,le="60"} 1`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_bucket{%s,le="120"} 2`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_bucket{%s,le="600"} 3`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_bucket{%s,le="+Inf"} 3`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_sum{%s} 735`, labels),
+		fmt.Sprintf(`dashboard_pipeline_run_duration_seconds_count{%s} 3`, labels),
+	}
+	var scrape string
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", server.URL+"/metrics", nil))
+		if err != nil {
+			t.Fatalf("Error scraping metrics: %v", err)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		scrape = string(body)
+		if strings.Contains(scrape, expected[len(expected)-1]) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	for _, line := range expected {
+		if !strings.Contains(scrape, line+"\n") {
+			t.Errorf("Expected scrape to contain %s, actual:\n%s", line, scrape)
+		}
+	}
+	if strings.Contains(scrape, "slo-1") {
+		t.Errorf("Expected no run names in the scrape, actual:\n%s", scrape)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Labels and annotations recording where a triggered run came from, in order
+// of preference. Pipelines as Code labels are read as a fallback
+var (
+	sourceURLKeys      = []string{"tekton.dev/git-url", "tekton.dev/git-repo-url", "pipelinesascode.tekton.dev/repo-url"}
+	sourceRevisionKeys = []string{"tekton.dev/git-commit", "tekton.dev/git-revision", "pipelinesascode.tekton.dev/sha"}
+	sourceBranchKeys   = []string{"tekton.dev/git-branch", "pipelinesascode.tekton.dev/branch"}
+)
+
+// Labels set by Tekton Triggers on the runs it creates
+const (
+	EventListenerLabel = "triggers.tekton.dev/eventlistener"
+	TriggerLabel       = "triggers.tekton.dev/trigger"
+	EventIDLabel       = "triggers.tekton.dev/triggers-eventid"
+)
+
+// Source describes the code and trigger a run came from
+type Source struct {
+	URL           string `json:"url,omitempty"`
+	Revision      string `json:"revision,omitempty"`
+	Branch        string `json:"branch,omitempty"`
+	EventListener string `json:"eventListener,omitempty"`
+	Trigger       string `json:"trigger,omitempty"`
+	EventID       string `json:"eventID,omitempty"`
+}
+
+// GetPipelineRunSource returns the git source and trigger a PipelineRun was
+// created from, or 204 if the run carries no source information
+func (r Resource) GetPipelineRunSource(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	source := runSource(pipelineRun)
+	if source == (Source{}) {
+		response.WriteHeader(http.StatusNoContent)
+		return
+	}
+	response.WriteEntity(source)
+}
+
+// runSource reads the source of a run from its annotations, then its labels
+func runSource(object metav1.Object) Source {
+	return Source{
+		URL:           metadataValue(object, sourceURLKeys...),
+		Revision:      metadataValue(object, sourceRevisionKeys...),
+		Branch:        metadataValue(object, sourceBranchKeys...),
+		EventListener: metadataValue(object, EventListenerLabel),
+		Trigger:       metadataValue(object, TriggerLabel),
+		EventID:       metadataValue(object, EventIDLabel),
+	}
+}
+
+// metadataValue returns the value of the first of the keys set as an
+// annotation or label of the object
+func metadataValue(object metav1.Object, keys ...string) string {
+	annotations := object.GetAnnotations()
+	labels := object.GetLabels()
+	for _, key := range keys {
+		if value := annotations[key]; value != "" {
+			return value
+		}
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun source normalises the git and trigger metadata of a run
+func TestGETPipelineRunSource(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "pipelineruns",
+	}
+	triggered := testutils.GetObject("v1beta1", "PipelineRun", namespace, "triggered", "1")
+	triggered.SetAnnotations(map[string]string{
+		"tekton.dev/git-url":    "https://github.com/tektoncd/dashboard",
+		"tekton.dev/git-commit": "0123456789abcdef",
+	})
+	triggered.SetLabels(map[string]string{
+		"tekton.dev/git-branch": "main",
+		EventListenerLabel:      "github-listener",
+		TriggerLabel:            "push",
+	})
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(triggered, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+	manual := testutils.GetObject("v1beta1", "PipelineRun", namespace, "manual", "1")
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(manual, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/triggered/source", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting source: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var source Source
+	if err := json.NewDecoder(response.Body).Decode(&source); err != nil {
+		t.Fatalf("Error decoding source: %v", err)
+	}
+	expected := Source{
+		URL:           "https://github.com/tektoncd/dashboard",
+		Revision:      "0123456789abcdef",
+		Branch:        "main",
+		EventListener: "github-listener",
+		Trigger:       "push",
+	}
+	if source != expected {
+		t.Errorf("Expected source %+v, actual %+v", expected, source)
+	}
+
+	httpReq = testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/manual/source", server.URL, namespace), nil)
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting source: %v", err)
+	}
+	if response.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected statusCode %d for run without source, actual %d", http.StatusNoContent, response.StatusCode)
+	}
+}
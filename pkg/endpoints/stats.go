@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// DurationStats summarises a set of durations, all values are in seconds
+type DurationStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"minSeconds"`
+	Max   float64 `json:"maxSeconds"`
+	Mean  float64 `json:"meanSeconds"`
+	P50   float64 `json:"p50Seconds"`
+	P95   float64 `json:"p95Seconds"`
+}
+
+// computeDurationStats returns the distribution of the given durations
+func computeDurationStats(durations []time.Duration) DurationStats {
+	stats := DurationStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	stats.Min = sorted[0].Seconds()
+	stats.Max = sorted[len(sorted)-1].Seconds()
+	stats.Mean = (total / time.Duration(len(sorted))).Seconds()
+	stats.P50 = percentile(sorted, 50).Seconds()
+	stats.P95 = percentile(sorted, 95).Seconds()
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// durationParameter reads a positive duration from a query parameter,
+// returning the default when the parameter is not set. Besides Go durations a
+// whole number of days such as 7d is accepted
+func durationParameter(request *restful.Request, name string, defaultValue time.Duration) (time.Duration, error) {
+	value := request.QueryParameter(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if days := strings.TrimSuffix(value, "d"); err != nil && days != value {
+		var count int
+		if count, err = strconv.Atoi(days); err == nil {
+			duration = time.Duration(count) * 24 * time.Hour
+		}
+	}
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s duration '%s'", name, value)
+	}
+	return duration, nil
+}
+
+// intParameter reads a positive integer no greater than max from a query
+// parameter, returning the default when the parameter is not set
+func intParameter(request *restful.Request, name string, defaultValue, max int) (int, error) {
+	value := request.QueryParameter(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 || parsed > max {
+		return 0, fmt.Errorf("invalid %s '%s', must be between 1 and %d", name, value, max)
+	}
+	return parsed, nil
+}
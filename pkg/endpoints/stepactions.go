@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// DefaultStepActionGVR is the resource of StepActions used when none was
+// detected
+var DefaultStepActionGVR = schema.GroupVersionResource{Group: tektonGroup, Version: "v1beta1", Resource: "stepactions"}
+
+// stepActionGVRs are the resources StepActions may be served as, in order of
+// preference. StepActions were introduced as v1alpha1
+var stepActionGVRs = []schema.GroupVersionResource{
+	DefaultStepActionGVR,
+	{Group: tektonGroup, Version: "v1alpha1", Resource: "stepactions"},
+}
+
+// GetStepActionGVR returns the StepActionGVR property if set or the
+// DefaultStepActionGVR otherwise
+func (o Options) GetStepActionGVR() schema.GroupVersionResource {
+	if o.StepActionGVR != (schema.GroupVersionResource{}) {
+		return o.StepActionGVR
+	}
+	return DefaultStepActionGVR
+}
+
+// DetectStepActionGVR returns the preferred resource the cluster serves
+// StepActions as, found is false if StepActions are not supported
+func DetectStepActionGVR(client discovery.DiscoveryInterface) (gvr schema.GroupVersionResource, found bool) {
+	return detectGVR(client, stepActionGVRs)
+}
+
+// StepActionReference is a Task step referencing a StepAction, and the
+// StepAction it resolves to. Unresolved references have a reason instead
+type StepActionReference struct {
+	Step       string                     `json:"step"`
+	Name       string                     `json:"name,omitempty"`
+	Resolved   bool                       `json:"resolved"`
+	Reason     string                     `json:"reason,omitempty"`
+	StepAction *unstructured.Unstructured `json:"stepAction,omitempty"`
+}
+
+// GetStepActions lists the StepActions in a namespace, optionally filtered
+// with the labelSelector query parameter. When NDJSON is accepted they are
+// listed a page at a time and written one per line
+func (r Resource) GetStepActions(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	selector := request.QueryParameter("labelSelector")
+	if _, err := labels.Parse(selector); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	listOptions := metav1.ListOptions{LabelSelector: selector}
+	if acceptsNDJSON(request) {
+		streamNDJSON(response, r.DynamicClient.Resource(r.Options.GetStepActionGVR()).Namespace(namespace), listOptions)
+		return
+	}
+
+	stepActions, err := r.DynamicClient.Resource(r.Options.GetStepActionGVR()).Namespace(namespace).List(listOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	response.WriteEntity(stepActions)
+}
+
+// GetStepAction gets a single StepAction by name
+func (r Resource) GetStepAction(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	stepAction, err := r.DynamicClient.Resource(r.Options.GetStepActionGVR()).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	response.WriteEntity(stepAction)
+}
+
+// GetTaskStepActions resolves the StepAction referenced by each step of a
+// Task, in step order. Steps without a ref are left out, StepActions the user
+// may not get are unresolved
+func (r Resource) GetTaskStepActions(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	task, err := r.getTektonResource("tasks", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	steps, _, _ := unstructured.NestedSlice(task.Object, "spec", "steps")
+	references := []StepActionReference{}
+	// StepActions referenced by several steps are only fetched once
+	fetched := map[string]StepActionReference{}
+	for _, s := range steps {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, ok, _ := unstructured.NestedMap(step, "ref")
+		if !ok {
+			continue
+		}
+		stepName, _ := step["name"].(string)
+		refName, _ := ref["name"].(string)
+		if refName == "" {
+			resolver, _ := ref["resolver"].(string)
+			references = append(references, StepActionReference{Step: stepName, Reason: fmt.Sprintf("remote resolution with resolver '%s' is not supported", resolver)})
+			continue
+		}
+		reference, ok := fetched[refName]
+		if !ok {
+			reference, err = r.resolveStepAction(request, namespace, refName)
+			if err != nil {
+				utils.RespondError(response, err, http.StatusInternalServerError)
+				return
+			}
+			fetched[refName] = reference
+		}
+		reference.Step = stepName
+		references = append(references, reference)
+	}
+	response.WriteEntity(references)
+}
+
+// resolveStepAction gets a StepAction referenced by name, a missing StepAction
+// is an unresolved reference rather than an error
+func (r Resource) resolveStepAction(request *restful.Request, namespace, name string) (StepActionReference, error) {
+	allowed, err := r.authorized(request, "get", "StepAction", namespace, name)
+	if err != nil {
+		return StepActionReference{}, err
+	}
+	if !allowed {
+		return StepActionReference{Name: name, Reason: fmt.Sprintf("user '%s' cannot get StepAction %s in namespace %s", request.HeaderParameter(UserHeader), name, namespace)}, nil
+	}
+	stepAction, err := r.DynamicClient.Resource(r.Options.GetStepActionGVR()).Namespace(namespace).Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return StepActionReference{Name: name, Reason: fmt.Sprintf("StepAction '%s' not found", name)}, nil
+	}
+	if err != nil {
+		return StepActionReference{}, err
+	}
+	return StepActionReference{Name: name, Resolved: true, StepAction: stepAction}, nil
+}
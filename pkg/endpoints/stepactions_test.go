@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// The StepActions referenced by a Task's steps are resolved, and their
+// creation is broadcast
+func TestGETTaskStepActions(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	subscriber, _ := ResourcesBroadcaster.Subscribe()
+	defer ResourcesBroadcaster.Unsubscribe(subscriber)
+
+	stepActions := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "stepactions"}
+	stepAction := testutils.GetObject("v1beta1", "StepAction", namespace, "git-clone", "1")
+	if _, err := r.DynamicClient.Resource(stepActions).Namespace(namespace).Create(stepAction, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating stepAction: %v", err)
+	}
+	timeout := time.After(5 * time.Second)
+	for created := false; !created; {
+		select {
+		case <-timeout:
+			t.Fatalf("Expected a %s event", broadcaster.StepActionCreated)
+		case event := <-subscriber.SubChan():
+			created = event.MessageType == broadcaster.StepActionCreated
+		}
+	}
+
+	task := testutils.GetObject("v1beta1", "Task", namespace, "build", "1")
+	unstructured.SetNestedSlice(task.Object, []interface{}{
+		map[string]interface{}{"name": "clone", "ref": map[string]interface{}{"name": "git-clone"}},
+		map[string]interface{}{"name": "compile", "image": "golang"},
+		map[string]interface{}{"name": "lint", "ref": map[string]interface{}{"name": "golangci-lint"}},
+		map[string]interface{}{"name": "scan", "ref": map[string]interface{}{"resolver": "git"}},
+	}, "spec", "steps")
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/tasks/build/stepactions", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting stepActions: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var references []StepActionReference
+	if err := json.NewDecoder(response.Body).Decode(&references); err != nil {
+		t.Fatalf("Error decoding stepActions: %v", err)
+	}
+	if len(references) != 3 {
+		t.Fatalf("Expected 3 references, actual %+v", references)
+	}
+	if clone := references[0]; clone.Step != "clone" || !clone.Resolved || clone.StepAction == nil || clone.StepAction.GetName() != "git-clone" {
+		t.Errorf("Expected the clone step to resolve git-clone, actual %+v", clone)
+	}
+	expected := StepActionReference{Step: "lint", Name: "golangci-lint", Reason: "StepAction 'golangci-lint' not found"}
+	if lint := references[1]; lint != expected {
+		t.Errorf("Expected %+v, actual %+v", expected, lint)
+	}
+	if scan := references[2]; scan.Step != "scan" || scan.Resolved || scan.Reason == "" {
+		t.Errorf("Expected the remotely resolved scan step to be unresolved, actual %+v", scan)
+	}
+
+	for name, expected := range map[string]int{"git-clone": http.StatusOK, "missing": http.StatusNotFound} {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/stepactions/%s", server.URL, namespace, name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting stepAction: %v", err)
+		}
+		if response.StatusCode != expected {
+			t.Errorf("%s: expected statusCode %d, actual %d", name, expected, response.StatusCode)
+		}
+	}
+}
+
+// Older Tekton releases serve StepActions as v1alpha1
+func TestDetectStepActionGVR(t *testing.T) {
+	client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	if _, found := DetectStepActionGVR(client); found {
+		t.Error("Expected no StepAction resource without Tekton")
+	}
+
+	client.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "tekton.dev/v1alpha1",
+		APIResources: []metav1.APIResource{{Name: "stepactions"}, {Name: "runs"}},
+	}}
+	expected := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1alpha1", Resource: "stepactions"}
+	if gvr, found := DetectStepActionGVR(client); !found || gvr != expected {
+		t.Errorf("Expected %v, actual %v (found %t)", expected, gvr, found)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Once a step's log stream ends, how long to wait for the pod to report the
+// step as terminated
+const (
+	stepCompletionInterval = 500 * time.Millisecond
+	stepCompletionTimeout  = 10 * time.Second
+)
+
+// maxLogLineSize is the longest log line sent, longer lines end the stream
+const maxLogLineSize = 1024 * 1024
+
+// LogLine is a numbered line of a container log, numbering starts at 1
+type LogLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// StepCompletion is sent once a streamed step has terminated
+type StepCompletion struct {
+	Step     string `json:"step"`
+	ExitCode int32  `json:"exitCode"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// EstablishStepLogsWebsocket streams the log of a single step as Log messages,
+// skipping the first sinceLine lines so clients can resume, followed by a
+// StepCompleted message once the step has terminated. grep only sends the
+// lines matching a regular expression, numbered as in the whole log
+func (r Resource) EstablishStepLogsWebsocket(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	step := request.PathParameter("step")
+
+	sinceLine := 0
+	if value := request.QueryParameter("sinceLine"); value != "" {
+		var err error
+		if sinceLine, err = strconv.Atoi(value); err != nil || sinceLine < 0 {
+			websocket.RespondUpgradeError(response, http.StatusBadRequest, fmt.Sprintf("invalid sinceLine '%s'", value))
+			return
+		}
+	}
+	grep, err := grepParameter(request)
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		websocket.RespondUpgradeError(response, errorStatus(err), err.Error())
+		return
+	}
+	container := stepContainerPrefix + step
+	if !hasContainer(pod, container) {
+		websocket.RespondUpgradeError(response, http.StatusNotFound, fmt.Sprintf("step '%s' not found in TaskRun %s", step, name))
+		return
+	}
+
+	if !acquireLogStream(request, response) {
+		return
+	}
+	defer LogStreams.release()
+	stream, err := PodLogs(r.K8sClient, namespace, pod.Name, &corev1.PodLogOptions{Container: container, Follow: true})
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer stream.Close()
+
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+	// Closing the stream unblocks the scanner when the client goes away
+	websocket.MonitorConnection(connection, func() {
+		stream.Close()
+	})
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line <= sinceLine || (grep != nil && !grep.MatchString(scanner.Text())) {
+			continue
+		}
+		data := broadcaster.SocketData{
+			MessageType: broadcaster.Log,
+			Payload:     LogLine{Line: line, Text: scanner.Text()},
+		}
+		if !websocket.Send(connection, data) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logging.Log.Debugf("Log stream of step %s of TaskRun %s ended: %s", step, name, err)
+		websocket.ReportClosing(connection)
+		return
+	}
+
+	completion, err := r.awaitStepCompletion(namespace, pod.Name, container)
+	if err != nil {
+		logging.Log.Errorf("Step %s of TaskRun %s not reported as terminated: %s", step, name, err)
+		websocket.ReportClosing(connection)
+		return
+	}
+	completion.Step = step
+	if websocket.Send(connection, broadcaster.SocketData{MessageType: broadcaster.StepCompleted, Payload: completion}) {
+		websocket.ReportClosing(connection)
+	}
+}
+
+// hasContainer reports whether the pod has a container or init container
+// with the given name
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, container := range containers {
+			if container.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// awaitStepCompletion waits for the pod to report the container as terminated
+func (r Resource) awaitStepCompletion(namespace, podName, container string) (StepCompletion, error) {
+	var completion StepCompletion
+	err := wait.PollImmediate(stepCompletionInterval, stepCompletionTimeout, func() (bool, error) {
+		pod, err := r.K8sClient.CoreV1().Pods(namespace).Get(podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container && status.State.Terminated != nil {
+				completion.ExitCode = status.State.Terminated.ExitCode
+				completion.Reason = status.State.Terminated.Reason
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return completion, err
+}
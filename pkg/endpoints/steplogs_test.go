@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// readUntilClosed reads all messages from a websocket until the server closes it
+func readUntilClosed(t *testing.T, websocketURL string) []broadcaster.SocketData {
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL, nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL, err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var messages []broadcaster.SocketData
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			if !gorillaSocket.IsCloseError(err, gorillaSocket.CloseNormalClosure) {
+				t.Errorf("Expected websocket to be closed normally, actual %s", err)
+			}
+			return messages
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		messages = append(messages, socketData)
+	}
+}
+
+// The step logs websocket resumes after sinceLine and reports completion
+func TestStepLogsWebsocket(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	defer stubPodLogs(map[string]string{"step-build": "first\nsecond\nthird\n"})()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "build-pod", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-build"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-build",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"},
+				},
+			}},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     fmt.Sprintf("/v1/websockets/namespaces/%s/taskruns/build/steps/build/logs", namespace),
+		RawQuery: "sinceLine=1",
+	}
+	messages := readUntilClosed(t, websocketURL.String())
+	if len(messages) != 3 {
+		t.Fatalf("Expected 2 log lines and a completion, actual %v", messages)
+	}
+
+	for i, expected := range []LogLine{{Line: 2, Text: "second"}, {Line: 3, Text: "third"}} {
+		var line LogLine
+		payload, _ := json.Marshal(messages[i].Payload)
+		json.Unmarshal(payload, &line)
+		if messages[i].MessageType != broadcaster.Log || line != expected {
+			t.Errorf("Expected log line %+v, actual %s %+v", expected, messages[i].MessageType, line)
+		}
+	}
+
+	var completion StepCompletion
+	payload, _ := json.Marshal(messages[2].Payload)
+	json.Unmarshal(payload, &completion)
+	expected := StepCompletion{Step: "build", ExitCode: 0, Reason: "Completed"}
+	if messages[2].MessageType != broadcaster.StepCompleted || !reflect.DeepEqual(completion, expected) {
+		t.Errorf("Expected completion %+v, actual %s %+v", expected, messages[2].MessageType, completion)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+)
+
+// stepContainerPrefix is prepended by Tekton to the name of each step container
+const stepContainerPrefix = "step-"
+
+// ResolvedStep is the container a TaskRun step actually ran, as recorded on
+// the backing pod
+type ResolvedStep struct {
+	Name       string   `json:"name"`
+	Container  string   `json:"container"`
+	Image      string   `json:"image"`
+	ImageID    string   `json:"imageID,omitempty"`
+	Command    []string `json:"command"`
+	Args       []string `json:"args"`
+	WorkingDir string   `json:"workingDir,omitempty"`
+}
+
+// GetTaskRunSteps returns the image, command and args each step of a TaskRun
+// was run with, after Tekton's entrypoint rewriting
+func (r Resource) GetTaskRunSteps(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pod, err := r.getTaskRunPod(namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+
+	imageIDs := map[string]string{}
+	for _, status := range pod.Status.ContainerStatuses {
+		imageIDs[status.Name] = status.ImageID
+	}
+
+	steps := []ResolvedStep{}
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+			continue
+		}
+		steps = append(steps, ResolvedStep{
+			Name:       strings.TrimPrefix(container.Name, stepContainerPrefix),
+			Container:  container.Name,
+			Image:      container.Image,
+			ImageID:    imageIDs[container.Name],
+			Command:    container.Command,
+			Args:       container.Args,
+			WorkingDir: container.WorkingDir,
+		})
+	}
+
+	response.WriteEntity(steps)
+}
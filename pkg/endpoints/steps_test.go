@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET TaskRun steps reports the resolved container of each step
+func TestGETTaskRunSteps(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1")
+	unstructured.SetNestedField(taskRun.Object, "build-pod", "status", "podName")
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "build-pod",
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "place-tools", Image: "entrypoint"}},
+			Containers: []corev1.Container{
+				{
+					Name:       "step-compile",
+					Image:      "golang:1.15",
+					Command:    []string{"/tekton/tools/entrypoint"},
+					Args:       []string{"-entrypoint", "go", "--", "build", "./..."},
+					WorkingDir: "/workspace/source",
+				},
+				{
+					Name:    "step-test",
+					Image:   "golang:1.15",
+					Command: []string{"/tekton/tools/entrypoint"},
+					Args:    []string{"-wait_file", "/tekton/tools/0", "-entrypoint", "go", "--", "test", "./..."},
+				},
+				{Name: "sidecar-registry", Image: "registry"},
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-compile", ImageID: "docker-pullable://golang@sha256:1234"},
+			},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(&pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/build/steps", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting steps: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var steps []ResolvedStep
+	if err := json.NewDecoder(response.Body).Decode(&steps); err != nil {
+		t.Fatalf("Error decoding steps: %v", err)
+	}
+
+	expected := []ResolvedStep{
+		{
+			Name:       "compile",
+			Container:  "step-compile",
+			Image:      "golang:1.15",
+			ImageID:    "docker-pullable://golang@sha256:1234",
+			Command:    []string{"/tekton/tools/entrypoint"},
+			Args:       []string{"-entrypoint", "go", "--", "build", "./..."},
+			WorkingDir: "/workspace/source",
+		},
+		{
+			Name:      "test",
+			Container: "step-test",
+			Image:     "golang:1.15",
+			Command:   []string{"/tekton/tools/entrypoint"},
+			Args:      []string{"-wait_file", "/tekton/tools/0", "-entrypoint", "go", "--", "test", "./..."},
+		},
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("Expected steps %+v, actual %+v", expected, steps)
+	}
+}
+
+// GET TaskRun steps returns 404 until the TaskRun has a pod
+func TestGETTaskRunStepsWithoutPod(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "pending", "1")
+	gvr := schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1beta1",
+		Resource: "taskruns",
+	}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/taskruns/pending/steps", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting steps: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Labels Tekton sets on the runs created for a Pipeline
+const (
+	PipelineLabel     = "tekton.dev/pipeline"
+	PipelineTaskLabel = "tekton.dev/pipelineTask"
+)
+
+// How many of a Pipeline's most recent PipelineRuns are inspected by default
+// and at most
+const (
+	defaultStepStatsRuns = 20
+	maxStepStatsRuns     = 100
+)
+
+// StepStats is the distribution of the durations of one step of a Pipeline
+type StepStats struct {
+	PipelineTask string        `json:"pipelineTask"`
+	Step         string        `json:"step"`
+	Stats        DurationStats `json:"stats"`
+}
+
+type stepKey struct {
+	pipelineTask string
+	step         string
+}
+
+// GetPipelineStepStats aggregates the durations of each step of a Pipeline's
+// TaskRuns over its most recent PipelineRuns, slowest mean first
+func (r Resource) GetPipelineStepStats(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	runs, err := intParameter(request, "runs", defaultStepStatsRuns, maxStepStatsRuns)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: PipelineLabel + "=" + name}
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(listOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	recent := recentRunNames(pipelineRuns.Items, runs)
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(listOptions)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	durations := map[stepKey][]time.Duration{}
+	for i := range taskRuns.Items {
+		taskRun := &taskRuns.Items[i]
+		labels := taskRun.GetLabels()
+		if !recent[labels[PipelineRunLabel]] {
+			continue
+		}
+		steps, _, _ := unstructured.NestedSlice(taskRun.Object, "status", "steps")
+		for _, s := range steps {
+			step, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			duration, ok := stepDuration(step)
+			if !ok {
+				continue
+			}
+			stepName, _ := step["name"].(string)
+			key := stepKey{pipelineTask: labels[PipelineTaskLabel], step: stepName}
+			durations[key] = append(durations[key], duration)
+		}
+	}
+
+	stats := []StepStats{}
+	for key, stepDurations := range durations {
+		stats = append(stats, StepStats{
+			PipelineTask: key.pipelineTask,
+			Step:         key.step,
+			Stats:        computeDurationStats(stepDurations),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Stats.Mean != stats[j].Stats.Mean {
+			return stats[i].Stats.Mean > stats[j].Stats.Mean
+		}
+		if stats[i].PipelineTask != stats[j].PipelineTask {
+			return stats[i].PipelineTask < stats[j].PipelineTask
+		}
+		return stats[i].Step < stats[j].Step
+	})
+
+	response.WriteEntity(stats)
+}
+
+// recentRunNames returns the names of the count most recently created runs
+func recentRunNames(items []unstructured.Unstructured, count int) map[string]bool {
+	names := map[string]bool{}
+	for _, run := range recentRuns(items, count) {
+		names[run.GetName()] = true
+	}
+	return names
+}
+
+// recentRuns returns the count most recently created runs, newest first
+func recentRuns(items []unstructured.Unstructured, count int) []*unstructured.Unstructured {
+	sorted := make([]*unstructured.Unstructured, len(items))
+	for i := range items {
+		sorted[i] = &items[i]
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetCreationTimestamp().After(sorted[j].GetCreationTimestamp().Time)
+	})
+	if len(sorted) > count {
+		sorted = sorted[:count]
+	}
+	return sorted
+}
+
+// stepDuration reads how long a terminated step ran from its status
+func stepDuration(step map[string]interface{}) (time.Duration, bool) {
+	status := &unstructured.Unstructured{Object: step}
+	started, ok := nestedTime(status, "terminated", "startedAt")
+	if !ok {
+		return 0, false
+	}
+	finished, ok := nestedTime(status, "terminated", "finishedAt")
+	if !ok || finished.Before(started) {
+		return 0, false
+	}
+	return finished.Sub(started), true
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// terminatedStep returns the status of a step that ran for the given duration
+func terminatedStep(name string, duration time.Duration) interface{} {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	return map[string]interface{}{
+		"name": name,
+		"terminated": map[string]interface{}{
+			"startedAt":  start.Format(time.RFC3339),
+			"finishedAt": start.Add(duration).Format(time.RFC3339),
+		},
+	}
+}
+
+// GET Pipeline step stats aggregates step durations over the recent runs
+func TestGETPipelineStepStats(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	taskRunsGVR := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	now := time.Now()
+	runs := []struct {
+		name    string
+		age     time.Duration
+		compile time.Duration
+	}{
+		{"old", 3 * time.Hour, time.Hour},
+		{"recent", 2 * time.Hour, 10 * time.Second},
+		{"latest", time.Hour, 30 * time.Second},
+	}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		pipelineRun.SetLabels(map[string]string{PipelineLabel: "build"})
+		pipelineRun.SetCreationTimestamp(metav1.NewTime(now.Add(-run.age)))
+		if _, err := r.DynamicClient.Resource(pipelineRunsGVR).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, run.name+"-compile", "1")
+		taskRun.SetLabels(map[string]string{PipelineLabel: "build", PipelineRunLabel: run.name, PipelineTaskLabel: "compile"})
+		unstructured.SetNestedSlice(taskRun.Object, []interface{}{
+			terminatedStep("fetch", 5*time.Second),
+			terminatedStep("compile", run.compile),
+			map[string]interface{}{"name": "upload", "running": map[string]interface{}{}},
+		}, "status", "steps")
+		if _, err := r.DynamicClient.Resource(taskRunsGVR).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelines/build/step-stats?runs=2", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting step stats: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var stats []StepStats
+	if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		t.Fatalf("Error decoding step stats: %v", err)
+	}
+	expected := []StepStats{
+		{PipelineTask: "compile", Step: "compile", Stats: DurationStats{Count: 2, Min: 10, Max: 30, Mean: 20, P50: 10, P95: 30}},
+		{PipelineTask: "compile", Step: "fetch", Stats: DurationStats{Count: 2, Min: 5, Max: 5, Mean: 5, P50: 5, P95: 5}},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Expected step stats %+v, actual %+v", expected, stats)
+	}
+}
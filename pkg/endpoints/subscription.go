@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+)
+
+// Actions of a websocket control message
+const (
+	ControlSubscribe   = "subscribe"
+	ControlUnsubscribe = "unsubscribe"
+)
+
+// ControlMessage is sent by websocket clients to change the events they
+// receive without reconnecting
+type ControlMessage struct {
+	Action    string   `json:"action"`
+	Kinds     []string `json:"kinds,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	// Namespaces are handled as Namespace, for several at once
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// namespaces returns the namespaces of the message, from both Namespace and
+// Namespaces
+func (m ControlMessage) namespaces() []string {
+	var namespaces []string
+	if m.Namespace != "" {
+		namespaces = append(namespaces, m.Namespace)
+	}
+	for _, namespace := range m.Namespaces {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// subscription tracks the kinds and namespaces a websocket client asked for.
+// It is only changed from the goroutine reading the client's messages
+type subscription struct {
+	// kinds is nil until the client subscribes to specific kinds
+	kinds    map[string]bool
+	excluded map[string]bool
+	// namespaces is nil while the client receives events of all namespaces
+	namespaces map[string]bool
+}
+
+// apply updates the subscription with a control message. Subscribing to kinds
+// narrows the events to those kinds, subscribing to namespaces narrows them to
+// those namespaces, further subscriptions add kinds and namespaces.
+// Unsubscribing removes kinds or namespaces, once the last namespace is
+// removed events of all namespaces are sent again
+func (s *subscription) apply(message ControlMessage) error {
+	namespaces := message.namespaces()
+	switch message.Action {
+	case ControlSubscribe:
+		if len(message.Kinds) == 0 && len(namespaces) == 0 {
+			return errors.New("subscribe requires kinds or a namespace")
+		}
+		for _, kind := range message.Kinds {
+			if !knownKind(kind, false) {
+				return fmt.Errorf("unknown kind '%s'", kind)
+			}
+		}
+		for _, kind := range message.Kinds {
+			if s.kinds == nil {
+				s.kinds = map[string]bool{}
+			}
+			s.kinds[strings.ToLower(kind)] = true
+			delete(s.excluded, strings.ToLower(kind))
+		}
+		for _, namespace := range namespaces {
+			if s.namespaces == nil {
+				s.namespaces = map[string]bool{}
+			}
+			s.namespaces[namespace] = true
+		}
+	case ControlUnsubscribe:
+		if len(message.Kinds) == 0 && len(namespaces) == 0 {
+			return errors.New("unsubscribe requires kinds or a namespace")
+		}
+		for _, kind := range message.Kinds {
+			if s.excluded == nil {
+				s.excluded = map[string]bool{}
+			}
+			delete(s.kinds, strings.ToLower(kind))
+			s.excluded[strings.ToLower(kind)] = true
+		}
+		for _, namespace := range namespaces {
+			delete(s.namespaces, namespace)
+		}
+		if len(s.namespaces) == 0 {
+			s.namespaces = nil
+		}
+	default:
+		return fmt.Errorf("unknown action '%s', must be %s or %s", message.Action, ControlSubscribe, ControlUnsubscribe)
+	}
+	return nil
+}
+
+// filter returns a broadcaster filter for the current state of the
+// subscription, later changes to the subscription do not affect it
+func (s *subscription) filter() broadcaster.Filter[broadcaster.SocketData] {
+	kinds := copySet(s.kinds)
+	excluded := copySet(s.excluded)
+	var inSelectedNamespaces broadcaster.Filter[broadcaster.SocketData]
+	if s.namespaces != nil {
+		inSelectedNamespaces = inNamespaces(copySet(s.namespaces))
+	}
+	return func(data broadcaster.SocketData) bool {
+		kind := strings.ToLower(messageKind(data.MessageType))
+		if excluded[kind] || (kinds != nil && !kinds[kind]) {
+			return false
+		}
+		return inSelectedNamespaces == nil || inSelectedNamespaces(data)
+	}
+}
+
+func copySet(set map[string]bool) map[string]bool {
+	if set == nil {
+		return nil
+	}
+	copied := make(map[string]bool, len(set))
+	for key := range set {
+		copied[key] = true
+	}
+	return copied
+}
+
+// messageKind removes the event suffix from a message type, e.g. TaskRun for
+// TaskRunCreated
+func messageKind(messageType broadcaster.MessageType) string {
+	kind := string(messageType)
+	for _, event := range []string{"Created", "Updated", "Deleted"} {
+		kind = strings.TrimSuffix(kind, event)
+	}
+	return kind
+}
+
+// handleControlMessage applies a control message sent over the resources
+// websocket to the client's subscription. Invalid messages leave the
+// subscription unchanged and are answered with a ControlError message
+func (s *subscription) handleControlMessage(subscriber *broadcaster.Subscriber[broadcaster.SocketData], payload []byte) *broadcaster.SocketData {
+	var message ControlMessage
+	err := json.Unmarshal(payload, &message)
+	if err == nil {
+		err = s.apply(message)
+	}
+	if err != nil {
+		return &broadcaster.SocketData{MessageType: broadcaster.ControlError, Payload: fmt.Sprintf("invalid control message: %s", err)}
+	}
+	subscriber.SetFilter(s.filter())
+	return nil
+}
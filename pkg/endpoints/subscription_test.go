@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Control messages narrow the events sent to a connected client
+func TestWebsocketControlMessages(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{Scheme: "ws", Host: strings.TrimPrefix(server.URL, "http://"), Path: "/v1/websockets/resources"}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	read := func() broadcaster.SocketData {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		return socketData
+	}
+
+	if err := connection.WriteJSON(ControlMessage{Action: ControlSubscribe, Kinds: []string{"TaskRun"}}); err != nil {
+		t.Fatalf("Error sending control message: %s", err)
+	}
+	// Control messages are handled in order, so the error reply shows the
+	// subscription has been applied and the connection is still open
+	if err := connection.WriteMessage(gorillaSocket.TextMessage, []byte(`{"action": "resubscribe"}`)); err != nil {
+		t.Fatalf("Error sending control message: %s", err)
+	}
+	if reply := read(); reply.MessageType != broadcaster.ControlError {
+		t.Fatalf("Expected %s reply to invalid control message, actual %s", broadcaster.ControlError, reply.MessageType)
+	}
+
+	for _, resource := range []string{"tasks", "taskruns"} {
+		gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: resource}
+		object := testutils.GetObject("v1beta1", "", namespace, "filtered", "1")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating %s: %v", resource, err)
+		}
+	}
+
+	if socketData := read(); socketData.MessageType != broadcaster.TaskRunCreated {
+		t.Errorf("Expected only %s after subscribing to TaskRuns, actual %s", broadcaster.TaskRunCreated, socketData.MessageType)
+	}
+	// Give a late Task event the chance to show up
+	connection.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	if _, message, err := connection.ReadMessage(); err == nil {
+		t.Errorf("Expected no further messages, actual %s", message)
+	}
+}
+
+// Control messages subscribe to several namespaces and unsubscribe from them
+// one at a time, unknown kinds are rejected
+func TestWebsocketControlNamespaces(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{Scheme: "ws", Host: strings.TrimPrefix(server.URL, "http://"), Path: "/v1/websockets/resources"}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	read := func() broadcaster.SocketData {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		return socketData
+	}
+	// The error reply to an unknown kind shows the previous messages applied
+	control := func(message ControlMessage) {
+		for _, message := range []ControlMessage{message, {Action: ControlSubscribe, Kinds: []string{"Tsk"}}} {
+			if err := connection.WriteJSON(message); err != nil {
+				t.Fatalf("Error sending control message: %s", err)
+			}
+		}
+		if reply := read(); reply.MessageType != broadcaster.ControlError || !strings.Contains(reply.Payload.(string), "Tsk") {
+			t.Fatalf("Expected %s reply naming the unknown kind, actual %s %v", broadcaster.ControlError, reply.MessageType, reply.Payload)
+		}
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	createTasks := func(name string, namespaces ...string) {
+		for _, namespace := range namespaces {
+			if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(testutils.GetObject("v1beta1", "Task", namespace, name, "1"), metav1.CreateOptions{}); err != nil {
+				t.Fatalf("Error creating task: %v", err)
+			}
+		}
+	}
+	readNamespaces := func(count int) map[string]bool {
+		received := map[string]bool{}
+		for i := 0; i < count; i++ {
+			socketData := read()
+			payload, _ := socketData.Payload.(map[string]interface{})
+			metadata, _ := payload["metadata"].(map[string]interface{})
+			received[fmt.Sprint(metadata["namespace"])] = true
+		}
+		return received
+	}
+
+	control(ControlMessage{Action: ControlSubscribe, Kinds: []string{"Task"}, Namespaces: []string{namespace, "other"}})
+	createTasks("first", namespace, "other", "ignored")
+	if received := readNamespaces(2); !received[namespace] || !received["other"] {
+		t.Errorf("Expected events of %s and other, actual %v", namespace, received)
+	}
+
+	control(ControlMessage{Action: ControlUnsubscribe, Namespace: namespace})
+	createTasks("second", namespace, "other")
+	if received := readNamespaces(1); !received["other"] {
+		t.Errorf("Expected events of other only, actual %v", received)
+	}
+	// Give a late event of an unsubscribed namespace the chance to show up
+	connection.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	if _, message, err := connection.ReadMessage(); err == nil {
+		t.Errorf("Expected no further messages, actual %s", message)
+	}
+}
+
+// Objects too large to send are narrowed by the namespaces subscribed to with
+// control messages too
+func TestWebsocketControlNamespacesTruncated(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	ResourcesBroadcaster.SetMaxObjectSize(1)
+	defer ResourcesBroadcaster.SetMaxObjectSize(0)
+
+	websocketURL := url.URL{Scheme: "ws", Host: strings.TrimPrefix(server.URL, "http://"), Path: "/v1/websockets/resources"}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	read := func() broadcaster.SocketData {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		return socketData
+	}
+	// The error reply to an unknown kind shows the subscription applied
+	for _, message := range []ControlMessage{
+		{Action: ControlSubscribe, Kinds: []string{"Task"}, Namespaces: []string{namespace}},
+		{Action: ControlSubscribe, Kinds: []string{"Tsk"}},
+	} {
+		if err := connection.WriteJSON(message); err != nil {
+			t.Fatalf("Error sending control message: %s", err)
+		}
+	}
+	if reply := read(); reply.MessageType != broadcaster.ControlError {
+		t.Fatalf("Expected %s reply, actual %s %v", broadcaster.ControlError, reply.MessageType, reply.Payload)
+	}
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	for _, taskNamespace := range []string{"other", namespace} {
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(taskNamespace).Create(testutils.GetObject("v1beta1", "Task", taskNamespace, "truncated", "1"), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+	socketData := read()
+	payload, _ := socketData.Payload.(map[string]interface{})
+	if !socketData.Truncated || payload["namespace"] != namespace {
+		t.Errorf("Expected truncated reference in %s, actual %v", namespace, socketData.Payload)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RunSummary counts PipelineRuns by status for the whole cluster and for each
+// namespace
+type RunSummary struct {
+	Total      map[string]int            `json:"total"`
+	Namespaces map[string]map[string]int `json:"namespaces"`
+}
+
+// GetPipelineRunSummary counts the PipelineRuns of every namespace by status,
+// reading from the informer cache when available so cluster admins can check
+// the health of all runs without listing them from the API server
+func (r Resource) GetPipelineRunSummary(request *restful.Request, response *restful.Response) {
+	pipelineRuns, err := r.allPipelineRuns()
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+	summary := RunSummary{Total: map[string]int{}, Namespaces: map[string]map[string]int{}}
+	for _, pipelineRun := range pipelineRuns {
+		status := runStatus(pipelineRun)
+		namespace := pipelineRun.GetNamespace()
+		if summary.Namespaces[namespace] == nil {
+			summary.Namespaces[namespace] = map[string]int{}
+		}
+		summary.Namespaces[namespace][status]++
+		summary.Total[status]++
+	}
+	response.WriteEntity(summary)
+}
+
+// allPipelineRuns returns the PipelineRuns of the tenant namespace, or of the
+// cluster when the dashboard is not restricted to one
+func (r Resource) allPipelineRuns() ([]*unstructured.Unstructured, error) {
+	pipelineRuns := []*unstructured.Unstructured{}
+	if r.PipelineRunLister != nil {
+		objects, err := r.PipelineRunLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			if pipelineRun, ok := object.(*unstructured.Unstructured); ok && !r.Options.hides(pipelineRun) {
+				pipelineRuns = append(pipelineRuns, pipelineRun)
+			}
+		}
+		return pipelineRuns, nil
+	}
+	list, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(r.Options.TenantNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if !r.Options.hides(&list.Items[i]) {
+			pipelineRuns = append(pipelineRuns, &list.Items[i])
+		}
+	}
+	return pipelineRuns, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/router"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// namespacedAuthorizer only allows requests scoped to a namespace
+type namespacedAuthorizer struct{}
+
+func (namespacedAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	return namespace != "", nil
+}
+
+// GET PipelineRun summary counts runs by status per namespace and in total
+func TestGETPipelineRunSummary(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+
+	pipelineRuns := []struct {
+		namespace string
+		name      string
+		status    string
+	}{
+		{"team-a", "passed", "True"},
+		{"team-a", "failed", "False"},
+		{"team-a", "running", "Unknown"},
+		{"team-b", "passed-1", "True"},
+		{"team-b", "passed-2", "True"},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, run := range pipelineRuns {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", run.namespace, run.name, "1")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(run.namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+	awaitFatal(func() bool {
+		cached, _ := r.PipelineRunLister.List(labels.Everything())
+		return len(cached) == len(pipelineRuns)
+	}, t, "PipelineRuns should be cached")
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/pipelineruns/summary/all", server.URL), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting summary: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var summary RunSummary
+	if err := json.NewDecoder(response.Body).Decode(&summary); err != nil {
+		t.Fatalf("Error decoding summary: %v", err)
+	}
+	expected := RunSummary{
+		Total: map[string]int{RunSucceeded: 3, RunFailed: 1, RunRunning: 1},
+		Namespaces: map[string]map[string]int{
+			"team-a": {RunSucceeded: 1, RunFailed: 1, RunRunning: 1},
+			"team-b": {RunSucceeded: 2},
+		},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("Expected summary %+v, actual %+v", expected, summary)
+	}
+}
+
+// GET PipelineRun summary requires permission to list PipelineRuns in all namespaces
+func TestGETPipelineRunSummaryForbidden(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = namespacedAuthorizer{}
+	server.Config.Handler = router.Register(*r)
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/pipelineruns/summary/all", server.URL), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting summary: %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected statusCode %d, actual %d", http.StatusForbidden, response.StatusCode)
+	}
+}
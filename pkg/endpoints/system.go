@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Health statuses of a Tekton component
+const (
+	ComponentHealthy   = "healthy"
+	ComponentUnhealthy = "unhealthy"
+	ComponentUnknown   = "unknown"
+)
+
+// systemDeployments are the Tekton Pipelines deployments checked by the
+// system health endpoint, in the order they are reported
+var systemDeployments = []string{"tekton-pipelines-controller", "tekton-pipelines-webhook"}
+
+// ComponentHealth is the readiness of the deployment of a Tekton component.
+// Crashes are the containers of its pods that restarted, with the reason they
+// last terminated
+type ComponentHealth struct {
+	Name            string           `json:"name"`
+	Namespace       string           `json:"namespace"`
+	Status          string           `json:"status"`
+	Replicas        int32            `json:"replicas"`
+	ReadyReplicas   int32            `json:"readyReplicas"`
+	UpdatedReplicas int32            `json:"updatedReplicas"`
+	Crashes         []ContainerCrash `json:"crashes,omitempty"`
+}
+
+// ContainerCrash is the last termination of a restarted container
+type ContainerCrash struct {
+	Pod          string     `json:"pod"`
+	Container    string     `json:"container"`
+	RestartCount int32      `json:"restartCount"`
+	Reason       string     `json:"reason,omitempty"`
+	ExitCode     int32      `json:"exitCode"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+}
+
+// GetSystemHealth reports the readiness of the Tekton Pipelines controller
+// and webhook deployments, to tell apart runs that do not progress because of
+// the Tekton install. A deployment that cannot be found is reported unknown
+func (r Resource) GetSystemHealth(request *restful.Request, response *restful.Response) {
+	namespace := r.Options.GetPipelinesNamespace()
+	components := []ComponentHealth{}
+	for _, name := range systemDeployments {
+		component := ComponentHealth{Name: name, Namespace: namespace, Status: ComponentUnknown}
+		deployment, err := r.K8sClient.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+			components = append(components, component)
+			continue
+		}
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		component.Replicas = deployment.Status.Replicas
+		component.ReadyReplicas = deployment.Status.ReadyReplicas
+		component.UpdatedReplicas = deployment.Status.UpdatedReplicas
+		component.Status = deploymentHealth(deployment)
+
+		crashes, err := r.deploymentCrashes(deployment)
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		component.Crashes = crashes
+		components = append(components, component)
+	}
+	response.WriteEntity(components)
+}
+
+// deploymentHealth is healthy when all desired replicas of a deployment are
+// ready and it has not reported being unavailable
+func deploymentHealth(deployment *appsv1.Deployment) string {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionFalse {
+			return ComponentUnhealthy
+		}
+	}
+	if desired == 0 || deployment.Status.ReadyReplicas < desired {
+		return ComponentUnhealthy
+	}
+	return ComponentHealthy
+}
+
+// deploymentCrashes lists the restarted containers of the pods selected by a
+// deployment
+func (r Resource) deploymentCrashes(deployment *appsv1.Deployment) ([]ContainerCrash, error) {
+	if deployment.Spec.Selector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := r.K8sClient.CoreV1().Pods(deployment.Namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var crashes []ContainerCrash
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.RestartCount == 0 {
+				continue
+			}
+			crash := ContainerCrash{Pod: pod.Name, Container: status.Name, RestartCount: status.RestartCount}
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				crash.Reason = terminated.Reason
+				crash.ExitCode = terminated.ExitCode
+				if !terminated.FinishedAt.IsZero() {
+					finishedAt := terminated.FinishedAt.Time
+					crash.FinishedAt = &finishedAt
+				}
+			}
+			crashes = append(crashes, crash)
+		}
+	}
+	return crashes, nil
+}
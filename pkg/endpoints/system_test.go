@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GET system health reports an unready controller as unhealthy with its
+// crashes, and a missing webhook as unknown
+func TestGETSystemHealth(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	replicas := int32(1)
+	podLabels := map[string]string{"app.kubernetes.io/name": "controller", "app.kubernetes.io/part-of": "tekton-pipelines"}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "tekton-pipelines-controller", Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 0},
+	}
+	if _, err := r.K8sClient.AppsV1().Deployments(namespace).Create(deployment); err != nil {
+		t.Fatalf("Error creating deployment: %v", err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tekton-pipelines-controller-abc12", Namespace: namespace, Labels: podLabels},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "tekton-pipelines-controller",
+				RestartCount:         4,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}},
+		},
+	}
+	if _, err := r.K8sClient.CoreV1().Pods(namespace).Create(pod); err != nil {
+		t.Fatalf("Error creating pod: %v", err)
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/system/health", server.URL), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting system health: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var components []ComponentHealth
+	if err := json.NewDecoder(response.Body).Decode(&components); err != nil {
+		t.Fatalf("Error decoding system health: %v", err)
+	}
+	if len(components) != 2 {
+		t.Fatalf("Expected 2 components, actual %+v", components)
+	}
+
+	controller := components[0]
+	if controller.Name != "tekton-pipelines-controller" || controller.Status != ComponentUnhealthy {
+		t.Errorf("Expected an unhealthy controller, actual %+v", controller)
+	}
+	if controller.Replicas != 1 || controller.ReadyReplicas != 0 {
+		t.Errorf("Expected 0 of 1 replicas ready, actual %d of %d", controller.ReadyReplicas, controller.Replicas)
+	}
+	if len(controller.Crashes) != 1 || controller.Crashes[0].Reason != "OOMKilled" || controller.Crashes[0].RestartCount != 4 {
+		t.Errorf("Expected an OOMKilled crash with 4 restarts, actual %+v", controller.Crashes)
+	}
+
+	if webhook := components[1]; webhook.Name != "tekton-pipelines-webhook" || webhook.Status != ComponentUnknown {
+		t.Errorf("Expected an unknown webhook, actual %+v", webhook)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CreateTaskRunRequest describes a TaskRun to create, running either the Task
+// or ClusterTask in TaskRef or the inline TaskSpec. The TaskRun is named from
+// the Task unless Name is set
+type CreateTaskRunRequest struct {
+	Name               string                   `json:"name,omitempty"`
+	TaskRef            map[string]interface{}   `json:"taskRef,omitempty"`
+	TaskSpec           map[string]interface{}   `json:"taskSpec,omitempty"`
+	Params             []map[string]interface{} `json:"params,omitempty"`
+	Workspaces         []map[string]interface{} `json:"workspaces,omitempty"`
+	ServiceAccountName string                   `json:"serviceAccountName,omitempty"`
+	Labels             map[string]string        `json:"labels,omitempty"`
+}
+
+// CreateTaskRun creates a TaskRun of a Task with the given params and
+// workspaces, once every param the Task declares without a default is set
+func (r Resource) CreateTaskRun(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	var createRequest CreateTaskRunRequest
+	if err := request.ReadEntity(&createRequest); err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	if (createRequest.TaskRef == nil) == (createRequest.TaskSpec == nil) {
+		utils.RespondErrorMessage(response, "exactly one of taskRef or taskSpec must be set", http.StatusBadRequest)
+		return
+	}
+	taskRun := newTaskRun(createRequest, r.Options.GetTektonVersion(), namespace)
+
+	declared, err := r.declaredParams(taskRun)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	if missing := missingParams(declared, createRequest.Params); len(missing) > 0 {
+		utils.RespondErrorMessage(response, fmt.Sprintf("missing required params: %s", strings.Join(missing, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	created, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).Create(taskRun, metav1.CreateOptions{})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case k8serrors.IsInvalid(err), k8serrors.IsBadRequest(err):
+			status = http.StatusBadRequest
+		case k8serrors.IsAlreadyExists(err):
+			status = http.StatusConflict
+		}
+		utils.RespondError(response, err, status)
+		return
+	}
+	response.WriteHeaderAndEntity(http.StatusCreated, created)
+}
+
+// newTaskRun builds the TaskRun described by a create request
+func newTaskRun(createRequest CreateTaskRunRequest, version, namespace string) *unstructured.Unstructured {
+	spec := map[string]interface{}{}
+	if createRequest.TaskRef != nil {
+		spec["taskRef"] = createRequest.TaskRef
+	} else {
+		spec["taskSpec"] = createRequest.TaskSpec
+	}
+	if len(createRequest.Params) > 0 {
+		spec["params"] = toInterfaceSlice(createRequest.Params)
+	}
+	if len(createRequest.Workspaces) > 0 {
+		spec["workspaces"] = toInterfaceSlice(createRequest.Workspaces)
+	}
+	if createRequest.ServiceAccountName != "" {
+		spec["serviceAccountName"] = createRequest.ServiceAccountName
+	}
+
+	taskRun := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	taskRun.SetAPIVersion(tektonGroup + "/" + version)
+	taskRun.SetKind("TaskRun")
+	taskRun.SetNamespace(namespace)
+	if createRequest.Name != "" {
+		taskRun.SetName(createRequest.Name)
+	} else if refName, _ := createRequest.TaskRef["name"].(string); refName != "" {
+		taskRun.SetGenerateName(refName + "-run-")
+	} else {
+		taskRun.SetGenerateName("run-")
+	}
+	if len(createRequest.Labels) > 0 {
+		taskRun.SetLabels(createRequest.Labels)
+	}
+	return taskRun
+}
+
+// missingParams returns the names of the declared params without a default
+// that are not set, in declaration order
+func missingParams(declared, params []map[string]interface{}) []string {
+	set := map[string]bool{}
+	for _, param := range params {
+		if name, _ := param["name"].(string); name != "" {
+			set[name] = true
+		}
+	}
+	var missing []string
+	for _, param := range declared {
+		name, _ := param["name"].(string)
+		if _, hasDefault := param["default"]; !hasDefault && !set[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// toInterfaceSlice converts a slice of maps to the form unstructured objects
+// hold lists in
+func toInterfaceSlice(items []map[string]interface{}) []interface{} {
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		converted[i] = item
+	}
+	return converted
+}
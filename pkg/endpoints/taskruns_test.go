@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// POST TaskRun creates a TaskRun of the referenced Task once its required
+// params are set
+func TestPOSTTaskRun(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	task := testutils.GetObject("tekton.dev/v1beta1", "Task", namespace, "build", "1")
+	task.Object["spec"] = map[string]interface{}{
+		"params": []interface{}{
+			map[string]interface{}{"name": "revision"},
+			map[string]interface{}{"name": "image", "default": "golang:1.15"},
+		},
+		"steps": []interface{}{map[string]interface{}{"name": "compile", "image": "$(params.image)"}},
+	}
+	if _, err := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	body := []byte(`{"name": "build-1", "taskRef": {"name": "build"}}`)
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/taskruns", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d without the revision param, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+	if message, _ := ioutil.ReadAll(response.Body); !strings.Contains(string(message), "revision") {
+		t.Errorf("Expected the missing revision param to be listed, actual %s", message)
+	}
+
+	body = []byte(`{
+		"name": "build-1",
+		"taskRef": {"name": "build"},
+		"params": [{"name": "revision", "value": "main"}],
+		"workspaces": [{"name": "source", "emptyDir": {}}],
+		"serviceAccountName": "builder"
+	}`)
+	httpReq = testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/taskruns", server.URL, namespace), bytes.NewReader(body))
+	response, err = http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	if response.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusCreated, response.StatusCode)
+	}
+
+	taskRun, err := r.DynamicClient.Resource(schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}).Namespace(namespace).Get("build-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting taskRun: %v", err)
+	}
+	expected := map[string]interface{}{
+		"taskRef":            map[string]interface{}{"name": "build"},
+		"params":             []interface{}{map[string]interface{}{"name": "revision", "value": "main"}},
+		"workspaces":         []interface{}{map[string]interface{}{"name": "source", "emptyDir": map[string]interface{}{}}},
+		"serviceAccountName": "builder",
+	}
+	if spec, _, _ := unstructured.NestedMap(taskRun.Object, "spec"); !reflect.DeepEqual(spec, expected) {
+		t.Errorf("Expected spec %v, actual %v", expected, spec)
+	}
+}
+
+// POST TaskRun requires either a taskRef or a taskSpec
+func TestPOSTTaskRunWithoutTask(t *testing.T) {
+	server, _, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	body := []byte(`{"params": [{"name": "revision", "value": "main"}]}`)
+	httpReq := testutils.DummyHTTPRequest("POST", fmt.Sprintf("%s/v1/namespaces/%s/taskruns", server.URL, namespace), bytes.NewReader(body))
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusBadRequest, response.StatusCode)
+	}
+}
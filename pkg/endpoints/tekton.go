@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const tektonGroup = "tekton.dev"
+
+// DefaultTektonVersion is the Tekton Pipelines API version used when none is
+// pinned and the cluster does not advertise a supported one
+const DefaultTektonVersion = "v1beta1"
+
+// TektonVersions are the Tekton Pipelines API versions the dashboard can use
+var TektonVersions = []string{"v1", "v1beta1"}
+
+// GetTektonVersion returns the TektonVersion property if set or the
+// DefaultTektonVersion otherwise
+func (o Options) GetTektonVersion() string {
+	if o.TektonVersion != "" {
+		return o.TektonVersion
+	}
+	return DefaultTektonVersion
+}
+
+// ResolveTektonVersion returns the Tekton Pipelines API version to use for all
+// handlers and informers. A pinned version must be supported and served by the
+// cluster, otherwise the preferred version of the tekton.dev group is used
+func ResolveTektonVersion(client discovery.DiscoveryInterface, pinned string) (string, error) {
+	if pinned != "" {
+		if !isTektonVersion(pinned) {
+			return "", fmt.Errorf("unsupported Tekton API version '%s', expected one of %v", pinned, TektonVersions)
+		}
+		if _, err := client.ServerResourcesForGroupVersion(tektonGroup + "/" + pinned); err != nil {
+			return "", fmt.Errorf("Tekton API version '%s' is not available: %v", pinned, err)
+		}
+		return pinned, nil
+	}
+
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups.Groups {
+		if group.Name != tektonGroup {
+			continue
+		}
+		if isTektonVersion(group.PreferredVersion.Version) {
+			return group.PreferredVersion.Version, nil
+		}
+		for _, version := range TektonVersions {
+			for _, served := range group.Versions {
+				if served.Version == version {
+					return version, nil
+				}
+			}
+		}
+	}
+	return DefaultTektonVersion, nil
+}
+
+func isTektonVersion(version string) bool {
+	for _, v := range TektonVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// tektonGVR returns the GroupVersionResource for the given Tekton Pipelines
+// resource in the configured API version
+func (r Resource) tektonGVR(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    tektonGroup,
+		Version:  r.Options.GetTektonVersion(),
+		Resource: resource,
+	}
+}
+
+// runResultsFields are the status fields of the results of PipelineRuns and
+// TaskRuns, pipelineResults and taskResults in v1beta1 and results in v1
+var runResultsFields = []string{"pipelineResults", "taskResults", "results"}
+
+// runResults returns the results of a PipelineRun or TaskRun in any of the
+// API versions the dashboard can use
+func runResults(run *unstructured.Unstructured) []interface{} {
+	for _, field := range runResultsFields {
+		if results, found, _ := unstructured.NestedSlice(run.Object, "status", field); found {
+			return results
+		}
+	}
+	return nil
+}
+
+// runResult returns the value of a named result of a PipelineRun or TaskRun
+func runResult(run *unstructured.Unstructured, name string) (interface{}, bool) {
+	for _, r := range runResults(run) {
+		result, ok := r.(map[string]interface{})
+		if ok && result["name"] == name {
+			return result["value"], true
+		}
+	}
+	return nil, false
+}
+
+// getTektonResource gets a single namespaced Tekton resource by name
+func (r Resource) getTektonResource(resource, namespace, name string) (*unstructured.Unstructured, error) {
+	return r.DynamicClient.Resource(r.tektonGVR(resource)).Namespace(namespace).Get(name, metav1.GetOptions{})
+}
+
+// Run statuses derived from the Succeeded condition of a PipelineRun or TaskRun
+const (
+	RunPending   = "Pending"
+	RunRunning   = "Running"
+	RunSucceeded = "Succeeded"
+	RunFailed    = "Failed"
+	RunCancelled = "Cancelled"
+)
+
+// succeededCondition returns the status, reason and message of the Succeeded
+// condition of a run, found is false if the run has no such condition yet
+func succeededCondition(run *unstructured.Unstructured) (status, reason, message string, found bool) {
+	conditions, _, _ := unstructured.NestedSlice(run.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Succeeded" {
+			continue
+		}
+		status, _ = condition["status"].(string)
+		reason, _ = condition["reason"].(string)
+		message, _ = condition["message"].(string)
+		return status, reason, message, true
+	}
+	return "", "", "", false
+}
+
+// runStatus summarises the Succeeded condition of a run as one of the Run*
+// statuses
+func runStatus(run *unstructured.Unstructured) string {
+	status, reason, _, found := succeededCondition(run)
+	switch {
+	case !found:
+		return RunPending
+	case status == "True":
+		return RunSucceeded
+	case status == "False" && strings.HasSuffix(reason, "Cancelled"):
+		return RunCancelled
+	case status == "False":
+		return RunFailed
+	default:
+		return RunRunning
+	}
+}
+
+// getTaskRunPod gets the pod backing a TaskRun, returning a NotFound error if
+// the TaskRun has no pod yet
+func (r Resource) getTaskRunPod(namespace, name string) (*corev1.Pod, error) {
+	taskRun, err := r.getTektonResource("taskruns", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return r.taskRunPod(taskRun)
+}
+
+// taskRunPod gets the pod backing a TaskRun, returning a NotFound error if the
+// TaskRun has no pod yet
+func (r Resource) taskRunPod(taskRun *unstructured.Unstructured) (*corev1.Pod, error) {
+	podName, _, _ := unstructured.NestedString(taskRun.Object, "status", "podName")
+	if podName == "" {
+		return nil, k8serrors.NewNotFound(corev1.Resource("pods"), "")
+	}
+	return r.K8sClient.CoreV1().Pods(taskRun.GetNamespace()).Get(podName, metav1.GetOptions{})
+}
+
+// respondGetError writes a 404 if the requested object could not be found or
+// a 500 for any other error
+func respondGetError(response *restful.Response, err error) {
+	utils.RespondError(response, err, errorStatus(err))
+}
+
+// errorStatus is the HTTP status reported for a client error, 404 if the
+// object could not be found or 500 otherwise
+func errorStatus(err error) int {
+	if k8serrors.IsNotFound(err) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// nestedTime reads an RFC3339 timestamp from an unstructured object
+func nestedTime(object *unstructured.Unstructured, fields ...string) (time.Time, bool) {
+	value, found, err := unstructured.NestedString(object.Object, fields...)
+	if err != nil || !found || value == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Handlers use the pinned Tekton API version
+func TestPinnedTektonVersion(t *testing.T) {
+	server, r, namespace := testutils.DummyServerWithOptions(Options{TektonVersion: "v1"})
+	defer server.Close()
+
+	for _, version := range []string{"v1", "v1beta1"} {
+		gvr := schema.GroupVersionResource{
+			Group:    "tekton.dev",
+			Version:  version,
+			Resource: "pipelineruns",
+		}
+		pipelineRun := testutils.GetObject(version, "PipelineRun", namespace, "run-"+version, "1")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating %s pipelineRun: %v", version, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{"run-v1", http.StatusNoContent},
+		{"run-v1beta1", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/%s/source", server.URL, namespace, test.name), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting source: %v", err)
+		}
+		if response.StatusCode != test.expected {
+			t.Errorf("%s: expected statusCode %d, actual %d", test.name, test.expected, response.StatusCode)
+		}
+	}
+}
+
+// The Tekton API version is validated against, or detected from, discovery
+func TestResolveTektonVersion(t *testing.T) {
+	client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{}}
+	client.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "tekton.dev/v1"},
+		{GroupVersion: "tekton.dev/v1beta1"},
+	}
+
+	tests := []struct {
+		pinned   string
+		expected string
+		err      bool
+	}{
+		{"", "v1", false},
+		{"v1beta1", "v1beta1", false},
+		{"v1alpha1", "", true},
+	}
+	for _, test := range tests {
+		version, err := ResolveTektonVersion(client, test.pinned)
+		if (err != nil) != test.err {
+			t.Errorf("Pinned '%s': expected error %t, actual %v", test.pinned, test.err, err)
+		}
+		if version != test.expected {
+			t.Errorf("Pinned '%s': expected version '%s', actual '%s'", test.pinned, test.expected, version)
+		}
+	}
+
+	client.Resources = []*metav1.APIResourceList{{GroupVersion: "tekton.dev/v1beta1"}}
+	if _, err := ResolveTektonVersion(client, "v1"); err == nil {
+		t.Error("Expected error pinning a version not served by the cluster")
+	}
+	if version, _ := ResolveTektonVersion(client, ""); version != "v1beta1" {
+		t.Errorf("Expected detected version 'v1beta1', actual '%s'", version)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+)
+
+const (
+	defaultThroughputWindow = 7 * 24 * time.Hour
+	defaultThroughputBucket = time.Hour
+	// maxThroughputBuckets bounds the size of a throughput response
+	maxThroughputBuckets = 1000
+)
+
+// Throughput is the number of PipelineRuns started in each bucket of a
+// window, oldest bucket first
+type Throughput struct {
+	Bucket  float64            `json:"bucketSeconds"`
+	Buckets []ThroughputBucket `json:"buckets"`
+}
+
+// ThroughputBucket counts the PipelineRuns started from Start for the bucket
+// duration. Outcomes splits the count by run status when requested
+type ThroughputBucket struct {
+	Start    time.Time      `json:"start"`
+	Count    int            `json:"count"`
+	Outcomes map[string]int `json:"outcomes,omitempty"`
+}
+
+// GetThroughput buckets the start times of the PipelineRuns of a namespace
+// started within the window parameter into intervals of the bucket parameter.
+// Buckets are aligned on multiples of their duration, the last one holding
+// the current time. With split=outcome each bucket is also split by status
+func (r Resource) GetThroughput(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	window, err := durationParameter(request, "window", defaultThroughputWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	bucket, err := durationParameter(request, "bucket", defaultThroughputBucket)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	split := request.QueryParameter("split")
+	if split != "" && split != "outcome" {
+		utils.RespondErrorMessage(response, fmt.Sprintf("invalid split '%s', must be outcome", split), http.StatusBadRequest)
+		return
+	}
+	count := int((window + bucket - 1) / bucket)
+	if count > maxThroughputBuckets {
+		utils.RespondErrorMessage(response, fmt.Sprintf("window %s holds %d buckets of %s, more than the maximum of %d", window, count, bucket, maxThroughputBuckets), http.StatusBadRequest)
+		return
+	}
+
+	kind, _ := lookupKind(r.Options, "PipelineRun")
+	pipelineRuns, err := r.listKind(kind, namespace)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	first := time.Now().Truncate(bucket).Add(-time.Duration(count-1) * bucket)
+	throughput := Throughput{Bucket: bucket.Seconds(), Buckets: make([]ThroughputBucket, count)}
+	for i := range throughput.Buckets {
+		throughput.Buckets[i].Start = first.Add(time.Duration(i) * bucket)
+		if split != "" {
+			throughput.Buckets[i].Outcomes = map[string]int{}
+		}
+	}
+	for _, pipelineRun := range pipelineRuns {
+		started, ok := nestedTime(pipelineRun, "status", "startTime")
+		if !ok || started.Before(first) {
+			continue
+		}
+		i := int(started.Sub(first) / bucket)
+		if i >= count {
+			continue
+		}
+		throughput.Buckets[i].Count++
+		if split != "" {
+			throughput.Buckets[i].Outcomes[runStatus(pipelineRun)]++
+		}
+	}
+
+	response.WriteEntity(throughput)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET throughput counts the PipelineRuns started in each bucket of the window
+func TestGETThroughput(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	current := time.Now().Truncate(time.Hour)
+	runs := []struct {
+		name    string
+		started time.Time
+		status  string
+	}{
+		{"two-hours-ago", current.Add(-90 * time.Minute), "True"},
+		{"last-hour", current.Add(-30 * time.Minute), "True"},
+		{"last-hour-failed", current.Add(-20 * time.Minute), "False"},
+		{"outside-window", current.Add(-5 * time.Hour), "True"},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	for _, run := range runs {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, run.name, "1")
+		unstructured.SetNestedField(pipelineRun.Object, run.started.UTC().Format(time.RFC3339), "status", "startTime")
+		unstructured.SetNestedSlice(pipelineRun.Object, []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": run.status},
+		}, "status", "conditions")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+
+	getThroughput := func() Throughput {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/throughput?window=3h&bucket=1h&split=outcome", server.URL, namespace), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting throughput: %v", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+		}
+		var throughput Throughput
+		if err := json.NewDecoder(response.Body).Decode(&throughput); err != nil {
+			t.Fatalf("Error decoding throughput: %v", err)
+		}
+		return throughput
+	}
+	// PipelineRuns are read from the informer cache which is eventually consistent
+	var throughput Throughput
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if throughput = getThroughput(); len(throughput.Buckets) == 3 && throughput.Buckets[1].Count == 2 {
+			break
+		}
+	}
+
+	if throughput.Bucket != 3600 || len(throughput.Buckets) != 3 {
+		t.Fatalf("Expected 3 buckets of 3600 seconds, actual %+v", throughput)
+	}
+	expected := []struct {
+		start    time.Time
+		count    int
+		outcomes map[string]int
+	}{
+		{current.Add(-2 * time.Hour), 1, map[string]int{"Succeeded": 1}},
+		{current.Add(-time.Hour), 2, map[string]int{"Succeeded": 1, "Failed": 1}},
+		{current, 0, map[string]int{}},
+	}
+	for i, bucket := range throughput.Buckets {
+		if !bucket.Start.Equal(expected[i].start) || bucket.Count != expected[i].count {
+			t.Errorf("Expected bucket %d to start at %s with %d runs, actual %+v", i, expected[i].start, expected[i].count, bucket)
+		}
+		if len(expected[i].outcomes) > 0 && !reflect.DeepEqual(bucket.Outcomes, expected[i].outcomes) {
+			t.Errorf("Expected bucket %d outcomes %v, actual %v", i, expected[i].outcomes, bucket.Outcomes)
+		}
+	}
+}
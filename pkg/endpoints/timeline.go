@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Reasons of the gaps in a TaskRun's timeline where none of its steps ran
+const (
+	// GapScheduling is the time between the TaskRun starting and its first
+	// step starting, spent scheduling the pod and running init containers
+	GapScheduling = "Scheduling"
+	// GapWaiting is the time between a step finishing and the next starting
+	GapWaiting = "Waiting"
+)
+
+// Timeline is the spans of the TaskRuns of a PipelineRun and of their steps.
+// Offsets are in seconds since the PipelineRun started
+type Timeline struct {
+	StartTime time.Time         `json:"startTime"`
+	TaskRuns  []TaskRunTimeline `json:"taskRuns"`
+}
+
+// TaskRunTimeline is the span of a TaskRun with the spans of its steps in the
+// order they ran, and the gaps between them
+type TaskRunTimeline struct {
+	PipelineTask string `json:"pipelineTask"`
+	TaskRun      string `json:"taskRun"`
+	Span
+	Steps []StepSpan `json:"steps"`
+	Gaps  []Gap      `json:"gaps"`
+}
+
+// Span is when something ran and its status, start is omitted until it started
+// and end until it finished
+type Span struct {
+	Status string   `json:"status"`
+	Start  *float64 `json:"startSeconds,omitempty"`
+	End    *float64 `json:"endSeconds,omitempty"`
+}
+
+// StepSpan is the span of a single step
+type StepSpan struct {
+	Name string `json:"name"`
+	Span
+}
+
+// Gap is a period of a TaskRun where none of its steps ran
+type Gap struct {
+	Reason string  `json:"reason"`
+	Start  float64 `json:"startSeconds"`
+	End    float64 `json:"endSeconds"`
+}
+
+// GetPipelineRunTimeline returns the spans of a PipelineRun's TaskRuns and
+// their steps, in the order they started, for drawing the run as a timeline
+func (r Resource) GetPipelineRunTimeline(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	pipelineRun, err := r.getTektonResource("pipelineruns", namespace, name)
+	if err != nil {
+		respondGetError(response, err)
+		return
+	}
+	started, ok := nestedTime(pipelineRun, "status", "startTime")
+	if !ok {
+		started = pipelineRun.GetCreationTimestamp().Time
+	}
+
+	taskRuns, err := r.DynamicClient.Resource(r.tektonGVR("taskruns")).Namespace(namespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", PipelineRunLabel, name),
+	})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	timeline := Timeline{StartTime: started.UTC(), TaskRuns: []TaskRunTimeline{}}
+	for i := range taskRuns.Items {
+		timeline.TaskRuns = append(timeline.TaskRuns, taskRunTimeline(&taskRuns.Items[i], started))
+	}
+	sort.SliceStable(timeline.TaskRuns, func(i, j int) bool {
+		a, b := timeline.TaskRuns[i], timeline.TaskRuns[j]
+		if (a.Start == nil) != (b.Start == nil) {
+			return a.Start != nil
+		}
+		if a.Start != nil && *a.Start != *b.Start {
+			return *a.Start < *b.Start
+		}
+		return a.TaskRun < b.TaskRun
+	})
+
+	response.WriteEntity(timeline)
+}
+
+// taskRunTimeline builds the spans of a TaskRun and its steps relative to the
+// start of its PipelineRun
+func taskRunTimeline(taskRun *unstructured.Unstructured, since time.Time) TaskRunTimeline {
+	timeline := TaskRunTimeline{
+		PipelineTask: taskRun.GetLabels()[PipelineTaskLabel],
+		TaskRun:      taskRun.GetName(),
+		Span:         Span{Status: runStatus(taskRun)},
+		Steps:        []StepSpan{},
+		Gaps:         []Gap{},
+	}
+	if started, ok := nestedTime(taskRun, "status", "startTime"); ok {
+		timeline.Start = offset(started, since)
+	}
+	if completed, ok := nestedTime(taskRun, "status", "completionTime"); ok {
+		timeline.End = offset(completed, since)
+	}
+
+	// previous is where the last step ended, the first gap starts with the TaskRun
+	previous, reason := timeline.Start, GapScheduling
+	steps, _, _ := unstructured.NestedSlice(taskRun.Object, "status", "steps")
+	for _, s := range steps {
+		step, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		span := stepSpan(step, since)
+		if previous != nil && span.Start != nil && *span.Start > *previous {
+			timeline.Gaps = append(timeline.Gaps, Gap{Reason: reason, Start: *previous, End: *span.Start})
+		}
+		previous, reason = span.End, GapWaiting
+		timeline.Steps = append(timeline.Steps, span)
+	}
+	return timeline
+}
+
+// stepSpan reads the span of a step from its container state
+func stepSpan(step map[string]interface{}, since time.Time) StepSpan {
+	status := &unstructured.Unstructured{Object: step}
+	name, _ := step["name"].(string)
+	span := StepSpan{Name: name, Span: Span{Status: RunPending}}
+	if started, ok := nestedTime(status, "running", "startedAt"); ok {
+		span.Status = RunRunning
+		span.Start = offset(started, since)
+	}
+	if _, ok := step["terminated"]; ok {
+		span.Status = RunSucceeded
+		if exitCode, _, _ := unstructured.NestedInt64(step, "terminated", "exitCode"); exitCode != 0 {
+			span.Status = RunFailed
+		}
+		if started, ok := nestedTime(status, "terminated", "startedAt"); ok {
+			span.Start = offset(started, since)
+		}
+		if finished, ok := nestedTime(status, "terminated", "finishedAt"); ok {
+			span.End = offset(finished, since)
+		}
+	}
+	return span
+}
+
+// offset returns the seconds between since and t
+func offset(t, since time.Time) *float64 {
+	seconds := t.Sub(since).Seconds()
+	return &seconds
+}
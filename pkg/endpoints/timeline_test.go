@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun timeline returns step spans relative to the run's start
+func TestGETPipelineRunTimeline(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) string {
+		return start.Add(time.Duration(seconds) * time.Second).Format(time.RFC3339)
+	}
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "ci", "1")
+	unstructured.SetNestedField(pipelineRun.Object, at(0), "status", "startTime")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	taskRuns := []struct {
+		pipelineTask string
+		status       map[string]interface{}
+	}{
+		{"build", map[string]interface{}{
+			"startTime":  at(20),
+			"conditions": []interface{}{map[string]interface{}{"type": "Succeeded", "status": "Unknown"}},
+			"steps": []interface{}{
+				map[string]interface{}{"name": "compile", "terminated": map[string]interface{}{"exitCode": int64(0), "startedAt": at(22), "finishedAt": at(40)}},
+				map[string]interface{}{"name": "push", "running": map[string]interface{}{"startedAt": at(43)}},
+			},
+		}},
+		{"deploy", map[string]interface{}{}},
+		{"fetch", map[string]interface{}{
+			"startTime":      at(2),
+			"completionTime": at(15),
+			"conditions":     []interface{}{map[string]interface{}{"type": "Succeeded", "status": "False"}},
+			"steps": []interface{}{
+				map[string]interface{}{"name": "clone", "terminated": map[string]interface{}{"exitCode": int64(1), "startedAt": at(5), "finishedAt": at(15)}},
+			},
+		}},
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	for _, run := range taskRuns {
+		taskRun := testutils.GetObject("v1beta1", "TaskRun", namespace, "ci-"+run.pipelineTask, "1")
+		taskRun.SetLabels(map[string]string{PipelineRunLabel: "ci", PipelineTaskLabel: run.pipelineTask})
+		unstructured.SetNestedField(taskRun.Object, run.status, "status")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/ci/timeline", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting timeline: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var timeline Timeline
+	if err := json.NewDecoder(response.Body).Decode(&timeline); err != nil {
+		t.Fatalf("Error decoding timeline: %v", err)
+	}
+
+	seconds := func(s float64) *float64 {
+		return &s
+	}
+	expected := Timeline{
+		StartTime: start,
+		TaskRuns: []TaskRunTimeline{
+			{
+				PipelineTask: "fetch",
+				TaskRun:      "ci-fetch",
+				Span:         Span{Status: RunFailed, Start: seconds(2), End: seconds(15)},
+				Steps:        []StepSpan{{Name: "clone", Span: Span{Status: RunFailed, Start: seconds(5), End: seconds(15)}}},
+				Gaps:         []Gap{{Reason: GapScheduling, Start: 2, End: 5}},
+			},
+			{
+				PipelineTask: "build",
+				TaskRun:      "ci-build",
+				Span:         Span{Status: RunRunning, Start: seconds(20)},
+				Steps: []StepSpan{
+					{Name: "compile", Span: Span{Status: RunSucceeded, Start: seconds(22), End: seconds(40)}},
+					{Name: "push", Span: Span{Status: RunRunning, Start: seconds(43)}},
+				},
+				Gaps: []Gap{{Reason: GapScheduling, Start: 20, End: 22}, {Reason: GapWaiting, Start: 40, End: 43}},
+			},
+			{
+				PipelineTask: "deploy",
+				TaskRun:      "ci-deploy",
+				Span:         Span{Status: RunPending},
+				Steps:        []StepSpan{},
+				Gaps:         []Gap{},
+			},
+		},
+	}
+	if !reflect.DeepEqual(timeline, expected) {
+		t.Errorf("Expected timeline %+v, actual %+v", expected, timeline)
+	}
+}
@@ -17,9 +17,12 @@ import (
 	"net/http"
 
 	dashboardclientset "github.com/tektoncd/dashboard/pkg/client/clientset/versioned"
+	"github.com/tektoncd/dashboard/pkg/redact"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	k8sclientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Options for enpoints
@@ -33,6 +36,21 @@ type Options struct {
 	LogoutURL          string
 	StreamLogs         bool
 	ExternalLogsURL    string
+	// Namespaces hidden from every endpoint, requests for their resources get
+	// HTTP code 403
+	DeniedNamespaces []string
+	// Tekton Pipelines API version used by all handlers, empty uses the default
+	TektonVersion string
+	// Resource of custom task runs, detected from discovery. Empty uses the default
+	CustomRunGVR schema.GroupVersionResource
+	// Resource of StepActions, detected from discovery. Empty uses the default
+	StepActionGVR schema.GroupVersionResource
+	// Port the websocket endpoints are served on by their own listener, zero
+	// serves them with the REST API
+	WebsocketPort int
+	// Per-connection websocket buffer sizes in bytes, zero uses the defaults
+	WebsocketReadBufferSize  int
+	WebsocketWriteBufferSize int
 }
 
 // GetPipelinesNamespace returns the PipelinesNamespace property if set
@@ -64,4 +82,18 @@ type Resource struct {
 	DynamicClient   dynamic.Interface
 	K8sClient       k8sclientset.Interface
 	Options         Options
+	// Authorizer is consulted before serving API requests, nil allows all
+	Authorizer Authorizer
+	// LogCache keeps the logs of completed TaskRuns, nil disables caching
+	LogCache LogCache
+	// PipelineRunLister reads PipelineRuns from the informer cache, nil lists
+	// them from the API server
+	PipelineRunLister cache.GenericLister
+	// Redactor masks fields of the objects in API responses, nil returns them
+	// unchanged
+	Redactor *redact.Redactor
+	// Listers read the namespaced kinds watched by the controllers from the
+	// informer caches, keyed by kind. Kinds without one are listed from the
+	// API server
+	Listers map[string]cache.GenericLister
 }
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultWaitTimeWindow   = time.Hour
+	defaultPendingThreshold = 5 * time.Minute
+)
+
+// WaitTimes describes how long PipelineRuns waited between creation and start
+type WaitTimes struct {
+	Stats   DurationStats `json:"stats"`
+	Pending []PendingRun  `json:"pending"`
+}
+
+// PendingRun is a PipelineRun that has not started after the pending threshold
+type PendingRun struct {
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Waiting   float64 `json:"waitingSeconds"`
+}
+
+// GetPipelineRunWaitTimes returns the distribution of the time PipelineRuns
+// started within the window spent waiting to start, and the runs that have
+// been pending for longer than the threshold
+func (r Resource) GetPipelineRunWaitTimes(request *restful.Request, response *restful.Response) {
+	namespace := utils.GetNamespace(request)
+	window, err := durationParameter(request, "window", defaultWaitTimeWindow)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+	threshold, err := durationParameter(request, "threshold", defaultPendingThreshold)
+	if err != nil {
+		utils.RespondError(response, err, http.StatusBadRequest)
+		return
+	}
+
+	pipelineRuns, err := r.DynamicClient.Resource(r.tektonGVR("pipelineruns")).Namespace(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		utils.RespondError(response, err, http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-window)
+	waits := []time.Duration{}
+	waitTimes := WaitTimes{Pending: []PendingRun{}}
+	for i := range pipelineRuns.Items {
+		pipelineRun := &pipelineRuns.Items[i]
+		created := pipelineRun.GetCreationTimestamp().Time
+		started, ok := nestedTime(pipelineRun, "status", "startTime")
+		if !ok {
+			if waiting := now.Sub(created); waiting > threshold {
+				waitTimes.Pending = append(waitTimes.Pending, PendingRun{
+					Namespace: pipelineRun.GetNamespace(),
+					Name:      pipelineRun.GetName(),
+					Waiting:   waiting.Seconds(),
+				})
+			}
+			continue
+		}
+		if started.Before(cutoff) {
+			continue
+		}
+		if wait := started.Sub(created); wait >= 0 {
+			waits = append(waits, wait)
+		}
+	}
+	waitTimes.Stats = computeDurationStats(waits)
+
+	response.WriteEntity(waitTimes)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET PipelineRun wait times computes the creation to start delay
+func TestGETPipelineRunWaitTimes(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	now := time.Now().Truncate(time.Second)
+	createRun := func(name string, created time.Time, wait time.Duration) {
+		pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, name, "1")
+		pipelineRun.SetCreationTimestamp(metav1.NewTime(created))
+		if wait >= 0 {
+			unstructured.SetNestedField(pipelineRun.Object, created.Add(wait).Format(time.RFC3339), "status", "startTime")
+		}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating pipelineRun: %v", err)
+		}
+	}
+	createRun("fast", now.Add(-30*time.Minute), 10*time.Second)
+	createRun("medium", now.Add(-20*time.Minute), 20*time.Second)
+	createRun("slow", now.Add(-10*time.Minute), 60*time.Second)
+	// Outside of the window
+	createRun("old", now.Add(-3*time.Hour), time.Hour)
+	// Not started yet
+	createRun("stuck", now.Add(-10*time.Minute), -1)
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/pipelineruns/waittimes?window=1h&threshold=5m", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting wait times: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var waitTimes WaitTimes
+	if err := json.NewDecoder(response.Body).Decode(&waitTimes); err != nil {
+		t.Fatalf("Error decoding wait times: %v", err)
+	}
+
+	expected := DurationStats{Count: 3, Min: 10, Max: 60, Mean: 30, P50: 20, P95: 60}
+	if waitTimes.Stats != expected {
+		t.Errorf("Expected stats %+v, actual %+v", expected, waitTimes.Stats)
+	}
+	if len(waitTimes.Pending) != 1 || waitTimes.Pending[0].Name != "stuck" {
+		t.Errorf("Expected only the stuck run to be pending, actual %+v", waitTimes.Pending)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	restful "github.com/emicklei/go-restful"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+	"github.com/tektoncd/dashboard/pkg/redact"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchKey identifies the informer shared by the clients watching a resource
+// in a namespace, all namespaces when empty
+type watchKey struct {
+	client    dynamic.Interface
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// resourceWatch broadcasts the events of a single informer to its clients
+type resourceWatch struct {
+	broadcaster *broadcaster.SocketBroadcaster
+	channel     chan broadcaster.SocketData
+	stopCh      chan struct{}
+	// lock guards closing the channel against events still being sent
+	lock    sync.RWMutex
+	stopped bool
+	clients int
+}
+
+// watchPool shares informers between the clients of the watch websocket
+type watchPool struct {
+	mutex   sync.Mutex
+	watches map[watchKey]*resourceWatch
+}
+
+var watches = &watchPool{watches: map[watchKey]*resourceWatch{}}
+
+// acquire returns the watch for a resource, starting its informer if this is
+// the first client, whose redactor masks the objects it broadcasts
+func (p *watchPool) acquire(key watchKey, kind string, redactor *redact.Redactor) *resourceWatch {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if watch, ok := p.watches[key]; ok {
+		watch.clients++
+		return watch
+	}
+	watch := &resourceWatch{
+		channel: make(chan broadcaster.SocketData),
+		stopCh:  make(chan struct{}),
+		clients: 1,
+	}
+	watch.broadcaster = broadcaster.NewBroadcaster(watch.channel)
+	watch.broadcaster.SetRedactor(redactor)
+	informer := dynamicinformer.NewFilteredDynamicInformer(key.client, key.gvr, key.namespace, 0, cache.Indexers{}, nil).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			watch.send(broadcaster.MessageType(kind+"Created"), obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if oldObj.(metav1.Object).GetResourceVersion() != newObj.(metav1.Object).GetResourceVersion() {
+				watch.send(broadcaster.MessageType(kind+"Updated"), newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			watch.send(broadcaster.MessageType(kind+"Deleted"), obj)
+		},
+	})
+	go informer.Run(watch.stopCh)
+	p.watches[key] = watch
+	return watch
+}
+
+// release stops the informer of a watch once its last client has gone
+func (p *watchPool) release(key watchKey) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	watch := p.watches[key]
+	watch.clients--
+	if watch.clients > 0 {
+		return
+	}
+	delete(p.watches, key)
+	close(watch.stopCh)
+	watch.lock.Lock()
+	watch.stopped = true
+	// Expires the broadcaster
+	close(watch.channel)
+	watch.lock.Unlock()
+}
+
+func (w *resourceWatch) send(messageType broadcaster.MessageType, obj interface{}) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if w.stopped {
+		return
+	}
+	select {
+	case w.channel <- broadcaster.SocketData{MessageType: messageType, Payload: obj}:
+	case <-w.stopCh:
+	}
+}
+
+// EstablishWatchWebsocket streams the Created, Updated and Deleted events of a
+// single resource, in the namespace given by the namespace query parameter or
+// in all namespaces. Only the kinds served by the dashboard API may be
+// watched, clients watching the same resource share one informer. The first
+// client of a watch also receives a Created event for each existing object
+func (r Resource) EstablishWatchWebsocket(request *restful.Request, response *restful.Response) {
+	gvr := schema.GroupVersionResource{
+		Group:    request.PathParameter("group"),
+		Version:  request.PathParameter("version"),
+		Resource: request.PathParameter("resource"),
+	}
+	namespace := request.QueryParameter("namespace")
+	if r.Options.TenantNamespace != "" {
+		if namespace != "" && namespace != r.Options.TenantNamespace {
+			websocket.RespondUpgradeError(response, http.StatusForbidden, fmt.Sprintf("namespace %s is not watched by the dashboard", namespace))
+			return
+		}
+		namespace = r.Options.TenantNamespace
+	}
+	if r.Options.NamespaceDenied(namespace) {
+		websocket.RespondUpgradeError(response, http.StatusForbidden, fmt.Sprintf("namespace %s is not available in the dashboard", namespace))
+		return
+	}
+
+	kind, ok := watchableKind(r.Options, gvr)
+	if !ok {
+		websocket.RespondUpgradeError(response, http.StatusForbidden, fmt.Sprintf("%s cannot be watched", gvr))
+		return
+	}
+	if !r.servedResource(gvr) {
+		websocket.RespondUpgradeError(response, http.StatusNotFound, fmt.Sprintf("%s is not served by the cluster", gvr))
+		return
+	}
+	if allowed, err := r.authorized(request, "watch", kind, namespace, ""); !allowed {
+		if err != nil {
+			websocket.RespondUpgradeError(response, http.StatusInternalServerError, err.Error())
+			return
+		}
+		websocket.RespondUpgradeError(response, http.StatusForbidden, fmt.Sprintf("user '%s' cannot watch %s in namespace %s", request.HeaderParameter(UserHeader), kind, namespace))
+		return
+	}
+
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+	key := watchKey{client: r.DynamicClient, gvr: gvr, namespace: namespace}
+	watch := watches.acquire(key, kind, r.Redactor)
+	defer watches.release(key)
+	websocket.WriteOnlyWebsocket(connection, watch.broadcaster.Broadcaster, broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		return !r.Options.hides(data.Payload)
+	}))
+}
+
+// watchableKind returns the kind of a resource the dashboard API serves,
+// whatever its version
+func watchableKind(options Options, gvr schema.GroupVersionResource) (string, bool) {
+	for _, kind := range namespacedKinds(options) {
+		if kind.GVR.Group == gvr.Group && kind.GVR.Resource == gvr.Resource {
+			return kind.Kind, true
+		}
+	}
+	return "", false
+}
+
+// servedResource checks discovery for a resource
+func (r Resource) servedResource(gvr schema.GroupVersionResource) bool {
+	resources, err := r.K8sClient.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	gorillaSocket "github.com/gorilla/websocket"
+	"github.com/tektoncd/dashboard/pkg/broadcaster"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	"github.com/tektoncd/dashboard/pkg/websocket"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+)
+
+// Watching a single resource streams its events to every client
+func TestWatchWebsocket(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.K8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "tekton.dev/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "taskruns"}},
+	}}
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/watch/tekton.dev/v1beta1/taskruns?namespace=%s", strings.TrimPrefix(server.URL, "http://"), namespace)
+	var connections []*gorillaSocket.Conn
+	for i := 0; i < 2; i++ {
+		connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, nil)
+		if err != nil {
+			t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+		}
+		defer connection.Close()
+		connections = append(connections, connection)
+	}
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	taskRuns := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	// Runs of other namespaces are not watched
+	if _, err := r.DynamicClient.Resource(gvr).Namespace("other").Create(testutils.GetObject("v1beta1", "TaskRun", "other", "ignored", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	taskRun, err := taskRuns.Create(testutils.GetObject("v1beta1", "TaskRun", namespace, "build", "1"), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("Error creating taskRun: %v", err)
+	}
+	// Give the informer time to see the creation before updating
+	time.Sleep(200 * time.Millisecond)
+	taskRun.SetResourceVersion("2")
+	if _, err := taskRuns.Update(taskRun, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating taskRun: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if err := taskRuns.Delete("build", &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting taskRun: %v", err)
+	}
+
+	expected := []broadcaster.MessageType{broadcaster.TaskRunCreated, broadcaster.TaskRunUpdated, broadcaster.TaskRunDeleted}
+	for i, connection := range connections {
+		connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var received []broadcaster.MessageType
+		for len(received) < len(expected) {
+			_, message, err := connection.ReadMessage()
+			if err != nil {
+				t.Fatalf("Client %d: error reading message: %s", i, err)
+			}
+			var socketData broadcaster.SocketData
+			if err := json.Unmarshal(message, &socketData); err != nil {
+				t.Fatalf("Client %d: error decoding message: %s", i, err)
+			}
+			if name := socketData.Payload.(map[string]interface{})["metadata"].(map[string]interface{})["name"]; name != "build" {
+				t.Errorf("Client %d: unexpected event for %s", i, name)
+			}
+			received = append(received, socketData.MessageType)
+		}
+		if fmt.Sprint(received) != fmt.Sprint(expected) {
+			t.Errorf("Client %d: expected %v, actual %v", i, expected, received)
+		}
+	}
+}
+
+// Only the kinds served by the dashboard can be watched
+func TestWatchWebsocketNotAllowed(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+	r.K8sClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{{Name: "deployments"}},
+	}}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	for path, expected := range map[string]int{
+		"apps/v1/deployments":     http.StatusForbidden,
+		"tekton.dev/v1/pipelines": http.StatusNotFound,
+	} {
+		status, upgradeError := dialUpgradeError(fmt.Sprintf("ws://%s/v1/websockets/watch/%s", host, path), nil, t)
+		if status != expected {
+			t.Errorf("%s: expected status %d, actual %d", path, expected, status)
+		}
+		if code := map[int]string{http.StatusForbidden: websocket.CodeForbidden, http.StatusNotFound: websocket.CodeNotFound}[expected]; upgradeError.Code != code {
+			t.Errorf("%s: expected code %s, actual %+v", path, code, upgradeError)
+		}
+	}
+}
@@ -14,6 +14,13 @@ limitations under the License.
 package endpoints
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
 	restful "github.com/emicklei/go-restful"
 	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
 	logging "github.com/tektoncd/dashboard/pkg/logging"
@@ -26,12 +33,198 @@ var ResourcesChannel = make(chan broadcaster.SocketData)
 
 var ResourcesBroadcaster = broadcaster.NewBroadcaster(ResourcesChannel)
 
+// ResourcesEvents keeps the latest event of each resource for compacted
+// websocket subscriptions
+var ResourcesEvents = broadcaster.NewEventCache(broadcaster.DefaultTombstoneTTL)
+
+func init() {
+	ResourcesBroadcaster.SetEventCache(ResourcesEvents)
+	ResourcesBroadcaster.SetDeltaFunc(objectDelta)
+}
+
+// subscriberRetryAfter is how long websocket clients are asked to wait before
+// reconnecting when the broadcaster is full
+const subscriberRetryAfter = 10 * time.Second
+
+var websocketPriorities = map[string]broadcaster.Priority{
+	"":       broadcaster.PriorityNormal,
+	"normal": broadcaster.PriorityNormal,
+	"low":    broadcaster.PriorityLow,
+}
+
 // Establish websocket and subscribe to pipelinerun events
+// The priority query parameter decides which clients are dropped first when
+// the server is over its client limit, overflow what happens to the events of
+// a client whose buffer is full, annotationSelector restricts the events
+// sent to objects with matching annotations, namespaces and kinds to objects
+// in those namespaces and to those kinds or events. Clients can narrow the
+// kinds and namespace of the events they receive further by sending
+// ControlMessages. With compact=true the latest event of each resource, and of
+// resources deleted in the last few minutes, is sent before live events.
+// since resumes from the Sequence of the last event received, replaying the
+// events missed. enrich=duration adds the Duration or Elapsed time of runs to
+// their events. convertTo delivers Tekton Pipelines objects in the given API
+// version. delta=true sends Updated events as a JSON Patch from the previous
+// state of the object, and every deltaSnapshotEvery-th update of an object
+// whole. With an Authorizer, events are only delivered for objects the user
+// may get
 func (r Resource) EstablishResourcesWebsocket(request *restful.Request, response *restful.Response) {
-	connection, err := websocket.UpgradeToWebsocket(request, response)
+	opts, priority, err := r.resourcesSubscribeOptions(request, request.QueryParameter("since"))
+	if err != nil {
+		websocket.RespondUpgradeError(response, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !acceptSubscriber(response, priority) {
+		return
+	}
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
 	if err != nil {
 		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
 		return
 	}
-	websocket.WriteOnlyWebsocket(connection, ResourcesBroadcaster)
+	var subscription subscription
+	websocket.ControlledWebsocket(connection, ResourcesBroadcaster.Broadcaster, subscription.handleControlMessage, opts...)
+}
+
+// resourcesSubscribeOptions returns the options of a subscription to the
+// resources broadcaster from the query parameters of a request, see
+// EstablishResourcesWebsocket. since is given separately as event streams may
+// also resume from the Last-Event-ID header
+func (r Resource) resourcesSubscribeOptions(request *restful.Request, since string) ([]broadcaster.SubscribeOption[broadcaster.SocketData], broadcaster.Priority, error) {
+	priority, ok := websocketPriorities[request.QueryParameter("priority")]
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid priority '%s', must be low or normal", request.QueryParameter("priority"))
+	}
+	opts := []broadcaster.SubscribeOption[broadcaster.SocketData]{broadcaster.WithPriority[broadcaster.SocketData](priority)}
+	if overflow := request.QueryParameter("overflow"); overflow != "" {
+		policy, err := broadcaster.ParseOverflowPolicy(overflow)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = append(opts, broadcaster.WithOverflow[broadcaster.SocketData](policy))
+	}
+	if r.Authorizer != nil {
+		opts = append(opts, broadcaster.WithFilter(r.readAccessFilter(request.Request.Context(), request.HeaderParameter(UserHeader))))
+	}
+	if selector := request.QueryParameter("annotationSelector"); selector != "" {
+		filter, err := annotationFilter(selector)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = append(opts, broadcaster.WithFilter(filter))
+	}
+	if filter := namespacesFilter(request.QueryParameter("namespaces")); filter != nil {
+		opts = append(opts, broadcaster.WithFilter(filter))
+	}
+	filter, err := kindsFilter(request.QueryParameter("kinds"))
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter != nil {
+		opts = append(opts, broadcaster.WithFilter(filter))
+	}
+	minAge, err := durationParameter(request, "minAge", 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	maxAge, err := durationParameter(request, "maxAge", 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if minAge > 0 && maxAge > 0 && minAge > maxAge {
+		return nil, 0, fmt.Errorf("minAge %s must not be greater than maxAge %s", minAge, maxAge)
+	}
+	if minAge > 0 || maxAge > 0 {
+		opts = append(opts, broadcaster.WithFilter(ageFilter(minAge, maxAge)))
+	}
+	maxEvents, err := intParameter(request, "maxEvents", 0, math.MaxInt32)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts = append(opts, broadcaster.WithMaxEvents[broadcaster.SocketData](maxEvents))
+	compacted := false
+	if compact := request.QueryParameter("compact"); compact != "" {
+		compacted, err = strconv.ParseBool(compact)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid compact '%s', must be true or false", compact)
+		}
+		if compacted {
+			opts = append(opts, broadcaster.WithCompacted[broadcaster.SocketData]())
+		}
+	}
+	if since != "" {
+		sequence, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid since '%s', must be the Sequence of an event", since)
+		}
+		if compacted {
+			return nil, 0, errors.New("since cannot be combined with compact")
+		}
+		opts = append(opts, broadcaster.WithReplay[broadcaster.SocketData](sequence))
+	}
+	if enrich := request.QueryParameter("enrich"); enrich != "" {
+		transforms, err := enrichTransforms(enrich)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, transform := range transforms {
+			opts = append(opts, broadcaster.WithTransform(transform))
+		}
+	}
+	if convertTo := request.QueryParameter("convertTo"); convertTo != "" {
+		transform, err := r.convertTransform(convertTo)
+		if err != nil {
+			return nil, 0, err
+		}
+		opts = append(opts, broadcaster.WithTransform(transform))
+	}
+	// Applied last, objects converted to another version are sent whole
+	if delta := request.QueryParameter("delta"); delta != "" {
+		deltas, err := strconv.ParseBool(delta)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid delta '%s', must be true or false", delta)
+		}
+		snapshotEvery, err := intParameter(request, "deltaSnapshotEvery", DefaultDeltaSnapshotEvery, math.MaxInt32)
+		if err != nil {
+			return nil, 0, err
+		}
+		if deltas {
+			opts = append(opts, broadcaster.WithTransform(deltaTransform(snapshotEvery)))
+		}
+	}
+	return opts, priority, nil
+}
+
+// EstablishNamespacesWebsocket only streams the creation and deletion of
+// namespaces, for tools that do not need the other resource events. Namespaces
+// are not watched when the dashboard is limited to a single namespace. With an
+// Authorizer, events are only delivered if the user may get namespaces
+func (r Resource) EstablishNamespacesWebsocket(request *restful.Request, response *restful.Response) {
+	if !acceptSubscriber(response, broadcaster.PriorityNormal) {
+		return
+	}
+	connection, err := websocket.UpgradeToWebsocket(request, response, r.Options.WebsocketReadBufferSize, r.Options.WebsocketWriteBufferSize)
+	if err != nil {
+		logging.Log.Errorf("Could not upgrade to websocket connection: %s", err)
+		return
+	}
+	opts := []broadcaster.SubscribeOption[broadcaster.SocketData]{broadcaster.WithFilter(func(data broadcaster.SocketData) bool {
+		return data.MessageType == broadcaster.NamespaceCreated || data.MessageType == broadcaster.NamespaceDeleted
+	})}
+	if r.Authorizer != nil {
+		opts = append(opts, broadcaster.WithFilter(r.readAccessFilter(request.Request.Context(), request.HeaderParameter(UserHeader))))
+	}
+	websocket.WriteOnlyWebsocket(connection, ResourcesBroadcaster.Broadcaster, opts...)
+}
+
+// acceptSubscriber responds with 503 and a Retry-After header when the
+// resources broadcaster is full and would evict a subscriber of the given
+// priority as soon as it connects
+func acceptSubscriber(response *restful.Response, priority broadcaster.Priority) bool {
+	if !ResourcesBroadcaster.Full(priority) {
+		return true
+	}
+	response.AddHeader("Retry-After", strconv.Itoa(int(subscriberRetryAfter.Seconds())))
+	websocket.RespondUpgradeError(response, http.StatusServiceUnavailable, fmt.Sprintf("too many websocket clients, retry in %s", subscriberRetryAfter))
+	return false
 }
@@ -13,10 +13,13 @@ limitations under the License.
 package endpoints_test
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -32,7 +35,9 @@ import (
 	"github.com/tektoncd/dashboard/pkg/testutils"
 	"github.com/tektoncd/dashboard/pkg/websocket"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -177,6 +182,901 @@ func TestWebsocketResources(t *testing.T) {
 	}
 }
 
+// The connection closes after maxEvents events and a MaxEventsReached message
+func TestWebsocketMaxEvents(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"maxEvents": {"3"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected capped client within pool")
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	for i := 0; i < 5; i++ {
+		task := testutils.GetObject("v1beta1", "Task", namespace, "task-"+strconv.Itoa(i), "1")
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	var received []broadcaster.MessageType
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			if !gorillaSocket.IsCloseError(err, gorillaSocket.CloseNormalClosure) {
+				t.Errorf("Expected a normal closure, actual %s", err)
+			}
+			break
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		received = append(received, socketData.MessageType)
+	}
+	expected := []broadcaster.MessageType{broadcaster.TaskCreated, broadcaster.TaskCreated, broadcaster.TaskCreated, broadcaster.MaxEventsReached}
+	if fmt.Sprint(received) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, actual %v", expected, received)
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Compacted websockets start with the latest event of each object, including
+// tombstones of deleted objects
+func TestWebsocketCompacted(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	tasks := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	for _, name := range []string{"compact-created", "compact-updated", "compact-deleted"} {
+		if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, name, "1"), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+	// Give the informer time to see the creations before changing them
+	time.Sleep(200 * time.Millisecond)
+	updated := testutils.GetObject("v1beta1", "Task", namespace, "compact-updated", "2")
+	if _, err := tasks.Update(updated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating task: %v", err)
+	}
+	if err := tasks.Delete("compact-deleted", &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting task: %v", err)
+	}
+
+	compacted := func() string {
+		var events []string
+		for _, socketData := range ResourcesEvents.Compacted() {
+			if name := payloadName(socketData); strings.HasPrefix(name, "compact-") {
+				events = append(events, string(socketData.MessageType)+" "+name)
+			}
+		}
+		return strings.Join(events, ", ")
+	}
+	expected := "TaskCreated compact-created, TaskUpdated compact-updated, TaskDeleted compact-deleted"
+	awaitFatal(func() bool {
+		return compacted() == expected
+	}, t, "Expected events to be cached")
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"compact": {"true"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+
+	var received []string
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading compacted events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		if socketData.MessageType == broadcaster.CompactionComplete {
+			break
+		}
+		if name := payloadName(socketData); strings.HasPrefix(name, "compact-") {
+			received = append(received, string(socketData.MessageType)+" "+name)
+		}
+	}
+	if actual := strings.Join(received, ", "); actual != expected {
+		t.Errorf("Expected compacted events %s, actual %s", expected, actual)
+	}
+
+	// Live events follow the compacted ones
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "live-task", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading live events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		if payloadName(socketData) == "live-task" {
+			if socketData.MessageType != broadcaster.TaskCreated {
+				t.Errorf("Expected %s, actual %s", broadcaster.TaskCreated, socketData.MessageType)
+			}
+			break
+		}
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Clients reconnecting with since are sent the events they missed before
+// live ones
+func TestWebsocketSince(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	query := url.Values{"kinds": {"TaskCreated"}, "namespaces": {namespace}}
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: query.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	read := func() broadcaster.SocketData {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Read error: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		return socketData
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	tasks := r.DynamicClient.Resource(gvr).Namespace(namespace)
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "resume-first", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	first := read()
+	if payloadName(first) != "resume-first" || first.Sequence == 0 {
+		t.Fatalf("Expected resume-first with a sequence number, actual %s %d", payloadName(first), first.Sequence)
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+
+	for _, name := range []string{"resume-second", "resume-third"} {
+		if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, name, "1"), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+	awaitFatal(func() bool {
+		for _, socketData := range ResourcesEvents.Compacted() {
+			if payloadName(socketData) == "resume-third" {
+				return true
+			}
+		}
+		return false
+	}, t, "Expected events to be broadcast")
+
+	// since may be combined with compact as long as it is false
+	query.Set("since", strconv.FormatUint(first.Sequence, 10))
+	query.Set("compact", "false")
+	websocketURL.RawQuery = query.Encode()
+	connection, _, err = gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var received []string
+	sequence := first.Sequence
+	for {
+		socketData := read()
+		if socketData.MessageType == broadcaster.ReplayComplete {
+			if count, _ := socketData.Payload.(float64); int(count) != len(received) {
+				t.Errorf("Expected %s with %d replayed events, actual %v", broadcaster.ReplayComplete, len(received), socketData.Payload)
+			}
+			break
+		}
+		if socketData.Sequence <= sequence {
+			t.Errorf("Expected sequence numbers after %d, actual %d", sequence, socketData.Sequence)
+		}
+		sequence = socketData.Sequence
+		received = append(received, payloadName(socketData))
+	}
+	if expected := "resume-second, resume-third"; strings.Join(received, ", ") != expected {
+		t.Errorf("Expected replayed %s, actual %s", expected, strings.Join(received, ", "))
+	}
+
+	// Live events follow the replayed ones
+	if _, err := tasks.Create(testutils.GetObject("v1beta1", "Task", namespace, "resume-live", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	if live := read(); payloadName(live) != "resume-live" || live.Sequence <= sequence {
+		t.Errorf("Expected resume-live after sequence %d, actual %s %d", sequence, payloadName(live), live.Sequence)
+	}
+}
+
+// Invalid since values, or since combined with compact, fail the upgrade
+func TestWebsocketInvalidSince(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+
+	for _, query := range []url.Values{{"since": {"-1"}}, {"since": {"12"}, "compact": {"true"}}} {
+		websocketURL := url.URL{
+			Scheme:   "ws",
+			Host:     strings.TrimPrefix(server.URL, "http://"),
+			Path:     "/v1/websockets/resources",
+			RawQuery: query.Encode(),
+		}
+		if status, _ := dialUpgradeError(websocketURL.String(), nil, t); status != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, actual %d", query.Encode(), http.StatusBadRequest, status)
+		}
+	}
+}
+
+// payloadName returns the name of the object in a cached or decoded message
+func payloadName(socketData broadcaster.SocketData) string {
+	if object, err := meta.Accessor(socketData.Payload); err == nil {
+		return object.GetName()
+	}
+	payload, _ := socketData.Payload.(map[string]interface{})
+	metadata, _ := payload["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
+
+// The namespaces websocket only streams namespace creations and deletions
+func TestWebsocketNamespaces(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme: "ws",
+		Host:   strings.TrimPrefix(server.URL, "http://"),
+		Path:   "/v1/websockets/namespaces",
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected namespaces client within pool")
+
+	namespaces := r.K8sClient.CoreV1().Namespaces()
+	if _, err := namespaces.Create(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "lifecycle"}}); err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(testutils.GetObject("v1beta1", "Task", namespace, "ignored", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	// Give the informer time to see the creation before deleting
+	time.Sleep(200 * time.Millisecond)
+	if err := namespaces.Delete("lifecycle", &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Error deleting namespace: %v", err)
+	}
+
+	var received []string
+	connection.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			// No further events once the deadline passes
+			break
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		received = append(received, fmt.Sprintf("%s %s", socketData.MessageType, payloadName(socketData)))
+	}
+	expected := []string{"NamespaceCreated lifecycle", "NamespaceDeleted lifecycle"}
+	if fmt.Sprint(received) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, actual %v", expected, received)
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// enrich=duration adds the duration of completed runs to their events, and
+// leaves other kinds alone
+func TestWebsocketEnrichDuration(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"enrich": {"duration"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected enriched client within pool")
+
+	task := testutils.GetObject("v1beta1", "Task", namespace, "enrich-task", "1")
+	unstructured.SetNestedField(task.Object, "2021-01-01T00:00:00Z", "status", "startTime")
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	pipelineRun := testutils.GetObject("v1beta1", "PipelineRun", namespace, "enrich-run", "1")
+	unstructured.SetNestedField(pipelineRun.Object, "2021-01-01T00:00:00Z", "status", "startTime")
+	unstructured.SetNestedField(pipelineRun.Object, "2021-01-01T00:01:30Z", "status", "completionTime")
+	pipelineRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "pipelineruns"}
+	if _, err := r.DynamicClient.Resource(pipelineRuns).Namespace(namespace).Create(pipelineRun, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating pipelineRun: %v", err)
+	}
+
+	enriched := map[string]broadcaster.SocketData{}
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(enriched) < 2 {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading enriched events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		if name := payloadName(socketData); strings.HasPrefix(name, "enrich-") {
+			enriched[name] = socketData
+		}
+	}
+	if run := enriched["enrich-run"]; run.Duration == nil || *run.Duration != 90 || run.Elapsed != nil {
+		t.Errorf("Expected PipelineRun event with a duration of 90 seconds and no elapsed time, actual %+v", run)
+	}
+	if task := enriched["enrich-task"]; task.Duration != nil || task.Elapsed != nil {
+		t.Errorf("Expected Task event without duration, actual %+v", task)
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// delta=true sends Updated events as a patch of the fields that changed, and
+// every deltaSnapshotEvery-th update whole
+func TestWebsocketDelta(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"delta": {"true"}, "deltaSnapshotEvery": {"2"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected delta client within pool")
+
+	task := testutils.GetObject("v1beta1", "Task", namespace, "delta-task", "1")
+	var steps []interface{}
+	for i := 0; i < 100; i++ {
+		steps = append(steps, map[string]interface{}{
+			"name":   fmt.Sprintf("step-%d", i),
+			"image":  "alpine",
+			"script": strings.Repeat("echo hello\n", 10),
+		})
+	}
+	unstructured.SetNestedSlice(task.Object, steps, "spec", "steps")
+	unstructured.SetNestedField(task.Object, "before", "spec", "description")
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(task, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	// readTaskEvent returns the next event of the Task
+	readTaskEvent := func() broadcaster.SocketData {
+		connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			_, message, err := connection.ReadMessage()
+			if err != nil {
+				t.Fatalf("Error reading events: %s", err)
+			}
+			var socketData broadcaster.SocketData
+			if err := json.Unmarshal(message, &socketData); err != nil {
+				t.Fatalf("Error decoding message: %s", err)
+			}
+			if socketData.MessageType == broadcaster.TaskCreated || socketData.MessageType == broadcaster.TaskUpdated {
+				// Deltas name the object at the top level
+				if payload, _ := socketData.Payload.(map[string]interface{}); payload["name"] == "delta-task" || payloadName(socketData) == "delta-task" {
+					return socketData
+				}
+			}
+		}
+	}
+
+	created := readTaskEvent()
+	if created.MessageType != broadcaster.TaskCreated || created.Delta {
+		t.Fatalf("Expected the whole Task on creation, actual %+v", created)
+	}
+	if description, _, _ := unstructured.NestedString(created.Payload.(map[string]interface{}), "spec", "description"); description != "before" {
+		t.Errorf("Expected the created Task's description, actual '%s'", description)
+	}
+
+	task.SetResourceVersion("2")
+	unstructured.SetNestedField(task.Object, "after", "spec", "description")
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Update(task, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating task: %v", err)
+	}
+	updated := readTaskEvent()
+	if updated.MessageType != broadcaster.TaskUpdated || !updated.Delta {
+		t.Fatalf("Expected a delta on update, actual %+v", updated)
+	}
+	var delta ObjectDelta
+	payload, _ := json.Marshal(updated.Payload)
+	json.Unmarshal(payload, &delta)
+	expected := ObjectDelta{
+		Name:                "delta-task",
+		Namespace:           namespace,
+		Kind:                "Task",
+		ResourceVersion:     "2",
+		FromResourceVersion: "1",
+		Patch: []PatchOperation{
+			{Op: "replace", Path: "/metadata/resourceVersion", Value: "2"},
+			{Op: "replace", Path: "/spec/description", Value: "after"},
+		},
+	}
+	if !reflect.DeepEqual(delta, expected) {
+		t.Errorf("Expected delta %+v, actual %+v", expected, delta)
+	}
+
+	task.SetResourceVersion("3")
+	unstructured.SetNestedField(task.Object, "snapshot", "spec", "description")
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Update(task, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Error updating task: %v", err)
+	}
+	snapshot := readTaskEvent()
+	if snapshot.MessageType != broadcaster.TaskUpdated || snapshot.Delta {
+		t.Fatalf("Expected the whole Task on the second update, actual %+v", snapshot)
+	}
+	if description, _, _ := unstructured.NestedString(snapshot.Payload.(map[string]interface{}), "spec", "description"); description != "snapshot" {
+		t.Errorf("Expected the updated Task's description, actual '%s'", description)
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// convertTo delivers Tekton objects in the requested API version, and flags
+// those that cannot be converted
+func TestWebsocketConvertTo(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	websocketURL := url.URL{
+		Scheme:   "ws",
+		Host:     strings.TrimPrefix(server.URL, "http://"),
+		Path:     "/v1/websockets/resources",
+		RawQuery: url.Values{"convertTo": {"v1"}}.Encode(),
+	}
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", websocketURL.String(), err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected converting client within pool")
+
+	// The fake client does not convert, serve the v1 version of the Task as
+	// the API server would
+	v1Tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(v1Tasks).Namespace(namespace).Create(testutils.GetObject("tekton.dev/v1", "Task", namespace, "convert-task", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating v1 task: %v", err)
+	}
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(testutils.GetObject("tekton.dev/v1beta1", "Task", namespace, "convert-task", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+	clusterTasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}
+	if _, err := r.DynamicClient.Resource(clusterTasks).Create(testutils.GetClusterObject("tekton.dev/v1beta1", "ClusterTask", "convert-clustertask", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating clusterTask: %v", err)
+	}
+
+	received := map[string]broadcaster.SocketData{}
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(received) < 2 {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading converted events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		if name := payloadName(socketData); strings.HasPrefix(name, "convert-") {
+			received[name] = socketData
+		}
+	}
+	apiVersion := func(socketData broadcaster.SocketData) string {
+		payload, _ := socketData.Payload.(map[string]interface{})
+		version, _ := payload["apiVersion"].(string)
+		return version
+	}
+	if task := received["convert-task"]; apiVersion(task) != "tekton.dev/v1" || task.Unconverted {
+		t.Errorf("Expected Task event in tekton.dev/v1, actual %+v", task)
+	}
+	if clusterTask := received["convert-clustertask"]; apiVersion(clusterTask) != "tekton.dev/v1beta1" || !clusterTask.Unconverted {
+		t.Errorf("Expected unconverted ClusterTask event in tekton.dev/v1beta1, actual %+v", clusterTask)
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// namespaceUserAuthorizer only allows a single user in a single namespace
+type namespaceUserAuthorizer struct {
+	user      string
+	namespace string
+}
+
+func (a namespaceUserAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	return user == a.user && namespace == a.namespace, nil
+}
+
+// With an Authorizer, events of objects the user may not get are withheld
+func TestWebsocketReadAccess(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = namespaceUserAuthorizer{user: "alice", namespace: namespace}
+	server.Config.Handler = router.Register(*r)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/resources", strings.TrimPrefix(server.URL, "http://"))
+	header := http.Header{}
+	header.Set(UserHeader, "alice")
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, header)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	// The hidden Task is created first so its event would arrive before the
+	// readable one if it were delivered
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	for _, task := range []*unstructured.Unstructured{
+		testutils.GetObject("v1beta1", "Task", "other", "access-hidden", "1"),
+		testutils.GetObject("v1beta1", "Task", namespace, "access-readable", "1"),
+	} {
+		if _, err := r.DynamicClient.Resource(tasks).Namespace(task.GetNamespace()).Create(task, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating task: %v", err)
+		}
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		name := payloadName(socketData)
+		if name == "access-hidden" {
+			t.Fatalf("Expected the event of a Task in another namespace to be withheld, received %s", socketData.MessageType)
+		}
+		if name == "access-readable" {
+			break
+		}
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// With an Authorizer, OwnershipChanged messages of objects the user may not
+// get are withheld like their other events
+func TestWebsocketReadAccessOwnershipChanged(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = namespaceUserAuthorizer{user: "alice", namespace: namespace}
+	server.Config.Handler = router.Register(*r)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/resources", strings.TrimPrefix(server.URL, "http://"))
+	header := http.Header{}
+	header.Set(UserHeader, "alice")
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, header)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	// The hidden TaskRun is adopted first so its message would arrive before
+	// the readable one if it were delivered
+	taskRuns := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "taskruns"}
+	owner := metav1.OwnerReference{APIVersion: "tekton.dev/v1beta1", Kind: "PipelineRun", Name: "owner", UID: "owner-uid"}
+	for _, taskRun := range []*unstructured.Unstructured{
+		testutils.GetObject("v1beta1", "TaskRun", "other", "adopted-hidden", "1"),
+		testutils.GetObject("v1beta1", "TaskRun", namespace, "adopted-readable", "1"),
+	} {
+		client := r.DynamicClient.Resource(taskRuns).Namespace(taskRun.GetNamespace())
+		if _, err := client.Create(taskRun, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating taskRun: %v", err)
+		}
+		taskRun.SetResourceVersion("2")
+		taskRun.SetOwnerReferences([]metav1.OwnerReference{owner})
+		if _, err := client.Update(taskRun, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("Error updating taskRun: %v", err)
+		}
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		name := payloadName(socketData)
+		if socketData.MessageType == broadcaster.OwnershipChanged {
+			payload, _ := socketData.Payload.(map[string]interface{})
+			name, _ = payload["name"].(string)
+		}
+		if name == "adopted-hidden" {
+			t.Fatalf("Expected the messages of a TaskRun in another namespace to be withheld, received %s", socketData.MessageType)
+		}
+		if name == "adopted-readable" && socketData.MessageType == broadcaster.OwnershipChanged {
+			break
+		}
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// With an Authorizer, events of cluster scoped objects are checked without a
+// namespace rather than accepted
+func TestWebsocketReadAccessClusterScoped(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = namespaceUserAuthorizer{user: "alice", namespace: namespace}
+	server.Config.Handler = router.Register(*r)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/resources", strings.TrimPrefix(server.URL, "http://"))
+	header := http.Header{}
+	header.Set(UserHeader, "alice")
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, header)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	// The hidden ClusterTask is created first so its event would arrive before
+	// the readable Task if it were delivered
+	clusterTasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "clustertasks"}
+	if _, err := r.DynamicClient.Resource(clusterTasks).Create(testutils.GetClusterObject("tekton.dev/v1beta1", "ClusterTask", "access-hidden", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating clusterTask: %v", err)
+	}
+	tasks := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: "tasks"}
+	if _, err := r.DynamicClient.Resource(tasks).Namespace(namespace).Create(testutils.GetObject("v1beta1", "Task", namespace, "access-readable", "1"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Error creating task: %v", err)
+	}
+
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, message, err := connection.ReadMessage()
+		if err != nil {
+			t.Fatalf("Error reading events: %s", err)
+		}
+		var socketData broadcaster.SocketData
+		if err := json.Unmarshal(message, &socketData); err != nil {
+			t.Fatalf("Error decoding message: %s", err)
+		}
+		name := payloadName(socketData)
+		if name == "access-hidden" {
+			t.Fatalf("Expected the event of a ClusterTask to be withheld, received %s", socketData.MessageType)
+		}
+		if name == "access-readable" {
+			break
+		}
+	}
+	connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// clusterUserAuthorizer only allows a single user across the cluster
+type clusterUserAuthorizer struct {
+	user string
+}
+
+func (a clusterUserAuthorizer) Authorize(ctx context.Context, user, verb, kind, namespace, name string) (bool, error) {
+	return user == a.user && namespace == "", nil
+}
+
+// With an Authorizer, the namespaces websocket only streams to users who may
+// get namespaces
+func TestWebsocketNamespacesReadAccess(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = clusterUserAuthorizer{user: "alice"}
+	server.Config.Handler = router.Register(*r)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/namespaces", strings.TrimPrefix(server.URL, "http://"))
+	connections := map[string]*gorillaSocket.Conn{}
+	for _, user := range []string{"alice", "bob"} {
+		header := http.Header{}
+		header.Set(UserHeader, user)
+		connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, header)
+		if err != nil {
+			t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+		}
+		defer connection.Close()
+		connections[user] = connection
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 2
+	}, t, "Expected clients within pool")
+
+	if _, err := r.K8sClient.CoreV1().Namespaces().Create(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "access-namespace"}}); err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	for user, expected := range map[string]bool{"alice": true, "bob": false} {
+		connections[user].SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, message, err := connections[user].ReadMessage()
+		if received := err == nil; received != expected {
+			t.Errorf("Expected namespace event received by %s %t, actual %t: %s", user, expected, received, message)
+		}
+	}
+	for _, connection := range connections {
+		connection.Close()
+	}
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 0
+	}, t, "Pool should be empty")
+}
+
+// Rejected websocket upgrades respond with a JSON error code
+func TestWebsocketUpgradeErrorAuthorization(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+	r.Authorizer = denyKindAuthorizer{kind: "TaskRun"}
+	server.Config.Handler = router.Register(*r)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/namespaces/%s/taskruns/build/steps/compile/logs", strings.TrimPrefix(server.URL, "http://"), namespace)
+	tests := []struct {
+		user   string
+		status int
+		code   string
+	}{
+		{"", http.StatusUnauthorized, websocket.CodeUnauthorized},
+		{"alice", http.StatusForbidden, websocket.CodeForbidden},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		if test.user != "" {
+			header.Set(UserHeader, test.user)
+		}
+		status, upgradeError := dialUpgradeError(endpoint, header, t)
+		if status != test.status {
+			t.Errorf("Expected status %d for user '%s', actual %d", test.status, test.user, status)
+		}
+		if upgradeError.Code != test.code || upgradeError.Message == "" {
+			t.Errorf("Expected error code %s for user '%s', actual %+v", test.code, test.user, upgradeError)
+		}
+	}
+}
+
+// Clients that would be evicted straight away are told to retry later
+func TestWebsocketUpgradeErrorOverloaded(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+	ResourcesBroadcaster.SetPoolLimit(1)
+	defer ResourcesBroadcaster.SetPoolLimit(0)
+
+	endpoint := fmt.Sprintf("ws://%s/v1/websockets/resources", strings.TrimPrefix(server.URL, "http://"))
+	connection, _, err := gorillaSocket.DefaultDialer.Dial(endpoint, nil)
+	if err != nil {
+		t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+	}
+	defer connection.Close()
+	awaitFatal(func() bool {
+		return ResourcesBroadcaster.PoolSize() == 1
+	}, t, "Expected client within pool")
+
+	status, upgradeError := dialUpgradeError(endpoint, nil, t)
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, actual %d", http.StatusServiceUnavailable, status)
+	}
+	if upgradeError.Code != websocket.CodeOverloaded {
+		t.Errorf("Expected error code %s, actual %+v", websocket.CodeOverloaded, upgradeError)
+	}
+	if ResourcesBroadcaster.PoolSize() != 1 {
+		t.Errorf("Expected the connected client to be kept, pool size %d", ResourcesBroadcaster.PoolSize())
+	}
+}
+
+// dialUpgradeError dials a websocket expected to be rejected, returning the
+// status and JSON error of the response
+func dialUpgradeError(endpoint string, header http.Header, t *testing.T) (int, websocket.UpgradeError) {
+	connection, response, err := gorillaSocket.DefaultDialer.Dial(endpoint, header)
+	if err == nil {
+		connection.Close()
+		t.Fatalf("Expected upgrade of %s to be rejected", endpoint)
+	}
+	if response == nil {
+		t.Fatalf("Dial error connecting to %s: %s", endpoint, err)
+	}
+	defer response.Body.Close()
+	var upgradeError websocket.UpgradeError
+	if err := json.NewDecoder(response.Body).Decode(&upgradeError); err != nil {
+		t.Fatalf("Error decoding upgrade error: %s", err)
+	}
+	return response.StatusCode, upgradeError
+}
+
 // Abstract connection into a channel of broadcaster.SocketData
 // Closed channel = closed connection
 func clientWebsocket(websocketEndpoint string, readDeadline time.Duration, t *testing.T) <-chan broadcaster.SocketData {
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/tektoncd/dashboard/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Workspace is a workspace name declared by Pipelines or Tasks. Conflicting
+// is true if its declarations disagree on whether it is optional, or Tasks
+// disagree on where or how it is mounted
+type Workspace struct {
+	Name         string                 `json:"name"`
+	Conflicting  bool                   `json:"conflicting"`
+	Declarations []WorkspaceDeclaration `json:"declarations"`
+}
+
+// WorkspaceDeclaration is a workspace declared in the spec of a Pipeline or
+// Task. Only Tasks have a mount path and read only flag
+type WorkspaceDeclaration struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Optional    bool   `json:"optional"`
+	MountPath   string `json:"mountPath,omitempty"`
+	ReadOnly    bool   `json:"readOnly,omitempty"`
+}
+
+// GetWorkspaces lists the workspaces declared by the Pipelines and Tasks of a
+// namespace grouped by name
+func (r Resource) GetWorkspaces(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+
+	workspaces := map[string]*Workspace{}
+	for _, kind := range []struct{ kind, resource string }{{"Pipeline", "pipelines"}, {"Task", "tasks"}} {
+		list, err := r.DynamicClient.Resource(r.tektonGVR(kind.resource)).Namespace(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			utils.RespondError(response, err, http.StatusInternalServerError)
+			return
+		}
+		for i := range list.Items {
+			for _, declaration := range workspaceDeclarations(kind.kind, &list.Items[i]) {
+				workspace, ok := workspaces[declaration.workspace]
+				if !ok {
+					workspace = &Workspace{Name: declaration.workspace}
+					workspaces[declaration.workspace] = workspace
+				}
+				workspace.add(declaration.WorkspaceDeclaration)
+			}
+		}
+	}
+
+	result := []Workspace{}
+	for _, workspace := range workspaces {
+		sort.Slice(workspace.Declarations, func(i, j int) bool {
+			a, b := workspace.Declarations[i], workspace.Declarations[j]
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			return a.Name < b.Name
+		})
+		result = append(result, *workspace)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	response.WriteEntity(result)
+}
+
+// add records a declaration of the workspace, checking it against the others
+func (w *Workspace) add(declaration WorkspaceDeclaration) {
+	for _, other := range w.Declarations {
+		if other.Optional != declaration.Optional {
+			w.Conflicting = true
+		}
+		if other.Kind == "Task" && declaration.Kind == "Task" &&
+			(other.MountPath != declaration.MountPath || other.ReadOnly != declaration.ReadOnly) {
+			w.Conflicting = true
+		}
+	}
+	w.Declarations = append(w.Declarations, declaration)
+}
+
+// namedDeclaration is a declaration with the name of the workspace it declares
+type namedDeclaration struct {
+	WorkspaceDeclaration
+	workspace string
+}
+
+// workspaceDeclarations reads the workspaces declared in the spec of a
+// Pipeline or Task
+func workspaceDeclarations(kind string, object *unstructured.Unstructured) []namedDeclaration {
+	var declarations []namedDeclaration
+	workspaces, _, _ := unstructured.NestedSlice(object.Object, "spec", "workspaces")
+	for _, w := range workspaces {
+		workspace, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := workspace["name"].(string)
+		if name == "" {
+			continue
+		}
+		declaration := WorkspaceDeclaration{Kind: kind, Name: object.GetName()}
+		declaration.Description, _ = workspace["description"].(string)
+		declaration.Optional, _ = workspace["optional"].(bool)
+		declaration.MountPath, _ = workspace["mountPath"].(string)
+		declaration.ReadOnly, _ = workspace["readOnly"].(bool)
+		declarations = append(declarations, namedDeclaration{WorkspaceDeclaration: declaration, workspace: name})
+	}
+	return declarations
+}
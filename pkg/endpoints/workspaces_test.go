@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoints_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	. "github.com/tektoncd/dashboard/pkg/endpoints"
+	"github.com/tektoncd/dashboard/pkg/testutils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GET workspaces groups the workspaces declared by Pipelines and Tasks by name
+func TestGETWorkspaces(t *testing.T) {
+	server, r, namespace := testutils.DummyServer()
+	defer server.Close()
+
+	objects := []struct {
+		kind       string
+		resource   string
+		name       string
+		workspaces []interface{}
+	}{
+		{"Pipeline", "pipelines", "build", []interface{}{
+			map[string]interface{}{"name": "source", "description": "The repository"},
+			map[string]interface{}{"name": "cache", "optional": true},
+		}},
+		{"Task", "tasks", "git-clone", []interface{}{
+			map[string]interface{}{"name": "source", "mountPath": "/workspace/source"},
+		}},
+		{"Task", "tasks", "npm", []interface{}{
+			map[string]interface{}{"name": "source", "mountPath": "/src", "readOnly": true},
+			map[string]interface{}{"name": "cache", "optional": true},
+		}},
+	}
+	for _, object := range objects {
+		resource := testutils.GetObject("v1beta1", object.kind, namespace, object.name, "1")
+		unstructured.SetNestedSlice(resource.Object, object.workspaces, "spec", "workspaces")
+		gvr := schema.GroupVersionResource{Group: "tekton.dev", Version: "v1beta1", Resource: object.resource}
+		if _, err := r.DynamicClient.Resource(gvr).Namespace(namespace).Create(resource, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Error creating %s: %v", object.kind, err)
+		}
+	}
+
+	httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/namespaces/%s/workspaces", server.URL, namespace), nil)
+	response, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("Error getting workspaces: %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected statusCode %d, actual %d", http.StatusOK, response.StatusCode)
+	}
+	var workspaces []Workspace
+	if err := json.NewDecoder(response.Body).Decode(&workspaces); err != nil {
+		t.Fatalf("Error decoding workspaces: %v", err)
+	}
+
+	// The Tasks mount source differently
+	expected := []Workspace{
+		{Name: "cache", Declarations: []WorkspaceDeclaration{
+			{Kind: "Pipeline", Name: "build", Optional: true},
+			{Kind: "Task", Name: "npm", Optional: true},
+		}},
+		{Name: "source", Conflicting: true, Declarations: []WorkspaceDeclaration{
+			{Kind: "Pipeline", Name: "build", Description: "The repository"},
+			{Kind: "Task", Name: "git-clone", MountPath: "/workspace/source"},
+			{Kind: "Task", Name: "npm", MountPath: "/src", ReadOnly: true},
+		}},
+	}
+	if !reflect.DeepEqual(workspaces, expected) {
+		t.Errorf("Expected workspaces %+v, actual %+v", expected, workspaces)
+	}
+}
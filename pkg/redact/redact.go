@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact masks the values of configured fields of objects before they
+// leave the dashboard, over websockets or in REST responses
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Marker replaces the redacted values
+const Marker = "[REDACTED]"
+
+// wildcard is the path segment matching every key of a map or element of a
+// list
+const wildcard = "*"
+
+// Redactor replaces the values found at a list of paths with Marker
+type Redactor struct {
+	paths [][]string
+}
+
+// NewRedactor returns a Redactor for paths of dot separated keys, such as
+// spec.steps.env.value. A segment applied to a list applies to each of its
+// elements unless it is an index, * matches every key or element and \.
+// escapes a dot that is part of a key
+func NewRedactor(paths []string) *Redactor {
+	r := &Redactor{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path != "" {
+			r.paths = append(r.paths, splitPath(path))
+		}
+	}
+	return r
+}
+
+// splitPath splits a path on its unescaped dots
+func splitPath(path string) []string {
+	var segments []string
+	var segment strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			segment.WriteByte('.')
+			i++
+		case path[i] == '.':
+			segments = append(segments, segment.String())
+			segment.Reset()
+		default:
+			segment.WriteByte(path[i])
+		}
+	}
+	return append(segments, segment.String())
+}
+
+// Empty returns whether the Redactor has no paths, so redacting is a no-op
+func (r *Redactor) Empty() bool {
+	return r == nil || len(r.paths) == 0
+}
+
+// Object redacts an object decoded from JSON in place
+func (r *Redactor) Object(object map[string]interface{}) {
+	if r.Empty() {
+		return
+	}
+	for _, path := range r.paths {
+		redactPath(object, path)
+	}
+}
+
+func redactPath(value interface{}, path []string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		segment := path[0]
+		for key, child := range typed {
+			if segment != wildcard && segment != key {
+				continue
+			}
+			if len(path) == 1 {
+				typed[key] = Marker
+			} else {
+				redactPath(child, path[1:])
+			}
+		}
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil {
+			// A key applies to every element, a wildcard selects them
+			rest := path
+			if path[0] == wildcard {
+				rest = path[1:]
+			}
+			for i, element := range typed {
+				if len(rest) == 0 {
+					typed[i] = Marker
+				} else {
+					redactPath(element, rest)
+				}
+			}
+			return
+		}
+		if index < 0 || index >= len(typed) {
+			return
+		}
+		if len(path) == 1 {
+			typed[index] = Marker
+		} else {
+			redactPath(typed[index], path[1:])
+		}
+	}
+}
+
+// Payload returns a redacted copy of an object broadcast by the informers,
+// which must not be modified as they share it with their cache. Payloads that
+// are not objects are returned unchanged
+func (r *Redactor) Payload(payload interface{}) interface{} {
+	if r.Empty() {
+		return payload
+	}
+	if tombstone, ok := payload.(cache.DeletedFinalStateUnknown); ok {
+		tombstone.Obj = r.Payload(tombstone.Obj)
+		return tombstone
+	}
+	var object *unstructured.Unstructured
+	switch typed := payload.(type) {
+	case *unstructured.Unstructured:
+		object = typed.DeepCopy()
+	case runtime.Object:
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+		if err != nil {
+			return payload
+		}
+		object = &unstructured.Unstructured{Object: content}
+		// Typed objects from informers have an empty TypeMeta
+		if gvk := typed.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+			object.SetGroupVersionKind(gvk)
+		}
+	default:
+		return payload
+	}
+	r.Object(object.Object)
+	return object
+}
+
+// JSON returns a redacted copy of a JSON response body holding an object, a
+// list of objects in its items, or a watch event with the object. Bodies that
+// are not JSON objects are returned unchanged
+func (r *Redactor) JSON(body []byte) []byte {
+	if r.Empty() {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Numbers are kept as written, as decoding them to float64 loses precision
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return body
+	}
+	r.Object(document)
+	if items, ok := document["items"].([]interface{}); ok {
+		for _, item := range items {
+			if object, ok := item.(map[string]interface{}); ok {
+				r.Object(object)
+			}
+		}
+	}
+	if _, ok := document["type"].(string); ok {
+		if object, ok := document["object"].(map[string]interface{}); ok {
+			r.Object(object)
+		}
+	}
+	redacted, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	if bytes.HasSuffix(bytes.TrimRight(body, " \t\r"), []byte("\n")) {
+		redacted = append(redacted, '\n')
+	}
+	return redacted
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func decode(t *testing.T, document string) map[string]interface{} {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(document), &object); err != nil {
+		t.Fatalf("Error decoding %s: %v", document, err)
+	}
+	return object
+}
+
+func TestRedactorObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		object   string
+		expected string
+	}{
+		{
+			name:     "nested key",
+			paths:    []string{"spec.token"},
+			object:   `{"spec":{"token":"a","other":"b"}}`,
+			expected: `{"spec":{"token":"[REDACTED]","other":"b"}}`,
+		},
+		{
+			name:     "key applied to every element of a list",
+			paths:    []string{"spec.steps.env.value"},
+			object:   `{"spec":{"steps":[{"env":[{"name":"A","value":"1"}]},{"env":[{"name":"B","value":"2"},{"name":"C"}]}]}}`,
+			expected: `{"spec":{"steps":[{"env":[{"name":"A","value":"[REDACTED]"}]},{"env":[{"name":"B","value":"[REDACTED]"},{"name":"C"}]}]}}`,
+		},
+		{
+			name:     "index",
+			paths:    []string{"spec.params.1"},
+			object:   `{"spec":{"params":["a","b","c"]}}`,
+			expected: `{"spec":{"params":["a","[REDACTED]","c"]}}`,
+		},
+		{
+			name:     "wildcard",
+			paths:    []string{"data.*"},
+			object:   `{"data":{"a":"1","b":"2"}}`,
+			expected: `{"data":{"a":"[REDACTED]","b":"[REDACTED]"}}`,
+		},
+		{
+			name:     "escaped dot",
+			paths:    []string{`metadata.annotations.example\.com/secret`},
+			object:   `{"metadata":{"annotations":{"example.com/secret":"a","example":{"com/secret":"b"}}}}`,
+			expected: `{"metadata":{"annotations":{"example.com/secret":"[REDACTED]","example":{"com/secret":"b"}}}}`,
+		},
+		{
+			name:     "missing path",
+			paths:    []string{"spec.missing.value", "spec.params.5"},
+			object:   `{"spec":{"params":["a"]}}`,
+			expected: `{"spec":{"params":["a"]}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			object := decode(t, test.object)
+			NewRedactor(test.paths).Object(object)
+			if expected := decode(t, test.expected); !reflect.DeepEqual(object, expected) {
+				t.Errorf("Expected %v, actual %v", expected, object)
+			}
+		})
+	}
+}
+
+func TestRedactorPayloadCopies(t *testing.T) {
+	redactor := NewRedactor([]string{"data.password"})
+
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"data": map[string]interface{}{"password": "hunter2"},
+	}}
+	redacted := redactor.Payload(object).(*unstructured.Unstructured)
+	if value, _, _ := unstructured.NestedString(redacted.Object, "data", "password"); value != Marker {
+		t.Errorf("Expected redacted password, actual %s", value)
+	}
+	if value, _, _ := unstructured.NestedString(object.Object, "data", "password"); value != "hunter2" {
+		t.Errorf("Expected original object to be unchanged, actual %s", value)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "credentials"}, Data: map[string]string{"password": "hunter2"}}
+	redacted = redactor.Payload(configMap).(*unstructured.Unstructured)
+	if value, _, _ := unstructured.NestedString(redacted.Object, "data", "password"); value != Marker {
+		t.Errorf("Expected redacted password, actual %s", value)
+	}
+	if redacted.GetName() != "credentials" {
+		t.Errorf("Expected name credentials, actual %s", redacted.GetName())
+	}
+	if configMap.Data["password"] != "hunter2" {
+		t.Errorf("Expected original ConfigMap to be unchanged, actual %s", configMap.Data["password"])
+	}
+
+	if payload := redactor.Payload("log line"); payload != "log line" {
+		t.Errorf("Expected payload that is not an object to be unchanged, actual %v", payload)
+	}
+}
+
+func TestRedactorJSON(t *testing.T) {
+	redactor := NewRedactor([]string{"spec.token"})
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"object", `{"spec":{"token":"a","replicas":12345678901234567890}}`, `{"spec":{"replicas":12345678901234567890,"token":"[REDACTED]"}}`},
+		{"list", `{"kind":"List","items":[{"spec":{"token":"a"}},{"spec":{}}]}`, `{"items":[{"spec":{"token":"[REDACTED]"}},{"spec":{}}],"kind":"List"}`},
+		{"watch event", "{\"type\":\"ADDED\",\"object\":{\"spec\":{\"token\":\"a\"}}}\n", "{\"object\":{\"spec\":{\"token\":\"[REDACTED]\"}},\"type\":\"ADDED\"}\n"},
+		{"not an object", `["a","b"]`, `["a","b"]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := string(redactor.JSON([]byte(test.body))); actual != test.expected {
+				t.Errorf("Expected %s, actual %s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	logging "github.com/tektoncd/dashboard/pkg/logging"
+)
+
+// ProfilingRoot is the URL root of the pprof endpoints
+const ProfilingRoot = "/debug/pprof/"
+
+// RegisterProfiling returns an HTTP handler serving the pprof endpoints, for
+// an admin listener separate from the dashboard. They are never registered
+// with the dashboard router
+func RegisterProfiling() *http.ServeMux {
+	logging.Log.Info("Registering profiling endpoints")
+	mux := http.NewServeMux()
+	mux.HandleFunc(ProfilingRoot, pprof.Index)
+	mux.HandleFunc(ProfilingRoot+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(ProfilingRoot+"profile", pprof.Profile)
+	mux.HandleFunc(ProfilingRoot+"symbol", pprof.Symbol)
+	mux.HandleFunc(ProfilingRoot+"trace", pprof.Trace)
+	return mux
+}
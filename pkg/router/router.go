@@ -51,6 +51,10 @@ const ExtensionBundleLocationKey = "tekton-dashboard-bundle-location"
 // ExtensionDisplayNameKey is the display name annotation key
 const ExtensionDisplayNameKey = "tekton-dashboard-display-name"
 
+// ExtensionKindsKey is the annotation listing the comma separated resource
+// kinds an extension provides views for
+const ExtensionKindsKey = "tekton-dashboard-kinds"
+
 // ExtensionRoot is the URL root when accessing extensions
 const ExtensionRoot = "/v1/extensions"
 
@@ -59,25 +63,48 @@ const webResourcesDir = "/var/run/ko"
 var webResourcesStaticPattern = regexp.MustCompile("^/([[:alnum:]]+\\.)?[[:alnum:]]+\\.(js)|(css)|(png)$")
 var webResourcesStaticExcludePattern = regexp.MustCompile("^/favicon.png$")
 
-// Register returns an HTTP handler that has the Dashboard REST API registered
+// Register returns an HTTP handler that has the Dashboard REST API registered.
+// The websocket endpoints are left out when they have their own port, see
+// RegisterWebsockets
 func Register(resource endpoints.Resource) *Handler {
 	logging.Log.Info("Registering all endpoints")
 	h := &Handler{
 		Container:       restful.NewContainer(),
 		uidExtensionMap: make(map[string]*Extension),
 	}
+	if !resource.Redactor.Empty() {
+		h.Container.Filter(resource.RedactResponses)
+	}
 
 	registerWeb(h.Container)
 	registerPropertiesEndpoint(resource, h.Container)
-	registerWebsocket(resource, h.Container)
+	registerFeaturesEndpoint(resource, h.Container)
+	registerSystemEndpoint(resource, h.Container)
+	registerMetricsEndpoint(resource, h.Container)
+	if resource.Options.WebsocketPort == 0 {
+		registerWebsocket(resource, h.Container)
+		registerEventStream(resource, h.Container)
+	}
 	registerHealthProbe(resource, h.Container)
 	registerReadinessProbe(resource, h.Container)
 	registerKubeAPIProxy(resource, h.Container)
 	registerLogsProxy(resource, h.Container)
+	registerNamespacedAPI(resource, h.Container)
+	registerClusterAPI(resource, h.Container)
 	h.registerExtensions()
 	return h
 }
 
+// RegisterWebsockets returns an HTTP handler that only has the websocket and
+// event stream endpoints registered, for serving them on their own port
+func RegisterWebsockets(resource endpoints.Resource) *restful.Container {
+	logging.Log.Info("Registering websocket endpoints")
+	container := restful.NewContainer()
+	registerWebsocket(resource, container)
+	registerEventStream(resource, container)
+	return container
+}
+
 // Handler is an HTTP handler with internal configuration to avoid global state
 type Handler struct {
 	*restful.Container
@@ -158,14 +185,16 @@ func (h *Handler) registerExtensions() {
 		Consumes(restful.MIME_JSON).
 		Produces(restful.MIME_JSON)
 	extensionWebService.Route(extensionWebService.GET("").To(h.getAllExtensions))
+	extensionWebService.Route(extensionWebService.GET("/for-kind/{kind}").To(h.getExtensionsForKind))
 	h.Add(extensionWebService)
 	h.extensionWebService = extensionWebService
 }
 
 type RedactedExtension struct {
-	Name           string `json:"name"`
-	DisplayName    string `json:"displayname"`
-	BundleLocation string `json:"bundlelocation"`
+	Name           string   `json:"name"`
+	DisplayName    string   `json:"displayname"`
+	BundleLocation string   `json:"bundlelocation"`
+	Kinds          []string `json:"kinds,omitempty"`
 	endpoints      []string
 }
 
@@ -180,6 +209,7 @@ func (h *Handler) getExtensions() []RedactedExtension {
 			Name:           e.Name,
 			DisplayName:    e.DisplayName,
 			BundleLocation: e.BundleLocation,
+			Kinds:          e.Kinds,
 			endpoints:      e.endpoints,
 		}
 		extensions = append(extensions, redactedExtension)
@@ -195,6 +225,22 @@ func (h *Handler) getAllExtensions(request *restful.Request, response *restful.R
 	response.WriteEntity(extensions)
 }
 
+// getExtensionsForKind returns the extensions declaring they handle the kind
+// given by the kind path parameter, ignoring case
+func (h *Handler) getExtensionsForKind(request *restful.Request, response *restful.Response) {
+	kind := request.PathParameter("kind")
+	extensions := []RedactedExtension{}
+	for _, extension := range h.getExtensions() {
+		for _, extensionKind := range extension.Kinds {
+			if strings.EqualFold(extensionKind, kind) {
+				extensions = append(extensions, extension)
+				break
+			}
+		}
+	}
+	response.WriteEntity(extensions)
+}
+
 func registerKubeAPIProxy(r endpoints.Resource, container *restful.Container) {
 	proxy := new(restful.WebService)
 	proxy.Filter(restful.NoBrowserCacheFilter)
@@ -231,14 +277,34 @@ func registerWeb(container *restful.Container) {
 func registerWebsocket(r endpoints.Resource, container *restful.Container) {
 	logging.Log.Info("Adding API for websocket")
 	wsv2 := new(restful.WebService)
+	wsv2.Filter(r.DenyNamespaces)
 	wsv2.
 		Path("/v1/websockets").
 		Consumes(restful.MIME_JSON).
 		Produces(restful.MIME_JSON)
 	wsv2.Route(wsv2.GET("/resources").To(r.EstablishResourcesWebsocket))
+	wsv2.Route(wsv2.GET("/stats").To(r.EstablishRunStatsWebsocket))
+	wsv2.Route(wsv2.GET("/completions").To(r.EstablishCompletionsWebsocket))
+	wsv2.Route(wsv2.GET("/namespaces").To(r.EstablishNamespacesWebsocket))
+	wsv2.Route(wsv2.GET("/watch/{group}/{version}/{resource}").To(r.EstablishWatchWebsocket))
+	wsv2.Route(wsv2.GET("/namespaces/{namespace}/taskruns/{name}/steps/{step}/logs").Filter(r.Authorize("get", "TaskRun")).To(r.EstablishStepLogsWebsocket))
+	wsv2.Route(wsv2.GET("/namespaces/{namespace}/active-logs").Filter(r.Authorize("list", "TaskRun")).To(r.EstablishActiveLogsWebsocket))
 	container.Add(wsv2)
 }
 
+// registerEventStream registers the server-sent event streams, for clients
+// that cannot open websockets
+func registerEventStream(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for event streams")
+	wsv := new(restful.WebService)
+	wsv.Filter(r.DenyNamespaces)
+	wsv.
+		Path("/v1/sse").
+		Produces("text/event-stream")
+	wsv.Route(wsv.GET("/resources").To(r.EstablishResourcesEventStream))
+	container.Add(wsv)
+}
+
 // registerHealthProbes registers the /health endpoint
 func registerHealthProbe(r endpoints.Resource, container *restful.Container) {
 	logging.Log.Info("Adding API for health")
@@ -278,6 +344,48 @@ func registerPropertiesEndpoint(r endpoints.Resource, container *restful.Contain
 	container.Add(wsDefaults)
 }
 
+// registerFeaturesEndpoint adds the endpoint reporting the optional features
+// enabled on the server
+func registerFeaturesEndpoint(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for features")
+	ws := new(restful.WebService)
+	ws.Filter(restful.NoBrowserCacheFilter)
+	ws.
+		Path("/v1/features").
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("").To(r.GetFeatures))
+	container.Add(ws)
+}
+
+// registerSystemEndpoint adds the endpoint reporting the health of the Tekton
+// install for diagnostics
+func registerSystemEndpoint(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for system health")
+	ws := new(restful.WebService)
+	ws.Filter(restful.NoBrowserCacheFilter)
+	ws.
+		Path("/v1/system").
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/health").To(r.GetSystemHealth))
+	container.Add(ws)
+}
+
+// registerMetricsEndpoint adds the endpoint serving the PipelineRun metrics
+// for Prometheus to scrape
+func registerMetricsEndpoint(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for metrics")
+	ws := new(restful.WebService)
+	ws.Filter(restful.NoBrowserCacheFilter)
+	ws.
+		Path("/metrics").
+		Produces("text/plain")
+
+	ws.Route(ws.GET("").To(r.GetMetrics))
+	container.Add(ws)
+}
+
 func registerLogsProxy(r endpoints.Resource, container *restful.Container) {
 	if r.Options.ExternalLogsURL != "" {
 		ws := new(restful.WebService)
@@ -287,6 +395,89 @@ func registerLogsProxy(r endpoints.Resource, container *restful.Container) {
 	}
 }
 
+// registerClusterAPI registers the endpoints that aggregate resources across
+// all namespaces, they require cluster-wide permissions
+func registerClusterAPI(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for cluster-wide resources")
+	ws := new(restful.WebService)
+	ws.Filter(restful.NoBrowserCacheFilter)
+	ws.
+		Path("/v1/pipelineruns").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/summary/all").Filter(r.Authorize("list", "PipelineRun")).To(r.GetPipelineRunSummary))
+	container.Add(ws)
+}
+
+// registerNamespacedAPI registers the endpoints that operate on resources
+// within a namespace
+func registerNamespacedAPI(r endpoints.Resource, container *restful.Container) {
+	logging.Log.Info("Adding API for namespaced resources")
+	ws := new(restful.WebService)
+	ws.Filter(restful.NoBrowserCacheFilter)
+	ws.Filter(r.DenyNamespaces)
+	ws.
+		Path("/v1/namespaces").
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/{namespace}/recent").To(r.GetRecentChanges))
+	ws.Route(ws.GET("/{namespace}/ci-overview").Filter(r.Authorize("list", "PipelineRun")).To(r.GetCIOverview))
+	ws.Route(ws.GET("/{namespace}/eventlisteners/{name}/overview").Filter(r.Authorize("get", "EventListener")).Filter(r.Authorize("list", "PipelineRun")).To(r.GetEventListenerOverview))
+	ws.Route(ws.GET("/{namespace}/customruns").Filter(r.Authorize("list", "CustomRun")).Produces(restful.MIME_JSON, endpoints.MIMENDJSON).To(r.GetCustomRuns))
+	ws.Route(ws.GET("/{namespace}/customruns/{name}").Filter(r.Authorize("get", "CustomRun")).To(r.GetCustomRun))
+	ws.Route(ws.GET("/{namespace}/stepactions").Filter(r.Authorize("list", "StepAction")).Produces(restful.MIME_JSON, endpoints.MIMENDJSON).To(r.GetStepActions))
+	ws.Route(ws.GET("/{namespace}/stepactions/{name}").Filter(r.Authorize("get", "StepAction")).To(r.GetStepAction))
+	ws.Route(ws.POST("/{namespace}/batch-get").To(r.BatchGet))
+	ws.Route(ws.GET("/{namespace}/label-keys").To(r.GetLabelKeys))
+	ws.Route(ws.GET("/{namespace}/{kind}/{name}/can-i").To(r.GetCanI))
+	ws.Route(ws.GET("/{namespace}/{kind}/{name}/history").To(r.GetObjectHistory))
+	ws.Route(ws.GET("/{namespace}/{kind}/{name}/hash").To(r.GetContentHash))
+	ws.Route(ws.POST("/{namespace}/lint/{kind}").To(r.LintResource))
+	ws.Route(ws.GET("/{namespace}/throughput").Filter(r.Authorize("list", "PipelineRun")).To(r.GetThroughput))
+	ws.Route(ws.GET("/{namespace}/pvc-usage").Filter(r.Authorize("list", "PipelineRun")).Filter(r.Authorize("list", "TaskRun")).To(r.GetPVCUsage))
+	ws.Route(ws.GET("/{namespace}/workspaces").Filter(r.Authorize("list", "Pipeline")).Filter(r.Authorize("list", "Task")).To(r.GetWorkspaces))
+	ws.Route(ws.POST("/{namespace}/pipelines/{name}/clone").Filter(r.RequireWriteAccess).Filter(r.Authorize("get", "Pipeline")).To(r.ClonePipeline))
+	ws.Route(ws.GET("/{namespace}/pipelines/{name}/step-stats").Filter(r.Authorize("list", "TaskRun")).To(r.GetPipelineStepStats))
+	ws.Route(ws.GET("/{namespace}/pipelineruns").Filter(r.Authorize("list", "PipelineRun")).Produces(restful.MIME_JSON, endpoints.MIMENDJSON).To(r.GetPipelineRuns))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/blocked").Filter(r.Authorize("list", "PipelineRun")).Filter(r.Authorize("list", "TaskRun")).To(r.GetBlockedPipelineRuns))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/failure-reasons").Filter(r.Authorize("list", "PipelineRun")).To(r.GetPipelineRunFailureReasons))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/waittimes").Filter(r.Authorize("list", "PipelineRun")).To(r.GetPipelineRunWaitTimes))
+	ws.Route(ws.POST("/{namespace}/pipelineruns/cancel").Filter(r.RequireWriteAccess).Filter(r.Authorize("list", "PipelineRun")).Filter(r.Authorize("patch", "PipelineRun")).To(r.CancelPipelineRuns))
+	ws.Route(ws.POST("/{namespace}/pipelineruns/rerun-failed").Filter(r.RequireWriteAccess).Filter(r.Authorize("list", "PipelineRun")).Filter(r.Authorize("create", "PipelineRun")).To(r.RerunFailedPipelineRuns))
+	ws.Route(ws.PATCH("/{namespace}/pipelineruns/labels").Filter(r.RequireWriteAccess).Filter(r.Authorize("patch", "PipelineRun")).To(r.LabelPipelineRuns))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/critical-path").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunCriticalPath))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/drift").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunDrift))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/identity").Filter(r.Authorize("get", "PipelineRun")).Filter(r.Authorize("list", "TaskRun")).To(r.GetPipelineRunIdentity))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/matrix/{task}").Filter(r.Authorize("get", "PipelineRun")).Filter(r.Authorize("list", "TaskRun")).To(r.GetPipelineRunMatrix))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/pipeline").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunPipeline))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/report").Filter(r.Authorize("get", "PipelineRun")).Produces("application/gzip").To(r.GetPipelineRunReport))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/resources").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunResources))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/result-flow").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunResultFlow))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/source").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunSource))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/retries").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunRetries))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/timeline").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunTimeline))
+	ws.Route(ws.DELETE("/{namespace}/pipelineruns/{name}").Filter(r.RequireWriteAccess).Filter(r.Authorize("delete", "PipelineRun")).To(r.DeletePipelineRun))
+	ws.Route(ws.POST("/{namespace}/resolutionrequests/{name}/retry").Filter(r.RequireWriteAccess).Filter(r.Authorize("delete", "ResolutionRequest")).To(r.RetryResolutionRequest))
+	ws.Route(ws.POST("/{namespace}/tasks/{name}/clone").Filter(r.RequireWriteAccess).Filter(r.Authorize("get", "Task")).To(r.CloneTask))
+	ws.Route(ws.GET("/{namespace}/tasks/{name}/reliability").Filter(r.Authorize("list", "TaskRun")).To(r.GetTaskReliability))
+	ws.Route(ws.GET("/{namespace}/tasks/{name}/stepactions").Filter(r.Authorize("get", "Task")).To(r.GetTaskStepActions))
+	ws.Route(ws.POST("/{namespace}/taskruns").Filter(r.RequireWriteAccess).Filter(r.Authorize("create", "TaskRun")).To(r.CreateTaskRun))
+	ws.Route(ws.POST("/{namespace}/taskruns/preview-pod").Filter(r.Authorize("create", "TaskRun")).To(r.PreviewTaskRunPod))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/containers/{container}/logs").Filter(r.Authorize("get", "TaskRun")).Produces("text/plain").To(r.GetTaskRunContainerLogs))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/entrypoint").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunEntrypoint))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/params").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunParams))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/metrics").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunMetrics))
+	ws.Route(ws.GET("/{namespace}/pipelineruns/{name}/effective-params").Filter(r.Authorize("get", "PipelineRun")).To(r.GetPipelineRunParams))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/provenance").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunProvenance))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/scheduling").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunScheduling))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/scheduling-constraints").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunSchedulingConstraints))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/sidecars").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunSidecars))
+	ws.Route(ws.GET("/{namespace}/taskruns/{name}/steps").Filter(r.Authorize("get", "TaskRun")).To(r.GetTaskRunSteps))
+	container.Add(ws)
+}
+
 // Extension is the back-end representation of an extension. A service is an
 // extension when it is in the dashboard namespace with the dashboard label
 // key/value pair. Endpoints are specified with the extension URL annotation
@@ -296,6 +487,7 @@ type Extension struct {
 	Port           string   `json:"port"`
 	DisplayName    string   `json:"displayname"`
 	BundleLocation string   `json:"bundlelocation"`
+	Kinds          []string `json:"kinds,omitempty"`
 	endpoints      []string
 }
 
@@ -309,6 +501,7 @@ func newExtension(extService *corev1.Service) *Extension {
 		Port:           port,
 		DisplayName:    extService.ObjectMeta.Annotations[ExtensionDisplayNameKey],
 		BundleLocation: extService.ObjectMeta.Annotations[ExtensionBundleLocationKey],
+		Kinds:          getExtensionKinds(extService.ObjectMeta.Annotations[ExtensionKindsKey]),
 		endpoints:      getExtensionEndpoints(extService.ObjectMeta.Annotations[ExtensionURLKey]),
 	}
 }
@@ -341,6 +534,18 @@ func getExtensionEndpoints(delimited string) []string {
 	return endpoints
 }
 
+// getExtensionKinds splits the comma separated kinds annotation, dropping
+// empty entries
+func getExtensionKinds(delimited string) []string {
+	var kinds []string
+	for _, kind := range strings.Split(delimited, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
 // extensionPath constructs the extension path (excluding the root) used by
 // restful.Route
 func extensionPath(extName, path string) string {
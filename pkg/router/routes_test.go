@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -25,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	gorillaSocket "github.com/gorilla/websocket"
 	"github.com/tektoncd/dashboard/pkg/endpoints"
 	"github.com/tektoncd/dashboard/pkg/router"
 	. "github.com/tektoncd/dashboard/pkg/router"
@@ -32,6 +34,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -50,6 +53,7 @@ var excludeRoutes []string = []string{
 	"readiness",      // Returns 204
 	"proxy",          // Kube API server has its own standard
 	"properties",     // Pods and namespace will not exist
+	"/v1/namespaces", // Dashboard API endpoints are covered by their own tests
 }
 
 var methodRouteMap = make(map[string][]string) // k, v := HTTP_METHOD, []route
@@ -304,3 +308,104 @@ func TestGetAllExtensions(t *testing.T) {
 		}
 	}
 }
+
+func TestGetExtensionsForKind(t *testing.T) {
+	server, r, _ := testutils.DummyServer()
+	defer server.Close()
+
+	h := router.Register(*r)
+	for name, kinds := range map[string]string{"task-viewer": "Task, ClusterTask", "run-viewer": "PipelineRun"} {
+		h.RegisterExtension(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "tekton-pipelines",
+				Annotations: map[string]string{
+					ExtensionKindsKey: kinds,
+				},
+				UID: types.UID(name),
+			},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "172.30.155.248",
+				Ports:     []corev1.ServicePort{{Port: 8080}},
+			},
+		})
+	}
+	server.Config.Handler = h
+
+	getExtensions := func(kind string) []RedactedExtension {
+		httpReq := testutils.DummyHTTPRequest("GET", fmt.Sprintf("%s/v1/extensions/for-kind/%s", server.URL, kind), nil)
+		response, err := http.DefaultClient.Do(httpReq)
+		if err != nil {
+			t.Fatalf("Error getting extensions for kind %s: %s", kind, err.Error())
+		}
+		var extensions []RedactedExtension
+		if err := json.NewDecoder(response.Body).Decode(&extensions); err != nil {
+			t.Fatalf("Error decoding extensions for kind %s: %v", kind, err)
+		}
+		return extensions
+	}
+
+	extensions := getExtensions("task")
+	if len(extensions) != 1 || extensions[0].Name != "task-viewer" {
+		t.Errorf("Expected only task-viewer for Task, actual %+v", extensions)
+	}
+	if extensions := getExtensions("Condition"); len(extensions) != 0 {
+		t.Errorf("Expected no extensions for Condition, actual %+v", extensions)
+	}
+}
+
+// With a websocket port the websockets are only served by their own router
+func TestSeparateWebsocketPort(t *testing.T) {
+	server, r, _ := testutils.DummyServerWithOptions(endpoints.Options{WebsocketPort: 8081})
+	defer server.Close()
+	websocketServer := httptest.NewServer(router.RegisterWebsockets(*r))
+	defer websocketServer.Close()
+
+	dial := func(serverURL string) (*gorillaSocket.Conn, *http.Response, error) {
+		websocketURL := url.URL{Scheme: "ws", Host: strings.TrimPrefix(serverURL, "http://"), Path: "/v1/websockets/resources"}
+		return gorillaSocket.DefaultDialer.Dial(websocketURL.String(), nil)
+	}
+	if _, response, err := dial(server.URL); err == nil || response == nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected websocket not found on the REST port, actual %v", err)
+	}
+	connection, _, err := dial(websocketServer.URL)
+	if err != nil {
+		t.Fatalf("Expected websocket on the websocket port, actual %v", err)
+	}
+	connection.Close()
+
+	for serverURL, expected := range map[string]int{server.URL: http.StatusOK, websocketServer.URL: http.StatusNotFound} {
+		response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", serverURL+"/health", nil))
+		if err != nil {
+			t.Fatalf("Error getting health: %v", err)
+		}
+		if response.StatusCode != expected {
+			t.Errorf("Expected statusCode %d for health on %s, actual %d", expected, serverURL, response.StatusCode)
+		}
+	}
+}
+
+// The pprof endpoints are only served by the profiling handler
+func TestProfiling(t *testing.T) {
+	server, _, _ := testutils.DummyServer()
+	defer server.Close()
+	profilingServer := httptest.NewServer(router.RegisterProfiling())
+	defer profilingServer.Close()
+
+	response, err := http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", profilingServer.URL+ProfilingRoot, nil))
+	if err != nil {
+		t.Fatalf("Error getting pprof index: %v", err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine") {
+		t.Errorf("Expected the pprof index on the profiling server, actual statusCode %d", response.StatusCode)
+	}
+
+	response, err = http.DefaultClient.Do(testutils.DummyHTTPRequest("GET", server.URL+ProfilingRoot, nil))
+	if err != nil {
+		t.Fatalf("Error getting pprof index: %v", err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected statusCode %d for pprof on the dashboard server, actual %d", http.StatusNotFound, response.StatusCode)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sse writes broadcaster messages as server-sent events, for clients
+// behind proxies that block websocket upgrades
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+)
+
+// DefaultKeepAlive is how long a stream may stay idle before a keepalive
+// comment is sent
+const DefaultKeepAlive = 15 * time.Second
+
+// keepAliveComment is ignored by event stream parsers, it only keeps idle
+// connections open through proxies
+const keepAliveComment = ": keepalive\n\n"
+
+// Writer writes server-sent events to an HTTP response
+type Writer struct {
+	writer  http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewWriter sets the event stream headers on the response, failing if it
+// cannot be flushed after each event
+func NewWriter(writer http.ResponseWriter) (*Writer, error) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming not supported by the response writer")
+	}
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &Writer{writer: writer, flusher: flusher}, nil
+}
+
+// WriteEvent sends a message as an event named after its MessageType with
+// the JSON encoded message as data. Numbered messages are sent with their
+// Sequence as id, browsers send the last one back in the Last-Event-ID header
+// when reconnecting
+func (w *Writer) WriteEvent(data broadcaster.SocketData) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if data.Sequence != 0 {
+		if _, err := fmt.Fprintf(w.writer, "id: %d\n", data.Sequence); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w.writer, "event: %s\ndata: %s\n\n", data.MessageType, encoded); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+// WriteKeepAlive sends a comment that clients ignore
+func (w *Writer) WriteKeepAlive() error {
+	if _, err := fmt.Fprint(w.writer, keepAliveComment); err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+// Stream writes the events received until the channel or done is closed, or
+// a write fails. A keepalive comment is sent whenever no event has been
+// written for keepAlive, DefaultKeepAlive if it is not positive
+func (w *Writer) Stream(events <-chan broadcaster.SocketData, done <-chan struct{}, keepAlive time.Duration) error {
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	timer := time.NewTimer(keepAlive)
+	defer timer.Stop()
+	for {
+		select {
+		case data, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := w.WriteEvent(data); err != nil {
+				return err
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(keepAlive)
+		case <-timer.C:
+			if err := w.WriteKeepAlive(); err != nil {
+				return err
+			}
+			timer.Reset(keepAlive)
+		case <-done:
+			return nil
+		}
+	}
+}
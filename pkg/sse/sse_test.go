@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+)
+
+// event is a parsed server-sent event
+type event struct {
+	id   string
+	name string
+	data string
+}
+
+// parseEventStream splits an event stream into its events the way browsers
+// do, counting the comment lines they ignore
+func parseEventStream(t *testing.T, body io.Reader) ([]event, int) {
+	var events []event
+	comments := 0
+	current := event{}
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if current != (event{}) {
+				events = append(events, current)
+			}
+			current = event{}
+		case strings.HasPrefix(line, ":"):
+			comments++
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data += strings.TrimPrefix(line, "data: ")
+		default:
+			t.Errorf("Unexpected line in event stream: %q", line)
+		}
+	}
+	return events, comments
+}
+
+// Keepalive comments are sent while idle and ignored by the event parser
+func TestStreamKeepAlive(t *testing.T) {
+	events := make(chan broadcaster.SocketData)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		w, err := NewWriter(writer)
+		if err != nil {
+			t.Errorf("Error creating writer: %s", err)
+			return
+		}
+		w.Stream(events, request.Context().Done(), 20*time.Millisecond)
+	}))
+	defer server.Close()
+
+	go func() {
+		events <- broadcaster.SocketData{MessageType: broadcaster.TaskCreated, Payload: "first"}
+		time.Sleep(100 * time.Millisecond)
+		events <- broadcaster.SocketData{MessageType: broadcaster.TaskDeleted, Payload: "second", Sequence: 2}
+		close(events)
+	}()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error getting event stream: %s", err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Expected Content-Type text/event-stream, actual %s", contentType)
+	}
+	received, comments := parseEventStream(t, response.Body)
+	if comments < 2 {
+		t.Errorf("Expected keepalive comments while idle, actual %d", comments)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 events, actual %+v", received)
+	}
+	for i, expected := range []broadcaster.SocketData{
+		{MessageType: broadcaster.TaskCreated, Payload: "first"},
+		{MessageType: broadcaster.TaskDeleted, Payload: "second", Sequence: 2},
+	} {
+		if received[i].name != string(expected.MessageType) {
+			t.Errorf("Expected event %s, actual %s", expected.MessageType, received[i].name)
+		}
+		// Only numbered messages have an id
+		expectedID := ""
+		if expected.Sequence != 0 {
+			expectedID = fmt.Sprint(expected.Sequence)
+		}
+		if received[i].id != expectedID {
+			t.Errorf("Expected event id %q, actual %q", expectedID, received[i].id)
+		}
+		var data broadcaster.SocketData
+		if err := json.Unmarshal([]byte(received[i].data), &data); err != nil {
+			t.Fatalf("Error decoding event data: %s", err)
+		}
+		if data.MessageType != expected.MessageType || data.Payload != expected.Payload {
+			t.Errorf("Expected data %+v, actual %+v", expected, data)
+		}
+	}
+}
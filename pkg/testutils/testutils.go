@@ -66,7 +66,14 @@ func DummyHTTPRequest(method string, url string, body io.Reader) *http.Request {
 
 // DummyServer returns a httptest server of the Tekton Dashboard
 func DummyServer() (*httptest.Server, *endpoints.Resource, string) {
+	return DummyServerWithOptions(endpoints.Options{})
+}
+
+// DummyServerWithOptions returns a httptest server of the Tekton Dashboard
+// configured with the given options
+func DummyServerWithOptions(options endpoints.Options) (*httptest.Server, *endpoints.Resource, string) {
 	resource := DummyResource()
+	resource.Options = options
 	// Create subscriber to wait for controller to detect created namespace
 	subscriber, _ := endpoints.ResourcesBroadcaster.Subscribe()
 
@@ -79,11 +86,14 @@ func DummyServer() (*httptest.Server, *endpoints.Resource, string) {
 
 	// K8s signals only allows for a single channel, which will panic when executed twice
 	// There should be no os signals for testing purposes
-	routerHandler := router.Register(*resource)
 	logging.Log.Info("Creating controllers")
 	stopCh := make(<-chan struct{})
 	resyncDur := time.Second * 30
-	controllers.StartTektonControllers(resource.DynamicClient, resyncDur, "", stopCh)
+	resource.Listers = controllers.StartTektonControllers(resource.DynamicClient, resyncDur, "", options.GetTektonVersion(), stopCh)
+	resource.PipelineRunLister = resource.Listers["PipelineRun"]
+	resource.Listers["CustomRun"] = controllers.StartCustomRunController(resource.DynamicClient, resyncDur, "", options.GetCustomRunGVR(), stopCh)
+	resource.Listers["StepAction"] = controllers.StartStepActionController(resource.DynamicClient, resyncDur, "", options.GetStepActionGVR(), stopCh)
+	routerHandler := router.Register(*resource)
 	controllers.StartKubeControllers(resource.K8sClient, resyncDur, "", false, routerHandler, stopCh)
 	// Wait until namespace is detected by informer and functionally "dropped" since the informer will be eventually consistent
 	timeout := time.After(5 * time.Second)
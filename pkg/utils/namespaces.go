@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NamespaceDenied reports whether a namespace is one of the denied namespaces
+func NamespaceDenied(denied []string, namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+	for _, d := range denied {
+		if d == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// InDeniedNamespace reports whether an object, or the object of a tombstone,
+// belongs to one of the denied namespaces or is one of them
+func InDeniedNamespace(denied []string, obj interface{}) bool {
+	if len(denied) == 0 {
+		return false
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	if NamespaceDenied(denied, object.GetNamespace()) {
+		return true
+	}
+	switch o := obj.(type) {
+	case *corev1.Namespace:
+		return NamespaceDenied(denied, o.Name)
+	case *unstructured.Unstructured:
+		return o.GetKind() == "Namespace" && NamespaceDenied(denied, o.GetName())
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Codes of the errors returned instead of upgrading a websocket request
+const (
+	CodeBadRequest      = "BadRequest"
+	CodeBadOrigin       = "BadOrigin"
+	CodeUnauthorized    = "Unauthorized"
+	CodeForbidden       = "Forbidden"
+	CodeNotFound        = "NotFound"
+	CodeTooManyRequests = "TooManyRequests"
+	CodeOverloaded      = "Overloaded"
+	CodeInternalError   = "InternalError"
+)
+
+// upgradeErrorCodes are the codes of the HTTP statuses upgrades are rejected with
+var upgradeErrorCodes = map[int]string{
+	http.StatusBadRequest:         CodeBadRequest,
+	http.StatusUnauthorized:       CodeUnauthorized,
+	http.StatusForbidden:          CodeForbidden,
+	http.StatusNotFound:           CodeNotFound,
+	http.StatusTooManyRequests:    CodeTooManyRequests,
+	http.StatusServiceUnavailable: CodeOverloaded,
+}
+
+// UpgradeError is the JSON body of a websocket request rejected before it was
+// upgraded, the code lets clients react without parsing the message
+type UpgradeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// RespondUpgradeError rejects a websocket request with a JSON UpgradeError
+// whose code is derived from the status
+func RespondUpgradeError(writer http.ResponseWriter, status int, message string) {
+	code, ok := upgradeErrorCodes[status]
+	if !ok {
+		code = CodeInternalError
+	}
+	respondUpgradeError(writer, status, code, message)
+}
+
+func respondUpgradeError(writer http.ResponseWriter, status int, code, message string) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Sec-Websocket-Version", "13")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(UpgradeError{Code: code, Message: message})
+}
+
+// IsUpgradeRequest returns whether the request asks to upgrade to a websocket
+func IsUpgradeRequest(request *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(request)
+}
+
+// checkUpgrade validates a websocket handshake before upgrading, responding
+// with an UpgradeError and returning false if it would fail
+func checkUpgrade(writer http.ResponseWriter, request *http.Request) bool {
+	if request.Method != http.MethodGet || !websocket.IsWebSocketUpgrade(request) {
+		respondUpgradeError(writer, http.StatusBadRequest, CodeBadRequest, "not a websocket handshake")
+		return false
+	}
+	if !sameOrigin(request) {
+		respondUpgradeError(writer, http.StatusForbidden, CodeBadOrigin, "request origin not allowed")
+		return false
+	}
+	return true
+}
+
+// sameOrigin applies the default origin check of the upgrader, requests
+// without an Origin header are not from browsers and are allowed
+func sameOrigin(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, request.Host)
+}
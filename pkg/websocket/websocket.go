@@ -15,7 +15,9 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	restful "github.com/emicklei/go-restful"
@@ -24,78 +26,307 @@ import (
 	logging "github.com/tektoncd/dashboard/pkg/logging"
 )
 
+// Default I/O buffer sizes in bytes for upgraded connections. Clients mostly
+// send control frames and receive small JSON frames, so the buffers are kept
+// small to limit memory per connection
+const (
+	DefaultReadBufferSize  = 1024
+	DefaultWriteBufferSize = 4096
+)
+
+// Defaults of the keepalive of upgraded connections, see SetKeepalive
+const (
+	DefaultPingInterval = 10 * time.Second
+	DefaultPongWait     = 30 * time.Second
+)
+
+// writeWait is how long a write may block on a client that is not reading
+// before the connection is closed
+const writeWait = 10 * time.Second
+
+var (
+	keepaliveMutex sync.RWMutex
+	pingInterval   = DefaultPingInterval
+	pongWait       = DefaultPongWait
+)
+
+// SetKeepalive sets how often connections upgraded afterwards are pinged and
+// how long they may go without answering before they are closed as lost, so
+// half-open connections are reaped. pongWait should exceed pingInterval
+func SetKeepalive(interval, wait time.Duration) {
+	keepaliveMutex.Lock()
+	defer keepaliveMutex.Unlock()
+
+	pingInterval = interval
+	pongWait = wait
+}
+
+func keepalive() (time.Duration, time.Duration) {
+	keepaliveMutex.RLock()
+	defer keepaliveMutex.RUnlock()
+	return pingInterval, pongWait
+}
+
 // UpgradeToWebsocket attempts to upgrade connection from HTTP(S) to WS(S)
-func UpgradeToWebsocket(request *restful.Request, response *restful.Response) (*websocket.Conn, error) {
+// using the given buffer sizes, or the defaults when a size is not positive.
+// Failures are written to the response as a JSON UpgradeError
+func UpgradeToWebsocket(request *restful.Request, response *restful.Response, readBufferSize, writeBufferSize int) (*websocket.Conn, error) {
 	var writer http.ResponseWriter = response
+	if !checkUpgrade(writer, request.Request) {
+		return nil, errUpgradeRejected
+	}
 	logging.Log.Debug("Upgrading connection to websocket...")
 	// Handles writing error to response
-	upgrader := websocket.Upgrader{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 4096,
-	}
+	upgrader := newUpgrader(readBufferSize, writeBufferSize)
 	connection, err := upgrader.Upgrade(writer, request.Request, nil)
 	return connection, err
 }
 
+var errUpgradeRejected = errors.New("websocket handshake rejected")
+
+func newUpgrader(readBufferSize, writeBufferSize int) websocket.Upgrader {
+	if readBufferSize <= 0 {
+		readBufferSize = DefaultReadBufferSize
+	}
+	if writeBufferSize <= 0 {
+		writeBufferSize = DefaultWriteBufferSize
+	}
+	return websocket.Upgrader{
+		ReadBufferSize:  readBufferSize,
+		WriteBufferSize: writeBufferSize,
+		Error: func(writer http.ResponseWriter, request *http.Request, status int, reason error) {
+			RespondUpgradeError(writer, status, reason.Error())
+		},
+	}
+}
+
 // WriteOnlyWebsocket discards text messages from the peer connection
-func WriteOnlyWebsocket(connection *websocket.Conn, b *broadcaster.Broadcaster) {
+func WriteOnlyWebsocket(connection *websocket.Conn, b *broadcaster.Broadcaster[broadcaster.SocketData], opts ...broadcaster.SubscribeOption[broadcaster.SocketData]) {
 	// The underlying connection is never closed so this cannot error
-	subscriber, _ := b.Subscribe()
-	go readControl(connection, b, subscriber)
-	write(connection, subscriber)
+	subscriber, _ := b.Subscribe(opts...)
+	go readControl(connection, func() {
+		b.Unsubscribe(subscriber)
+	})
+	write(connection, subscriber, nil)
+}
+
+// ControlHandler handles a text message sent by the client of a subscriber,
+// a non-nil reply is sent back to that client only
+type ControlHandler func(subscriber *broadcaster.Subscriber[broadcaster.SocketData], message []byte) *broadcaster.SocketData
+
+// ControlledWebsocket behaves like WriteOnlyWebsocket but passes text messages
+// from the peer connection to handle, e.g. to change the subscriber's filter
+func ControlledWebsocket(connection *websocket.Conn, b *broadcaster.Broadcaster[broadcaster.SocketData], handle ControlHandler, opts ...broadcaster.SubscribeOption[broadcaster.SocketData]) {
+	subscriber, _ := b.Subscribe(opts...)
+	replies := make(chan broadcaster.SocketData)
+	go readMessages(connection, func(message []byte) {
+		if reply := handle(subscriber, message); reply != nil {
+			// Replies go through the writer as connections allow one writer at a time
+			select {
+			case replies <- *reply:
+			case <-subscriber.UnsubChan():
+			}
+		}
+	}, func() {
+		b.Unsubscribe(subscriber)
+	})
+	write(connection, subscriber, replies)
+}
+
+// WritePeriodic sends the data returned by next straight away then on every
+// interval until the client goes away. Returning false from next skips a tick
+func WritePeriodic(connection *websocket.Conn, interval time.Duration, next func() (broadcaster.SocketData, bool)) {
+	lost := make(chan struct{})
+	go readControl(connection, func() {
+		close(lost)
+	})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pingInterval, _ := keepalive()
+	pings := time.NewTicker(pingInterval)
+	defer pings.Stop()
+	if socketData, ok := next(); ok && !websocketSend(connection, socketData) {
+		return
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if socketData, ok := next(); ok && !websocketSend(connection, socketData) {
+				return
+			}
+		case <-pings.C:
+			if !writePing(connection) {
+				return
+			}
+		case <-lost:
+			return
+		}
+	}
+}
+
+// MonitorConnection keeps the connection alive with ping/pong and calls onLost
+// once the client has gone away, for handlers writing their own messages
+func MonitorConnection(connection *websocket.Conn, onLost func()) {
+	lost := make(chan struct{})
+	go readControl(connection, func() {
+		close(lost)
+		onLost()
+	})
+	// Pings, and the close frame sent when they fail, are control frames which
+	// may be written alongside the handler
+	go func() {
+		pingInterval, _ := keepalive()
+		pings := time.NewTicker(pingInterval)
+		defer pings.Stop()
+		for {
+			select {
+			case <-pings.C:
+				if !writePing(connection) {
+					return
+				}
+			case <-lost:
+				return
+			}
+		}
+	}()
+}
+
+// Send writes data over the connection, closing it and returning false on failure
+func Send(connection *websocket.Conn, data broadcaster.SocketData) bool {
+	return websocketSend(connection, data)
 }
 
-// ping over the socket with a given deadline; if there's an error, close
-func writePing(connection *websocket.Conn, deadline time.Time) {
-	if err := connection.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+// ping over the socket; if there's an error, close and return false
+func writePing(connection *websocket.Conn) bool {
+	if err := connection.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
 		ReportClosing(connection)
+		return false
 	}
+	return true
 }
 
-// readControl will unsubscribe on connection failures
-func readControl(connection *websocket.Conn, b *broadcaster.Broadcaster, s *broadcaster.Subscriber) {
-	// Connection lifecycle handler
+// readControl will call onLost on connection failures
+func readControl(connection *websocket.Conn, onLost func()) {
+	readMessages(connection, nil, onLost)
+}
+
+// readMessages passes text messages to onMessage, if set, and will call
+// onLost on connection failures. The writer pings the client, a client that
+// has not answered within the pong wait is lost and its connection closed
+func readMessages(connection *websocket.Conn, onMessage func([]byte), onLost func()) {
+	_, pongWait := keepalive()
+	connection.SetReadDeadline(time.Now().Add(pongWait))
 	connection.SetPongHandler(func(string) error {
 		// Extend deadline to prevent expiration
-		deadline := time.Now().Add(time.Second * 2)
-		connection.SetReadDeadline(deadline)
-		// Cut down on ping/pong traffic
-		time.Sleep(time.Second)
-		// Ellicit another ping
-		writePing(connection, deadline)
-		return nil
+		return connection.SetReadDeadline(time.Now().Add(pongWait))
 	})
-	initialDeadline := time.Now().Add(time.Second)
-	connection.SetReadDeadline(initialDeadline)
-	// Kick off cycle
-	writePing(connection, initialDeadline)
 	for {
 		// Connection has either decayed or close has been requested from server side
-		if _, _, err := connection.ReadMessage(); err != nil {
+		messageType, message, err := connection.ReadMessage()
+		if err != nil {
 			logging.Log.Error("websocket connection to client lost: ", err)
-			b.Unsubscribe(s)
+			onLost()
+			// Unblocks a writer stuck on a half-open connection
+			connection.Close()
 			return
 		}
+		if onMessage != nil && messageType == websocket.TextMessage {
+			onMessage(message)
+		}
+	}
+}
+
+// ReportOverloaded tells the client to try again later then closes connection
+func ReportOverloaded(connection *websocket.Conn) {
+	deadline := time.Now().Add(time.Second)
+	connection.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server overloaded"), deadline)
+	connection.Close()
+}
+
+// ReportTooSlow tells a client that did not keep up with its messages why it
+// is disconnected then closes connection
+func ReportTooSlow(connection *websocket.Conn) {
+	deadline := time.Now().Add(time.Second)
+	connection.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "client too slow"), deadline)
+	connection.Close()
+}
+
+// reportEvicted closes the connection of an evicted subscriber. Subscribers
+// that did not keep up with their messages, within the send timeout or their
+// buffer under the Disconnect overflow policy, are told they are too slow and
+// the others that the server is overloaded
+func reportEvicted(connection *websocket.Conn, subscriber *broadcaster.Subscriber[broadcaster.SocketData]) {
+	if subscriber.TooSlow() || subscriber.Overflowed() {
+		ReportTooSlow(connection)
+	} else if subscriber.Evicted() {
+		ReportOverloaded(connection)
 	}
 }
 
-// ReportClosing sends close to client then closes connection
+// ReportClosing sends close to client then closes connection. The close frame
+// is written as a control message, so it is safe alongside the handler's writes
 func ReportClosing(connection *websocket.Conn) {
-	connection.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	deadline := time.Now().Add(time.Second)
+	connection.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
 	connection.Close()
 }
 
-// Send data over the connection using the subscriber channel, if there's a failure we return
-func write(connection *websocket.Conn, subscriber *broadcaster.Subscriber) {
+// Send data over the connection using the subscriber channel along with any
+// replies to the client and keepalive pings, if there's a failure we return.
+// Once the subscriber's MaxEvents have been sent a MaxEventsReached message is
+// sent and the connection closed. The compacted or replayed initial events of
+// a subscriber are sent first, followed by CompactionComplete or the end of
+// the replay, and do not count towards MaxEvents. An EventsDropped message
+// with the number of messages dropped so far precedes the first message sent
+// after the subscriber's buffer overflowed. Evicted subscribers are closed
+// with the reason given by reportEvicted
+func write(connection *websocket.Conn, subscriber *broadcaster.Subscriber[broadcaster.SocketData], replies <-chan broadcaster.SocketData) {
+	if initial := subscriber.Initial(); initial != nil {
+		for _, socketData := range initial {
+			if !websocketSend(connection, socketData) {
+				return
+			}
+		}
+		if !websocketSend(connection, subscriber.InitialComplete()) {
+			return
+		}
+	}
 	subChan := subscriber.SubChan()
 	unsubChan := subscriber.UnsubChan()
+	maxEvents := subscriber.MaxEvents()
+	sent := 0
+	var reported uint64
+	pingInterval, _ := keepalive()
+	pings := time.NewTicker(pingInterval)
+	defer pings.Stop()
 	for {
 		select {
 		case socketData := <-subChan:
+			if dropped := subscriber.Dropped(); dropped > reported {
+				reported = dropped
+				if !websocketSend(connection, broadcaster.SocketData{MessageType: broadcaster.EventsDropped, Payload: dropped}) {
+					return
+				}
+			}
 			if !websocketSend(connection, socketData) {
 				return
 			}
+			if sent++; maxEvents > 0 && sent >= maxEvents {
+				if websocketSend(connection, broadcaster.SocketData{MessageType: broadcaster.MaxEventsReached, Payload: maxEvents}) {
+					ReportClosing(connection)
+				}
+				return
+			}
+		case reply := <-replies:
+			if !websocketSend(connection, reply) {
+				return
+			}
+		case <-pings.C:
+			if !writePing(connection) {
+				return
+			}
 		case <-unsubChan:
+			reportEvicted(connection, subscriber)
 			return
 		}
 	}
@@ -109,6 +340,7 @@ func websocketSend(connection *websocket.Conn, data broadcaster.SocketData) bool
 		ReportClosing(connection)
 		return false
 	}
+	connection.SetWriteDeadline(time.Now().Add(writeWait))
 	if err := connection.WriteMessage(websocket.TextMessage, payload); err != nil {
 		logging.Log.Errorf("could not write the message to the websocket client connection, error: %s", err)
 		ReportClosing(connection)
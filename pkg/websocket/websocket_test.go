@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The Tekton Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+		http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	broadcaster "github.com/tektoncd/dashboard/pkg/broadcaster"
+)
+
+// Configured buffer sizes are applied to the upgrader, unset sizes use the defaults
+func TestUpgraderBufferSizes(t *testing.T) {
+	upgrader := newUpgrader(512, 2048)
+	if upgrader.ReadBufferSize != 512 || upgrader.WriteBufferSize != 2048 {
+		t.Errorf("Expected buffer sizes 512/2048, actual %d/%d", upgrader.ReadBufferSize, upgrader.WriteBufferSize)
+	}
+
+	upgrader = newUpgrader(0, -1)
+	if upgrader.ReadBufferSize != DefaultReadBufferSize || upgrader.WriteBufferSize != DefaultWriteBufferSize {
+		t.Errorf("Expected default buffer sizes %d/%d, actual %d/%d", DefaultReadBufferSize, DefaultWriteBufferSize, upgrader.ReadBufferSize, upgrader.WriteBufferSize)
+	}
+}
+
+// Handshakes that would fail are rejected with a JSON error before upgrading
+func TestCheckUpgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		upgrade bool
+		status  int
+		code    string
+	}{
+		{"same origin", "http://example.com", true, http.StatusOK, ""},
+		{"no origin", "", true, http.StatusOK, ""},
+		{"cross origin", "http://other.com", true, http.StatusForbidden, CodeBadOrigin},
+		{"not an upgrade", "", false, http.StatusBadRequest, CodeBadRequest},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", "http://example.com/v1/websockets/resources", nil)
+		if test.upgrade {
+			request.Header.Set("Connection", "Upgrade")
+			request.Header.Set("Upgrade", "websocket")
+		}
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+		recorder := httptest.NewRecorder()
+		if ok := checkUpgrade(recorder, request); ok != (test.code == "") {
+			t.Errorf("%s: expected accepted %t, actual %t", test.name, test.code == "", ok)
+		}
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected status %d, actual %d", test.name, test.status, recorder.Code)
+		}
+		if test.code == "" {
+			continue
+		}
+		var upgradeError UpgradeError
+		if err := json.NewDecoder(recorder.Body).Decode(&upgradeError); err != nil {
+			t.Fatalf("%s: error decoding upgrade error: %s", test.name, err)
+		}
+		if upgradeError.Code != test.code {
+			t.Errorf("%s: expected code %s, actual %s", test.name, test.code, upgradeError.Code)
+		}
+	}
+}
+
+// Clients that stop answering pings are closed and unsubscribed within the
+// pong wait, clients answering them stay connected
+func TestKeepalive(t *testing.T) {
+	SetKeepalive(50*time.Millisecond, 200*time.Millisecond)
+	defer SetKeepalive(DefaultPingInterval, DefaultPongWait)
+
+	c := make(chan broadcaster.SocketData)
+	b := broadcaster.NewBroadcaster(c)
+	defer close(c)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		upgrader := newUpgrader(0, 0)
+		connection, err := upgrader.Upgrade(writer, request, nil)
+		if err != nil {
+			t.Errorf("Error upgrading: %s", err)
+			return
+		}
+		WriteOnlyWebsocket(connection, b.Broadcaster)
+	}))
+	defer server.Close()
+
+	dial := func(answerPings bool) <-chan error {
+		connection, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("Dial error: %s", err)
+		}
+		if !answerPings {
+			connection.SetPingHandler(func(string) error {
+				return nil
+			})
+		}
+		lost := make(chan error, 1)
+		go func() {
+			defer connection.Close()
+			for {
+				if _, _, err := connection.ReadMessage(); err != nil {
+					lost <- err
+					return
+				}
+			}
+		}()
+		return lost
+	}
+	alive := dial(true)
+	halfOpen := dial(false)
+
+	select {
+	case <-halfOpen:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the connection not answering pings to be closed")
+	}
+	deadline := time.Now().Add(time.Second)
+	for b.PoolSize() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if size := b.PoolSize(); size != 1 {
+		t.Fatalf("Expected pool size 1, actual %d", size)
+	}
+	select {
+	case err := <-alive:
+		t.Fatalf("Expected the connection answering pings to stay open, actual %s", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// Evicted clients are told why they were closed, a client that overflowed its
+// buffer under the disconnect policy fell behind rather than the server being
+// overloaded
+func TestReportEvicted(t *testing.T) {
+	tests := []struct {
+		name   string
+		evict  func(b *broadcaster.SocketBroadcaster, c chan broadcaster.SocketData) *broadcaster.Subscriber[broadcaster.SocketData]
+		code   int
+		reason string
+	}{
+		{"overflowed", func(b *broadcaster.SocketBroadcaster, c chan broadcaster.SocketData) *broadcaster.Subscriber[broadcaster.SocketData] {
+			subscriber, _ := b.Subscribe(broadcaster.WithBuffer[broadcaster.SocketData](1, broadcaster.Disconnect))
+			for i := 0; i < 2; i++ {
+				c <- broadcaster.SocketData{MessageType: broadcaster.TaskUpdated}
+			}
+			return subscriber
+		}, websocket.ClosePolicyViolation, "client too slow"},
+		{"over limit", func(b *broadcaster.SocketBroadcaster, c chan broadcaster.SocketData) *broadcaster.Subscriber[broadcaster.SocketData] {
+			first, _ := b.Subscribe()
+			second, _ := b.Subscribe()
+			b.SetPoolLimit(1)
+			if first.Evicted() {
+				return first
+			}
+			return second
+		}, websocket.CloseTryAgainLater, "server overloaded"},
+	}
+	for _, test := range tests {
+		c := make(chan broadcaster.SocketData)
+		b := broadcaster.NewBroadcaster(c)
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			upgrader := newUpgrader(0, 0)
+			connection, err := upgrader.Upgrade(writer, request, nil)
+			if err != nil {
+				t.Errorf("Error upgrading: %s", err)
+				return
+			}
+			subscriber := test.evict(b, c)
+			<-subscriber.UnsubChan()
+			reportEvicted(connection, subscriber)
+		}))
+
+		connection, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("%s: dial error: %s", test.name, err)
+		}
+		connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err = connection.ReadMessage()
+		closeError, ok := err.(*websocket.CloseError)
+		if !ok || closeError.Code != test.code || closeError.Text != test.reason {
+			t.Errorf("%s: expected close %d %q, actual %v", test.name, test.code, test.reason, err)
+		}
+		connection.Close()
+		server.Close()
+		close(c)
+	}
+}
+
+// Closing a connection while the handler is writing to it, as when pings fail
+// or the client goes away, is reported with a normal closure
+func TestReportClosingWhileWriting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		upgrader := newUpgrader(0, 0)
+		connection, err := upgrader.Upgrade(writer, request, nil)
+		if err != nil {
+			t.Errorf("Error upgrading: %s", err)
+			return
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			ReportClosing(connection)
+		}()
+		for Send(connection, broadcaster.SocketData{MessageType: broadcaster.TaskUpdated}) {
+		}
+	}))
+	defer server.Close()
+
+	connection, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial error: %s", err)
+	}
+	defer connection.Close()
+	connection.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err = connection.ReadMessage(); err != nil {
+			break
+		}
+	}
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("Expected close %d, actual %v", websocket.CloseNormalClosure, err)
+	}
+}